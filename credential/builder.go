@@ -1,9 +1,11 @@
 package credential
 
 import (
+	goerrors "errors"
 	"fmt"
 	"net/url"
 	"reflect"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -16,8 +18,11 @@ type IDValue string // IDValue represents different types of ID values for build
 
 const (
 	VerifiableCredentialsLinkedDataContext string = "https://www.w3.org/2018/credentials/v1"
-	VerifiableCredentialType               string = "VerifiableCredential"
-	VerifiableCredentialIDProperty         string = "id"
+	// VerifiableCredentialsV2LinkedDataContext is the base context of v2 of the data model
+	// https://www.w3.org/TR/vc-data-model-2.0/#contexts
+	VerifiableCredentialsV2LinkedDataContext string = "https://www.w3.org/ns/credentials/v2"
+	VerifiableCredentialType                 string = "VerifiableCredential"
+	VerifiableCredentialIDProperty           string = "id"
 	// VerifiableCredentialJSONSchemaProperty as defined by https://www.w3.org/TR/vc-json-schema/#jsonschemacredential
 	VerifiableCredentialJSONSchemaProperty string = "jsonSchema"
 	VerifiablePresentationType             string = "VerifiablePresentation"
@@ -263,6 +268,96 @@ func (vcb *VerifiableCredentialBuilder) SetEvidence(evidence []any) error {
 	return nil
 }
 
+// CredentialBuilder constructs a verifiable credential with fluent methods, as in
+//
+//	NewCredentialBuilder().WithIssuer(issuer).WithType("AlumniCredential").WithSubject(subject).Build()
+//
+// Unlike the VerifiableCredentialBuilder, its methods do not return errors: each error is kept, and Build returns
+// them all at once, with those of validating the credential, so that every mistake can be fixed in one go.
+type CredentialBuilder struct {
+	builder VerifiableCredentialBuilder
+	errs    []error
+}
+
+// NewCredentialBuilder returns a builder of a credential with the VerifiableCredential type and the credentials
+// context, a random id, and an issuanceDate of now
+func NewCredentialBuilder() *CredentialBuilder {
+	return &CredentialBuilder{builder: NewVerifiableCredentialBuilder(GenerateIDValue)}
+}
+
+// WithContext adds the JSON-LD contexts, after the credentials context, which must be absolute URLs
+func (cb *CredentialBuilder) WithContext(contexts ...string) *CredentialBuilder {
+	for _, context := range contexts {
+		if parsed, err := url.Parse(context); err != nil || !parsed.IsAbs() {
+			cb.errs = append(cb.errs, fmt.Errorf("context<%s> is not an absolute URL", context))
+		}
+	}
+	if err := cb.builder.AddContext(contexts); err != nil {
+		cb.errs = append(cb.errs, err)
+	}
+	return cb
+}
+
+// WithType adds the types, after the VerifiableCredential type
+func (cb *CredentialBuilder) WithType(types ...string) *CredentialBuilder {
+	for _, t := range types {
+		if t == "" {
+			cb.errs = append(cb.errs, errors.New("type cannot be empty"))
+		}
+	}
+	if err := cb.builder.AddType(types); err != nil {
+		cb.errs = append(cb.errs, err)
+	}
+	return cb
+}
+
+// WithSubject sets the credential subject
+func (cb *CredentialBuilder) WithSubject(subject CredentialSubject) *CredentialBuilder {
+	if len(subject) == 0 {
+		cb.errs = append(cb.errs, errors.New("credential subject cannot be empty"))
+	}
+	if err := cb.builder.SetCredentialSubject(subject); err != nil {
+		cb.errs = append(cb.errs, err)
+	}
+	return cb
+}
+
+// WithIssuer sets the issuer, a URI or an object with an id property, as for SetIssuer
+func (cb *CredentialBuilder) WithIssuer(issuer any) *CredentialBuilder {
+	if err := cb.builder.SetIssuer(issuer); err != nil {
+		cb.errs = append(cb.errs, err)
+	}
+	return cb
+}
+
+// WithExpiry sets the expirationDate, which must be after the issuanceDate
+func (cb *CredentialBuilder) WithExpiry(expiry time.Time) *CredentialBuilder {
+	if err := cb.builder.SetExpirationDate(expiry.UTC().Format(time.RFC3339)); err != nil {
+		cb.errs = append(cb.errs, err)
+	}
+	return cb
+}
+
+// Build returns the credential, ready to sign, or an error joining every error of the builder's methods and of
+// validating the credential, which must have an issuer and a subject
+func (cb *CredentialBuilder) Build() (*VerifiableCredential, error) {
+	errs := cb.errs
+	cred, err := cb.builder.Build()
+	if err != nil {
+		errs = append(errs, err)
+	} else if cred.ExpirationDate != "" {
+		issued, issuedErr := time.Parse(time.RFC3339, cred.IssuanceDate)
+		expires, expiresErr := time.Parse(time.RFC3339, cred.ExpirationDate)
+		if issuedErr == nil && expiresErr == nil && !expires.After(issued) {
+			errs = append(errs, fmt.Errorf("expiry<%s> must be after issuance<%s>", cred.ExpirationDate, cred.IssuanceDate))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Wrap(goerrors.Join(errs...), "building credential")
+	}
+	return cred, nil
+}
+
 // VerifiablePresentationBuilder uses the builder pattern to construct a verifiable presentation
 type VerifiablePresentationBuilder struct {
 	// contexts and types are kept to avoid having cast to/from any values
@@ -2,10 +2,12 @@ package credential
 
 import (
 	"testing"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/util"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCredential(t *testing.T) {
@@ -256,6 +258,55 @@ func TestCredentialBuilder(t *testing.T) {
 	assert.Equal(t, terms, cred.TermsOfUse)
 }
 
+func TestFluentCredentialBuilder(t *testing.T) {
+	subject := CredentialSubject{"id": "did:example:456", "alumniOf": "Example University"}
+
+	t.Run("builds a credential ready to sign", func(tt *testing.T) {
+		expiry := time.Now().Add(time.Hour)
+		cred, err := NewCredentialBuilder().
+			WithContext("https://www.w3.org/2018/credentials/examples/v1").
+			WithType("AlumniCredential").
+			WithIssuer("did:example:123").
+			WithSubject(subject).
+			WithExpiry(expiry).
+			Build()
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{VerifiableCredentialsLinkedDataContext, "https://www.w3.org/2018/credentials/examples/v1"}, cred.Context)
+		assert.Equal(tt, []string{VerifiableCredentialType, "AlumniCredential"}, cred.Type)
+		assert.Equal(tt, "did:example:123", cred.IssuerID())
+		assert.Equal(tt, subject, cred.CredentialSubject)
+		assert.Equal(tt, expiry.UTC().Format(time.RFC3339), cred.ExpirationDate)
+		assert.NotEmpty(tt, cred.ID)
+		assert.True(tt, util.IsRFC3339Timestamp(cred.IssuanceDate))
+		assert.NoError(tt, cred.IsValid())
+	})
+
+	t.Run("returns every error at once", func(tt *testing.T) {
+		_, err := NewCredentialBuilder().
+			WithContext("not a url").
+			WithType("").
+			WithSubject(CredentialSubject{}).
+			WithExpiry(time.Now().Add(-time.Hour)).
+			Build()
+		require.Error(tt, err)
+		assert.Contains(tt, err.Error(), "context<not a url> is not an absolute URL")
+		assert.Contains(tt, err.Error(), "type cannot be empty")
+		assert.Contains(tt, err.Error(), "credential subject cannot be empty")
+		assert.Contains(tt, err.Error(), "credential not ready to be built")
+	})
+
+	t.Run("expiry before issuance", func(tt *testing.T) {
+		_, err := NewCredentialBuilder().WithIssuer("did:example:123").WithSubject(subject).
+			WithExpiry(time.Now().Add(-time.Hour)).Build()
+		assert.ErrorContains(tt, err, "must be after issuance")
+	})
+
+	t.Run("issuer object without an id", func(tt *testing.T) {
+		_, err := NewCredentialBuilder().WithIssuer(map[string]any{"name": "Example University"}).WithSubject(subject).Build()
+		assert.ErrorContains(tt, err, "issuer object did not contain `id` property")
+	})
+}
+
 func TestVerifiablePresentationBuilder(t *testing.T) {
 	badBuilder := VerifiablePresentationBuilder{}
 	_, err := badBuilder.Build()
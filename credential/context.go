@@ -0,0 +1,112 @@
+package credential
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidContext is returned when the @context of a credential or presentation does not begin with the base
+// context of its data model
+var ErrInvalidContext = errors.New("invalid @context")
+
+// DataModelVersion is a version of the Verifiable Credentials Data Model
+type DataModelVersion int
+
+const (
+	DataModelV1 DataModelVersion = 1
+	DataModelV2 DataModelVersion = 2
+)
+
+// BaseContext returns the context the @context of credentials and presentations of the version must begin with
+func (v DataModelVersion) BaseContext() string {
+	if v == DataModelV2 {
+		return VerifiableCredentialsV2LinkedDataContext
+	}
+	return VerifiableCredentialsLinkedDataContext
+}
+
+// v2Properties are properties only credentials of the v2 data model have
+var v2Properties = []string{"validFrom", "validUntil"}
+
+// DataModelVersion returns the version of the data model of the credential, which is that of the base context in its
+// @context, v2 taking precedence when both are. Credentials with neither are of v2 if they have the validFrom or
+// validUntil properties of v2, and of v1 otherwise.
+func (v *VerifiableCredential) DataModelVersion() DataModelVersion {
+	if version, ok := contextVersion(v.Context); ok {
+		return version
+	}
+	for _, property := range v2Properties {
+		if _, ok := v.Extensions[property]; ok {
+			return DataModelV2
+		}
+	}
+	return DataModelV1
+}
+
+// ValidateBaseContext checks the first entry of the credential's @context is the base context of its data model
+// version, returning an error wrapping ErrInvalidContext if not, as the data model requires
+// https://www.w3.org/TR/vc-data-model/#contexts
+func (v *VerifiableCredential) ValidateBaseContext() error {
+	return validateBaseContext(v.Context, v.DataModelVersion())
+}
+
+// DataModelVersion returns the version of the data model of the presentation, which is that of the base context in
+// its @context, v2 taking precedence when both are, and v1 when neither is
+func (v *VerifiablePresentation) DataModelVersion() DataModelVersion {
+	if version, ok := contextVersion(v.Context); ok {
+		return version
+	}
+	return DataModelV1
+}
+
+// ValidateBaseContext checks the first entry of the presentation's @context is the base context of its data model
+// version, returning an error wrapping ErrInvalidContext if not
+func (v *VerifiablePresentation) ValidateBaseContext() error {
+	return validateBaseContext(v.Context, v.DataModelVersion())
+}
+
+// contextVersion returns the version of the base context among the entries of a @context, if any is
+func contextVersion(context any) (DataModelVersion, bool) {
+	entries := contextEntries(context)
+	switch {
+	case slices.Contains(entries, VerifiableCredentialsV2LinkedDataContext):
+		return DataModelV2, true
+	case slices.Contains(entries, VerifiableCredentialsLinkedDataContext):
+		return DataModelV1, true
+	}
+	return 0, false
+}
+
+func validateBaseContext(context any, version DataModelVersion) error {
+	entries := contextEntries(context)
+	if len(entries) == 0 {
+		return errors.Wrap(ErrInvalidContext, "@context is empty")
+	}
+	if entries[0] != version.BaseContext() {
+		return errors.Wrapf(ErrInvalidContext, "first @context entry<%v> is not the base context<%s> of data model v%d", entries[0], version.BaseContext(), version)
+	}
+	return nil
+}
+
+// contextEntries returns the entries of a @context, which is a single context or an ordered set of them, with
+// embedded contexts, which are objects, as empty strings
+func contextEntries(context any) []string {
+	switch typedContext := context.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{typedContext}
+	case []string:
+		return typedContext
+	case []any:
+		entries := make([]string, 0, len(typedContext))
+		for _, entry := range typedContext {
+			entryString, _ := entry.(string)
+			entries = append(entries, entryString)
+		}
+		return entries
+	}
+	return []string{fmt.Sprintf("%v", context)}
+}
@@ -0,0 +1,54 @@
+package credential
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBaseContext(t *testing.T) {
+	t.Run("credentials", func(tt *testing.T) {
+		tests := []struct {
+			name    string
+			cred    VerifiableCredential
+			version DataModelVersion
+			valid   bool
+		}{
+			{"v1", VerifiableCredential{Context: []any{VerifiableCredentialsLinkedDataContext, "https://w3id.org/security/suites/jws-2020/v1"}}, DataModelV1, true},
+			{"v1 string", VerifiableCredential{Context: VerifiableCredentialsLinkedDataContext}, DataModelV1, true},
+			{"v2", VerifiableCredential{Context: []string{VerifiableCredentialsV2LinkedDataContext, "https://www.w3.org/ns/credentials/examples/v2"}}, DataModelV2, true},
+			{"v1 out of order", VerifiableCredential{Context: []any{"https://w3id.org/security/suites/jws-2020/v1", VerifiableCredentialsLinkedDataContext}}, DataModelV1, false},
+			{"v2 after v1", VerifiableCredential{Context: []any{VerifiableCredentialsLinkedDataContext, VerifiableCredentialsV2LinkedDataContext}}, DataModelV2, false},
+			{"embedded context first", VerifiableCredential{Context: []any{map[string]any{"name": "https://schema.org/name"}, VerifiableCredentialsLinkedDataContext}}, DataModelV1, false},
+			{"no base context", VerifiableCredential{Context: []any{"https://example.com/context"}}, DataModelV1, false},
+			{"no base context of v2", VerifiableCredential{Context: []any{"https://example.com/context"}, Extensions: map[string]any{"validFrom": "2021-01-01T19:23:24Z"}}, DataModelV2, false},
+			{"no context", VerifiableCredential{}, DataModelV1, false},
+		}
+		for _, test := range tests {
+			assert.Equal(tt, test.version, test.cred.DataModelVersion(), test.name)
+			err := test.cred.ValidateBaseContext()
+			if test.valid {
+				assert.NoError(tt, err, test.name)
+			} else {
+				assert.ErrorIs(tt, err, ErrInvalidContext, test.name)
+			}
+		}
+	})
+
+	t.Run("the error names the expected base context", func(tt *testing.T) {
+		cred := VerifiableCredential{Context: []any{"https://example.com/context", VerifiableCredentialsV2LinkedDataContext}}
+		assert.ErrorContains(tt, cred.ValidateBaseContext(), "first @context entry<https://example.com/context> is not the base context<https://www.w3.org/ns/credentials/v2> of data model v2")
+	})
+
+	t.Run("presentations", func(tt *testing.T) {
+		presentation := VerifiablePresentation{Context: []string{VerifiableCredentialsV2LinkedDataContext}}
+		assert.Equal(tt, DataModelV2, presentation.DataModelVersion())
+		assert.NoError(tt, presentation.ValidateBaseContext())
+
+		presentation.Context = []any{"https://identity.foundation/presentation-exchange/submission/v1", VerifiableCredentialsLinkedDataContext}
+		assert.Equal(tt, DataModelV1, presentation.DataModelVersion())
+		assert.ErrorIs(tt, presentation.ValidateBaseContext(), ErrInvalidContext)
+
+		assert.ErrorIs(tt, (&VerifiablePresentation{}).ValidateBaseContext(), ErrInvalidContext)
+	})
+}
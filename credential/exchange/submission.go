@@ -9,6 +9,7 @@ import (
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/integrity"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/schema"
 	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
@@ -230,23 +231,61 @@ type processedClaim struct {
 	SubmissionDescriptor
 }
 
+// BuildSubmission selects, from the set of available claims, those that satisfy each input descriptor of the given
+// presentation definition, and constructs the resulting presentation submission along with the selected claims.
+// The paths in the submission's descriptor map index into the returned claims, in the order they are returned, so
+// that the claims can be set as the `verifiableCredential` value of a Verifiable Presentation, such as one signed
+// with integrity.SignVerifiablePresentationJWT.
+// https://identity.foundation/presentation-exchange/#input-evaluation
+func BuildSubmission(def PresentationDefinition, claims []PresentationClaim) (*PresentationSubmission, []any, error) {
+	normalizedClaims, err := normalizePresentationClaims(claims)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "normalizing some presentation claims")
+	}
+	if len(normalizedClaims) == 0 {
+		return nil, nil, errors.New("no claims remain after normalization; cannot continue processing")
+	}
+	return buildSubmission(def, normalizedClaims)
+}
+
 // BuildPresentationSubmissionVP takes a presentation definition and a set of claims. According to the presentation
 // definition, and the algorithm defined - https://identity.foundation/presentation-exchange/#input-evaluation - in
 // the specification, a presentation submission is constructed as a Verifiable Presentation.
 func BuildPresentationSubmissionVP(submitter string, def PresentationDefinition, claims []NormalizedClaim) (*credential.VerifiablePresentation, error) {
-	if err := canProcessDefinition(def); err != nil {
-		return nil, errors.Wrap(err, "feature not supported in processing given presentation definition")
+	submission, selectedClaims, err := buildSubmission(def, claims)
+	if err != nil {
+		return nil, err
 	}
+
 	builder := credential.NewVerifiablePresentationBuilder()
-	if err := builder.AddContext(PresentationSubmissionContext); err != nil {
+	if err = builder.AddContext(PresentationSubmissionContext); err != nil {
 		return nil, err
 	}
-	if err := builder.AddType(PresentationSubmissionType); err != nil {
+	if err = builder.AddType(PresentationSubmissionType); err != nil {
 		return nil, err
 	}
-	if err := builder.SetHolder(submitter); err != nil {
+	if err = builder.SetHolder(submitter); err != nil {
+		return nil, err
+	}
+	if len(selectedClaims) > 0 {
+		if err = builder.AddVerifiableCredentials(selectedClaims...); err != nil {
+			return nil, errors.Wrap(err, "adding claim to verifiable presentation")
+		}
+	}
+
+	// set submission in vp, build, and return
+	if err = builder.SetPresentationSubmission(*submission); err != nil {
 		return nil, err
 	}
+	return builder.Build()
+}
+
+// buildSubmission runs input evaluation for each input descriptor in the definition against the normalized claims,
+// returning the presentation submission and the de-duplicated set of claims its descriptor map refers to
+func buildSubmission(def PresentationDefinition, claims []NormalizedClaim) (*PresentationSubmission, []any, error) {
+	if err := canProcessDefinition(def); err != nil {
+		return nil, nil, errors.Wrap(err, "feature not supported in processing given presentation definition")
+	}
 
 	submission := PresentationSubmission{
 		ID:           uuid.NewString(),
@@ -261,10 +300,10 @@ func BuildPresentationSubmissionVP(submitter string, def PresentationDefinition,
 	for _, id := range def.InputDescriptors {
 		processedDescriptor, err := processInputDescriptor(id, claims)
 		if err != nil {
-			return nil, errors.Wrapf(err, "error processing input descriptor: %s", id.ID)
+			return nil, nil, errors.Wrapf(err, "error processing input descriptor: %s", id.ID)
 		}
 		if processedDescriptor == nil {
-			return nil, fmt.Errorf("input descrpitor<%s> could not be fulfilled; could not build a valid presentation submission", id.ID)
+			return nil, nil, fmt.Errorf("input descrpitor<%s> could not be fulfilled; could not build a valid presentation submission", id.ID)
 		}
 
 		// check if claim already exists. if it has, we won't duplicate the claim
@@ -289,26 +328,20 @@ func BuildPresentationSubmissionVP(submitter string, def PresentationDefinition,
 		})
 	}
 
-	// set descriptor map in submission and credentials to the VP
+	// set descriptor map in submission and collect the claims it refers to
 	var descriptorMap []SubmissionDescriptor
+	var selectedClaims []any
 	for _, claim := range processedClaims {
 		descriptorMap = append(descriptorMap, claim.SubmissionDescriptor)
 		// in the case where we've seen the claim, we need to check as to not add a nil claim value
 		if claim.claim != nil {
-			if err := builder.AddVerifiableCredentials(claim.claim); err != nil {
-				return nil, errors.Wrap(err, "adding claim to verifiable presentation")
-			}
+			selectedClaims = append(selectedClaims, claim.claim)
 		}
 	}
 
 	// add the built descriptor map to the submission
 	submission.DescriptorMap = descriptorMap
-
-	// set submission in vp, build, and return
-	if err := builder.SetPresentationSubmission(submission); err != nil {
-		return nil, err
-	}
-	return builder.Build()
+	return &submission, selectedClaims, nil
 }
 
 // processedInputDescriptor
@@ -464,6 +497,16 @@ func processInputDescriptorField(field Field, claimData map[string]any) (*limite
 	for _, path := range field.Path {
 		pathedData, err := jsonpath.JsonPathLookup(claimData, path)
 		if err == nil {
+			// the pathed data must also conform to the field's filter, if present
+			if field.Filter != nil {
+				filterJSON, err := field.Filter.ToJSON()
+				if err != nil {
+					continue
+				}
+				if err = schema.IsAnyValidAgainstJSONSchema(pathedData, filterJSON); err != nil {
+					continue
+				}
+			}
 			limited := &limitedInputDescriptor{
 				Path: path,
 				Data: pathedData,
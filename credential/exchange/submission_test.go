@@ -52,22 +52,15 @@ func TestBuildPresentationSubmission(t *testing.T) {
 		}
 		assert.NoError(tt, def.IsValid())
 
-		signer, verifier := getJWKSignerVerifier(tt)
+		signer, _ := getJWKSignerVerifier(tt)
 		testVC := getTestVerifiableCredential(signer.ID, signer.ID)
 		presentationClaim := PresentationClaim{
 			Credential:                    &testVC,
 			LDPFormat:                     LDPVC.Ptr(),
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
-		submissionBytes, err := BuildPresentationSubmission(*signer, signer.ID, def, []PresentationClaim{presentationClaim}, JWTVPTarget)
-		assert.NoError(tt, err)
-		assert.NotEmpty(tt, submissionBytes)
-
-		resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
-		assert.NoError(tt, err)
-		_, _, _, err = integrity.VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(submissionBytes))
-		assert.Error(tt, err)
-		assert.Contains(tt, err.Error(), "credential must have a proof")
+		_, err := BuildPresentationSubmission(*signer, signer.ID, def, []PresentationClaim{presentationClaim}, JWTVPTarget)
+		assert.ErrorIs(tt, err, integrity.ErrUnsignedCredential)
 	})
 
 	t.Run("Supported embed target with JWT credential", func(tt *testing.T) {
@@ -359,8 +352,9 @@ func TestBuildPresentationSubmissionVP(t *testing.T) {
 		assert.Equal(tt, "test-verifiable-credential", asVC.ID)
 		assert.Equal(tt, "Block", asVC.CredentialSubject["company"])
 
-		_, vcJWTToken, asVCJWT, err := integrity.ParseVerifiableCredentialFromJWT(*(vp.VerifiableCredential[1].(*string)))
-		assert.NoError(tt, err)
+		parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(*(vp.VerifiableCredential[1].(*string)))
+		require.NoError(tt, err)
+		vcJWTToken, asVCJWT := parsed.Trust().Token, parsed.Trust().Credential
 		assert.NotEmpty(tt, vcJWTToken)
 		assert.NotEmpty(tt, asVCJWT)
 
@@ -883,3 +877,80 @@ func getJWKSignerVerifier(t *testing.T) (*jwx.Signer, *jwx.Verifier) {
 
 	return signer, verifier
 }
+
+func TestBuildSubmission(t *testing.T) {
+	def := PresentationDefinition{
+		ID: "test-id",
+		InputDescriptors: []InputDescriptor{
+			{
+				ID: "id-1",
+				Constraints: &Constraints{
+					Fields: []Field{
+						{
+							Path:    []string{"$.vc.issuer", "$.issuer"},
+							ID:      "issuer-input-descriptor",
+							Purpose: "need to check the issuer",
+							Filter: &Filter{
+								Type:    "string",
+								Pattern: "test-issuer",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Selects only the matching claim", func(tt *testing.T) {
+		matchingVC := getTestVerifiableCredential("test-issuer", "test-subject")
+		otherVC := getTestVerifiableCredential("other-issuer", "test-subject")
+		otherVC.ID = "other-verifiable-credential"
+		claims := []PresentationClaim{
+			{
+				Credential:                    &otherVC,
+				LDPFormat:                     LDPVC.Ptr(),
+				SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+			},
+			{
+				Credential:                    &matchingVC,
+				LDPFormat:                     LDPVC.Ptr(),
+				SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+			},
+		}
+
+		submission, selected, err := BuildSubmission(def, claims)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, submission)
+		assert.NoError(tt, submission.IsValid())
+		assert.Equal(tt, def.ID, submission.DefinitionID)
+		assert.Len(tt, submission.DescriptorMap, 1)
+		assert.Equal(tt, "id-1", submission.DescriptorMap[0].ID)
+		assert.Equal(tt, "$.verifiableCredential[0]", submission.DescriptorMap[0].Path)
+
+		assert.Len(tt, selected, 1)
+		selectedVC, ok := selected[0].(*credential.VerifiableCredential)
+		assert.True(tt, ok)
+		assert.Equal(tt, matchingVC.ID, selectedVC.ID)
+	})
+
+	t.Run("No matching claim", func(tt *testing.T) {
+		otherVC := getTestVerifiableCredential("other-issuer", "test-subject")
+		claims := []PresentationClaim{
+			{
+				Credential:                    &otherVC,
+				LDPFormat:                     LDPVC.Ptr(),
+				SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+			},
+		}
+
+		_, _, err := BuildSubmission(def, claims)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "no claims could fulfill the input descriptor")
+	})
+
+	t.Run("No claims", func(tt *testing.T) {
+		_, _, err := BuildSubmission(def, nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "no claims remain after normalization")
+	})
+}
@@ -3,6 +3,7 @@ package exchange
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/TBD54566975/ssi-sdk/credential/integrity"
@@ -68,6 +69,29 @@ func VerifyPresentationSubmission(ctx context.Context, verifier any, resolver re
 	}
 }
 
+// ValidateSubmission verifies whether a presentation submission, transported alongside a verifiable presentation
+// rather than embedded in it (as is the case for OIDC4VP), is valid for a given presentation definition.
+// If the presentation already carries a presentation submission it must match the one provided.
+// No signature verification happens here.
+func ValidateSubmission(def PresentationDefinition, submission PresentationSubmission, vp credential.VerifiablePresentation) ([]VerifiedSubmissionData, error) {
+	if vp.PresentationSubmission != nil {
+		embedded, err := toPresentationSubmission(vp.PresentationSubmission)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse presentation submission from verifiable presentation")
+		}
+		provided, err := toPresentationSubmission(submission)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse provided presentation submission")
+		}
+		if !reflect.DeepEqual(embedded, provided) {
+			return nil, fmt.Errorf("presentation submission<%s> does not match the submission<%s> embedded in the verifiable presentation",
+				submission.ID, embedded.ID)
+		}
+	}
+	vp.PresentationSubmission = submission
+	return VerifyPresentationSubmissionVP(def, vp)
+}
+
 // VerifyPresentationSubmissionVP verifies whether a verifiable presentation is a valid presentation submission
 // for a given presentation definition. No signature verification happens here.
 func VerifyPresentationSubmissionVP(def PresentationDefinition, vp credential.VerifiablePresentation) ([]VerifiedSubmissionData, error) {
@@ -8,6 +8,7 @@ import (
 	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/did/key"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
@@ -91,9 +92,6 @@ func TestVerifyPresentationSubmission(t *testing.T) {
 		}
 		assert.NoError(tt, def.IsValid())
 
-		resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
-		assert.NoError(tt, err)
-
 		signer, verifier := getJWKSignerVerifier(tt)
 		testVC := getTestVerifiableCredential(signer.ID, signer.ID)
 		presentationClaim := PresentationClaim{
@@ -101,13 +99,9 @@ func TestVerifyPresentationSubmission(t *testing.T) {
 			LDPFormat:                     LDPVC.Ptr(),
 			SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
 		}
-		submissionBytes, err := BuildPresentationSubmission(*signer, verifier.ID, def, []PresentationClaim{presentationClaim}, JWTVPTarget)
-		assert.NoError(tt, err)
-		assert.NotEmpty(tt, submissionBytes)
-
-		_, err = VerifyPresentationSubmission(context.Background(), *verifier, resolver, JWTVPTarget, def, submissionBytes)
-		assert.Error(tt, err)
-		assert.Contains(tt, err.Error(), "credential must have a proof")
+		// an unsigned credential object cannot be embedded in a presentation
+		_, err := BuildPresentationSubmission(*signer, verifier.ID, def, []PresentationClaim{presentationClaim}, JWTVPTarget)
+		assert.ErrorIs(tt, err, integrity.ErrUnsignedCredential)
 	})
 
 	t.Run("Supported embed target, valid submission", func(tt *testing.T) {
@@ -178,6 +172,9 @@ func TestVerifyPresentationSubmissionVP(t *testing.T) {
 
 		signer, _ := getJWKSignerVerifier(tt)
 		testVC := getTestVerifiableCredential("test-issuer", "test-subject")
+		// the submission is checked without verifying the credential, which only has to carry a proof to be embedded
+		proof := crypto.Proof(map[string]any{"type": string(jws2020.JSONWebSignature2020)})
+		testVC.Proof = &proof
 		presentationClaim := PresentationClaim{
 			Credential:                    &testVC,
 			LDPFormat:                     LDPVC.Ptr(),
@@ -550,3 +547,98 @@ func TestVerifyPresentationSubmissionVP(t *testing.T) {
 		assert.NotEmpty(tt, verifiedSubmissionData)
 	})
 }
+
+func TestValidateSubmission(t *testing.T) {
+	def := PresentationDefinition{
+		ID: "test-id",
+		InputDescriptors: []InputDescriptor{
+			{
+				ID: "id-1",
+				Constraints: &Constraints{
+					Fields: []Field{
+						{
+							Path:    []string{"$.vc.issuer", "$.issuer"},
+							ID:      "issuer-input-descriptor",
+							Purpose: "need to check the issuer",
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.NoError(t, def.IsValid())
+
+	testVC := getTestVerifiableCredential("test-issuer", "test-subject")
+	proof := crypto.Proof(map[string]any{"type": string(jws2020.JSONWebSignature2020)})
+	testVC.Proof = &proof
+	presentationClaim := PresentationClaim{
+		Credential:                    &testVC,
+		LDPFormat:                     LDPVC.Ptr(),
+		SignatureAlgorithmOrProofType: string(jws2020.JSONWebSignature2020),
+	}
+
+	t.Run("Detached submission", func(tt *testing.T) {
+		submission, claims, err := BuildSubmission(def, []PresentationClaim{presentationClaim})
+		assert.NoError(tt, err)
+
+		vp := credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			VerifiableCredential: claims,
+		}
+		verified, err := ValidateSubmission(def, *submission, vp)
+		assert.NoError(tt, err)
+		assert.Len(tt, verified, 1)
+		assert.Equal(tt, "id-1", verified[0].InputDescriptorID)
+	})
+
+	t.Run("Signed submission", func(tt *testing.T) {
+		submission, claims, err := BuildSubmission(def, []PresentationClaim{presentationClaim})
+		assert.NoError(tt, err)
+
+		signer, _ := getJWKSignerVerifier(tt)
+		vp := credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Holder:               signer.ID,
+			Type:                 []string{"VerifiablePresentation"},
+			VerifiableCredential: claims,
+		}
+		signed, err := integrity.SignVerifiablePresentationJWT(*signer, nil, vp)
+		assert.NoError(tt, err)
+
+		_, _, parsed, err := integrity.ParseVerifiablePresentationFromJWT(string(signed))
+		assert.NoError(tt, err)
+		_, err = ValidateSubmission(def, *submission, *parsed)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("Mismatched embedded submission", func(tt *testing.T) {
+		submission, claims, err := BuildSubmission(def, []PresentationClaim{presentationClaim})
+		assert.NoError(tt, err)
+
+		vp := credential.VerifiablePresentation{
+			Context:                []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                   []string{"VerifiablePresentation"},
+			PresentationSubmission: PresentationSubmission{ID: "other", DefinitionID: def.ID},
+			VerifiableCredential:   claims,
+		}
+		_, err = ValidateSubmission(def, *submission, vp)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "does not match the submission")
+	})
+
+	t.Run("Unfulfilled input descriptor", func(tt *testing.T) {
+		submission, claims, err := BuildSubmission(def, []PresentationClaim{presentationClaim})
+		assert.NoError(tt, err)
+		submission.DescriptorMap[0].ID = "id-2"
+
+		vp := credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			VerifiableCredential: claims,
+		}
+		_, err = ValidateSubmission(def, *submission, vp)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unfulfilled input descriptor<id-1>")
+	})
+}
@@ -0,0 +1,95 @@
+package integrity
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// DelegatesProperty is the property of a credential subject listing the DIDs, besides the subject's own, that may
+// issue the next credential of a chain on the subject's behalf, as a DID or an array of DIDs
+const DelegatesProperty = "delegates"
+
+// VerifyCredentialChain verifies a chain of JWT credentials delegating authority, where each credential after the
+// first is issued by a subject of the credential preceding it, or by one of the delegates that subject lists in its
+// DelegatesProperty. Each credential is verified as by VerifyJWTCredential, and the verified credentials are returned
+// in order along with the trust anchor: the DID of the issuer of the first credential, which the caller is to trust.
+// With the WithTrustRegistry option, the first credential is instead verified against the registry, so that the
+// anchor must be one of its accredited issuers. The WithClock, WithClockSkew, and AllowControllerKID options apply to
+// every credential.
+func VerifyCredentialChain(ctx context.Context, tokens []string, r resolution.Resolver, opts ...VerifyOption) ([]credential.VerifiableCredential, string, error) {
+	if len(tokens) == 0 {
+		return nil, "", errors.New("chain must have at least one credential")
+	}
+	if r == nil {
+		return nil, "", errors.New("resolution cannot be empty")
+	}
+	var registry TrustRegistry
+	for _, opt := range opts {
+		if opt.Type != TrustRegistryOption {
+			continue
+		}
+		var ok bool
+		if registry, ok = opt.Value.(TrustRegistry); !ok || registry == nil {
+			return nil, "", errors.New("trust registry verification requires a registry")
+		}
+	}
+	opts = withoutVerifyOption(opts, TrustRegistryOption)
+
+	chain := make([]credential.VerifiableCredential, 0, len(tokens))
+	for i, token := range tokens {
+		var ok bool
+		var err error
+		if i == 0 && registry != nil {
+			ok, err = VerifyJWTCredentialWithTrustRegistry(ctx, token, registry, opts...)
+		} else {
+			ok, err = VerifyJWTCredential(ctx, token, r, opts...)
+		}
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "verifying credential %d of chain", i)
+		}
+		if !ok {
+			return nil, "", errors.Wrapf(ErrSignatureInvalid, "credential %d of chain failed signature validation", i)
+		}
+		_, _, cred, err := parseCredentialJWT(token)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "parsing credential %d of chain", i)
+		}
+
+		if i > 0 {
+			issuer := cred.IssuerID()
+			if !isSubjectOrDelegate(chain[i-1], issuer) {
+				return nil, "", errors.Wrapf(ErrBrokenChain,
+					"issuer<%s> of credential %d is neither a subject nor a delegate of a subject of credential %d", issuer, i, i-1)
+			}
+		}
+		chain = append(chain, *cred)
+	}
+	return chain, chain[0].IssuerID(), nil
+}
+
+// isSubjectOrDelegate reports whether the id is the ID of one of the credential's subjects or one of their delegates
+func isSubjectOrDelegate(cred credential.VerifiableCredential, id string) bool {
+	if id == "" {
+		return false
+	}
+	subjects := cred.CredentialSubjects
+	if len(subjects) == 0 {
+		subjects = []credential.CredentialSubject{cred.CredentialSubject}
+	}
+	for _, subject := range subjects {
+		if subjectID, ok := subject[credential.VerifiableCredentialIDProperty].(string); ok && subjectID == id {
+			return true
+		}
+		if delegates, ok := subject[DelegatesProperty]; ok {
+			if delegateIDs, err := util.InterfaceToStrings(delegates); err == nil && util.Contains(id, delegateIDs) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestVerifyCredentialChain(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	regulator := getTestDIDKeySigner(t)
+	company := getTestDIDKeySigner(t)
+	manager := getTestDIDKeySigner(t)
+	assistant := getTestDIDKeySigner(t)
+	employee := getTestDIDKeySigner(t)
+
+	// delegate signs a credential granting authority to the subject
+	delegate := func(tt *testing.T, issuer jwx.Signer, subject map[string]any) string {
+		signed, err := SignVerifiableCredentialJWT(issuer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{credential.VerifiableCredentialType, "AuthorityCredential"},
+			Issuer:            issuer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: subject,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("chain of subjects", func(tt *testing.T) {
+		tokens := []string{
+			delegate(tt, regulator, map[string]any{"id": company.ID}),
+			delegate(tt, company, map[string]any{"id": manager.ID}),
+			delegate(tt, manager, map[string]any{"id": employee.ID}),
+		}
+		chain, anchor, err := VerifyCredentialChain(context.Background(), tokens, resolver)
+		assert.NoError(tt, err)
+		assert.Equal(tt, regulator.ID, anchor)
+		require.Len(tt, chain, 3)
+		assert.Equal(tt, company.ID, chain[1].IssuerID())
+		assert.Equal(tt, employee.ID, chain[2].CredentialSubject.GetID())
+	})
+
+	t.Run("authorized delegate", func(tt *testing.T) {
+		tokens := []string{
+			delegate(tt, company, map[string]any{"id": manager.ID, DelegatesProperty: []any{assistant.ID}}),
+			delegate(tt, assistant, map[string]any{"id": employee.ID}),
+		}
+		_, anchor, err := VerifyCredentialChain(context.Background(), tokens, resolver)
+		assert.NoError(tt, err)
+		assert.Equal(tt, company.ID, anchor)
+	})
+
+	t.Run("broken chain", func(tt *testing.T) {
+		tokens := []string{
+			delegate(tt, company, map[string]any{"id": manager.ID}),
+			delegate(tt, assistant, map[string]any{"id": employee.ID}),
+		}
+		_, _, err := VerifyCredentialChain(context.Background(), tokens, resolver)
+		assert.ErrorIs(tt, err, ErrBrokenChain)
+		assert.ErrorContains(tt, err, "issuer<"+assistant.ID+"> of credential 1")
+	})
+
+	t.Run("invalid link", func(tt *testing.T) {
+		tokens := []string{
+			delegate(tt, company, map[string]any{"id": manager.ID}),
+			delegate(tt, manager, map[string]any{"id": employee.ID}) + "tampered",
+		}
+		_, _, err := VerifyCredentialChain(context.Background(), tokens, resolver)
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+		assert.ErrorContains(tt, err, "verifying credential 1 of chain")
+	})
+
+	t.Run("anchor from a trust registry", func(tt *testing.T) {
+		expanded, err := resolver.Resolve(context.Background(), regulator.ID)
+		require.NoError(tt, err)
+		registry := testTrustRegistry{
+			regulator.ID: {DID: regulator.ID, Document: expanded.Document, CredentialTypes: []string{"AuthorityCredential"}},
+		}
+		tokens := []string{
+			delegate(tt, regulator, map[string]any{"id": company.ID}),
+			delegate(tt, company, map[string]any{"id": manager.ID}),
+		}
+		_, anchor, err := VerifyCredentialChain(context.Background(), tokens, resolver, WithTrustRegistry(registry))
+		assert.NoError(tt, err)
+		assert.Equal(tt, regulator.ID, anchor)
+
+		_, _, err = VerifyCredentialChain(context.Background(), tokens[1:], resolver, WithTrustRegistry(registry))
+		assert.ErrorIs(tt, err, ErrUntrustedIssuer)
+	})
+
+	t.Run("invalid arguments", func(tt *testing.T) {
+		_, _, err := VerifyCredentialChain(context.Background(), nil, resolver)
+		assert.ErrorContains(tt, err, "chain must have at least one credential")
+		_, _, err = VerifyCredentialChain(context.Background(), []string{"token"}, nil)
+		assert.ErrorContains(tt, err, "resolution cannot be empty")
+		_, _, err = VerifyCredentialChain(context.Background(), []string{"token"}, resolver, WithTrustRegistry(nil))
+		assert.ErrorContains(tt, err, "trust registry verification requires a registry")
+	})
+}
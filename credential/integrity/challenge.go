@@ -0,0 +1,117 @@
+package integrity
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// DefaultChallengeTTL is how long a holder has to answer a challenge of a MemoryChallengeStore by default
+const DefaultChallengeTTL = 5 * time.Minute
+
+// Challenge is a nonce a verifier issues to a holder, who signs it into a presentation with the JWTVVPParameters of
+// SignVerifiablePresentationJWT, so that the verifier knows the presentation was made for its request
+type Challenge struct {
+	Nonce string `json:"nonce"`
+	// Audience, if set, is the audience the presentation answering the challenge must be intended for
+	Audience string    `json:"audience,omitempty"`
+	Expiry   time.Time `json:"expiry"`
+}
+
+// ChallengeStore issues challenges and holds them until they are answered
+type ChallengeStore interface {
+	// CreateChallenge issues a new challenge for presentations intended for the audience
+	CreateChallenge(audience string) (*Challenge, error)
+	// Redeem removes the outstanding challenge with the nonce and returns it, or nil if there is none, so that each
+	// challenge is answered at most once
+	Redeem(nonce string) *Challenge
+}
+
+// MemoryChallengeStore is an in-memory ChallengeStore, which drops expired challenges as new ones are created
+type MemoryChallengeStore struct {
+	mu         sync.Mutex
+	challenges map[string]Challenge
+	ttl        time.Duration
+	// now is replaceable for testing expiry
+	now func() time.Time
+}
+
+var _ ChallengeStore = (*MemoryChallengeStore)(nil)
+
+// NewMemoryChallengeStore creates a store whose challenges expire after the ttl, or DefaultChallengeTTL if it is not
+// positive
+func NewMemoryChallengeStore(ttl time.Duration) *MemoryChallengeStore {
+	if ttl <= 0 {
+		ttl = DefaultChallengeTTL
+	}
+	return &MemoryChallengeStore{
+		challenges: make(map[string]Challenge),
+		ttl:        ttl,
+		now:        time.Now,
+	}
+}
+
+func (m *MemoryChallengeStore) CreateChallenge(audience string) (*Challenge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	for n, c := range m.challenges {
+		if !now.Before(c.Expiry) {
+			delete(m.challenges, n)
+		}
+	}
+	challenge := Challenge{
+		Nonce:    uuid.New().String(),
+		Audience: audience,
+		Expiry:   now.Add(m.ttl),
+	}
+	m.challenges[challenge.Nonce] = challenge
+	return &challenge, nil
+}
+
+func (m *MemoryChallengeStore) Redeem(nonce string) *Challenge {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	challenge, ok := m.challenges[nonce]
+	if !ok {
+		return nil
+	}
+	delete(m.challenges, nonce)
+	return &challenge
+}
+
+// Size returns the number of outstanding challenges, including expired challenges that have not been dropped yet
+func (m *MemoryChallengeStore) Size() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.challenges)
+}
+
+// redeemChallenge redeems the challenge a presentation token answers with its nonce, rejecting the token if the
+// challenge is not outstanding, has expired, or is for an audience the token is not intended for
+func redeemChallenge(store ChallengeStore, token jwt.Token, now time.Time) error {
+	nonceClaim, ok := token.Get(NonceProperty)
+	if !ok {
+		return errors.Wrap(ErrMissingClaim, "presentation has no nonce answering a challenge")
+	}
+	nonce, ok := nonceClaim.(string)
+	if !ok || nonce == "" {
+		return errors.Wrapf(ErrMalformedClaim, "presentation nonce<%v> is not a valid string", nonceClaim)
+	}
+	challenge := store.Redeem(nonce)
+	if challenge == nil {
+		return errors.Wrapf(ErrUnknownChallenge, "presentation nonce<%s>", nonce)
+	}
+	if !now.Before(challenge.Expiry) {
+		return errors.Wrapf(ErrChallengeExpired, "challenge<%s> expired at %s", nonce, challenge.Expiry.Format(time.RFC3339))
+	}
+	if challenge.Audience != "" && !util.Contains(challenge.Audience, token.Audience()) {
+		return errors.Wrapf(ErrAudienceMismatch, "challenge<%s> is for audience<%s>", nonce, challenge.Audience)
+	}
+	return nil
+}
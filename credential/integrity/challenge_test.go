@@ -0,0 +1,40 @@
+package integrity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryChallengeStore(t *testing.T) {
+	store := NewMemoryChallengeStore(time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	challenge, err := store.CreateChallenge("did:example:verifier")
+	require.NoError(t, err)
+	assert.NotEmpty(t, challenge.Nonce)
+	assert.Equal(t, "did:example:verifier", challenge.Audience)
+	assert.Equal(t, now.Add(time.Minute), challenge.Expiry)
+
+	other, err := store.CreateChallenge("")
+	require.NoError(t, err)
+	assert.NotEqual(t, challenge.Nonce, other.Nonce)
+	assert.Equal(t, 2, store.Size())
+
+	// a challenge is redeemed once
+	assert.Equal(t, challenge, store.Redeem(challenge.Nonce))
+	assert.Nil(t, store.Redeem(challenge.Nonce))
+	assert.Nil(t, store.Redeem("unknown"))
+
+	// expired challenges are dropped when another challenge is created
+	now = now.Add(2 * time.Minute)
+	_, err = store.CreateChallenge("")
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Size())
+	assert.Nil(t, store.Redeem(other.Nonce))
+
+	assert.Equal(t, DefaultChallengeTTL, NewMemoryChallengeStore(0).ttl)
+}
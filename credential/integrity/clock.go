@@ -0,0 +1,120 @@
+package integrity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+)
+
+// Clock tells the time credentials and presentations are signed and verified at, so that it can be fixed in tests
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is the Clock of the system, which is used unless another is given with WithClock or WithSigningClock
+type RealClock struct{}
+
+var _ Clock = RealClock{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithSigningClock sets the iat and nbf claims of presentations, and the default issuanceDate of credentials, to the
+// time of the clock rather than that of the system
+func WithSigningClock(clock Clock) SignOption {
+	return SignOption{Type: SigningClockOption, Value: clock}
+}
+
+// WithClock validates the iat, nbf, and exp claims of credentials and presentations at the time of the clock rather
+// than that of the system
+func WithClock(clock Clock) VerifyOption {
+	return VerifyOption{Type: ClockOption, Value: clock}
+}
+
+// WithClockSkew tolerates clocks of the signer and verifier that differ by up to skew when validating the iat, nbf,
+// and exp claims of credentials and presentations
+func WithClockSkew(skew time.Duration) VerifyOption {
+	return VerifyOption{Type: ClockSkewOption, Value: skew}
+}
+
+// signingClock returns the clock of the WithSigningClock option, or the RealClock without one
+func signingClock(opts []SignOption) (Clock, error) {
+	for _, opt := range opts {
+		if opt.Type != SigningClockOption {
+			continue
+		}
+		clock, ok := opt.Value.(Clock)
+		if !ok || clock == nil {
+			return nil, errors.New("signing clock cannot be empty")
+		}
+		return clock, nil
+	}
+	return RealClock{}, nil
+}
+
+// timeValidation holds the clock and skew tolerance the time-based claims of tokens are validated with
+type timeValidation struct {
+	clock Clock
+	skew  time.Duration
+}
+
+// apply sets the clock or skew of a WithClock or WithClockSkew option, returning false for other options
+func (tv *timeValidation) apply(opt VerifyOption) (bool, error) {
+	switch opt.Type {
+	case ClockOption:
+		clock, ok := opt.Value.(Clock)
+		if !ok || clock == nil {
+			return true, errors.New("clock cannot be empty")
+		}
+		tv.clock = clock
+	case ClockSkewOption:
+		skew, ok := opt.Value.(time.Duration)
+		if !ok || skew < 0 {
+			return true, fmt.Errorf("clock skew<%v> cannot be negative", opt.Value)
+		}
+		tv.skew = skew
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (tv timeValidation) now() time.Time {
+	if tv.clock == nil {
+		return time.Now()
+	}
+	return tv.clock.Now()
+}
+
+// parseOptions returns the options that make parsing a token validate its claims with the clock and skew
+func (tv timeValidation) parseOptions() []jwt.ParseOption {
+	return []jwt.ParseOption{jwt.WithClock(jwt.ClockFunc(tv.now)), jwt.WithAcceptableSkew(tv.skew)}
+}
+
+// options returns the verify options setting the clock and skew, for verifying the credentials of a presentation
+func (tv timeValidation) options() []VerifyOption {
+	opts := []VerifyOption{WithClockSkew(tv.skew)}
+	if tv.clock != nil {
+		opts = append(opts, WithClock(tv.clock))
+	}
+	return opts
+}
+
+// credentialTimeValidation reads the options of verifying a credential, which may only set the clock and skew its
+// claims are validated with
+func credentialTimeValidation(opts []VerifyOption) (*timeValidation, error) {
+	var tv timeValidation
+	for _, opt := range opts {
+		applied, err := tv.apply(opt)
+		if err != nil {
+			return nil, err
+		}
+		if !applied {
+			return nil, fmt.Errorf("unsupported verify option<%s> for credentials", opt.Type)
+		}
+	}
+	return &tv, nil
+}
@@ -0,0 +1,109 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestClock(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	verifier, err := signer.ToVerifier(signer.ID)
+	require.NoError(t, err)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	issuedAt := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	signCredential := func(tt *testing.T, cred credential.VerifiableCredential) string {
+		cred.Context = []any{"https://www.w3.org/2018/credentials/v1"}
+		cred.Type = []string{"VerifiableCredential"}
+		cred.Issuer = signer.ID
+		cred.CredentialSubject = map[string]any{"id": "did:example:456"}
+		signed, err := SignVerifiableCredentialJWT(signer, cred, WithSigningClock(fixedClock(issuedAt)))
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	signPresentation := func(tt *testing.T, clock Clock, creds ...any) string {
+		signed, err := SignVerifiablePresentationJWT(signer, nil, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+		}, WithSigningClock(clock))
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("signing clock sets the time claims", func(tt *testing.T) {
+		_, _, cred, err := ParseVerifiableCredentialFromJWT(signCredential(tt, credential.VerifiableCredential{}))
+		require.NoError(tt, err)
+		assert.Equal(tt, "2021-03-01T00:00:00Z", cred.IssuanceDate)
+
+		_, token, _, err := ParseVerifiablePresentationFromJWT(signPresentation(tt, fixedClock(issuedAt)))
+		require.NoError(tt, err)
+		assert.Equal(tt, issuedAt, token.IssuedAt().UTC())
+		assert.Equal(tt, issuedAt, token.NotBefore().UTC())
+	})
+
+	t.Run("verifying at the time of the clock", func(tt *testing.T) {
+		expired := signCredential(tt, credential.VerifiableCredential{
+			IssuanceDate:   "2021-01-01T00:00:00Z",
+			ExpirationDate: "2021-06-01T00:00:00Z",
+		})
+		_, err := VerifyJWTCredential(context.Background(), expired, resolver)
+		assert.ErrorIs(tt, err, ErrClaimsNotSatisfied)
+		ok, err := VerifyCredentialSignature(context.Background(), expired, resolver, WithClock(fixedClock(issuedAt)))
+		assert.NoError(tt, err)
+		assert.True(tt, ok)
+
+		presentation := signPresentation(tt, fixedClock(issuedAt), expired)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, presentation)
+		assert.ErrorIs(tt, err, ErrClaimsNotSatisfied)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, presentation,
+			WithClock(fixedClock(issuedAt)))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("skew between the signer and verifier", func(tt *testing.T) {
+		// the signer's clock is ahead of the verifier's, so the presentation is not yet valid
+		presentation := signPresentation(tt, fixedClock(time.Now().Add(time.Hour)))
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, presentation)
+		assert.ErrorIs(tt, err, ErrClaimsNotSatisfied)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, presentation,
+			WithClockSkew(2*time.Hour))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("invalid options", func(tt *testing.T) {
+		_, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		}, WithSigningClock(nil))
+		assert.ErrorContains(tt, err, "signing clock cannot be empty")
+
+		presentation := signPresentation(tt, RealClock{})
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, presentation, WithClock(nil))
+		assert.ErrorContains(tt, err, "clock cannot be empty")
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, presentation, WithClockSkew(-time.Second))
+		assert.ErrorContains(tt, err, "clock skew<-1s> cannot be negative")
+
+		cred := signCredential(tt, credential.VerifiableCredential{})
+		_, err = VerifyJWTCredential(context.Background(), cred, resolver, WithoutCredentialVerification)
+		assert.ErrorContains(tt, err, "unsupported verify option<WithoutCredentialVerification> for credentials")
+	})
+}
@@ -0,0 +1,67 @@
+package integrity
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+
+	"github.com/goccy/go-json"
+)
+
+// Codec encodes and decodes the JSON of the credentials, presentations, and claims the package signs and parses
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	MarshalIndent(v any, prefix, indent string) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	// UnmarshalUseNumber unmarshals as Unmarshal does, but decodes numbers into interface values as json.Number,
+	// keeping them as written, rather than as float64
+	UnmarshalUseNumber(data []byte, v any) error
+}
+
+// JSONCodec is the Codec the package marshals and unmarshals JSON with, GoccyCodec by default. It may be set to
+// StandardCodec for the package's own encoding to be done by encoding/json, though credentials still encode with
+// github.com/goccy/go-json in their MarshalJSON, so the dependency remains. It is meant to be set once, before
+// credentials are signed or parsed. Credentials marshal to the same bytes with both codecs, so a credential signed
+// with one verifies with the other; other values may differ in formatting only, as TestCodecs shows.
+var JSONCodec Codec = GoccyCodec{}
+
+// GoccyCodec is a Codec of github.com/goccy/go-json
+type GoccyCodec struct{}
+
+func (GoccyCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (GoccyCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+func (GoccyCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (GoccyCodec) UnmarshalUseNumber(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// StandardCodec is a Codec of encoding/json
+type StandardCodec struct{}
+
+func (StandardCodec) Marshal(v any) ([]byte, error) {
+	return stdjson.Marshal(v)
+}
+
+func (StandardCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return stdjson.MarshalIndent(v, prefix, indent)
+}
+
+func (StandardCodec) Unmarshal(data []byte, v any) error {
+	return stdjson.Unmarshal(data, v)
+}
+
+func (StandardCodec) UnmarshalUseNumber(data []byte, v any) error {
+	decoder := stdjson.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
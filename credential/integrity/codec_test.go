@@ -0,0 +1,168 @@
+package integrity
+
+import (
+	stdjson "encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+// useCodec sets JSONCodec for the rest of the test
+func useCodec(t *testing.T, codec Codec) {
+	previous := JSONCodec
+	JSONCodec = codec
+	t.Cleanup(func() { JSONCodec = previous })
+}
+
+// getTestEdgeCaseSubject returns a subject with the values the codecs could encode differently
+func getTestEdgeCaseSubject() credential.CredentialSubject {
+	return credential.CredentialSubject{
+		"id":         "did:example:456",
+		"html":       "<script>alert('&')</script>",
+		"separators": "line\u2028paragraph\u2029",
+		"unicode":    "héllo 世界 🎓",
+		"control":    "tab\tnull\x00",
+		"numbers": []any{
+			0.1, -3.5, 1e20, 1e21, 1e-6, -1e-10, 1e-300, -0.0, math.MaxInt64, uint64(math.MaxUint64), math.MaxFloat64,
+			stdjson.Number("12345678901234567890"), stdjson.Number("0.10000000000000000555"),
+		},
+		"nested": map[string]any{"z": []any{map[string]any{"b": nil, "a": true}}, "a": map[string]any{}},
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	codecs := map[string]Codec{"goccy": GoccyCodec{}, "standard": StandardCodec{}}
+	cred := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		ID:                "http://example.edu/credentials/1872",
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            "did:example:123",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: getTestEdgeCaseSubject(),
+	}
+
+	t.Run("marshal the same bytes", func(tt *testing.T) {
+		for name, value := range map[string]any{
+			"credential": cred,
+			"subject":    getTestEdgeCaseSubject(),
+			"pointer":    &cred,
+		} {
+			expected, err := GoccyCodec{}.Marshal(value)
+			require.NoError(tt, err, name)
+			actual, err := StandardCodec{}.Marshal(value)
+			require.NoError(tt, err, name)
+			assert.Equal(tt, string(expected), string(actual), name)
+
+			expected, err = GoccyCodec{}.MarshalIndent(value, "", "  ")
+			require.NoError(tt, err, name)
+			actual, err = StandardCodec{}.MarshalIndent(value, "", "  ")
+			require.NoError(tt, err, name)
+			assert.Equal(tt, string(expected), string(actual), name)
+		}
+
+		encoded, err := StandardCodec{}.Marshal(getTestEdgeCaseSubject())
+		require.NoError(tt, err)
+		assert.Contains(tt, string(encoded), `\u003cscript\u003ealert('\u0026')\u003c/script\u003e`)
+		assert.Contains(tt, string(encoded), `line\u2028paragraph\u2029`)
+		assert.Contains(tt, string(encoded), "12345678901234567890")
+	})
+
+	t.Run("marshal other values differing in formatting only", func(tt *testing.T) {
+		value := map[string]any{"small": []any{1e-7, -2.5e-9}, "invalid": "bad \xff byte"}
+		goccyBytes, err := GoccyCodec{}.Marshal(value)
+		require.NoError(tt, err)
+		assert.Equal(tt, `{"invalid":"bad \ufffd byte","small":[1e-07,-2.5e-09]}`, string(goccyBytes))
+		standardBytes, err := StandardCodec{}.Marshal(value)
+		require.NoError(tt, err)
+		assert.Equal(tt, "{\"invalid\":\"bad \ufffd byte\",\"small\":[1e-7,-2.5e-9]}", string(standardBytes))
+
+		var fromGoccy, fromStandard map[string]any
+		require.NoError(tt, StandardCodec{}.Unmarshal(goccyBytes, &fromGoccy))
+		require.NoError(tt, StandardCodec{}.Unmarshal(standardBytes, &fromStandard))
+		assert.Equal(tt, fromGoccy, fromStandard)
+
+		// credentials are encoded by their MarshalJSON, and so the same
+		valuesCred := cred
+		valuesCred.CredentialSubject = credential.CredentialSubject{"id": "did:example:456", "values": value}
+		goccyBytes, err = GoccyCodec{}.Marshal(valuesCred)
+		require.NoError(tt, err)
+		standardBytes, err = StandardCodec{}.Marshal(valuesCred)
+		require.NoError(tt, err)
+		assert.Equal(tt, string(goccyBytes), string(standardBytes))
+	})
+
+	t.Run("unmarshal the same values", func(tt *testing.T) {
+		data := []byte(`{"big":12345678901234567890,"precise":0.10000000000000000555,"html":"<&>","list":[1,2.5,"x"]}`)
+		var expected, actual map[string]any
+		require.NoError(tt, GoccyCodec{}.Unmarshal(data, &expected))
+		require.NoError(tt, StandardCodec{}.Unmarshal(data, &actual))
+		assert.Equal(tt, expected, actual)
+		assert.Equal(tt, 1.2345678901234567e19, actual["big"])
+		assert.Equal(tt, "<&>", actual["html"])
+
+		for name, codec := range codecs {
+			var numbers map[string]any
+			require.NoError(tt, codec.UnmarshalUseNumber(data, &numbers), name)
+			assert.Equal(tt, stdjson.Number("12345678901234567890"), numbers["big"], name)
+			assert.Equal(tt, stdjson.Number("0.10000000000000000555"), numbers["precise"], name)
+			assert.Equal(tt, []any{stdjson.Number("1"), stdjson.Number("2.5"), "x"}, numbers["list"], name)
+		}
+	})
+
+	t.Run("unmarshal the same credentials", func(tt *testing.T) {
+		credBytes, err := GoccyCodec{}.Marshal(cred)
+		require.NoError(tt, err)
+		roundTripped := make(map[string]credential.VerifiableCredential)
+		for name, codec := range codecs {
+			var unmarshalled credential.VerifiableCredential
+			require.NoError(tt, codec.Unmarshal(credBytes, &unmarshalled), name)
+			roundTripped[name] = unmarshalled
+		}
+		assert.Equal(tt, roundTripped["goccy"], roundTripped["standard"])
+		// numbers become float64, losing precision
+		assert.Equal(tt, 1.2345678901234567e19, roundTripped["standard"].CredentialSubject["numbers"].([]any)[11])
+		assert.Equal(tt, cred.CredentialSubject["html"], roundTripped["standard"].CredentialSubject["html"])
+	})
+
+	t.Run("give the same canonical credentials", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJWT(getTestVectorKey0Signer(tt), cred)
+		require.NoError(tt, err)
+
+		canonical := make(map[string]string)
+		for name, codec := range codecs {
+			useCodec(tt, codec)
+			canonicalBytes, err := CredentialToCanonicalJSON(string(signed))
+			require.NoError(tt, err, name)
+			canonical[name] = string(canonicalBytes)
+		}
+		assert.Equal(tt, canonical["goccy"], canonical["standard"])
+		assert.Contains(tt, canonical["standard"], `\u003cscript\u003e`)
+		assert.Contains(tt, canonical["standard"], "1e-300")
+	})
+
+	t.Run("sign and verify JWS credentials interchangeably", func(tt *testing.T) {
+		signer := getTestVectorKey0Signer(tt)
+		verifier, err := signer.ToVerifier(signer.ID)
+		require.NoError(tt, err)
+
+		signed := make(map[string]string)
+		for name, codec := range codecs {
+			useCodec(tt, codec)
+			token, err := SignVerifiableCredentialJWS(signer, cred)
+			require.NoError(tt, err, name)
+			signed[name] = string(token)
+		}
+		// Ed25519 signatures are deterministic, so the payloads being the same makes the tokens the same
+		assert.Equal(tt, signed["goccy"], signed["standard"])
+
+		useCodec(tt, StandardCodec{})
+		_, verified, err := VerifyVerifiableCredentialJWS(*verifier, signed["goccy"])
+		require.NoError(tt, err)
+		assert.Equal(tt, cred.ID, verified.ID)
+		assert.Equal(tt, "<script>alert('&')</script>", verified.CredentialSubject["html"])
+	})
+}
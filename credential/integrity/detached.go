@@ -0,0 +1,44 @@
+package integrity
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DetachedCredentialPrefix begins each reference to a detached credential, telling references apart from credential
+// tokens and hashes
+const DetachedCredentialPrefix = "detached:"
+
+// DetachedCredentialReference returns the reference to the credential with the given id, which may be placed in a
+// presentation's verifiableCredential property in place of the credential's token when the token is sent alongside
+// the presentation rather than in it, as for transports limiting the size of presentations. The id only has to be
+// unique among the credentials sent with the presentation.
+func DetachedCredentialReference(id string) string {
+	return DetachedCredentialPrefix + id
+}
+
+// IsDetachedCredentialReference returns whether the value is a reference to a detached credential rather than a
+// credential
+func IsDetachedCredentialReference(value string) bool {
+	return strings.HasPrefix(value, DetachedCredentialPrefix)
+}
+
+// WithDetachedCredentials supplies the credential tokens sent alongside a presentation, keyed by the ids of the
+// DetachedCredentialReference the presentation references them with. The referenced credentials are verified as if
+// they were embedded in the presentation, and the verified presentation holds them in place of their references.
+// Unlike credentials referenced by CredentialHash, the presentation's signature does not cover the content of
+// detached credentials, so a verifier binding credentials to their presenter should also use WithHolderMatch.
+func WithDetachedCredentials(credentials map[string]string) VerifyOption {
+	return VerifyOption{Type: DetachedCredentialsOption, Value: credentials}
+}
+
+// resolveDetachedCredential returns the credential token of a reference to a detached credential
+func resolveDetachedCredential(reference string, credentials map[string]string) (string, error) {
+	id := strings.TrimPrefix(reference, DetachedCredentialPrefix)
+	token, ok := credentials[id]
+	if !ok || token == "" {
+		return "", errors.Wrapf(ErrMissingClaim, "detached credential<%s> was not supplied", id)
+	}
+	return token, nil
+}
@@ -0,0 +1,77 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestVerifyVerifiablePresentationJWTWithDetachedCredentials(t *testing.T) {
+	holder := getTestDIDKeySigner(t)
+	issuer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	verifier, err := holder.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+
+	first, second := getTestJWTCredential(t, issuer), getTestJWTCredential(t, issuer)
+	reference := DetachedCredentialReference("cred-1")
+	assert.True(t, IsDetachedCredentialReference(reference))
+	assert.False(t, IsDetachedCredentialReference(first))
+	assert.False(t, IsDetachedCredentialReference(CredentialHash(first)))
+
+	signed, err := SignVerifiablePresentationJWT(holder, &JWTVVPParameters{Audience: []string{verifier.ID}}, credential.VerifiablePresentation{
+		Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+		Type:                 []string{"VerifiablePresentation"},
+		Holder:               holder.ID,
+		VerifiableCredential: []any{reference, second},
+	})
+	require.NoError(t, err)
+
+	t.Run("verifies detached credentials with the embedded ones", func(tt *testing.T) {
+		_, _, pres, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed),
+			WithDetachedCredentials(map[string]string{"cred-1": first}))
+		require.NoError(tt, err)
+		assert.Equal(tt, []any{first, second}, pres.VerifiableCredential)
+	})
+
+	t.Run("detached credentials that are not supplied", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed))
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+		assert.ErrorContains(tt, err, "resolving credential 0: detached credential<cred-1> was not supplied")
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed),
+			WithDetachedCredentials(map[string]string{"cred-2": first}))
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+	})
+
+	t.Run("detached credentials are verified", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed),
+			WithDetachedCredentials(map[string]string{"cred-1": first[:len(first)-4] + "AAAA"}))
+		assert.ErrorContains(tt, err, "verifying credential 0")
+	})
+
+	t.Run("in a report", func(tt *testing.T) {
+		report, err := VerifyPresentationReport(context.Background(), *verifier, resolver, string(signed))
+		require.NoError(tt, err)
+		require.Len(tt, report.Failed(), 1)
+		assert.ErrorIs(tt, report.Failed()[0].Error, ErrMissingClaim)
+
+		report, err = VerifyPresentationReport(context.Background(), *verifier, resolver, string(signed),
+			WithDetachedCredentials(map[string]string{"cred-1": first}))
+		require.NoError(tt, err)
+		assert.True(tt, report.Valid())
+	})
+
+	t.Run("invalid option", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed),
+			VerifyOption{Type: DetachedCredentialsOption, Value: []string{first}})
+		assert.ErrorContains(tt, err, "detached credentials must be a map")
+	})
+}
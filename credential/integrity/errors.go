@@ -0,0 +1,81 @@
+package integrity
+
+import (
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+)
+
+// Errors returned by this package wrap one of these sentinels, so that callers can tell failures apart with errors.Is
+var (
+	// ErrEmptyCredential is returned when a credential to sign or verify is empty
+	ErrEmptyCredential = errors.New("credential cannot be empty")
+	// ErrEmptyPresentation is returned when a presentation to sign or verify is empty
+	ErrEmptyPresentation = errors.New("presentation cannot be empty")
+	// ErrProofPresent is returned when a credential or presentation to sign already has a proof
+	ErrProofPresent = errors.New("proof already present")
+	// ErrUnsignedCredential is returned when a presentation to sign embeds a credential object without a proof
+	ErrUnsignedCredential = errors.New("credential is not signed")
+	// ErrInvalidParameters is returned when the parameters to sign a presentation with are malformed
+	ErrInvalidParameters = errors.New("invalid parameters")
+	// ErrSignatureInvalid is returned when the signature of a credential or presentation does not verify
+	ErrSignatureInvalid = errors.New("invalid signature")
+	// ErrClaimsNotSatisfied is returned when a token is signed correctly but is expired or not yet valid
+	ErrClaimsNotSatisfied = errors.New("claims not satisfied")
+	// ErrInconsistentClaims is returned when the time-based claims of a JWT (iat, nbf, exp) are logically impossible
+	ErrInconsistentClaims = errors.New("inconsistent claims")
+	// ErrMissingClaim is returned when a token lacks a claim it requires, such as vc, vp, iss, or nonce
+	ErrMissingClaim = errors.New("missing claim")
+	// ErrMalformedClaim is returned when a claim of a token cannot be decoded
+	ErrMalformedClaim = errors.New("malformed claim")
+	// ErrMissingKID is returned when a token has no kid header to find the key to verify it with
+	ErrMissingKID = errors.New("missing kid")
+	// ErrTypeMismatch is returned when the typ header of a token is not the one expected
+	ErrTypeMismatch = errors.New("token type mismatch")
+	// ErrAudienceMismatch is returned when a presentation is not intended for the verifier
+	ErrAudienceMismatch = errors.New("audience mismatch")
+	// ErrIssuerMismatch is returned when the key or signer of a token does not belong to its issuer
+	ErrIssuerMismatch = errors.New("issuer mismatch")
+	// ErrHolderMismatch is returned when the holder of a presentation is not the party a credential is bound to
+	ErrHolderMismatch = errors.New("holder mismatch")
+	// ErrMissingSubjectID is returned when the subject of a credential to resolve has no id
+	ErrMissingSubjectID = errors.New("credential subject has no id")
+	// ErrSubjectNotDID is returned when the id of the subject of a credential to resolve is a URI other than a DID
+	ErrSubjectNotDID = errors.New("credential subject is not a DID")
+	// ErrProofPurposeMismatch is returned when a presentation was not signed for the proof purpose a verifier requires
+	ErrProofPurposeMismatch = errors.New("proof purpose mismatch")
+	// ErrNestingTooDeep is returned when presentations are nested deeper than allowed
+	ErrNestingTooDeep = errors.New("presentation nested too deeply")
+	// ErrUntrustedIssuer is returned when an issuer is not in a trust registry or not accredited for a credential
+	ErrUntrustedIssuer = errors.New("untrusted issuer")
+	// ErrCredentialHashMismatch is returned when a credential fetched by its hash does not match the hash
+	ErrCredentialHashMismatch = errors.New("credential hash mismatch")
+	// ErrNonceReplayed is returned when a presentation's nonce has already been used
+	ErrNonceReplayed = errors.New("nonce already used")
+	// ErrDeactivatedDID is returned when the DID a credential is signed with is deactivated, as told by the
+	// document metadata of its resolution
+	ErrDeactivatedDID = errors.New("DID deactivated")
+	// ErrBrokenChain is returned when a credential of a chain is not issued by a subject, or a delegate of a subject,
+	// of the credential preceding it
+	ErrBrokenChain = errors.New("broken credential chain")
+	// ErrUnknownChallenge is returned when a presentation's nonce is not an outstanding challenge of the verifier
+	ErrUnknownChallenge = errors.New("unknown challenge")
+	// ErrChallengeExpired is returned when a presentation answers a challenge after the challenge expired
+	ErrChallengeExpired = errors.New("challenge expired")
+	// ErrRevoked is returned when the credentialStatus of a presentation tells it has been revoked
+	ErrRevoked = errors.New("revoked")
+	// ErrTokenTooLarge is returned when a token exceeds MaxTokenSize or nests JSON deeper than MaxJSONDepth
+	ErrTokenTooLarge = errors.New("token too large")
+)
+
+// verificationError wraps an error from verifying a JWT with ErrClaimsNotSatisfied when the token failed validation
+// of its claims, otherwise with ErrSignatureInvalid
+func verificationError(err error) error {
+	for cause := err; cause != nil; cause = errors.Unwrap(cause) {
+		if jwt.IsValidationError(cause) {
+			return fmt.Errorf("%w: %w", ErrClaimsNotSatisfied, err)
+		}
+	}
+	return fmt.Errorf("%w: %w", ErrSignatureInvalid, err)
+}
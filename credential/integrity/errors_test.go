@@ -0,0 +1,112 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	newCredential := func() credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		}
+	}
+
+	t.Run("empty credential", func(tt *testing.T) {
+		_, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{})
+		assert.ErrorIs(tt, err, ErrEmptyCredential)
+		_, err = VerifyCredentialSignature(context.Background(), "", resolver)
+		assert.ErrorIs(tt, err, ErrEmptyCredential)
+	})
+
+	t.Run("proof present", func(tt *testing.T) {
+		cred := newCredential()
+		var proof crypto.Proof = map[string]any{"type": "JsonWebSignature2020"}
+		cred.Proof = &proof
+		_, err := SignVerifiableCredentialJWT(signer, cred)
+		assert.ErrorIs(tt, err, ErrProofPresent)
+
+		// other problems are still reported
+		cred.IssuanceDate = "not-a-date"
+		err = ValidateCredentialForSigning(cred)
+		assert.ErrorIs(tt, err, ErrProofPresent)
+		assert.ErrorContains(tt, err, "issuanceDate<not-a-date> is not a valid RFC3339 date")
+	})
+
+	t.Run("invalid signature", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJWT(signer, newCredential())
+		require.NoError(tt, err)
+		otherPubKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		otherVerifier, err := jwx.NewJWXVerifier("did:example:123", nil, otherPubKey)
+		require.NoError(tt, err)
+
+		_, _, _, err = VerifyVerifiableCredentialJWT(*otherVerifier, string(signed))
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+		assert.NotErrorIs(tt, err, ErrClaimsNotSatisfied)
+	})
+
+	t.Run("expired credential", func(tt *testing.T) {
+		cred := newCredential()
+		cred.ExpirationDate = time.Now().Add(-time.Hour).Format(time.RFC3339)
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+
+		_, _, _, err = VerifyVerifiableCredentialJWT(*verifier, string(signed))
+		assert.ErrorIs(tt, err, ErrClaimsNotSatisfied)
+		assert.NotErrorIs(tt, err, ErrSignatureInvalid)
+	})
+
+	t.Run("audience mismatch", func(tt *testing.T) {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{"did:example:other"}}, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		require.NoError(tt, err)
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed))
+		assert.ErrorIs(tt, err, ErrAudienceMismatch)
+	})
+
+	t.Run("type mismatch", func(tt *testing.T) {
+		signed, err := SignVerifiablePresentationJWT(signer, nil, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		require.NoError(tt, err)
+
+		_, _, _, err = ParseVerifiableCredentialFromJWT(string(signed))
+		assert.ErrorIs(tt, err, ErrTypeMismatch)
+	})
+
+	t.Run("missing kid", func(tt *testing.T) {
+		noKIDSigner := signer
+		noKIDSigner.KID = ""
+		signed, err := SignVerifiableCredentialJWT(noKIDSigner, newCredential())
+		require.NoError(tt, err)
+
+		_, err = VerifyJWTCredential(context.Background(), string(signed), resolver)
+		assert.ErrorIs(tt, err, ErrMissingKID)
+	})
+}
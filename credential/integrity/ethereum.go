@@ -0,0 +1,140 @@
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+)
+
+// ES256KR is the JSON Web Algorithm of recoverable secp256k1 signatures, as produced by Ethereum wallets
+// https://identity.foundation/EcdsaSecp256k1RecoverySignature2020/
+const ES256KR = "ES256K-R"
+
+// eip191Prefix begins a message signed with personal_sign https://eips.ethereum.org/EIPS/eip-191
+const eip191Prefix = "\x19Ethereum Signed Message:\n"
+
+// VerifyEthereumCredential verifies a credential JWT signed with an ES256K-R signature, as held by Ethereum wallets,
+// by recovering the signer's address from the signature. The signature may be over the SHA-256 of the JWS signing
+// input, or over the signing input as an EIP-191 personal_sign message, as MetaMask-style wallets sign it. The
+// recovered address must be the expected address, and the address of the issuer where the issuer is a did:pkh or
+// did:ethr DID. If expectedAddress is empty, the issuer's address is expected.
+func VerifyEthereumCredential(token, expectedAddress string) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
+	headers, parsed, cred, err := parseCredentialJWT(token)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if alg := headers.Algorithm().String(); alg != ES256KR {
+		return nil, nil, nil, fmt.Errorf("unsupported alg<%s>, expected %s", alg, ES256KR)
+	}
+	if err = checkClaimConsistency(parsed); err != nil {
+		return nil, nil, nil, err
+	}
+
+	issuerAddress := ethereumAddressFromDID(parsed.Issuer())
+	if expectedAddress == "" {
+		expectedAddress = issuerAddress
+	}
+	if expectedAddress == "" {
+		return nil, nil, nil, errors.Errorf("no expected address for issuer<%s>", parsed.Issuer())
+	}
+	if issuerAddress != "" && !strings.EqualFold(issuerAddress, expectedAddress) {
+		return nil, nil, nil, errors.Wrapf(ErrIssuerMismatch, "issuer address<%s> is not the expected address<%s>", issuerAddress, expectedAddress)
+	}
+
+	addresses, err := recoverEthereumAddresses(token)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var recovered bool
+	for _, address := range addresses {
+		if strings.EqualFold(address, expectedAddress) {
+			recovered = true
+			break
+		}
+	}
+	if !recovered {
+		return nil, nil, nil, errors.Wrapf(ErrSignatureInvalid, "signature was not made by address<%s>", expectedAddress)
+	}
+
+	if err = jwt.Validate(parsed); err != nil {
+		return nil, nil, nil, errors.Wrap(verificationError(err), "validating JWT")
+	}
+	return headers, parsed, cred, nil
+}
+
+// recoverEthereumAddresses returns the addresses recovered from the ES256K-R signature of a token, taking the signed
+// message to be the SHA-256 of the signing input and the EIP-191 message of the signing input in turn
+func recoverEthereumAddresses(token string) ([]string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a compact JWS")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding signature")
+	}
+	if len(signature) != 65 {
+		return nil, errors.Wrapf(ErrSignatureInvalid, "recoverable signature must be 65 bytes, got %d", len(signature))
+	}
+	// the recovery id is 0 or 1, or 27 or 28 as set by some wallets
+	recoveryID := signature[64]
+	if recoveryID >= 27 {
+		recoveryID -= 27
+	}
+	if recoveryID > 1 {
+		return nil, errors.Wrapf(ErrSignatureInvalid, "invalid recovery id<%d>", signature[64])
+	}
+	// the compact format of the secp256k1 library leads with the recovery code, for an uncompressed key
+	compact := append([]byte{27 + recoveryID}, signature[:64]...)
+
+	signingInput := []byte(parts[0] + "." + parts[1])
+	sha256Hash := sha256.Sum256(signingInput)
+	eip191Hash := keccak256([]byte(fmt.Sprintf("%s%d", eip191Prefix, len(signingInput))), signingInput)
+
+	var addresses []string
+	for _, hash := range [][]byte{sha256Hash[:], eip191Hash} {
+		pubKey, _, err := ecdsa.RecoverCompact(compact, hash)
+		if err != nil {
+			continue
+		}
+		addresses = append(addresses, "0x"+hex.EncodeToString(keccak256(pubKey.SerializeUncompressed()[1:])[12:]))
+	}
+	if len(addresses) == 0 {
+		return nil, errors.Wrap(ErrSignatureInvalid, "could not recover a public key from the signature")
+	}
+	return addresses, nil
+}
+
+// ethereumAddressFromDID returns the address of a did:pkh DID on an EIP-155 chain or a did:ethr DID identified by an
+// address, and an empty string for other DIDs
+func ethereumAddressFromDID(id string) string {
+	split := strings.Split(id, ":")
+	var address string
+	switch {
+	case len(split) == 5 && split[1] == "pkh" && split[2] == "eip155":
+		address = split[4]
+	case len(split) >= 3 && split[1] == "ethr":
+		address = split[len(split)-1]
+	}
+	if len(address) != 42 || !strings.HasPrefix(address, "0x") {
+		return ""
+	}
+	return address
+}
+
+func keccak256(data ...[]byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		hash.Write(d)
+	}
+	return hash.Sum(nil)
+}
@@ -0,0 +1,102 @@
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+func TestVerifyEthereumCredential(t *testing.T) {
+	// the example account of the web3.js documentation
+	privKeyBytes, err := hex.DecodeString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	require.NoError(t, err)
+	privKey := secp256k1.PrivKeyFromBytes(privKeyBytes)
+	const address = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	issuer := "did:pkh:eip155:1:" + address
+
+	signToken := func(tt *testing.T, issuer string, expiration time.Time, eip191 bool) string {
+		claims, err := JWTClaimSetFromVC(credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            issuer,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			ExpirationDate:    expiration.Format(time.RFC3339),
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		payload, err := json.Marshal(claims)
+		require.NoError(tt, err)
+		signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256K-R","typ":"JWT"}`)) + "." +
+			base64.RawURLEncoding.EncodeToString(payload)
+
+		var hash []byte
+		if eip191 {
+			hash = keccak256([]byte(fmt.Sprintf("%s%d", eip191Prefix, len(signingInput))), []byte(signingInput))
+		} else {
+			sum := sha256.Sum256([]byte(signingInput))
+			hash = sum[:]
+		}
+		compact := ecdsa.SignCompact(privKey, hash, false)
+		// reorder the compact signature into r || s || recovery id
+		signature := append(compact[1:], compact[0]-27)
+		return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+	expiration := time.Now().Add(time.Hour)
+
+	t.Run("sha-256 signature from the issuer", func(tt *testing.T) {
+		_, token, cred, err := VerifyEthereumCredential(signToken(tt, issuer, expiration, false), "")
+		assert.NoError(tt, err)
+		assert.Equal(tt, issuer, token.Issuer())
+		assert.Equal(tt, "did:example:456", cred.CredentialSubject.GetID())
+	})
+
+	t.Run("eip-191 signature from the expected address", func(tt *testing.T) {
+		_, _, _, err := VerifyEthereumCredential(signToken(tt, "did:ethr:"+address, expiration, true), "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23")
+		assert.NoError(tt, err)
+	})
+
+	t.Run("signature from another address", func(tt *testing.T) {
+		_, _, _, err := VerifyEthereumCredential(signToken(tt, "did:example:123", expiration, false), "0x0000000000000000000000000000000000000001")
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+	})
+
+	t.Run("issuer is not the expected address", func(tt *testing.T) {
+		_, _, _, err := VerifyEthereumCredential(signToken(tt, issuer, expiration, false), "0x0000000000000000000000000000000000000001")
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+	})
+
+	t.Run("expired credential", func(tt *testing.T) {
+		_, _, _, err := VerifyEthereumCredential(signToken(tt, issuer, time.Now().Add(-time.Hour), false), "")
+		assert.ErrorIs(tt, err, ErrClaimsNotSatisfied)
+	})
+
+	t.Run("issuer without an address", func(tt *testing.T) {
+		_, _, _, err := VerifyEthereumCredential(signToken(tt, "did:example:123", expiration, false), "")
+		assert.ErrorContains(tt, err, "no expected address for issuer<did:example:123>")
+	})
+
+	t.Run("not an ES256K-R token", func(tt *testing.T) {
+		signer := getTestVectorKey0Signer(tt)
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		_, _, _, err = VerifyEthereumCredential(string(signed), address)
+		assert.ErrorContains(tt, err, "expected ES256K-R")
+	})
+}
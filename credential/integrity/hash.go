@@ -0,0 +1,49 @@
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CredentialHashPrefix begins each credential hash, telling hashes apart from credential tokens
+const CredentialHashPrefix = "sha256:"
+
+// CredentialResolver returns the credential token with the given hash, e.g. from a verifier's store of credentials
+// it already holds
+type CredentialResolver func(hash string) (string, error)
+
+// CredentialHash returns the content hash of a credential token, which may be placed in a presentation's
+// verifiableCredential property in place of the token when the verifier already holds the credential
+func CredentialHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return CredentialHashPrefix + base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// IsCredentialHash returns whether the value is a credential hash rather than a credential
+func IsCredentialHash(value string) bool {
+	return strings.HasPrefix(value, CredentialHashPrefix)
+}
+
+// WithCredentialResolver fetches the credentials a presentation references by their CredentialHash, which are
+// verified as if they were embedded in the presentation. The verified presentation holds the fetched credentials.
+func WithCredentialResolver(resolver CredentialResolver) VerifyOption {
+	return VerifyOption{Type: CredentialResolverOption, Value: resolver}
+}
+
+// resolveCredentialHash fetches the credential with the given hash, checking its content matches the hash
+func resolveCredentialHash(hash string, resolver CredentialResolver) (string, error) {
+	if resolver == nil {
+		return "", errors.Wrapf(ErrMissingClaim, "credential<%s> is referenced by hash without a credential resolver", hash)
+	}
+	token, err := resolver(hash)
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching credential<%s>", hash)
+	}
+	if CredentialHash(token) != hash {
+		return "", errors.Wrapf(ErrCredentialHashMismatch, "fetched credential does not match hash<%s>", hash)
+	}
+	return token, nil
+}
@@ -0,0 +1,91 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestVerifyVerifiablePresentationJWTWithCredentialHashes(t *testing.T) {
+	holder := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	verifier, err := holder.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	issuer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	signedVC, err := SignVerifiableCredentialJWT(*issuer, credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            didKey.String(),
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	})
+	require.NoError(t, err)
+	hash := CredentialHash(string(signedVC))
+	assert.True(t, IsCredentialHash(hash))
+	assert.False(t, IsCredentialHash(string(signedVC)))
+
+	signed, err := SignVerifiablePresentationJWT(holder, &JWTVVPParameters{Audience: []string{"did:example:verifier"}}, credential.VerifiablePresentation{
+		Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+		Type:                 []string{"VerifiablePresentation"},
+		Holder:               holder.ID,
+		VerifiableCredential: []any{hash},
+	})
+	require.NoError(t, err)
+
+	t.Run("fetches and verifies referenced credentials", func(tt *testing.T) {
+		held := map[string]string{hash: string(signedVC)}
+		_, _, pres, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed),
+			WithCredentialResolver(func(hash string) (string, error) {
+				token, ok := held[hash]
+				if !ok {
+					return "", errors.New("unknown credential")
+				}
+				return token, nil
+			}))
+		assert.NoError(tt, err)
+		assert.Equal(tt, []any{string(signedVC)}, pres.VerifiableCredential)
+	})
+
+	t.Run("fetched credential does not match the hash", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed),
+			WithCredentialResolver(func(string) (string, error) {
+				return string(signedVC) + "tampered", nil
+			}))
+		assert.ErrorIs(tt, err, ErrCredentialHashMismatch)
+		assert.ErrorContains(tt, err, "resolving credential 0")
+	})
+
+	t.Run("credential cannot be fetched", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed),
+			WithCredentialResolver(func(string) (string, error) {
+				return "", errors.New("unknown credential")
+			}))
+		assert.ErrorContains(tt, err, "unknown credential")
+	})
+
+	t.Run("no credential resolver", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed))
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+		assert.ErrorContains(tt, err, "is referenced by hash without a credential resolver")
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed), WithCredentialResolver(nil))
+		assert.ErrorContains(tt, err, "credential resolution requires a credential resolver")
+	})
+}
@@ -0,0 +1,104 @@
+package integrity
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+// HolderMatchMode sets who the holder of a presentation must be for each of its credentials
+type HolderMatchMode int
+
+const (
+	// HolderMatchSubject requires the holder of the presentation to be the subject of each credential, or one of the
+	// subjects of a credential with many
+	HolderMatchSubject HolderMatchMode = iota
+	// HolderMatchHolder requires the holder of the presentation to be the holder of each credential, such as the
+	// parent of a child the credential is about, or the delegate of its subject
+	HolderMatchHolder
+	// HolderMatchEither requires the holder of the presentation to be either the holder or a subject of each
+	// credential
+	HolderMatchEither
+)
+
+// WithHolderMatch binds the credentials of a presentation to the presentation's holder, which must be their subject,
+// holder, or either as set by the mode. Credentials are not bound to their presenter by default. The credentials of
+// nested presentations are bound to the holder of the presentation they are in.
+func WithHolderMatch(mode HolderMatchMode) VerifyOption {
+	return VerifyOption{Type: HolderMatchOption, Value: mode}
+}
+
+// holderMatchMode returns the mode of a WithHolderMatch option
+func holderMatchMode(opt VerifyOption) (*HolderMatchMode, error) {
+	mode, ok := opt.Value.(HolderMatchMode)
+	if !ok || mode < HolderMatchSubject || mode > HolderMatchEither {
+		return nil, fmt.Errorf("unknown holder match mode<%v>", opt.Value)
+	}
+	return &mode, nil
+}
+
+// checkCredentialHolder checks the presentation's holder is the party the mode binds the credential to, returning
+// an error wrapping ErrHolderMismatch if not
+func checkCredentialHolder(cred any, holder string, mode HolderMatchMode) error {
+	vc, err := presentedCredential(cred)
+	if err != nil {
+		return err
+	}
+	subjects := vc.CredentialSubjects
+	if subjects == nil && vc.CredentialSubject != nil {
+		subjects = []credential.CredentialSubject{vc.CredentialSubject}
+	}
+	isSubject := holder != "" && slices.ContainsFunc(subjects, func(subject credential.CredentialSubject) bool {
+		return subject.GetID() == holder
+	})
+	isHolder := holder != "" && vc.Holder == holder
+
+	switch mode {
+	case HolderMatchHolder:
+		if !isHolder {
+			return errors.Wrapf(ErrHolderMismatch, "presentation holder<%s> is not the credential's holder<%s>", holder, vc.Holder)
+		}
+	case HolderMatchEither:
+		if !isHolder && !isSubject {
+			return errors.Wrapf(ErrHolderMismatch, "presentation holder<%s> is neither the holder nor a subject of the credential", holder)
+		}
+	default:
+		if !isSubject {
+			return errors.Wrapf(ErrHolderMismatch, "presentation holder<%s> is not a subject of the credential", holder)
+		}
+	}
+	return nil
+}
+
+// presentedCredential returns the credential of an entry of a presentation's verifiableCredential property, which
+// has been verified as a credential
+func presentedCredential(cred any) (*credential.VerifiableCredential, error) {
+	switch typedCred := cred.(type) {
+	case credential.VerifiableCredential:
+		return &typedCred, nil
+	case *credential.VerifiableCredential:
+		return typedCred, nil
+	case map[string]any:
+		var vc credential.VerifiableCredential
+		if err := vc.UnmarshalJSONMap(typedCred); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling credential object")
+		}
+		return &vc, nil
+	case []byte:
+		return presentedCredential(string(typedCred))
+	case string:
+		var vc credential.VerifiableCredential
+		if err := JSONCodec.Unmarshal([]byte(typedCred), &vc); err == nil && !vc.IsEmpty() {
+			return &vc, nil
+		}
+		_, _, parsed, err := parseCredentialJWT(typedCred)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing credential JWT")
+		}
+		return parsed, nil
+	}
+	return nil, fmt.Errorf("unsupported credential type<%T>", cred)
+}
@@ -0,0 +1,109 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestVerifyVerifiablePresentationJWTHolderMatch(t *testing.T) {
+	issuer := getTestDIDKeySigner(t)
+	parent := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	verifier, err := parent.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+
+	issue := func(tt *testing.T, subject, holder string) string {
+		signed, err := SignVerifiableCredentialJWT(issuer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            issuer.ID,
+			Holder:            holder,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": subject, "dateOfBirth": "2019-06-01"},
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	present := func(tt *testing.T, creds ...any) string {
+		signed, err := SignVerifiablePresentationJWT(parent, &JWTVVPParameters{Audience: []string{verifier.ID}}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               parent.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	// a credential about a child, held by their parent
+	guardianship := present(t, issue(t, "did:example:child", parent.ID))
+	// a credential about the parent, with no holder of its own
+	own := present(t, issue(t, parent.ID, ""))
+
+	t.Run("credentials are not bound by default", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, guardianship)
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, present(tt, issue(tt, "did:example:other", "")))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("subject", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, own, WithHolderMatch(HolderMatchSubject))
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, guardianship, WithHolderMatch(HolderMatchSubject))
+		assert.ErrorIs(tt, err, ErrHolderMismatch)
+		assert.ErrorContains(tt, err, "checking holder of credential 0")
+	})
+
+	t.Run("holder", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, guardianship, WithHolderMatch(HolderMatchHolder))
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, own, WithHolderMatch(HolderMatchHolder))
+		assert.ErrorIs(tt, err, ErrHolderMismatch)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, present(tt, issue(tt, "did:example:child", "did:example:other")), WithHolderMatch(HolderMatchHolder))
+		assert.ErrorIs(tt, err, ErrHolderMismatch)
+	})
+
+	t.Run("either", func(tt *testing.T) {
+		both := present(tt, issue(tt, "did:example:child", parent.ID), issue(tt, parent.ID, ""))
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, both, WithHolderMatch(HolderMatchEither))
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, present(tt, issue(tt, "did:example:child", "did:example:other")), WithHolderMatch(HolderMatchEither))
+		assert.ErrorIs(tt, err, ErrHolderMismatch)
+	})
+
+	t.Run("credentials given as objects", func(tt *testing.T) {
+		cred := credential.VerifiableCredential{Holder: parent.ID, CredentialSubject: map[string]any{"id": "did:example:child"}}
+		assert.NoError(tt, checkCredentialHolder(cred, parent.ID, HolderMatchHolder))
+		assert.NoError(tt, checkCredentialHolder(map[string]any{"holder": parent.ID}, parent.ID, HolderMatchEither))
+		assert.ErrorIs(tt, checkCredentialHolder(&cred, parent.ID, HolderMatchSubject), ErrHolderMismatch)
+
+		many := credential.VerifiableCredential{CredentialSubjects: []credential.CredentialSubject{{"id": "did:example:child"}, {"id": parent.ID}}}
+		assert.NoError(tt, checkCredentialHolder(many, parent.ID, HolderMatchSubject))
+
+		// a presentation without a holder matches no credential
+		assert.ErrorIs(tt, checkCredentialHolder(credential.VerifiableCredential{}, "", HolderMatchEither), ErrHolderMismatch)
+	})
+
+	t.Run("in a report", func(tt *testing.T) {
+		report, err := VerifyPresentationReport(context.Background(), *verifier, resolver, present(tt, issue(tt, "did:example:child", parent.ID), issue(tt, parent.ID, "")),
+			WithHolderMatch(HolderMatchHolder))
+		require.NoError(tt, err)
+		assert.False(tt, report.Valid())
+		require.Len(tt, report.Failed(), 1)
+		assert.Equal(tt, 1, report.Failed()[0].Index)
+		assert.ErrorIs(tt, report.Failed()[0].Error, ErrHolderMismatch)
+	})
+
+	t.Run("unknown modes", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, own, WithHolderMatch(HolderMatchMode(7)))
+		assert.ErrorContains(tt, err, "unknown holder match mode<7>")
+	})
+}
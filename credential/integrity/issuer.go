@@ -0,0 +1,98 @@
+package integrity
+
+import (
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+// Issuer signs many credentials with one key, as SignVerifiableCredentialJWT does for a single credential. The
+// algorithm, protected headers, and sign options are prepared once when it is constructed instead of on every call.
+type Issuer struct {
+	opts       []SignOption
+	clock      Clock
+	signOption jwt.SignOption
+}
+
+// NewIssuer prepares an Issuer signing with the signer and the sign options, which apply to every credential issued
+func NewIssuer(signer jwx.Signer, opts ...SignOption) (*Issuer, error) {
+	clock, err := signingClock(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	alg := jwx.SignerAlgorithm(signer)
+	hdrs := jws.NewHeaders()
+	if signer.KID != "" {
+		if err = hdrs.Set(jws.KeyIDKey, signer.KID); err != nil {
+			return nil, errors.Wrap(err, "setting KID protected header")
+		}
+	}
+	if err = hdrs.Set(jws.TypeKey, VCJWTType); err != nil {
+		return nil, errors.Wrap(err, "setting typ protected header")
+	}
+	// signing sets the alg header, so it is set beforehand to leave the shared headers unchanged by each signature
+	if err = hdrs.Set(jws.AlgorithmKey, alg); err != nil {
+		return nil, errors.Wrap(err, "setting alg protected header")
+	}
+	return &Issuer{
+		opts:       opts,
+		clock:      clock,
+		signOption: jwt.WithKey(alg, signer.PrivateKey, jws.WithProtectedHeaders(hdrs)),
+	}, nil
+}
+
+// Issue signs the credential, producing the same token as SignVerifiableCredentialJWT with the Issuer's signer and
+// options
+func (i *Issuer) Issue(cred credential.VerifiableCredential) ([]byte, error) {
+	if err := ValidateCredentialForSigning(cred, i.opts...); err != nil {
+		return nil, err
+	}
+	return i.issue(cred)
+}
+
+func (i *Issuer) issue(cred credential.VerifiableCredential) ([]byte, error) {
+	if cred.IssuanceDate == "" {
+		cred.IssuanceDate = i.clock.Now().UTC().Format(time.RFC3339)
+	}
+	t, err := JWTClaimSetFromVC(cred, i.opts...)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := jwt.Sign(t, i.signOption)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing JWT credential")
+	}
+	return serializeToken(signed, i.opts)
+}
+
+// IssueBatch signs each of the credentials with the Issuer's signer and options, returning the tokens and errors of
+// the credentials at the same indices. A credential that fails validation or signing has a nil token and its error
+// set, without affecting the others; the errors of credentials that are issued are nil.
+func (i *Issuer) IssueBatch(creds []credential.VerifiableCredential) ([][]byte, []error) {
+	issued := make([][]byte, len(creds))
+	errs := make([]error, len(creds))
+	for idx, cred := range creds {
+		issued[idx], errs[idx] = i.Issue(cred)
+	}
+	return issued, errs
+}
+
+// IssueBatch signs many credentials with one signer as the Issuer's IssueBatch does, preparing the algorithm and
+// protected headers once for the batch. If the signer or options are invalid, every credential has that error.
+func IssueBatch(signer jwx.Signer, creds []credential.VerifiableCredential, opts ...SignOption) ([][]byte, []error) {
+	issuer, err := NewIssuer(signer, opts...)
+	if err != nil {
+		errs := make([]error, len(creds))
+		for idx := range errs {
+			errs[idx] = err
+		}
+		return make([][]byte, len(creds)), errs
+	}
+	return issuer.IssueBatch(creds)
+}
@@ -0,0 +1,168 @@
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func testIssuerCredential(issuer, subject string) credential.VerifiableCredential {
+	return credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            issuer,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": subject},
+	}
+}
+
+func TestIssuer(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	t.Run("same token as the single-shot function", func(tt *testing.T) {
+		issuer, err := NewIssuer(signer, WithDeterministicNonce())
+		require.NoError(tt, err)
+		for i := 0; i < 3; i++ {
+			cred := testIssuerCredential(signer.ID, fmt.Sprintf("did:example:%d", i))
+			issued, err := issuer.Issue(cred)
+			require.NoError(tt, err)
+			signed, err := SignVerifiableCredentialJWT(signer, cred, WithDeterministicNonce())
+			require.NoError(tt, err)
+			assert.Equal(tt, string(signed), string(issued))
+
+			verified, err := VerifyCredentialSignature(context.Background(), string(issued), resolver)
+			assert.NoError(tt, err)
+			assert.True(tt, verified)
+		}
+	})
+
+	t.Run("options apply to every credential", func(tt *testing.T) {
+		issuer, err := NewIssuer(signer, RequireIssuanceDate)
+		require.NoError(tt, err)
+		cred := testIssuerCredential(signer.ID, "did:example:456")
+		cred.IssuanceDate = ""
+		_, err = issuer.Issue(cred)
+		assert.ErrorContains(tt, err, "credential must have an issuanceDate")
+
+		_, err = issuer.Issue(credential.VerifiableCredential{})
+		assert.ErrorIs(tt, err, ErrEmptyCredential)
+
+		_, err = NewIssuer(signer, WithSigningClock(nil))
+		assert.ErrorContains(tt, err, "signing clock cannot be empty")
+	})
+}
+
+func TestIssueBatch(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	t.Run("one bad credential does not abort the batch", func(tt *testing.T) {
+		creds := []credential.VerifiableCredential{
+			testIssuerCredential(signer.ID, "did:example:0"),
+			{},
+			testIssuerCredential(signer.ID, "did:example:2"),
+		}
+		creds[2].IssuanceDate = "not a date"
+		creds = append(creds, testIssuerCredential(signer.ID, "did:example:3"))
+
+		issued, errs := IssueBatch(signer, creds, WithDeterministicNonce())
+		require.Len(tt, issued, 4)
+		require.Len(tt, errs, 4)
+		assert.Nil(tt, issued[1])
+		assert.ErrorIs(tt, errs[1], ErrEmptyCredential)
+		assert.Nil(tt, issued[2])
+		assert.ErrorContains(tt, errs[2], "issuanceDate<not a date> is not a valid RFC3339 date")
+		for _, idx := range []int{0, 3} {
+			require.NoError(tt, errs[idx])
+			signed, err := SignVerifiableCredentialJWT(signer, creds[idx], WithDeterministicNonce())
+			require.NoError(tt, err)
+			assert.Equal(tt, string(signed), string(issued[idx]))
+
+			verified, err := VerifyCredentialSignature(context.Background(), string(issued[idx]), resolver)
+			assert.NoError(tt, err)
+			assert.True(tt, verified)
+		}
+	})
+
+	t.Run("invalid options fail every credential", func(tt *testing.T) {
+		creds := []credential.VerifiableCredential{
+			testIssuerCredential(signer.ID, "did:example:0"),
+			testIssuerCredential(signer.ID, "did:example:1"),
+		}
+		issued, errs := IssueBatch(signer, creds, WithSigningClock(nil))
+		assert.Equal(tt, [][]byte{nil, nil}, issued)
+		for _, err := range errs {
+			assert.ErrorContains(tt, err, "signing clock cannot be empty")
+		}
+
+		issued, errs = IssueBatch(signer, nil)
+		assert.Empty(tt, issued)
+		assert.Empty(tt, errs)
+	})
+}
+
+func BenchmarkIssuer(b *testing.B) {
+	signer := getTestDIDKeySigner(b)
+	cred := testIssuerCredential(signer.ID, "did:example:456")
+
+	b.Run("SignVerifiableCredentialJWT", func(bb *testing.B) {
+		bb.ReportAllocs()
+		for i := 0; i < bb.N; i++ {
+			if _, err := SignVerifiableCredentialJWT(signer, cred); err != nil {
+				bb.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Issuer", func(bb *testing.B) {
+		issuer, err := NewIssuer(signer)
+		if err != nil {
+			bb.Fatal(err)
+		}
+		bb.ReportAllocs()
+		bb.ResetTimer()
+		for i := 0; i < bb.N; i++ {
+			if _, err = issuer.Issue(cred); err != nil {
+				bb.Fatal(err)
+			}
+		}
+	})
+
+	// a batch of credentials to different subjects, reported per credential
+	const batchSize = 100
+	batch := make([]credential.VerifiableCredential, batchSize)
+	for i := range batch {
+		batch[i] = testIssuerCredential(signer.ID, fmt.Sprintf("did:example:%d", i))
+	}
+	b.Run("SignVerifiableCredentialJWT per credential", func(bb *testing.B) {
+		bb.ReportAllocs()
+		for i := 0; i < bb.N; i++ {
+			for _, c := range batch {
+				if _, err := SignVerifiableCredentialJWT(signer, c); err != nil {
+					bb.Fatal(err)
+				}
+			}
+		}
+		bb.ReportMetric(float64(bb.Elapsed().Nanoseconds())/float64(bb.N*batchSize), "ns/credential")
+	})
+
+	b.Run("IssueBatch", func(bb *testing.B) {
+		bb.ReportAllocs()
+		for i := 0; i < bb.N; i++ {
+			if _, errs := IssueBatch(signer, batch); errs[0] != nil {
+				bb.Fatal(errs[0])
+			}
+		}
+		bb.ReportMetric(float64(bb.Elapsed().Nanoseconds())/float64(bb.N*batchSize), "ns/credential")
+	})
+}
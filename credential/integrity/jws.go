@@ -1,10 +1,16 @@
 package integrity
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
-	"github.com/goccy/go-json"
-	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/pkg/errors"
 )
@@ -16,7 +22,7 @@ const (
 // SignVerifiableCredentialJWS is prepared according to https://transmute-industries.github.io/vc-jws/.
 // This is currently an experimental. It's unstable and subject to change. Use at your own peril.
 func SignVerifiableCredentialJWS(signer jwx.Signer, cred credential.VerifiableCredential) ([]byte, error) {
-	payload, err := json.Marshal(cred)
+	payload, err := JSONCodec.Marshal(cred)
 	if err != nil {
 		return nil, errors.Wrap(err, "marshalling credential")
 	}
@@ -28,7 +34,7 @@ func SignVerifiableCredentialJWS(signer jwx.Signer, cred credential.VerifiableCr
 	if err = headers.Set(jws.ContentTypeKey, VCMediaType); err != nil {
 		return nil, errors.Wrap(err, "setting content type JOSE header")
 	}
-	signed, err := jws.Sign(payload, jws.WithKey(jwa.SignatureAlgorithm(signer.ALG), signer.PrivateKey, jws.WithProtectedHeaders(headers)))
+	signed, err := jws.Sign(payload, jws.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey, jws.WithProtectedHeaders(headers)))
 	if err != nil {
 		return nil, errors.Wrap(err, "signing JWT credential")
 	}
@@ -40,6 +46,9 @@ func SignVerifiableCredentialJWS(signer jwx.Signer, cred credential.VerifiableCr
 // decodes the payload.
 // This is currently an experimental. It's unstable and subject to change. Use at your own peril.
 func ParseVerifiableCredentialFromJWS(token string) (*jws.Message, *credential.VerifiableCredential, error) {
+	if err := checkTokenSize(token); err != nil {
+		return nil, nil, err
+	}
 	parsed, err := jws.Parse([]byte(token))
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "parsing JWS")
@@ -52,12 +61,18 @@ func ParseVerifiableCredentialFromJWS(token string) (*jws.Message, *credential.V
 		}
 	}
 	if signature == nil {
-		_, _, cred, err := ParseVerifiableCredentialFromJWT(token)
+		_, _, cred, err := parseCredentialJWT(token)
 		return parsed, cred, err
 	}
 
+	payload := parsed.Payload()
+	if !bytes.HasPrefix(bytes.TrimSpace(payload), []byte("{")) {
+		if payload, err = decodeDoubleEncodedCredential(string(payload)); err != nil {
+			return nil, nil, errors.Wrap(err, "malformed JWS payload")
+		}
+	}
 	var cred credential.VerifiableCredential
-	if err = json.Unmarshal(parsed.Payload(), &cred); err != nil {
+	if err = JSONCodec.Unmarshal(payload, &cred); err != nil {
 		return nil, nil, errors.Wrap(err, "reconstructing Verifiable Credential")
 	}
 
@@ -69,7 +84,169 @@ func ParseVerifiableCredentialFromJWS(token string) (*jws.Message, *credential.V
 // This is currently an experimental. It's unstable and subject to change. Use at your own peril.
 func VerifyVerifiableCredentialJWS(verifier jwx.Verifier, token string) (*jws.Message, *credential.VerifiableCredential, error) {
 	if err := verifier.VerifyJWS(token); err != nil {
-		return nil, nil, errors.Wrap(err, "verifying JWS")
+		return nil, nil, errors.Wrap(verificationError(err), "verifying JWS")
 	}
 	return ParseVerifiableCredentialFromJWS(token)
 }
+
+// SignMultiSignatureCredential signs a credential with each of the given signers, producing a JWS using the
+// general JSON serialization https://www.rfc-editor.org/rfc/rfc7515#section-7.2.1 with one signature per signer.
+// This is currently an experimental. It's unstable and subject to change. Use at your own peril.
+func SignMultiSignatureCredential(signers []jwx.Signer, cred credential.VerifiableCredential) ([]byte, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("at least one signer is required")
+	}
+	payload, err := JSONCodec.Marshal(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential")
+	}
+
+	options := []jws.SignOption{jws.WithJSON()}
+	for _, signer := range signers {
+		headers := jws.NewHeaders()
+		if err = headers.Set(jws.KeyIDKey, signer.KID); err != nil {
+			return nil, errors.Wrap(err, "setting key ID JOSE header")
+		}
+		if err = headers.Set(jws.ContentTypeKey, VCMediaType); err != nil {
+			return nil, errors.Wrap(err, "setting content type JOSE header")
+		}
+		options = append(options, jws.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey, jws.WithProtectedHeaders(headers)))
+	}
+	signed, err := jws.Sign(payload, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing multi-signature credential")
+	}
+	return signed, nil
+}
+
+// multiSignatureJWS is the general JSON serialization of a JWS https://www.rfc-editor.org/rfc/rfc7515#section-7.2.1
+// The flattened serialization is supported by way of the top-level protected and signature values.
+type multiSignatureJWS struct {
+	Payload    string                `json:"payload"`
+	Protected  string                `json:"protected,omitempty"`
+	Signature  string                `json:"signature,omitempty"`
+	Signatures []multiSignatureEntry `json:"signatures,omitempty"`
+}
+
+type multiSignatureEntry struct {
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+// compactSignatures returns each signature in the JWS as its own compact serialization over the shared payload
+func (m multiSignatureJWS) compactSignatures() []string {
+	entries := m.Signatures
+	if len(entries) == 0 && m.Signature != "" {
+		entries = []multiSignatureEntry{{Protected: m.Protected, Signature: m.Signature}}
+	}
+	compact := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		compact = append(compact, strings.Join([]string{entry.Protected, m.Payload, entry.Signature}, "."))
+	}
+	return compact
+}
+
+// ParseMultiSignatureCredential parses a JWS in JSON serialization into a credential, without verifying any
+// of its signatures.
+// This is currently an experimental. It's unstable and subject to change. Use at your own peril.
+func ParseMultiSignatureCredential(jwsJSON string) (*credential.VerifiableCredential, error) {
+	var msg multiSignatureJWS
+	if err := JSONCodec.Unmarshal([]byte(jwsJSON), &msg); err != nil {
+		return nil, errors.Wrap(err, "parsing JWS JSON serialization")
+	}
+	return credentialFromMultiSignaturePayload(msg.Payload)
+}
+
+func credentialFromMultiSignaturePayload(encodedPayload string) (*credential.VerifiableCredential, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding JWS payload")
+	}
+	var cred credential.VerifiableCredential
+	if err = JSONCodec.Unmarshal(payload, &cred); err != nil {
+		return nil, errors.Wrap(err, "reconstructing Verifiable Credential")
+	}
+	if cred.IsEmpty() {
+		return nil, errors.New("JWS payload is not a valid credential")
+	}
+	return &cred, nil
+}
+
+// VerifyMultiSignatureCredential verifies a credential carried as the payload of a JWS in JSON serialization,
+// which may hold multiple signatures. Each signature is verified against the key identified by the `kid` in its
+// protected header, which must be a fully qualified DID URL resolvable by the given resolver to a DID that is not
+// deactivated. Verification passes only if at least `threshold` signatures are valid. The credential is returned
+// along with the DIDs of the signers whose signatures are valid.
+// This is currently an experimental. It's unstable and subject to change. Use at your own peril.
+func VerifyMultiSignatureCredential(ctx context.Context, jwsJSON string, r resolution.Resolver, threshold int) (*credential.VerifiableCredential, []string, error) {
+	if r == nil {
+		return nil, nil, errors.New("resolution cannot be empty")
+	}
+	if threshold < 1 {
+		return nil, nil, errors.New("threshold must be at least 1")
+	}
+	var msg multiSignatureJWS
+	if err := JSONCodec.Unmarshal([]byte(jwsJSON), &msg); err != nil {
+		return nil, nil, errors.Wrap(err, "parsing JWS JSON serialization")
+	}
+	cred, err := credentialFromMultiSignaturePayload(msg.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var signers []string
+	errs := util.NewAppendError()
+	for i, compact := range msg.compactSignatures() {
+		signer, err := verifySingleSignature(ctx, compact, r)
+		if err != nil {
+			errs.Append(errors.Wrapf(err, "signature %d", i))
+			continue
+		}
+		// a signer is only counted once, regardless of how many valid signatures it has produced
+		if !util.Contains(signer, signers) {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) < threshold {
+		if errs.IsEmpty() {
+			return nil, signers, errors.Errorf("%d valid signature(s) found; threshold of %d not met", len(signers), threshold)
+		}
+		return nil, signers, errors.Wrapf(errs.Error(), "%d valid signature(s) found; threshold of %d not met", len(signers), threshold)
+	}
+	return cred, signers, nil
+}
+
+// verifySingleSignature verifies a compact JWS against the key referenced by its kid, returning the signer's DID
+func verifySingleSignature(ctx context.Context, compact string, r resolution.Resolver) (string, error) {
+	headers, err := jwx.GetJWSHeaders([]byte(compact))
+	if err != nil {
+		return "", errors.Wrap(err, "getting JWS headers")
+	}
+	kid := headers.KeyID()
+	if kid == "" {
+		return "", errors.Wrap(ErrMissingKID, "missing kid in header")
+	}
+	signerDID, _, found := strings.Cut(kid, "#")
+	if !found || signerDID == "" {
+		return "", errors.Errorf("kid<%s> is not a fully qualified DID URL", kid)
+	}
+	resolved, err := r.Resolve(ctx, signerDID)
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving signer DID<%s>", signerDID)
+	}
+	if resolved.IsDeactivated() {
+		return "", errors.Wrapf(ErrDeactivatedDID, "signer DID<%s>", signerDID)
+	}
+	signerKey, err := did.GetKeyFromVerificationMethod(resolved.Document, kid)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting key<%s> to verify signature", kid)
+	}
+	verifier, err := jwx.NewJWXVerifier(signerDID, &kid, signerKey)
+	if err != nil {
+		return "", errors.Wrapf(err, "constructing verifier for key<%s>", kid)
+	}
+	if err = verifier.VerifyJWSWithContext(ctx, compact); err != nil {
+		return "", verificationError(err)
+	}
+	return signerDID, nil
+}
@@ -2,12 +2,18 @@ package integrity
 
 import (
 	"context"
+	"encoding/base64"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
 	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVerifiableCredentialJWS(t *testing.T) {
@@ -89,3 +95,100 @@ func TestVerifiableCredentialJWS(t *testing.T) {
 		assert.Equal(tt, &testCredential, parsedCred)
 	})
 }
+
+func TestMultiSignatureCredential(t *testing.T) {
+	testCredential := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []any{"VerifiableCredential"},
+		Issuer:            "did:example:123",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	}
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	newDIDKeySigner := func(tt *testing.T, kt crypto.KeyType) jwx.Signer {
+		privKey, didKey, err := key.GenerateDIDKey(kt)
+		require.NoError(tt, err)
+		expanded, err := didKey.Expand()
+		require.NoError(tt, err)
+		kid := expanded.VerificationMethod[0].ID
+		signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+		require.NoError(tt, err)
+		return *signer
+	}
+
+	t.Run("threshold met", func(tt *testing.T) {
+		signerA := newDIDKeySigner(tt, crypto.Ed25519)
+		signerB := newDIDKeySigner(tt, crypto.P256)
+		signed, err := SignMultiSignatureCredential([]jwx.Signer{signerA, signerB}, testCredential)
+		require.NoError(tt, err)
+
+		parsed, err := ParseMultiSignatureCredential(string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, &testCredential, parsed)
+
+		cred, signers, err := VerifyMultiSignatureCredential(context.Background(), string(signed), resolver, 2)
+		assert.NoError(tt, err)
+		assert.Equal(tt, &testCredential, cred)
+		assert.ElementsMatch(tt, []string{signerA.ID, signerB.ID}, signers)
+	})
+
+	t.Run("threshold not met with an invalid signature", func(tt *testing.T) {
+		signerA := newDIDKeySigner(tt, crypto.Ed25519)
+		signerB := newDIDKeySigner(tt, crypto.Ed25519)
+		// sign with B's key, but claim to be A's key
+		impostor := signerB
+		impostor.PrivateKeyJWK.KID = signerA.KID
+		signed, err := SignMultiSignatureCredential([]jwx.Signer{signerA, impostor}, testCredential)
+		require.NoError(tt, err)
+
+		_, signers, err := VerifyMultiSignatureCredential(context.Background(), string(signed), resolver, 2)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "threshold of 2 not met")
+		assert.Equal(tt, []string{signerA.ID}, signers)
+
+		cred, _, err := VerifyMultiSignatureCredential(context.Background(), string(signed), resolver, 1)
+		assert.NoError(tt, err)
+		assert.Equal(tt, &testCredential, cred)
+	})
+
+	t.Run("flattened serialization", func(tt *testing.T) {
+		signer := newDIDKeySigner(tt, crypto.Ed25519)
+		signed, err := SignMultiSignatureCredential([]jwx.Signer{signer}, testCredential)
+		require.NoError(tt, err)
+
+		_, signers, err := VerifyMultiSignatureCredential(context.Background(), string(signed), resolver, 1)
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{signer.ID}, signers)
+	})
+
+	t.Run("bad threshold", func(tt *testing.T) {
+		_, _, err := VerifyMultiSignatureCredential(context.Background(), "{}", resolver, 0)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "threshold must be at least 1")
+	})
+}
+
+func TestParseVerifiableCredentialFromJWSDoubleEncoded(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	vcJSON := `{"@context":["https://www.w3.org/2018/credentials/v1"],"type":["VerifiableCredential"],` +
+		`"issuer":"did:example:123","issuanceDate":"2021-01-01T19:23:24Z","credentialSubject":{"name":"JimBobertson"}}`
+
+	headers := jws.NewHeaders()
+	require.NoError(t, headers.Set(jws.ContentTypeKey, VCMediaType))
+	signed, err := jws.Sign([]byte(base64.RawURLEncoding.EncodeToString([]byte(vcJSON))),
+		jws.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey, jws.WithProtectedHeaders(headers)))
+	require.NoError(t, err)
+
+	_, cred, err := ParseVerifiableCredentialFromJWS(string(signed))
+	assert.NoError(t, err)
+	assert.Equal(t, "did:example:123", cred.Issuer)
+	assert.Equal(t, "JimBobertson", cred.CredentialSubject["name"])
+
+	signed, err = jws.Sign([]byte("not base64url!"),
+		jws.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey, jws.WithProtectedHeaders(headers)))
+	require.NoError(t, err)
+	_, _, err = ParseVerifiableCredentialFromJWS(string(signed))
+	assert.ErrorContains(t, err, "malformed JWS payload")
+}
@@ -2,63 +2,242 @@ package integrity
 
 import (
 	"context"
+	gocrypto "crypto"
+	"encoding/base64"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did"
+	didjwk "github.com/TBD54566975/ssi-sdk/did/jwk"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
 
-	"github.com/goccy/go-json"
 	"github.com/google/uuid"
-	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 const (
 	VCJWTProperty string = "vc"
 	VPJWTProperty string = "vp"
 	NonceProperty string = "nonce"
+	// ProofPurposeProperty is the claim of a presentation JWT recording the verification relationship of its key
+	ProofPurposeProperty string = "proofPurpose"
+
+	// VCJWTType and VPJWTType are the values of the typ header identifying credential and presentation JWTs
+	VCJWTType string = "vc+jwt"
+	VPJWTType string = "vp+jwt"
 )
 
-// SignVerifiableCredentialJWT is prepared according to https://w3c.github.io/vc-jwt/#version-1.1
-// which will soon be deprecated by https://w3c.github.io/vc-jwt/ see: https://github.com/TBD54566975/ssi-sdk/issues/191
-func SignVerifiableCredentialJWT(signer jwx.Signer, cred credential.VerifiableCredential) ([]byte, error) {
-	if cred.IsEmpty() {
-		return nil, errors.New("credential cannot be empty")
+type SignOptionType string
+
+const (
+	RequireIssuanceDateOption SignOptionType = "RequireIssuanceDate"
+	SigningClockOption        SignOptionType = "SigningClock"
+	DeterministicNonceOption  SignOptionType = "DeterministicNonce"
+	JSONSerializationOption   SignOptionType = "JSONSerialization"
+)
+
+// SignOption changes how a credential or presentation is checked and prepared before it is signed
+type SignOption struct {
+	Type  SignOptionType
+	Value any
+}
+
+var (
+	// RequireIssuanceDate disables defaulting an empty issuanceDate to the current time when signing, so that an
+	// issuanceDate must be set explicitly
+	RequireIssuanceDate = SignOption{Type: RequireIssuanceDateOption}
+
+	// deterministicNonceNamespace is the namespace of the name-based UUIDs used as deterministic nonces
+	deterministicNonceNamespace = uuid.MustParse("a832d00d-9435-496a-920d-676cee35bdc2")
+)
+
+// WithDeterministicNonce derives the nonce of a credential from a hash of its other claims rather than generating a
+// random one, so that signing the same credential twice yields the same claims. With a deterministic signature
+// algorithm such as EdDSA the tokens are then identical, which lets a retried issuance request be deduplicated. The
+// issuanceDate must be set, or come from a fixed signing clock, for the claims to be the same.
+func WithDeterministicNonce() SignOption {
+	return SignOption{Type: DeterministicNonceOption}
+}
+
+// WithJSONSerialization produces tokens in the general JSON serialization of JWS
+// https://www.rfc-editor.org/rfc/rfc7515#section-7.2.1 rather than the compact one, for tooling that only consumes
+// JSON. The tokens are parsed and verified like compact ones.
+func WithJSONSerialization() SignOption {
+	return SignOption{Type: JSONSerializationOption}
+}
+
+// SignVerifiableCredentialJWTJSON signs a credential as SignVerifiableCredentialJWT does, returning the token in the
+// general JSON serialization of JWS
+func SignVerifiableCredentialJWTJSON(signer jwx.Signer, cred credential.VerifiableCredential, opts ...SignOption) ([]byte, error) {
+	return SignVerifiableCredentialJWT(signer, cred, append(opts, WithJSONSerialization())...)
+}
+
+// JWTParts are the parts of a signed JWT in compact serialization, decoded from their base64url encoding
+type JWTParts struct {
+	// ProtectedHeader is the JSON of the protected header, as it was signed
+	ProtectedHeader []byte
+	// Payload is the JSON of the claims
+	Payload []byte
+	// Signature is the signature over the bytes returned by SigningInput
+	Signature []byte
+	// Token is the compact serialization of the parts
+	Token []byte
+}
+
+// SigningInput returns the bytes the signature is computed over: the base64url encoded protected header and payload,
+// joined by a period https://www.rfc-editor.org/rfc/rfc7515#section-5.1
+func (p JWTParts) SigningInput() []byte {
+	encoding := base64.RawURLEncoding
+	input := make([]byte, 0, encoding.EncodedLen(len(p.ProtectedHeader))+1+encoding.EncodedLen(len(p.Payload)))
+	input = encoding.AppendEncode(input, p.ProtectedHeader)
+	input = append(input, '.')
+	return encoding.AppendEncode(input, p.Payload)
+}
+
+// SignVerifiableCredentialJWTParts signs a credential as SignVerifiableCredentialJWT does, returning the protected
+// header, payload, and signature of the token separately along with the token, for conversion to formats such as
+// JAdES. The parts are those of the compact serialization, so the WithJSONSerialization option cannot be given.
+func SignVerifiableCredentialJWTParts(signer jwx.Signer, cred credential.VerifiableCredential, opts ...SignOption) (*JWTParts, error) {
+	if hasSignOption(opts, JSONSerializationOption) {
+		return nil, errors.New("the parts of a token are those of its compact serialization, which WithJSONSerialization replaces")
 	}
-	if cred.Proof != nil {
-		return nil, errors.New("credential cannot already have a proof")
+	signed, err := SignVerifiableCredentialJWT(signer, cred, opts...)
+	if err != nil {
+		return nil, err
+	}
+	protected, payload, signature, err := jws.SplitCompact(signed)
+	if err != nil {
+		return nil, errors.Wrap(err, "splitting signed token")
+	}
+	parts := JWTParts{Token: signed}
+	if parts.ProtectedHeader, err = base64.RawURLEncoding.DecodeString(string(protected)); err != nil {
+		return nil, errors.Wrap(err, "decoding protected header")
+	}
+	if parts.Payload, err = base64.RawURLEncoding.DecodeString(string(payload)); err != nil {
+		return nil, errors.Wrap(err, "decoding payload")
 	}
+	if parts.Signature, err = base64.RawURLEncoding.DecodeString(string(signature)); err != nil {
+		return nil, errors.Wrap(err, "decoding signature")
+	}
+	return &parts, nil
+}
+
+// serializeToken converts a signed compact token to the JSON serialization when the WithJSONSerialization option is
+// given
+func serializeToken(signed []byte, opts []SignOption) ([]byte, error) {
+	if !hasSignOption(opts, JSONSerializationOption) {
+		return signed, nil
+	}
+	return jwx.ToJSONSerialization(signed)
+}
 
-	t, err := JWTClaimSetFromVC(cred)
+// compactToken converts a token in the JSON serialization of JWS to the compact one, which is how tokens are parsed.
+// Tokens exceeding MaxTokenSize or MaxJSONDepth are rejected with ErrTokenTooLarge.
+func compactToken(token string) (string, error) {
+	if err := checkTokenSize(token); err != nil {
+		return "", err
+	}
+	compact, err := jwx.ToCompactSerialization([]byte(token))
+	if err != nil {
+		return "", errors.Wrap(err, "converting token to compact serialization")
+	}
+	if err = checkTokenDepth(string(compact)); err != nil {
+		return "", err
+	}
+	return string(compact), nil
+}
+
+// SignVerifiableCredentialJWT is prepared according to https://w3c.github.io/vc-jwt/#version-1.1
+// which will soon be deprecated by https://w3c.github.io/vc-jwt/ see: https://github.com/TBD54566975/ssi-sdk/issues/191
+// An empty issuanceDate is set to the current time, as told by the clock of the WithSigningClock option, unless the
+// RequireIssuanceDate option is given. The token is in compact serialization unless the WithJSONSerialization option
+// is given.
+func SignVerifiableCredentialJWT(signer jwx.Signer, cred credential.VerifiableCredential, opts ...SignOption) ([]byte, error) {
+	if err := ValidateCredentialForSigning(cred, opts...); err != nil {
+		return nil, err
+	}
+	issuer, err := NewIssuer(signer, opts...)
 	if err != nil {
 		return nil, err
 	}
+	return issuer.issue(cred)
+}
 
-	hdrs := jws.NewHeaders()
-	if signer.KID != "" {
-		if err := hdrs.Set(jws.KeyIDKey, signer.KID); err != nil {
-			return nil, errors.Wrap(err, "setting KID protected header")
+// ValidateCredentialForSigning runs the checks SignVerifiableCredentialJWT performs before signing without requiring
+// a key: the credential must be non-empty, must not already have a proof, and must have an issuer and dates that
+// can be represented as JWT claims. All problems found are returned together.
+func ValidateCredentialForSigning(cred credential.VerifiableCredential, opts ...SignOption) error {
+	if cred.IsEmpty() {
+		return ErrEmptyCredential
+	}
+
+	errs := util.NewAppendError()
+
+	// set each claim on a scratch token, which is how the values are validated during signing
+	t := jwt.New()
+	if cred.ExpirationDate != "" {
+		if err := t.Set(jwt.ExpirationKey, cred.ExpirationDate); err != nil {
+			errs.Append(errors.Wrap(err, "setting exp value"))
+		}
+	}
+	issuer := cred.Issuer
+	if cred.IsIssuerObject() {
+		if id, err := cred.GetIssuerID(); err != nil {
+			errs.Append(err)
+		} else {
+			issuer = id
+		}
+	}
+	if err := t.Set(jwt.IssuerKey, issuer); err != nil {
+		errs.Append(errors.Wrap(err, "setting iss value"))
+	}
+	switch {
+	case cred.IssuanceDate == "":
+		if hasSignOption(opts, RequireIssuanceDateOption) {
+			errs.AppendString("credential must have an issuanceDate")
+		}
+	default:
+		if _, err := time.Parse(time.RFC3339, cred.IssuanceDate); err != nil {
+			errs.Append(errors.Wrapf(err, "issuanceDate<%s> is not a valid RFC3339 date", cred.IssuanceDate))
+		} else if err = t.Set(jwt.IssuedAtKey, cred.IssuanceDate); err != nil {
+			errs.Append(errors.Wrap(err, "setting iat value"))
 		}
 	}
 
-	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
-	alg := signer.ALG
-	if alg == "Ed25519" {
-		alg = jwa.EdDSA.String()
+	// a proof is reported with ErrProofPresent, along with any other problems found
+	if cred.Proof != nil {
+		if err := errs.Error(); err != nil {
+			return errors.Wrapf(ErrProofPresent, "credential cannot already have a proof; %s", err)
+		}
+		return errors.Wrap(ErrProofPresent, "credential cannot already have a proof")
 	}
-	signed, err := jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
-	if err != nil {
-		return nil, errors.Wrap(err, "signing JWT credential")
+	return errs.Error()
+}
+
+func hasSignOption(opts []SignOption, want SignOptionType) bool {
+	for _, opt := range opts {
+		if opt.Type == want {
+			return true
+		}
 	}
-	return signed, nil
+	return false
 }
 
 // JWTClaimSetFromVC create a JWT claimset from the given cred according to https://w3c.github.io/vc-jwt/#version-1.1.
-func JWTClaimSetFromVC(cred credential.VerifiableCredential) (jwt.Token, error) {
+// The nonce is random unless the WithDeterministicNonce option is given.
+func JWTClaimSetFromVC(cred credential.VerifiableCredential, opts ...SignOption) (jwt.Token, error) {
 	t := jwt.New()
 	if cred.ExpirationDate != "" {
 		if err := t.Set(jwt.ExpirationKey, cred.ExpirationDate); err != nil {
@@ -69,15 +248,21 @@ func JWTClaimSetFromVC(cred credential.VerifiableCredential) (jwt.Token, error)
 		cred.ExpirationDate = ""
 	}
 
-	if err := t.Set(NonceProperty, uuid.New().String()); err != nil {
-		return nil, errors.Wrap(err, "setting nonce value")
+	issuer := cred.Issuer
+	if cred.IsIssuerObject() {
+		id, err := cred.GetIssuerID()
+		if err != nil {
+			return nil, err
+		}
+		issuer = id
 	}
-
-	if err := t.Set(jwt.IssuerKey, cred.Issuer); err != nil {
-		return nil, errors.Wrap(err, "setting exp value")
+	if err := t.Set(jwt.IssuerKey, issuer); err != nil {
+		return nil, errors.Wrap(err, "setting iss value")
+	}
+	// remove the issuer from the credential, unless it is an object holding metadata of the issuer beyond its id
+	if !cred.IsIssuerObject() {
+		cred.Issuer = nil
 	}
-	// remove the issuer from the credential
-	cred.Issuer = nil
 
 	if err := t.Set(jwt.IssuedAtKey, cred.IssuanceDate); err != nil {
 		return nil, errors.Wrap(err, "setting iat value")
@@ -97,37 +282,283 @@ func JWTClaimSetFromVC(cred credential.VerifiableCredential) (jwt.Token, error)
 		cred.ID = ""
 	}
 
-	subVal := cred.CredentialSubject.GetID()
-	if subVal != "" {
-		if err := t.Set(jwt.SubjectKey, subVal); err != nil {
-			return nil, errors.Wrap(err, "setting subject value")
+	// the sub claim is only set when there is a single subject with an id; multiple subjects are left intact
+	switch len(cred.CredentialSubjects) {
+	case 0:
+		if subVal := cred.CredentialSubject.GetID(); subVal != "" {
+			if err := t.Set(jwt.SubjectKey, subVal); err != nil {
+				return nil, errors.Wrap(err, "setting subject value")
+			}
+			cred.CredentialSubject = withoutSubjectID(cred.CredentialSubject)
+		}
+	case 1:
+		if subVal := cred.CredentialSubjects[0].GetID(); subVal != "" {
+			if err := t.Set(jwt.SubjectKey, subVal); err != nil {
+				return nil, errors.Wrap(err, "setting subject value")
+			}
+			cred.CredentialSubjects = []credential.CredentialSubject{withoutSubjectID(cred.CredentialSubjects[0])}
 		}
-		// remove the id from the credential subject
-		delete(cred.CredentialSubject, "id")
 	}
 
 	if err := t.Set(VCJWTProperty, cred); err != nil {
 		return nil, errors.New("setting credential value")
 	}
+
+	nonce := uuid.New()
+	if hasSignOption(opts, DeterministicNonceOption) {
+		// the claims are marshalled with sorted keys, so the same claims always hash to the same nonce
+		claims, err := JSONCodec.Marshal(t)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling claims for nonce")
+		}
+		nonce = uuid.NewSHA1(deterministicNonceNamespace, claims)
+	}
+	if err := t.Set(NonceProperty, nonce.String()); err != nil {
+		return nil, errors.Wrap(err, "setting nonce value")
+	}
 	return t, nil
 }
 
+// withoutSubjectID returns a copy of the subject without its id, leaving the caller's credential untouched
+func withoutSubjectID(subject credential.CredentialSubject) credential.CredentialSubject {
+	subjectCopy := make(credential.CredentialSubject, len(subject))
+	for k, v := range subject {
+		subjectCopy[k] = v
+	}
+	delete(subjectCopy, credential.VerifiableCredentialIDProperty)
+	return subjectCopy
+}
+
 // VerifyVerifiableCredentialJWT verifies the signature validity on the token and parses
-// the token in a verifiable credential.
+// the token in a verifiable credential. The WithClock and WithClockSkew options change the time its claims are
+// validated at. The verifier may be one of the keys an issuer publishes at a JWKS URL, see jwx.NewJWKSVerifier.
+// With WithStrictMode, the first entry of the credential's @context must be the base context of its data model.
 // TODO(gabe) modify this to add additional validation steps such as credential status, expiration, etc.
 // related to https://github.com/TBD54566975/ssi-service/issues/122
-func VerifyVerifiableCredentialJWT(verifier jwx.Verifier, token string) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
-	if err := verifier.Verify(token); err != nil {
-		return nil, nil, nil, errors.Wrap(err, "verifying JWT")
+func VerifyVerifiableCredentialJWT(verifier jwx.Verifier, token string, opts ...VerifyOption) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
+	strict := hasVerifyOption(opts, StrictOption)
+	timing, err := credentialTimeValidation(withoutVerifyOption(opts, StrictOption))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if token, err = compactToken(token); err != nil {
+		return nil, nil, nil, err
+	}
+	headers, parsed, cred, err := parseCredentialJWT(token)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// inconsistent claims are rejected regardless of signature validity
+	if err = checkClaimConsistency(parsed); err != nil {
+		return nil, nil, nil, err
+	}
+	if err = verifier.Verify(token, timing.parseOptions()...); err != nil {
+		return nil, nil, nil, errors.Wrap(verificationError(err), "verifying JWT")
+	}
+	if strict {
+		if err = cred.ValidateBaseContext(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return headers, parsed, cred, nil
+}
+
+// RenewCredentialJWT re-issues a credential JWT signed with the signer's key, typically as it nears expiry. The
+// claims of the credential are kept, but it is given a fresh jti, an iat and nbf of now, and the new expiration.
+// The old token's signature is verified first, so an expired or forged credential cannot be renewed.
+func RenewCredentialJWT(signer jwx.Signer, oldToken string, newExpiration time.Time) ([]byte, error) {
+	verifier, err := signer.ToVerifier(signer.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "constructing verifier")
+	}
+	_, _, cred, err := VerifyVerifiableCredentialJWT(*verifier, oldToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying credential to renew")
+	}
+	return renewCredential(signer, *cred, newExpiration)
+}
+
+// RenewCredentialJWTWithResolver re-issues a credential JWT as RenewCredentialJWT does, verifying the old token with
+// its issuer's key resolved from its kid, which allows renewing credentials signed with a key the issuer has since
+// rotated.
+func RenewCredentialJWTWithResolver(ctx context.Context, signer jwx.Signer, r resolution.Resolver, oldToken string, newExpiration time.Time) ([]byte, error) {
+	if _, err := VerifyJWTCredential(ctx, oldToken, r); err != nil {
+		return nil, errors.Wrap(err, "verifying credential to renew")
+	}
+	_, _, cred, err := parseCredentialJWT(oldToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing credential to renew")
+	}
+	return renewCredential(signer, *cred, newExpiration)
+}
+
+func renewCredential(signer jwx.Signer, cred credential.VerifiableCredential, newExpiration time.Time) ([]byte, error) {
+	if issuer := cred.IssuerID(); issuer != signer.ID {
+		return nil, errors.Wrapf(ErrIssuerMismatch, "credential issuer<%s> does not match signer<%s>", issuer, signer.ID)
+	}
+	now := time.Now().UTC()
+	if !newExpiration.After(now) {
+		return nil, errors.Errorf("new expiration<%s> must be in the future", newExpiration.Format(time.RFC3339))
+	}
+	cred.ID = uuid.NewString()
+	cred.Proof = nil
+	cred.IssuanceDate = now.Format(time.RFC3339)
+	cred.ExpirationDate = newExpiration.UTC().Format(time.RFC3339)
+	return SignVerifiableCredentialJWT(signer, cred)
+}
+
+// VerifyWithHeaderJWK verifies a credential JWT with the public key carried in its jwk header rather than with a key
+// known to the caller, as for self-issued credentials. Such a key is self-asserted, so allowHeaderKey must be set to
+// opt in to trusting it. When the issuer is a did:jwk the header key must be the key of the DID.
+func VerifyWithHeaderJWK(token string, allowHeaderKey bool) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
+	if !allowHeaderKey {
+		return nil, nil, nil, errors.New("verifying with the jwk header requires allowHeaderKey, as the key is self-asserted")
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(token))
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "getting JWT headers")
+	}
+	headerKey := headers.JWK()
+	if headerKey == nil {
+		return nil, nil, nil, errors.New("token has no jwk header")
+	}
+	publicKeyJWK, err := publicKeyJWKOf(headerKey)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "converting jwk header")
+	}
+
+	_, parsed, _, err := parseCredentialJWT(token)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "parsing credential token")
+	}
+	issuer := parsed.Issuer()
+	if issuer == "" {
+		return nil, nil, nil, errors.Wrap(ErrMissingClaim, "token has no issuer")
+	}
+	if strings.HasPrefix(issuer, didjwk.Prefix) {
+		if err = checkDIDJWKKey(issuer, *publicKeyJWK); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	verifier, err := jwx.NewJWXVerifierFromJWK(issuer, *publicKeyJWK)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "constructing verifier from jwk header")
+	}
+	return VerifyVerifiableCredentialJWT(*verifier, token)
+}
+
+// VerifyCredentialWithJWK verifies a credential JWT directly against its issuer's public key, known out-of-band,
+// without resolving the issuer, and parses it. The token's kid, when both it and the key have one, must name the key,
+// either exactly or as the fragment of a DID URL, and its alg must be the one the key verifies with.
+func VerifyCredentialWithJWK(token string, issuerJWK jwk.Key) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
+	if issuerJWK == nil {
+		return nil, nil, nil, errors.New("issuer jwk cannot be empty")
+	}
+	publicKeyJWK, err := publicKeyJWKOf(issuerJWK)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "converting issuer jwk")
+	}
+	headers, parsed, _, err := parseCredentialJWT(token)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "parsing credential token")
+	}
+	issuer := parsed.Issuer()
+	if issuer == "" {
+		return nil, nil, nil, errors.Wrap(ErrMissingClaim, "token has no issuer")
+	}
+
+	if kid, keyID := headers.KeyID(), issuerJWK.KeyID(); kid != "" && keyID != "" &&
+		kid != keyID && !strings.HasSuffix(kid, "#"+keyID) {
+		return nil, nil, nil, errors.Wrapf(ErrIssuerMismatch, "kid<%s> does not name the issuer key<%s>", kid, keyID)
+	}
+	verifier, err := jwx.NewJWXVerifierFromJWK(issuer, *publicKeyJWK)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "constructing verifier from issuer jwk")
+	}
+	alg := headers.Algorithm()
+	if keyAlg := issuerJWK.Algorithm(); keyAlg != nil && keyAlg.String() != "" && keyAlg.String() != alg.String() {
+		return nil, nil, nil, errors.Wrapf(ErrSignatureInvalid, "alg<%s> is not the alg<%s> of the issuer key", alg, keyAlg)
+	}
+	if !jwx.VerifiesAlgorithm(*verifier, alg) {
+		return nil, nil, nil, errors.Wrapf(ErrSignatureInvalid, "alg<%s> cannot be verified with the issuer key, which verifies alg<%s>", alg, jwx.VerifierAlgorithm(*verifier))
+	}
+	return VerifyVerifiableCredentialJWT(*verifier, token)
+}
+
+// publicKeyJWKOf converts the public part of a key to a JWK of this module
+func publicKeyJWKOf(key jwk.Key) (*jwx.PublicKeyJWK, error) {
+	publicKey, err := jwk.PublicKeyOf(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting public key")
 	}
-	return ParseVerifiableCredentialFromJWT(token)
+	publicKeyBytes, err := JSONCodec.Marshal(publicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling public key")
+	}
+	var publicKeyJWK jwx.PublicKeyJWK
+	if err = JSONCodec.Unmarshal(publicKeyBytes, &publicKeyJWK); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling public key")
+	}
+	return &publicKeyJWK, nil
+}
+
+// checkDIDJWKKey makes sure the given key is the key of the did:jwk, comparing their thumbprints
+func checkDIDJWKKey(issuer string, key jwx.PublicKeyJWK) error {
+	doc, err := didjwk.JWK(issuer).Expand()
+	if err != nil {
+		return errors.Wrapf(err, "expanding issuer<%s>", issuer)
+	}
+	issuerThumbprint, err := doc.VerificationMethod[0].PublicKeyJWK.Thumbprint()
+	if err != nil {
+		return errors.Wrapf(err, "computing thumbprint of issuer<%s> key", issuer)
+	}
+	headerThumbprint, err := key.Thumbprint()
+	if err != nil {
+		return errors.Wrap(err, "computing thumbprint of jwk header")
+	}
+	if issuerThumbprint != headerThumbprint {
+		return errors.Wrapf(ErrIssuerMismatch, "jwk header is not the key of issuer<%s>", issuer)
+	}
+	return nil
+}
+
+// checkClaimConsistency makes sure the iat, nbf, and exp claims of a token, when present, are in a possible order.
+// A token cannot expire before or at the moment it becomes valid or is issued, and cannot become valid before it
+// was issued.
+func checkClaimConsistency(token jwt.Token) error {
+	iat, nbf, exp := token.IssuedAt(), token.NotBefore(), token.Expiration()
+	if !exp.IsZero() && !nbf.IsZero() && !exp.After(nbf) {
+		return errors.Wrapf(ErrInconsistentClaims, "exp<%s> is not after nbf<%s>", exp.Format(time.RFC3339), nbf.Format(time.RFC3339))
+	}
+	if !nbf.IsZero() && !iat.IsZero() && nbf.Before(iat) {
+		return errors.Wrapf(ErrInconsistentClaims, "nbf<%s> is before iat<%s>", nbf.Format(time.RFC3339), iat.Format(time.RFC3339))
+	}
+	if !exp.IsZero() && !iat.IsZero() && !exp.After(iat) {
+		return errors.Wrapf(ErrInconsistentClaims, "exp<%s> is not after iat<%s>", exp.Format(time.RFC3339), iat.Format(time.RFC3339))
+	}
+	return nil
 }
 
 // ParseVerifiableCredentialFromJWT the JWT is decoded according to the specification.
 // https://www.w3.org/TR/vc-data-model/#jwt-decoding
 // If there are any issues during decoding, an error is returned. As a result, a successfully
-// decoded VerifiableCredential object is returned.
+// decoded VerifiableCredential object is returned. The token may be in the compact or JSON serialization of JWS.
+// The signature of the token is NOT verified, so nothing in the result can be trusted to come from its issuer; use
+// VerifyJWTCredential to verify a credential before relying on it.
+//
+// Deprecated: use ParseVerifiableCredentialFromJWTUnverified, whose result must be trusted explicitly before the
+// credential can be accessed.
 func ParseVerifiableCredentialFromJWT(token string) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
+	return parseCredentialJWT(token)
+}
+
+// parseCredentialJWT parses a credential JWT without verifying its signature
+func parseCredentialJWT(token string) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
+	token, err := compactToken(token)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	parsed, err := jwt.Parse([]byte(token), jwt.WithValidate(false), jwt.WithVerify(false))
 	if err != nil {
 		return nil, nil, nil, errors.Wrap(err, "parsing credential token")
@@ -138,6 +569,9 @@ func ParseVerifiableCredentialFromJWT(token string) (jws.Headers, jwt.Token, *cr
 	if err != nil {
 		return nil, nil, nil, errors.Wrap(err, "getting JWT headers")
 	}
+	if err = checkJWTType(headers, VCJWTType, VCJWTProperty); err != nil {
+		return nil, nil, nil, err
+	}
 
 	// parse remaining JWT properties and set in the credential
 	cred, err := ParseVerifiableCredentialFromToken(parsed)
@@ -148,20 +582,92 @@ func ParseVerifiableCredentialFromJWT(token string) (jws.Headers, jwt.Token, *cr
 	return headers, parsed, cred, nil
 }
 
+// ParsedCredential is a credential JWT parsed by ParseVerifiableCredential or
+// ParseVerifiableCredentialFromJWTUnverified, along with the token it was parsed from
+type ParsedCredential struct {
+	Headers    jws.Headers
+	Token      jwt.Token
+	Credential *credential.VerifiableCredential
+	// Raw is the token exactly as it was parsed, so that it can be stored or forwarded without being serialized again
+	Raw string
+}
+
+// ParseVerifiableCredential parses a credential JWT, keeping the original token alongside the parsed credential. The
+// signature is not verified, see ParseVerifiableCredentialFromJWTUnverified.
+func ParseVerifiableCredential(token string) (*ParsedCredential, error) {
+	headers, parsed, cred, err := parseCredentialJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	return &ParsedCredential{Headers: headers, Token: parsed, Credential: cred, Raw: token}, nil
+}
+
+// Issuer returns the id of the credential's issuer
+func (p ParsedCredential) Issuer() string {
+	if iss := p.Token.Issuer(); iss != "" {
+		return iss
+	}
+	return p.Credential.IssuerID()
+}
+
+// Subject returns the id of the credential's subject, or "" if it has none or has many subjects
+func (p ParsedCredential) Subject() string {
+	return p.Token.Subject()
+}
+
+// Expiry returns when the credential expires, and false if it does not
+func (p ParsedCredential) Expiry() (time.Time, bool) {
+	exp := p.Token.Expiration()
+	return exp, !exp.IsZero()
+}
+
+// checkJWTType makes sure a JWT's typ header, when it is one of vc+jwt or vp+jwt, is the expected one, preventing a
+// presentation from being accepted as a credential and vice versa. Tokens without one of these types (e.g. legacy
+// tokens with no typ or a typ of JWT) are accepted based on the presence of their claim alone, with a warning.
+func checkJWTType(headers jws.Headers, expected, property string) error {
+	switch typ := headers.Type(); typ {
+	case expected:
+		return nil
+	case VCJWTType, VPJWTType:
+		return errors.Wrapf(ErrTypeMismatch, "token typ<%s> does not match the expected typ<%s>", typ, expected)
+	default:
+		logrus.Warnf("token typ<%s> is not %s; relying on the presence of the %s property",
+			util.SanitizeLog(typ), expected, property)
+		return nil
+	}
+}
+
 // ParseVerifiableCredentialFromToken takes a JWT object and parses it into a VerifiableCredential
 func ParseVerifiableCredentialFromToken(token jwt.Token) (*credential.VerifiableCredential, error) {
 	// parse remaining JWT properties and set in the credential
 	vcClaim, ok := token.Get(VCJWTProperty)
 	if !ok {
-		return nil, fmt.Errorf("did not find %s property in token", VCJWTProperty)
-	}
-	vcBytes, err := json.Marshal(vcClaim)
-	if err != nil {
-		return nil, errors.Wrap(err, "marshalling credential claim")
+		return nil, errors.Wrapf(ErrMissingClaim, "did not find %s property in token", VCJWTProperty)
 	}
 	var cred credential.VerifiableCredential
-	if err = json.Unmarshal(vcBytes, &cred); err != nil {
-		return nil, errors.Wrap(err, "reconstructing Verifiable Credential")
+	switch typedClaim := vcClaim.(type) {
+	case map[string]any:
+		// the claim is already decoded, so it is converted without encoding it again
+		if err := cred.UnmarshalJSONMap(typedClaim); err != nil {
+			return nil, errors.Wrap(err, "reconstructing Verifiable Credential")
+		}
+	case string:
+		// some issuers encode the credential a second time, leaving a string rather than an object in the claim
+		vcBytes, err := decodeDoubleEncodedCredential(typedClaim)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrMalformedClaim, VCJWTProperty, err)
+		}
+		if err = JSONCodec.Unmarshal(vcBytes, &cred); err != nil {
+			return nil, errors.Wrap(err, "reconstructing Verifiable Credential")
+		}
+	default:
+		vcBytes, err := JSONCodec.Marshal(vcClaim)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling credential claim")
+		}
+		if err = JSONCodec.Unmarshal(vcBytes, &cred); err != nil {
+			return nil, errors.Wrap(err, "reconstructing Verifiable Credential")
+		}
 	}
 
 	jti, hasJTI := token.Get(jwt.JwtIDKey)
@@ -182,42 +688,159 @@ func ParseVerifiableCredentialFromToken(token jwt.Token) (*credential.Verifiable
 		cred.ExpirationDate = expTime.Format(time.RFC3339)
 	}
 
-	// Note: we only handle string issuer values, not objects for JWTs
+	// an issuer object in the credential is kept when it is of the issuer of the iss claim
 	iss, hasIss := token.Get(jwt.IssuerKey)
 	issStr, ok := iss.(string)
-	if hasIss && ok && issStr != "" {
+	if hasIss && ok && issStr != "" && !(cred.IsIssuerObject() && cred.IssuerID() == issStr) {
 		cred.Issuer = issStr
 	}
 
 	sub, hasSub := token.Get(jwt.SubjectKey)
 	subStr, ok := sub.(string)
 	if hasSub && ok && subStr != "" {
-		if cred.CredentialSubject == nil {
-			cred.CredentialSubject = make(map[string]any)
+		switch len(cred.CredentialSubjects) {
+		case 0:
+			if cred.CredentialSubject == nil {
+				cred.CredentialSubject = make(map[string]any)
+			}
+			cred.CredentialSubject[credential.VerifiableCredentialIDProperty] = subStr
+		case 1:
+			if cred.CredentialSubjects[0] == nil {
+				cred.CredentialSubjects[0] = make(map[string]any)
+			}
+			cred.CredentialSubjects[0][credential.VerifiableCredentialIDProperty] = subStr
 		}
-		cred.CredentialSubject[credential.VerifiableCredentialIDProperty] = subStr
 	}
 
 	return &cred, nil
 }
 
+// CredentialToCanonicalJSON returns the credential of a JWT, reconstructed from its claims as by
+// ParseVerifiableCredentialFromToken, as indented JSON with sorted keys, for display or as a representation that does
+// not depend on the signature. The signature is not verified. Signing the returned credential reproduces the token's
+// vc claim.
+func CredentialToCanonicalJSON(token string) ([]byte, error) {
+	_, _, cred, err := parseCredentialJWT(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing credential token")
+	}
+	credBytes, err := JSONCodec.Marshal(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential")
+	}
+	// decoding into a map orders the keys when encoded, and numbers are kept as written
+	var credMap map[string]any
+	if err = JSONCodec.UnmarshalUseNumber(credBytes, &credMap); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential")
+	}
+	return JSONCodec.MarshalIndent(credMap, "", "  ")
+}
+
+// decodeDoubleEncodedCredential returns the JSON object of a credential that was encoded as a string, either
+// base64url encoded, with or without padding, or as JSON text
+func decodeDoubleEncodedCredential(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	decoded := []byte(encoded)
+	if !strings.HasPrefix(encoded, "{") {
+		var err error
+		if decoded, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "=")); err != nil {
+			return nil, errors.Wrap(err, "string value is neither a JSON object nor base64url encoded")
+		}
+	}
+	// the encoded credential is hidden from the depth check of the token's claims
+	if err := checkJSONDepth(decoded); err != nil {
+		return nil, err
+	}
+	var obj map[string]any
+	if err := JSONCodec.Unmarshal(decoded, &obj); err != nil {
+		return nil, errors.Wrap(err, "decoded value is not a JSON object")
+	}
+	return decoded, nil
+}
+
 // JWTVVPParameters represents additional parameters needed when constructing a JWT VP as opposed to a VP
 type JWTVVPParameters struct {
 	// Audience is an optional audience of the JWT.
 	Audience []string
 	// Expiration is an optional expiration time of the JWT using the `exp` property.
 	Expiration int
+	// ProofPurpose is the verification relationship of the key signing the JWT, recorded in the `proofPurpose`
+	// property. Defaults to authentication.
+	ProofPurpose did.PublicKeyPurpose
+	// ClampToCredentialExpiry lowers the expiration of the JWT to the earliest expiration of the credentials and
+	// presentations it contains, so that it does not outlive them. This sets an expiration when none is given.
+	ClampToCredentialExpiry bool
+	// Challenge is the verifier's challenge the presentation answers. Its nonce is used as the `nonce` property in
+	// place of a random one, and its audience is added to the audience of the JWT.
+	Challenge *Challenge
+}
+
+// ValidatePresentationParameters checks the parameters of a presentation JWT would produce `aud`, `exp`, and
+// `proofPurpose` claims that verifiers accept: the expiration cannot be negative, audience entries cannot be empty or
+// repeated, the proof purpose must be a relationship of signing keys, and a challenge must have a nonce. All problems
+// found are returned together, wrapping ErrInvalidParameters. Nil parameters are valid.
+func ValidatePresentationParameters(parameters *JWTVVPParameters) error {
+	if parameters == nil {
+		return nil
+	}
+	errs := util.NewAppendError()
+	if parameters.Expiration < 0 {
+		errs.AppendString(fmt.Sprintf("expiration<%d> cannot be negative", parameters.Expiration))
+	}
+	seen := make(map[string]bool, len(parameters.Audience))
+	for i, aud := range parameters.Audience {
+		if strings.TrimSpace(aud) == "" {
+			errs.AppendString(fmt.Sprintf("audience %d cannot be empty", i))
+			continue
+		}
+		if seen[aud] {
+			errs.AppendString(fmt.Sprintf("audience %d<%s> is a duplicate", i, aud))
+		}
+		seen[aud] = true
+	}
+	if parameters.ProofPurpose != "" && !isSigningPurpose(parameters.ProofPurpose) {
+		errs.AppendString(fmt.Sprintf("proof purpose<%s> is not a signing purpose", parameters.ProofPurpose))
+	}
+	if parameters.Challenge != nil && parameters.Challenge.Nonce == "" {
+		errs.AppendString("challenge nonce cannot be empty")
+	}
+	if errs.IsEmpty() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidParameters, errs.Error())
+}
+
+// isSigningPurpose reports whether keys of a verification relationship sign, which all but key agreement keys do
+func isSigningPurpose(purpose did.PublicKeyPurpose) bool {
+	switch purpose {
+	case did.Authentication, did.AssertionMethod, did.CapabilityInvocation, did.CapabilityDelegation:
+		return true
+	}
+	return false
 }
 
 // SignVerifiablePresentationJWT transforms a VP into a VP JWT and signs it
 // According to https://w3c.github.io/vc-jwt/#version-1.1
-func SignVerifiablePresentationJWT(signer jwx.Signer, parameters *JWTVVPParameters, presentation credential.VerifiablePresentation) ([]byte, error) {
+// The iat and nbf claims are set to the current time, as told by the clock of the WithSigningClock option. The token is
+// in compact serialization unless the WithJSONSerialization option is given.
+// Credential JWTs are embedded in the vp claim verbatim, including those given as bytes, so that the signatures of
+// their issuers still verify. Credential objects must be signed with an embedded proof; those without one are
+// rejected with ErrUnsignedCredential, as nothing in the presentation would vouch for them.
+func SignVerifiablePresentationJWT(signer jwx.Signer, parameters *JWTVVPParameters, presentation credential.VerifiablePresentation, opts ...SignOption) ([]byte, error) {
 	if presentation.IsEmpty() {
-		return nil, errors.New("presentation cannot be empty")
+		return nil, ErrEmptyPresentation
 	}
 	if presentation.Proof != nil {
-		return nil, errors.New("presentation cannot have a proof")
+		return nil, errors.Wrap(ErrProofPresent, "presentation cannot have a proof")
+	}
+	if err := ValidatePresentationParameters(parameters); err != nil {
+		return nil, err
+	}
+	embedded, err := embeddedCredentials(presentation.VerifiableCredential)
+	if err != nil {
+		return nil, err
 	}
+	presentation.VerifiableCredential = embedded
 
 	t := jwt.New()
 	// set JWT-VP specific parameters
@@ -225,12 +848,16 @@ func SignVerifiablePresentationJWT(signer jwx.Signer, parameters *JWTVVPParamete
 	// NOTE: according to the JWT encoding rules (https://www.w3.org/TR/vc-data-model/#jwt-encoding) aud is a required
 	// property; however, aud is not required according to the JWT spec. Requiring audience limits a number of cases
 	// where JWT-VPs can be used, so we do not enforce this requirement.
-	if parameters != nil && parameters.Audience != nil {
-		if err := t.Set(jwt.AudienceKey, parameters.Audience); err != nil {
+	if audience := presentationAudience(parameters); audience != nil {
+		if err := t.Set(jwt.AudienceKey, audience); err != nil {
 			return nil, errors.Wrap(err, "setting audience value")
 		}
 	}
-	iatAndNBF := time.Now().Unix()
+	clock, err := signingClock(opts)
+	if err != nil {
+		return nil, err
+	}
+	iatAndNBF := clock.Now().Unix()
 	if err := t.Set(jwt.IssuedAtKey, iatAndNBF); err != nil {
 		return nil, errors.Wrap(err, "setting iat value")
 	}
@@ -238,16 +865,32 @@ func SignVerifiablePresentationJWT(signer jwx.Signer, parameters *JWTVVPParamete
 		return nil, errors.Wrap(err, "setting nbf value")
 	}
 
-	if err := t.Set(NonceProperty, uuid.New().String()); err != nil {
+	nonce := uuid.New().String()
+	if parameters != nil && parameters.Challenge != nil {
+		nonce = parameters.Challenge.Nonce
+	}
+	if err := t.Set(NonceProperty, nonce); err != nil {
 		return nil, errors.Wrap(err, "setting nonce value")
 	}
 
-	if parameters != nil && parameters.Expiration > 0 {
-		if err := t.Set(jwt.ExpirationKey, parameters.Expiration); err != nil {
+	expiration, err := presentationExpiration(parameters, presentation.VerifiableCredential)
+	if err != nil {
+		return nil, err
+	}
+	if expiration > 0 {
+		if err := t.Set(jwt.ExpirationKey, expiration); err != nil {
 			return nil, errors.Wrap(err, "setting exp value")
 		}
 	}
 
+	proofPurpose := did.Authentication
+	if parameters != nil && parameters.ProofPurpose != "" {
+		proofPurpose = parameters.ProofPurpose
+	}
+	if err := t.Set(ProofPurposeProperty, proofPurpose); err != nil {
+		return nil, errors.Wrap(err, "setting proofPurpose value")
+	}
+
 	// map the VP properties to JWT properties, and remove from the VP
 	if presentation.ID != "" {
 		if err := t.Set(jwt.JwtIDKey, presentation.ID); err != nil {
@@ -277,88 +920,843 @@ func SignVerifiablePresentationJWT(signer jwx.Signer, parameters *JWTVVPParamete
 			return nil, errors.Wrap(err, "setting KID protected header")
 		}
 	}
-	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
-	alg := signer.ALG
-	if alg == "Ed25519" {
-		alg = jwa.EdDSA.String()
+	if err := hdrs.Set(jws.TypeKey, VPJWTType); err != nil {
+		return nil, errors.Wrap(err, "setting typ protected header")
 	}
-	signed, err := jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	signed, err := jwt.Sign(t, jwt.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
 	if err != nil {
 		return nil, errors.Wrap(err, "signing JWT presentation")
 	}
-	return signed, nil
+	return serializeToken(signed, opts)
+}
+
+// embeddedCredentials returns the entries of a presentation's verifiableCredential property as they are embedded in
+// its vp claim: tokens, hashes and references as strings, and other values as they are. An error is returned for
+// credential and presentation objects without a proof.
+func embeddedCredentials(creds []any) ([]any, error) {
+	if creds == nil {
+		return nil, nil
+	}
+	embedded := make([]any, len(creds))
+	for i, cred := range creds {
+		var signed bool
+		switch typedCred := cred.(type) {
+		case nil:
+			return nil, errors.Wrapf(ErrEmptyCredential, "credential %d", i)
+		case string:
+			embedded[i] = typedCred
+			continue
+		case []byte:
+			// bytes would otherwise be encoded as base64, losing the token
+			embedded[i] = string(typedCred)
+			continue
+		case *string:
+			if typedCred != nil {
+				embedded[i] = *typedCred
+				continue
+			}
+			return nil, errors.Wrapf(ErrEmptyCredential, "credential %d", i)
+		case credential.VerifiableCredential:
+			signed = typedCred.Proof != nil
+		case *credential.VerifiableCredential:
+			signed = typedCred != nil && typedCred.Proof != nil
+		case credential.VerifiablePresentation:
+			signed = typedCred.Proof != nil
+		case *credential.VerifiablePresentation:
+			signed = typedCred != nil && typedCred.Proof != nil
+		default:
+			// values that are not objects are left for verifiers to reject
+			credJSON, err := util.ToJSONMap(typedCred)
+			signed = err != nil || credJSON["proof"] != nil
+		}
+		if !signed {
+			return nil, errors.Wrapf(ErrUnsignedCredential, "credential %d is an object without a proof", i)
+		}
+		embedded[i] = cred
+	}
+	return embedded, nil
+}
+
+// presentationAudience returns the aud claim of a presentation JWT, which is the requested audience along with the
+// audience of the challenge it answers
+func presentationAudience(parameters *JWTVVPParameters) []string {
+	if parameters == nil {
+		return nil
+	}
+	audience := parameters.Audience
+	if parameters.Challenge != nil && parameters.Challenge.Audience != "" && !util.Contains(parameters.Challenge.Audience, audience) {
+		audience = append(slices.Clone(audience), parameters.Challenge.Audience)
+	}
+	return audience
+}
+
+// presentationExpiration returns the exp claim of a presentation JWT, which is the requested expiration unless it is
+// clamped to the earliest expiry of the credentials. Zero means the JWT has no expiration.
+func presentationExpiration(parameters *JWTVVPParameters, creds []any) (int64, error) {
+	if parameters == nil {
+		return 0, nil
+	}
+	expiration := int64(parameters.Expiration)
+	if !parameters.ClampToCredentialExpiry {
+		return expiration, nil
+	}
+	earliest := -1
+	var earliestExpiry time.Time
+	for i, cred := range creds {
+		expiry, err := credentialExpiry(cred)
+		if err != nil {
+			return 0, errors.Wrapf(err, "reading expiry of credential %d", i)
+		}
+		if !expiry.IsZero() && (earliestExpiry.IsZero() || expiry.Before(earliestExpiry)) {
+			earliest, earliestExpiry = i, expiry
+		}
+	}
+	if earliest < 0 || (expiration > 0 && expiration <= earliestExpiry.Unix()) {
+		return expiration, nil
+	}
+	logrus.Warnf("presentation exp<%d> clamped to %d, the expiry of credential %d", expiration, earliestExpiry.Unix(), earliest)
+	return earliestExpiry.Unix(), nil
+}
+
+// credentialExpiry returns when an entry of a presentation's verifiableCredential property expires, which is zero if
+// it does not expire, or is a hash of or reference to a credential whose expiry is not known
+func credentialExpiry(cred any) (time.Time, error) {
+	var expirationDate string
+	switch typedCred := cred.(type) {
+	case string:
+		if IsCredentialHash(typedCred) || IsDetachedCredentialReference(typedCred) {
+			return time.Time{}, nil
+		}
+		// credentials and nested presentations both expire with their exp claim
+		compact, err := compactToken(typedCred)
+		if err != nil {
+			return time.Time{}, err
+		}
+		token, err := jwt.Parse([]byte(compact), jwt.WithValidate(false), jwt.WithVerify(false))
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "parsing JWT")
+		}
+		return token.Expiration(), nil
+	case credential.VerifiableCredential:
+		expirationDate = typedCred.ExpirationDate
+	case *credential.VerifiableCredential:
+		expirationDate = typedCred.ExpirationDate
+	case map[string]any:
+		expirationDate, _ = typedCred["expirationDate"].(string)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported credential type<%T>", cred)
+	}
+	if expirationDate == "" {
+		return time.Time{}, nil
+	}
+	expiry, err := time.Parse(time.RFC3339, expirationDate)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "expirationDate<%s> is not a valid RFC3339 date", expirationDate)
+	}
+	return expiry, nil
+}
+
+type VerifyOptionType string
+
+const (
+	WithoutCredentialVerificationOption VerifyOptionType = "WithoutCredentialVerification"
+	ReplayProtectionOption              VerifyOptionType = "ReplayProtection"
+	CredentialConcurrencyOption         VerifyOptionType = "CredentialConcurrency"
+	TrustRegistryOption                 VerifyOptionType = "TrustRegistry"
+	CredentialResolverOption            VerifyOptionType = "CredentialResolver"
+	ProofPurposeOption                  VerifyOptionType = "ProofPurpose"
+	ClockOption                         VerifyOptionType = "Clock"
+	ClockSkewOption                     VerifyOptionType = "ClockSkew"
+	ControllerKIDOption                 VerifyOptionType = "ControllerKID"
+	LenientHolderParsingOption          VerifyOptionType = "LenientHolderParsing"
+	ChallengeOption                     VerifyOptionType = "Challenge"
+	RequireNonEmptyOption               VerifyOptionType = "RequireNonEmpty"
+	StrictOption                        VerifyOptionType = "Strict"
+	RequireAudienceOption               VerifyOptionType = "RequireAudience"
+	PresentationStatusOption            VerifyOptionType = "PresentationStatus"
+	DeactivatedIssuerPolicyOption       VerifyOptionType = "DeactivatedIssuerPolicy"
+	HolderMatchOption                   VerifyOptionType = "HolderMatch"
+	DetachedCredentialsOption           VerifyOptionType = "DetachedCredentials"
+)
+
+// VerifyOption changes how a presentation is verified. Credentials may be verified with the WithClock,
+// WithClockSkew, AllowControllerKID, and WithDeactivatedIssuerPolicy options.
+type VerifyOption struct {
+	Type  VerifyOptionType
+	Value any
+}
+
+var (
+	// WithoutCredentialVerification limits verification of a presentation to its own signature and audience,
+	// skipping the verification of the credentials and presentations embedded in it, which are still parsed
+	WithoutCredentialVerification = VerifyOption{Type: WithoutCredentialVerificationOption}
+
+	// AllowControllerKID accepts tokens whose kid names a key of another DID than the one of their iss claim, as
+	// legacy tokens signed with a key of the issuer's controller do. The key must still be found in the document of
+	// the iss DID.
+	AllowControllerKID = VerifyOption{Type: ControllerKIDOption}
+)
+
+// WithLenientHolderParsing accepts presentation JWTs without an iss claim that name their holder in the holder
+// property of their vp claim instead, as some wallets do contrary to the JWT encoding rules
+// https://www.w3.org/TR/vc-data-model/#jwt-encoding. The signing key must then belong to that holder.
+func WithLenientHolderParsing() VerifyOption {
+	return VerifyOption{Type: LenientHolderParsingOption}
+}
+
+// WithReplayProtection rejects a presentation whose nonce has already been recorded in the given store, and records
+// the nonce of each presentation that is successfully verified until the presentation expires, or for
+// DefaultNonceTTL if it has no expiration. A store shared by concurrent verifications should be an AtomicNonceStore,
+// as MemoryNonceStore is, for a presentation replayed in parallel to be rejected.
+func WithReplayProtection(store NonceStore) VerifyOption {
+	return VerifyOption{Type: ReplayProtectionOption, Value: store}
+}
+
+// WithChallenges requires a presentation to answer an outstanding challenge of the given store with its nonce, such
+// as one created for the request the presentation responds to. The challenge is redeemed once the presentation is
+// verified, so it cannot be answered again, and a presentation answering a challenge that has expired, or that is
+// not intended for the challenge's audience, is rejected.
+func WithChallenges(store ChallengeStore) VerifyOption {
+	return VerifyOption{Type: ChallengeOption, Value: store}
+}
+
+// WithRequireNonEmpty sets whether a presentation, or one nested in it, with no credentials in its
+// verifiableCredential property fails verification with ErrEmptyPresentation. Presentations without credentials are
+// accepted by default, as they are when only authenticating their holder, and rejected in strict mode.
+func WithRequireNonEmpty(requireNonEmpty bool) VerifyOption {
+	return VerifyOption{Type: RequireNonEmptyOption, Value: requireNonEmpty}
+}
+
+// WithRequireAudience sets whether a presentation without an aud claim fails verification with ErrMissingClaim, so
+// that only presentations addressed to the verifier are accepted, and not ones replayed from elsewhere that were
+// addressed to no one. The aud claim is only matched against the verifier when present by default, and required in
+// strict mode. Presentations nested in the presentation are not required to have an audience.
+func WithRequireAudience(requireAudience bool) VerifyOption {
+	return VerifyOption{Type: RequireAudienceOption, Value: requireAudience}
+}
+
+// PresentationStatusChecker checks the credentialStatus of presentations, such as a status.StatusChecker does with
+// StatusList2021 entries
+type PresentationStatusChecker interface {
+	// IsPresentationRevoked returns whether the presentation has been revoked according to its credentialStatus
+	IsPresentationRevoked(ctx context.Context, vp credential.VerifiablePresentation) (bool, error)
+}
+
+// WithPresentationStatus checks the credentialStatus of a presentation, and of those nested in it, with the checker,
+// failing verification with ErrRevoked if the presentation has been revoked, as when a presentation used as a
+// session token is invalidated at logout. Presentations without a credentialStatus are not checked.
+func WithPresentationStatus(checker PresentationStatusChecker) VerifyOption {
+	return VerifyOption{Type: PresentationStatusOption, Value: checker}
+}
+
+// WithStrictMode rejects presentations that are valid but unlikely to be what a verifier expects, which for now are
+// presentations with no credentials or no audience. The checks of credentials and audiences may each be turned off
+// again with their own option, such as WithRequireNonEmpty(false), regardless of the order of the options. Strict
+// mode also enforces the data model's requirement that the first entry of the @context of presentations, and of the
+// credentials in them, is the base context of their data model version, failing with credential.ErrInvalidContext
+// otherwise. Credentials verified on their own, such as with VerifyJWTCredential, are checked the same way.
+func WithStrictMode() VerifyOption {
+	return VerifyOption{Type: StrictOption}
+}
+
+// WithCredentialConcurrency sets how many of a presentation's credentials are verified at once, which defaults to
+// DefaultCredentialConcurrency
+func WithCredentialConcurrency(workers int) VerifyOption {
+	return VerifyOption{Type: CredentialConcurrencyOption, Value: workers}
+}
+
+// WithProofPurpose requires presentations, including those nested in the presentation, to have been signed for the
+// given proof purpose with a key their holder lists under that verification relationship. Presentations without a
+// proofPurpose claim are taken to be signed for authentication.
+func WithProofPurpose(purpose did.PublicKeyPurpose) VerifyOption {
+	return VerifyOption{Type: ProofPurposeOption, Value: purpose}
+}
+
+// DefaultCredentialConcurrency is how many of a presentation's credentials are verified at once by default
+const DefaultCredentialConcurrency = 8
+
+// DefaultMaxPresentationDepth is how many levels of presentations nested within presentations are verified by default
+const DefaultMaxPresentationDepth = 3
+
+// VerifiedPresentation is the result of verifying a presentation JWT, including any presentations nested in it
+type VerifiedPresentation struct {
+	Headers      jws.Headers
+	Token        jwt.Token
+	Presentation *credential.VerifiablePresentation
+	// Nested holds the verified presentations found in the presentation's verifiableCredential property, keyed by
+	// the index of each in that property
+	Nested map[int]*VerifiedPresentation
 }
 
 // VerifyVerifiablePresentationJWT verifies the signature validity on the token. Then, the JWT is decoded according
 // to the specification: https://www.w3.org/TR/vc-data-model/#jwt-decoding
-// After decoding the signature of each credential in the presentation is verified. If there are any issues during
-// decoding or signature validation, an error is returned. As a result, a successfully decoded VerifiablePresentation
-// object is returned.
-func VerifyVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token string) (jws.Headers, jwt.Token, *credential.VerifiablePresentation, error) {
+// The verifier's key must be a key of the holder named by the presentation's iss claim, resolved with r, so that a
+// presentation signed by another key cannot claim to be the holder's.
+// After decoding the signature of each credential in the presentation is verified, concurrently with up to
+// DefaultCredentialConcurrency workers. If there are any issues during decoding or signature validation, an error is
+// returned, naming the first credential that failed. As a result, a successfully decoded VerifiablePresentation
+// object is returned. Presentations nested in the presentation are verified up to DefaultMaxPresentationDepth levels.
+// Presentations with no credentials are accepted, as when only authenticating their holder, unless WithStrictMode or
+// WithRequireNonEmpty is given, and so are presentations without an audience unless WithStrictMode or
+// WithRequireAudience is given. Credentials are not bound to the presentation's holder unless WithHolderMatch is
+// given, which requires the holder to be their subject or their holder.
+func VerifyVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token string, opts ...VerifyOption) (jws.Headers, jwt.Token, *credential.VerifiablePresentation, error) {
+	verified, err := VerifyNestedVerifiablePresentationJWT(ctx, verifier, r, token, DefaultMaxPresentationDepth, opts...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return verified.Headers, verified.Token, verified.Presentation, nil
+}
+
+// VerifyNestedVerifiablePresentationJWT verifies a presentation JWT as VerifyVerifiablePresentationJWT does, where
+// entries of the presentation's verifiableCredential property may themselves be presentation JWTs, as is the case
+// for delegation. Nested presentations are verified recursively with their holder's key, resolved from their kid,
+// and must be intended for the holder of the presentation containing them when they have an audience. An error is
+// returned if presentations are nested more than maxDepth levels deep, or if a presentation contains itself.
+func VerifyNestedVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token string, maxDepth int, opts ...VerifyOption) (*VerifiedPresentation, error) {
+	start := time.Now()
+	verified, err := verifyNestedVerifiablePresentationJWT(ctx, verifier, r, token, maxDepth, opts...)
+	observeVerification(ctx, JWTPresentationVerification, start, err == nil)
+	return verified, err
+}
+
+func verifyNestedVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token string, maxDepth int, opts ...VerifyOption) (*VerifiedPresentation, error) {
 	if r == nil {
-		return nil, nil, nil, errors.New("r cannot be empty")
+		return nil, errors.New("r cannot be empty")
+	}
+	if maxDepth < 0 {
+		return nil, fmt.Errorf("max depth<%d> cannot be negative", maxDepth)
+	}
+	pv := presentationVerification{maxDepth: maxDepth, seen: make(map[string]bool), concurrency: DefaultCredentialConcurrency}
+	var nonces NonceStore
+	var challenges ChallengeStore
+	var strict bool
+	var requireNonEmpty, requireAudience *bool
+	for _, opt := range opts {
+		switch opt.Type {
+		case WithoutCredentialVerificationOption:
+			pv.skipCredentials = true
+		case StrictOption:
+			strict = true
+			pv.strictContexts = true
+		case RequireNonEmptyOption:
+			required, ok := opt.Value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("require non-empty<%v> must be a bool", opt.Value)
+			}
+			requireNonEmpty = &required
+		case RequireAudienceOption:
+			required, ok := opt.Value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("require audience<%v> must be a bool", opt.Value)
+			}
+			requireAudience = &required
+		case PresentationStatusOption:
+			checker, ok := opt.Value.(PresentationStatusChecker)
+			if !ok || checker == nil {
+				return nil, errors.New("presentation status verification requires a status checker")
+			}
+			pv.status = checker
+		case ReplayProtectionOption:
+			store, ok := opt.Value.(NonceStore)
+			if !ok || store == nil {
+				return nil, errors.New("replay protection requires a nonce store")
+			}
+			nonces = store
+		case ChallengeOption:
+			store, ok := opt.Value.(ChallengeStore)
+			if !ok || store == nil {
+				return nil, errors.New("challenge verification requires a challenge store")
+			}
+			challenges = store
+		case CredentialConcurrencyOption:
+			workers, ok := opt.Value.(int)
+			if !ok || workers < 1 {
+				return nil, fmt.Errorf("credential concurrency<%v> must be a positive number", opt.Value)
+			}
+			pv.concurrency = workers
+		case TrustRegistryOption:
+			registry, ok := opt.Value.(TrustRegistry)
+			if !ok || registry == nil {
+				return nil, errors.New("trust registry verification requires a registry")
+			}
+			pv.registry = registry
+		case CredentialResolverOption:
+			resolver, ok := opt.Value.(CredentialResolver)
+			if !ok || resolver == nil {
+				return nil, errors.New("credential resolution requires a credential resolver")
+			}
+			pv.credentials = resolver
+		case DetachedCredentialsOption:
+			detached, ok := opt.Value.(map[string]string)
+			if !ok {
+				return nil, errors.New("detached credentials must be a map of credential ids to tokens")
+			}
+			pv.detached = detached
+		case ProofPurposeOption:
+			purpose, ok := opt.Value.(did.PublicKeyPurpose)
+			if !ok || !isSigningPurpose(purpose) {
+				return nil, fmt.Errorf("proof purpose<%v> is not a signing purpose", opt.Value)
+			}
+			pv.proofPurpose = purpose
+		case ClockOption, ClockSkewOption:
+			if _, err := pv.timing.apply(opt); err != nil {
+				return nil, err
+			}
+		case ControllerKIDOption:
+			pv.allowControllerKID = true
+		case DeactivatedIssuerPolicyOption:
+			pv.credentialPolicies = append(pv.credentialPolicies, opt)
+		case HolderMatchOption:
+			mode, err := holderMatchMode(opt)
+			if err != nil {
+				return nil, err
+			}
+			pv.holderMatch = mode
+		case LenientHolderParsingOption:
+			pv.lenientHolder = true
+		default:
+			return nil, fmt.Errorf("unsupported verify option<%s>", opt.Type)
+		}
+	}
+	pv.requireNonEmpty = strict
+	if requireNonEmpty != nil {
+		pv.requireNonEmpty = *requireNonEmpty
+	}
+	pv.requireAudience = strict
+	if requireAudience != nil {
+		pv.requireAudience = *requireAudience
 	}
+	verified, err := verifyPresentationJWT(ctx, verifier, []string{verifier.ID, verifier.KID}, r, token, 0, &pv)
+	if err != nil {
+		return nil, err
+	}
+	if challenges != nil {
+		if err = redeemChallenge(challenges, verified.Token, pv.timing.now()); err != nil {
+			return nil, err
+		}
+	}
+	if nonces != nil {
+		if err = recordNonce(nonces, verified.Token, pv.timing.now()); err != nil {
+			return nil, err
+		}
+	}
+	return verified, nil
+}
+
+// presentationVerification holds the state of verifying a presentation and those nested in it
+type presentationVerification struct {
+	maxDepth int
+	// seen holds the presentations being verified at lower depths
+	seen            map[string]bool
+	skipCredentials bool
+	concurrency     int
+	// registry, if set, verifies credentials in place of the resolver
+	registry TrustRegistry
+	// credentials fetches the credentials referenced by their CredentialHash
+	credentials CredentialResolver
+	// detached holds the credentials sent alongside presentations, referenced by DetachedCredentialReference
+	detached map[string]string
+	// proofPurpose, if set, is the proof purpose presentations must have been signed for
+	proofPurpose did.PublicKeyPurpose
+	// timing is what the time-based claims of presentations and their credentials are validated with
+	timing timeValidation
+	// allowControllerKID accepts presentations and credentials whose kid is not of the DID of their iss claim
+	allowControllerKID bool
+	// lenientHolder accepts presentations naming their holder in the vp claim rather than the iss claim
+	lenientHolder bool
+	// requireNonEmpty rejects presentations without credentials
+	requireNonEmpty bool
+	// requireAudience rejects presentations without an aud claim, other than nested ones
+	requireAudience bool
+	// status, if set, checks the credentialStatus of presentations that have one
+	status PresentationStatusChecker
+	// credentialPolicies are the options of how credentials are accepted passed on to their verification
+	credentialPolicies []VerifyOption
+	// holderMatch, if set, is who the holder of a presentation must be for each of its credentials
+	holderMatch *HolderMatchMode
+	// strictContexts rejects presentations and credentials whose @context does not begin with their base context
+	strictContexts bool
+}
+
+// checkHolders checks the credentials at the indices are bound to the presentation's holder, if the verification
+// binds them, setting the errors of those that are not and have no error yet
+func (pv *presentationVerification) checkHolders(vp credential.VerifiablePresentation, indices []int, errs []error) {
+	if pv.holderMatch == nil {
+		return
+	}
+	for _, i := range indices {
+		if errs[i] != nil {
+			continue
+		}
+		if err := checkCredentialHolder(vp.VerifiableCredential[i], vp.Holder, *pv.holderMatch); err != nil {
+			errs[i] = errors.Wrapf(err, "checking holder of credential %d", i)
+		}
+	}
+}
+
+// credentialOptions returns the verify options the credentials of a presentation are verified with
+func (pv *presentationVerification) credentialOptions() []VerifyOption {
+	opts := pv.timing.options()
+	if pv.allowControllerKID {
+		opts = append(opts, AllowControllerKID)
+	}
+	if pv.strictContexts {
+		opts = append(opts, WithStrictMode())
+	}
+	return append(opts, pv.credentialPolicies...)
+}
+
+// presentationOptions returns the options presentations are parsed with
+func (pv *presentationVerification) presentationOptions() []VerifyOption {
+	if pv.lenientHolder {
+		return []VerifyOption{WithLenientHolderParsing()}
+	}
+	return nil
+}
+
+// verifyPresentationJWT verifies a presentation JWT at the given depth, where audiences are the values accepted in
+// the presentation's aud claim
+func verifyPresentationJWT(ctx context.Context, verifier jwx.Verifier, audiences []string, r resolution.Resolver,
+	token string, depth int, pv *presentationVerification) (*VerifiedPresentation, error) {
+	token, err := compactToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if pv.seen[token] {
+		return nil, errors.New("presentation contains itself")
+	}
+	pv.seen[token] = true
+	defer delete(pv.seen, token)
 
 	// verify outer signature on the token
-	if err := verifier.Verify(token); err != nil {
-		return nil, nil, nil, errors.Wrap(err, "verifying JWT and its signature")
+	if err := verifier.VerifyWithContext(ctx, token, pv.timing.parseOptions()...); err != nil {
+		return nil, errors.Wrap(verificationError(err), "verifying JWT and its signature")
 	}
 
 	// parse the token into its parts (header, jwt, vp)
-	headers, vpToken, vp, err := ParseVerifiablePresentationFromJWT(token)
+	headers, vpToken, vp, err := ParseVerifiablePresentationFromJWT(token, pv.presentationOptions()...)
 	if err != nil {
-		return nil, nil, nil, errors.Wrap(err, "parsing VP from JWT")
+		return nil, errors.Wrap(err, "parsing VP from JWT")
 	}
 
 	// make sure the audience matches the verifier, if we have an audience
-	if len(vpToken.Audience()) != 0 {
-		audMatch := false
-		for _, aud := range vpToken.Audience() {
-			if aud == verifier.ID || aud == verifier.KID {
-				audMatch = true
+	if depth == 0 && pv.requireAudience && len(vpToken.Audience()) == 0 {
+		return nil, errors.Wrap(ErrMissingClaim, "presentation has no aud claim")
+	}
+	if err = checkAudience(vpToken, audiences); err != nil {
+		return nil, err
+	}
+
+	if !pv.allowControllerKID {
+		if err = checkKIDIssuer(headers.KeyID(), vpToken.Issuer(), "holder"); err != nil {
+			return nil, err
+		}
+	}
+	// the key of a nested presentation is already that of its holder, resolved from its iss
+	if depth == 0 {
+		if err = checkHolderKey(ctx, r, verifier, headers, vpToken); err != nil {
+			return nil, err
+		}
+	}
+	if err = checkProofPurpose(ctx, r, headers, vpToken, pv.proofPurpose); err != nil {
+		return nil, err
+	}
+	if pv.requireNonEmpty && len(vp.VerifiableCredential) == 0 {
+		return nil, errors.Wrap(ErrEmptyPresentation, "presentation has no credentials")
+	}
+	if pv.strictContexts {
+		if err = vp.ValidateBaseContext(); err != nil {
+			return nil, errors.Wrapf(err, "presentation<%s>", vpToken.JwtID())
+		}
+	}
+	if pv.status != nil && vp.CredentialStatus != nil {
+		revoked, err := pv.status.IsPresentationRevoked(ctx, *vp)
+		if err != nil {
+			return nil, errors.Wrap(err, "checking status of presentation")
+		}
+		if revoked {
+			return nil, errors.Wrapf(ErrRevoked, "presentation<%s>", vpToken.JwtID())
+		}
+	}
+
+	verified := VerifiedPresentation{Headers: headers, Token: vpToken, Presentation: vp}
+	if pv.skipCredentials {
+		return &verified, nil
+	}
+
+	// verify each credential in the vp, where the error of the first entry to fail is returned
+	errs := make([]error, len(vp.VerifiableCredential))
+	var credentials []int
+	for i, cred := range vp.VerifiableCredential {
+		// credentials may be referenced by hash, in which case they are fetched and verified in place of the hash
+		if hash, ok := cred.(string); ok && IsCredentialHash(hash) {
+			token, err := resolveCredentialHash(hash, pv.credentials)
+			if err != nil {
+				errs[i] = errors.Wrapf(err, "resolving credential %d", i)
 				break
 			}
+			vp.VerifiableCredential[i] = token
+			cred = token
 		}
-		if !audMatch {
-			return nil, nil, nil, errors.Errorf("audience mismatch: expected [%s] or [%s], got %s", verifier.ID, verifier.KID, vpToken.Audience())
+		// credentials may also be detached from the presentation, in which case they are verified in place of their
+		// reference
+		if reference, ok := cred.(string); ok && IsDetachedCredentialReference(reference) {
+			token, err := resolveDetachedCredential(reference, pv.detached)
+			if err != nil {
+				errs[i] = errors.Wrapf(err, "resolving credential %d", i)
+				break
+			}
+			vp.VerifiableCredential[i] = token
+			cred = token
+		}
+
+		// presentations may be nested in a presentation, in which case they are verified in turn
+		if nestedToken, ok := cred.(string); ok {
+			if jwtType, err := DetectJWTType(nestedToken); err == nil && jwtType == PresentationJWTType {
+				if depth+1 > pv.maxDepth {
+					errs[i] = errors.Wrapf(ErrNestingTooDeep, "presentation %d exceeds the max nesting depth of %d", i, pv.maxDepth)
+					break
+				}
+				nested, err := verifyNestedPresentationJWT(ctx, vp.Holder, r, nestedToken, depth+1, pv)
+				if err != nil {
+					errs[i] = errors.Wrapf(err, "verifying nested presentation %d", i)
+					break
+				}
+				if verified.Nested == nil {
+					verified.Nested = make(map[int]*VerifiedPresentation)
+				}
+				verified.Nested[i] = nested
+				continue
+			}
+		}
+		credentials = append(credentials, i)
+	}
+	verifyCredentialSignatures(ctx, r, pv.registry, vp.VerifiableCredential, credentials, errs, pv.concurrency, true, pv.credentialOptions())
+	pv.checkHolders(*vp, credentials, errs)
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	// verify signature for each credential in the vp
-	for i, cred := range vp.VerifiableCredential {
-		// verify the signature on the credential
-		verified, err := VerifyCredentialSignature(ctx, cred, r)
+	// return if successful
+	return &verified, nil
+}
+
+// checkAudience checks the aud claim of a presentation token holds one of the non-empty audiences, if it has one
+func checkAudience(token jwt.Token, audiences []string) error {
+	if len(token.Audience()) == 0 {
+		return nil
+	}
+	for _, aud := range token.Audience() {
+		for _, expected := range audiences {
+			if expected != "" && aud == expected {
+				return nil
+			}
+		}
+	}
+	return errors.Wrapf(ErrAudienceMismatch, "expected one of %s, got %s", audiences, token.Audience())
+}
+
+// checkHolderKey checks the key of the verifier, which verified the presentation's signature, is a key of the
+// presentation's holder, named by its iss claim: the key of the verification method matching the presentation's kid,
+// or of any of the holder's verification methods when it has no kid
+func checkHolderKey(ctx context.Context, r resolution.Resolver, verifier jwx.Verifier, headers jws.Headers, token jwt.Token) error {
+	holder := token.Issuer()
+	thumbprint, err := verifier.PublicKeyJWK.Thumbprint()
+	if err != nil {
+		return errors.Wrap(err, "computing thumbprint of verifier key")
+	}
+	resolved, err := r.Resolve(ctx, holder)
+	if err != nil {
+		return errors.Wrapf(err, "error getting holder DID<%s> to verify presentation<%s>", holder, token.JwtID())
+	}
+
+	var keys []gocrypto.PublicKey
+	if kid := headers.KeyID(); kid != "" {
+		key, err := did.GetKeyFromVerificationMethod(resolved.Document, kid)
+		if err != nil {
+			return errors.Wrapf(ErrIssuerMismatch, "kid<%s> is not a key of holder<%s>: %s", kid, holder, err)
+		}
+		keys = append(keys, key)
+	} else {
+		for _, method := range resolved.Document.VerificationMethod {
+			if key, err := did.GetKeyFromEmbeddedVerificationMethod(method); err == nil {
+				keys = append(keys, key)
+			}
+		}
+	}
+	for _, key := range keys {
+		holderKey, err := jwx.PublicKeyToPublicKeyJWK(nil, key)
 		if err != nil {
-			return nil, nil, nil, errors.Wrapf(err, "verifying credential %d", i)
+			continue
 		}
-		if !verified {
-			return nil, nil, nil, errors.Errorf("credential %d failed signature validation", i)
+		if holderThumbprint, err := holderKey.Thumbprint(); err == nil && holderThumbprint == thumbprint {
+			return nil
 		}
 	}
+	return errors.Wrapf(ErrIssuerMismatch, "presentation<%s> was not signed by a key of holder<%s>", token.JwtID(), holder)
+}
 
-	// return if successful
-	return headers, vpToken, vp, nil
+// checkKIDIssuer checks the kid of a token, if it names a DID, names the DID of the token's iss claim, so that a token
+// cannot claim to be issued by one DID while pointing its verifier at the key of another. Relative kids, such as
+// #key-1, are keys of the iss DID. The role names the iss DID in the error, as the holder or issuer of the token.
+func checkKIDIssuer(kid, iss, role string) error {
+	kidDID, _, _ := strings.Cut(kid, "#")
+	if !strings.HasPrefix(kidDID, "did:") || kidDID == iss {
+		return nil
+	}
+	return errors.Wrapf(ErrIssuerMismatch, "kid<%s> is not a key of %s<%s>", kid, role, iss)
+}
+
+// checkProofPurpose checks the proofPurpose claim of a presentation, which defaults to authentication, is a signing
+// purpose, and, if the verifier requires a purpose, that the presentation was signed for it with a key the holder
+// lists under that verification relationship
+func checkProofPurpose(ctx context.Context, r resolution.Resolver, headers jws.Headers, token jwt.Token, required did.PublicKeyPurpose) error {
+	purpose := did.Authentication
+	if claim, ok := token.Get(ProofPurposeProperty); ok {
+		claimStr, ok := claim.(string)
+		if !ok || !isSigningPurpose(did.PublicKeyPurpose(claimStr)) {
+			return errors.Wrapf(ErrMalformedClaim, "%s property<%v> is not a signing purpose", ProofPurposeProperty, claim)
+		}
+		purpose = did.PublicKeyPurpose(claimStr)
+	}
+	if required == "" {
+		return nil
+	}
+	if purpose != required {
+		return errors.Wrapf(ErrProofPurposeMismatch, "expected proof purpose<%s>, got %s", required, purpose)
+	}
+
+	kid := headers.KeyID()
+	if kid == "" {
+		return errors.Wrapf(ErrMissingKID, "missing kid in header of presentation<%s>", token.JwtID())
+	}
+	resolved, err := r.Resolve(ctx, token.Issuer())
+	if err != nil {
+		return errors.Wrapf(err, "error getting holder DID<%s> to check proof purpose", token.Issuer())
+	}
+	ok, err := did.HasVerificationMethodForPurpose(resolved.Document, kid, purpose)
+	if err != nil {
+		return errors.Wrapf(err, "getting %s verification methods of holder DID<%s>", purpose, token.Issuer())
+	}
+	if !ok {
+		return errors.Wrapf(ErrProofPurposeMismatch, "key<%s> is not a %s key of holder DID<%s>", kid, purpose, token.Issuer())
+	}
+	return nil
+}
+
+// verifyCredentialSignatures verifies the signatures of the credentials at the given indices with a pool of workers,
+// against the registry if one is given, setting the error of each that fails in errs. When failing fast, credentials
+// after one that has failed are skipped, as their errors would not be the first. Those not yet verified when the
+// context is done fail with the context's error.
+func verifyCredentialSignatures(ctx context.Context, r resolution.Resolver, registry TrustRegistry, creds []any,
+	indices []int, errs []error, workers int, failFast bool, opts []VerifyOption) {
+	if len(indices) == 0 {
+		return
+	}
+	var firstFailed atomic.Int64
+	firstFailed.Store(math.MaxInt64)
+	fail := func(i int, err error) {
+		errs[i] = err
+		for {
+			failed := firstFailed.Load()
+			if int64(i) >= failed || firstFailed.CompareAndSwap(failed, int64(i)) {
+				return
+			}
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(indices)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if failFast && int64(i) > firstFailed.Load() {
+					continue
+				}
+				if err := ctx.Err(); err != nil {
+					fail(i, errors.Wrapf(err, "verifying credential %d", i))
+					continue
+				}
+				var ok bool
+				var err error
+				if registry != nil {
+					ok, err = verifyTrustedCredential(ctx, creds[i], registry, opts...)
+				} else {
+					ok, err = VerifyCredentialSignature(ctx, creds[i], r, opts...)
+				}
+				if err != nil {
+					fail(i, errors.Wrapf(err, "verifying credential %d", i))
+				} else if !ok {
+					fail(i, errors.Wrapf(ErrSignatureInvalid, "credential %d failed signature validation", i))
+				}
+			}
+		}()
+	}
+	for _, i := range indices {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// verifyNestedPresentationJWT verifies a presentation JWT nested in the presentation of the given holder, using the
+// key of the nested presentation's own holder
+func verifyNestedPresentationJWT(ctx context.Context, holder string, r resolution.Resolver, token string,
+	depth int, pv *presentationVerification) (*VerifiedPresentation, error) {
+	headers, parsed, _, err := ParseVerifiablePresentationFromJWT(token, pv.presentationOptions()...)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing VP from JWT")
+	}
+	kid := headers.KeyID()
+	if kid == "" {
+		return nil, errors.Wrapf(ErrMissingKID, "missing kid in header of presentation<%s>", parsed.JwtID())
+	}
+	resolved, err := r.Resolve(ctx, parsed.Issuer())
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting holder DID<%s> to verify presentation<%s>", parsed.Issuer(), parsed.JwtID())
+	}
+	key, err := did.GetKeyFromVerificationMethod(resolved.Document, kid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting key to verify presentation<%s>", parsed.JwtID())
+	}
+	verifier, err := jwx.NewJWXVerifier(resolved.Document.ID, &kid, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error constructing verifier for presentation<%s>", parsed.JwtID())
+	}
+	return verifyPresentationJWT(ctx, *verifier, []string{holder}, r, token, depth, pv)
 }
 
 // ParseVerifiablePresentationFromJWT the JWT is decoded according to the specification.
 // https://www.w3.org/TR/vc-data-model/#jwt-decoding
 // If there are any issues during decoding, an error is returned. As a result, a successfully
-// decoded VerifiablePresentation object is returned.
-func ParseVerifiablePresentationFromJWT(token string) (jws.Headers, jwt.Token, *credential.VerifiablePresentation, error) {
+// decoded VerifiablePresentation object is returned. The token may be in the compact or JSON serialization of JWS.
+// With the WithLenientHolderParsing option, a token without an iss claim is parsed when its vp claim has a holder,
+// which is then set as the iss of the returned token so that the presentation is verified as the holder's. Other
+// options do not change parsing.
+func ParseVerifiablePresentationFromJWT(token string, opts ...VerifyOption) (jws.Headers, jwt.Token, *credential.VerifiablePresentation, error) {
+	token, err := compactToken(token)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	parsed, err := jwt.Parse([]byte(token), jwt.WithValidate(false), jwt.WithVerify(false))
 	if err != nil {
 		return nil, nil, nil, errors.Wrap(err, "parsing vp token")
 	}
 	vpClaim, ok := parsed.Get(VPJWTProperty)
 	if !ok {
-		return nil, nil, nil, fmt.Errorf("did not find %s property in token", VPJWTProperty)
+		return nil, nil, nil, errors.Wrapf(ErrMissingClaim, "did not find %s property in token", VPJWTProperty)
 	}
-	vpBytes, err := json.Marshal(vpClaim)
+	vpBytes, err := JSONCodec.Marshal(vpClaim)
 	if err != nil {
 		return nil, nil, nil, errors.Wrap(err, "marshalling vp claim")
 	}
 	var pres credential.VerifiablePresentation
-	if err = json.Unmarshal(vpBytes, &pres); err != nil {
+	if err = JSONCodec.Unmarshal(vpBytes, &pres); err != nil {
 		return nil, nil, nil, errors.Wrap(err, "reconstructing Verifiable Presentation")
 	}
 
@@ -367,15 +1765,26 @@ func ParseVerifiablePresentationFromJWT(token string) (jws.Headers, jwt.Token, *
 	if err != nil {
 		return nil, nil, nil, errors.Wrap(err, "getting JWT headers")
 	}
+	if err = checkJWTType(headers, VPJWTType, VPJWTProperty); err != nil {
+		return nil, nil, nil, err
+	}
 
 	// parse remaining JWT properties and set in the presentation
 	iss, ok := parsed.Get(jwt.IssuerKey)
+	if !ok && pres.Holder != "" && hasVerifyOption(opts, LenientHolderParsingOption) {
+		logrus.Warnf("presentation token has no %s property; using the holder<%s> of its %s property",
+			jwt.IssuerKey, util.SanitizeLog(pres.Holder), VPJWTProperty)
+		if err = parsed.Set(jwt.IssuerKey, pres.Holder); err != nil {
+			return nil, nil, nil, errors.Wrap(err, "setting iss value")
+		}
+		iss, ok = pres.Holder, true
+	}
 	if !ok {
-		return nil, nil, nil, fmt.Errorf("did not find %s property in token", jwt.IssuerKey)
+		return nil, nil, nil, errors.Wrapf(ErrMissingClaim, "did not find %s property in token", jwt.IssuerKey)
 	}
 	issStr, ok := iss.(string)
 	if !ok {
-		return nil, nil, nil, fmt.Errorf("issuer property is not a string")
+		return nil, nil, nil, errors.Wrap(ErrMalformedClaim, "issuer property is not a string")
 	}
 	pres.Holder = issStr
 
@@ -387,3 +1796,84 @@ func ParseVerifiablePresentationFromJWT(token string) (jws.Headers, jwt.Token, *
 
 	return headers, parsed, &pres, nil
 }
+
+// JWTType identifies whether a JWT carries a verifiable credential or a verifiable presentation
+type JWTType string
+
+const (
+	CredentialJWTType   JWTType = "vc"
+	PresentationJWTType JWTType = "vp"
+)
+
+// VerifiedJWT is the result of VerifyAny. Type determines which of Credential or Presentation is set.
+type VerifiedJWT struct {
+	Type    JWTType
+	Headers jws.Headers
+	// HeaderExtensions holds the header parameters not registered by RFC 7515, such as vendor extensions
+	HeaderExtensions map[string]any
+	Token            jwt.Token
+	Credential       *credential.VerifiableCredential
+	Presentation     *credential.VerifiablePresentation
+}
+
+// VerifyAny verifies a JWT that may be either a verifiable credential or a verifiable presentation. The type is
+// detected from the presence of the vc or vp claim, and the token is verified with VerifyVerifiableCredentialJWT
+// or VerifyVerifiablePresentationJWT accordingly. A token with both or neither of the claims is rejected. Header
+// parameters the token carries beyond those of RFC 7515 are returned in its HeaderExtensions.
+func VerifyAny(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token string) (*VerifiedJWT, error) {
+	t, err := DetectJWTType(token)
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case CredentialJWTType:
+		headers, parsed, cred, err := VerifyVerifiableCredentialJWT(verifier, token)
+		if err != nil {
+			return nil, errors.Wrap(err, "verifying credential JWT")
+		}
+		return &VerifiedJWT{Type: t, Headers: headers, HeaderExtensions: jwx.HeaderExtensions(headers), Token: parsed, Credential: cred}, nil
+	case PresentationJWTType:
+		headers, parsed, pres, err := VerifyVerifiablePresentationJWT(ctx, verifier, r, token)
+		if err != nil {
+			return nil, errors.Wrap(err, "verifying presentation JWT")
+		}
+		return &VerifiedJWT{Type: t, Headers: headers, HeaderExtensions: jwx.HeaderExtensions(headers), Token: parsed, Presentation: pres}, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWT type: %s", t)
+	}
+}
+
+// DetectJWTType inspects the typ header and claims of a JWT, without verifying it, to determine whether it carries a
+// verifiable credential or a verifiable presentation. A typ of vc+jwt or vp+jwt takes precedence over the claims.
+func DetectJWTType(token string) (JWTType, error) {
+	token, err := compactToken(token)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := jwt.Parse([]byte(token), jwt.WithValidate(false), jwt.WithVerify(false))
+	if err != nil {
+		return "", errors.Wrap(err, "parsing token")
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(token))
+	if err != nil {
+		return "", errors.Wrap(err, "getting JWT headers")
+	}
+	switch headers.Type() {
+	case VCJWTType:
+		return CredentialJWTType, nil
+	case VPJWTType:
+		return PresentationJWTType, nil
+	}
+	_, hasVC := parsed.Get(VCJWTProperty)
+	_, hasVP := parsed.Get(VPJWTProperty)
+	switch {
+	case hasVC && hasVP:
+		return "", fmt.Errorf("token cannot have both %s and %s properties", VCJWTProperty, VPJWTProperty)
+	case hasVC:
+		return CredentialJWTType, nil
+	case hasVP:
+		return PresentationJWTType, nil
+	default:
+		return "", errors.Wrapf(ErrMissingClaim, "token has neither a %s nor a %s property", VCJWTProperty, VPJWTProperty)
+	}
+}
@@ -2,17 +2,33 @@ package integrity
 
 import (
 	"context"
+	gocrypto "crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did"
+	didjwk "github.com/TBD54566975/ssi-sdk/did/jwk"
 	"github.com/TBD54566975/ssi-sdk/did/key"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
 )
 
 func TestVerifiableCredentialJWT(t *testing.T) {
@@ -88,6 +104,730 @@ func TestVerifiableCredentialJWT(t *testing.T) {
 	})
 }
 
+func TestVerifiableCredentialJWTRoundTrip(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	cred := credential.VerifiableCredential{
+		ID:             "http://example.edu/credentials/1872",
+		Context:        []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:           []any{"VerifiableCredential"},
+		Issuer:         "did:example:123",
+		IssuanceDate:   "2021-01-01T19:23:24Z",
+		ExpirationDate: "2031-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{
+			"id":   "did:example:456",
+			"name": "JimBobertson",
+		},
+		RefreshService: &credential.RefreshService{
+			ID:   "https://example.edu/refresh/3732",
+			Type: "ManualRefreshService2018",
+		},
+		TermsOfUse: []credential.TermsOfUse{
+			{
+				Type: "IssuerPolicy",
+				ID:   "http://example.com/policies/credential/4",
+				Prohibition: []credential.Prohibition{
+					{
+						Assigner: "https://example.edu/issuers/14",
+						Assignee: "AllVerifiers",
+						Target:   "http://example.edu/credentials/3732",
+						Action:   []string{"Archival"},
+					},
+				},
+			},
+		},
+		Evidence: []any{
+			map[string]any{
+				"id":               "https://example.edu/evidence/f2aeec97-fc0d-42bf-8ca7-0548192d4231",
+				"type":             []any{"DocumentVerification"},
+				"verifier":         "https://example.edu/issuers/14",
+				"evidenceDocument": "DriversLicense",
+			},
+		},
+		Extensions: map[string]any{
+			"name": "Example Credential",
+		},
+	}
+
+	signed, err := SignVerifiableCredentialJWT(signer, cred)
+	require.NoError(t, err)
+
+	_, _, parsed, err := ParseVerifiableCredentialFromJWT(string(signed))
+	assert.NoError(t, err)
+	assert.Equal(t, cred, *parsed)
+}
+
+func TestVerifiableCredentialJWTNonDIDSubject(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	cred := credential.VerifiableCredential{
+		Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:         []any{"VerifiableCredential"},
+		Issuer:       signer.ID,
+		IssuanceDate: "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{
+			"id":   "urn:uuid:7d3f9c2e-5b1a-4c8e-9f0d-2a6b4e8c1d3f",
+			"name": "JimBobertson",
+		},
+	}
+
+	signed, err := SignVerifiableCredentialJWT(signer, cred)
+	require.NoError(t, err)
+	_, token, parsed, err := ParseVerifiableCredentialFromJWT(string(signed))
+	require.NoError(t, err)
+	assert.Equal(t, "urn:uuid:7d3f9c2e-5b1a-4c8e-9f0d-2a6b4e8c1d3f", token.Subject())
+	assert.Equal(t, cred, *parsed)
+	assert.False(t, IsDID(parsed.CredentialSubject.GetID()))
+
+	// only the issuer is resolved, so a subject that is not a DID verifies
+	verified, err := VerifyCredentialSignature(context.Background(), string(signed), resolver)
+	require.NoError(t, err)
+	assert.True(t, verified)
+}
+
+func TestParseVerifiableCredential(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	cred := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []any{"VerifiableCredential"},
+		Issuer:            "did:example:123",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		ExpirationDate:    "2031-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	}
+
+	t.Run("keeps the original token", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+		parsed, err := ParseVerifiableCredential(string(signed))
+		require.NoError(tt, err)
+		assert.Equal(tt, string(signed), parsed.Raw)
+		assert.Equal(tt, cred, *parsed.Credential)
+		assert.Equal(tt, signer.KID, parsed.Headers.KeyID())
+
+		assert.Equal(tt, "did:example:123", parsed.Issuer())
+		assert.Equal(tt, "did:example:456", parsed.Subject())
+		expiry, ok := parsed.Expiry()
+		assert.True(tt, ok)
+		assert.Equal(tt, time.Date(2031, 1, 1, 19, 23, 24, 0, time.UTC), expiry.UTC())
+	})
+
+	t.Run("keeps the JSON serialization", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJWTJSON(signer, cred)
+		require.NoError(tt, err)
+		parsed, err := ParseVerifiableCredential(string(signed))
+		require.NoError(tt, err)
+		assert.Equal(tt, string(signed), parsed.Raw)
+		assert.Equal(tt, cred, *parsed.Credential)
+	})
+
+	t.Run("credential without expiry or subject id", func(tt *testing.T) {
+		unbounded := cred
+		unbounded.ExpirationDate = ""
+		unbounded.CredentialSubject = map[string]any{"name": "JimBobertson"}
+		signed, err := SignVerifiableCredentialJWT(signer, unbounded)
+		require.NoError(tt, err)
+		parsed, err := ParseVerifiableCredential(string(signed))
+		require.NoError(tt, err)
+		assert.Empty(tt, parsed.Subject())
+		_, ok := parsed.Expiry()
+		assert.False(tt, ok)
+	})
+
+	t.Run("invalid token", func(tt *testing.T) {
+		_, err := ParseVerifiableCredential("not a token")
+		assert.Error(tt, err)
+	})
+}
+
+func TestVerifiableCredentialJWTWithJWKS(t *testing.T) {
+	defer gock.Off()
+	_, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	kid := "2024-key"
+	signer, err := jwx.NewJWXSigner("https://issuer.example.com", &kid, privKey)
+	require.NoError(t, err)
+	jwksBytes, err := json.Marshal(map[string]any{"keys": []jwx.PublicKeyJWK{signer.ToPublicKeyJWK()}})
+	require.NoError(t, err)
+	gock.New("https://issuer.example.com").Get("/jwks").Times(1).Reply(200).BodyString(string(jwksBytes))
+
+	verifier, err := jwx.NewJWKSVerifier("https://issuer.example.com/jwks", http.DefaultClient, time.Hour)
+	require.NoError(t, err)
+	signed, err := SignVerifiableCredentialJWT(*signer, credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            signer.ID,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	})
+	require.NoError(t, err)
+
+	_, _, cred, err := VerifyVerifiableCredentialJWT(*verifier, string(signed))
+	assert.NoError(t, err)
+	assert.Equal(t, signer.ID, cred.IssuerID())
+
+	_, otherKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	forger, err := jwx.NewJWXSigner("https://issuer.example.com", &kid, otherKey)
+	require.NoError(t, err)
+	forged, err := SignVerifiableCredentialJWT(*forger, *cred)
+	require.NoError(t, err)
+	_, _, _, err = VerifyVerifiableCredentialJWT(*verifier, string(forged))
+	assert.ErrorIs(t, err, ErrSignatureInvalid)
+	assert.True(t, gock.IsDone())
+}
+
+func TestVerifiableCredentialJWTRSA(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}, didjwk.Resolver{}}...)
+	require.NoError(t, err)
+
+	rsaSigner := func(tt *testing.T, method string) jwx.Signer {
+		var privKey gocrypto.PrivateKey
+		var doc *did.Document
+		switch method {
+		case "key":
+			var didKey *key.DIDKey
+			privKey, didKey, err = key.GenerateDIDKey(crypto.RSA)
+			require.NoError(tt, err)
+			doc, err = didKey.Expand()
+		default:
+			var didJWK *didjwk.JWK
+			privKey, didJWK, err = didjwk.GenerateDIDJWK(crypto.RSA)
+			require.NoError(tt, err)
+			doc, err = didJWK.Expand()
+		}
+		require.NoError(tt, err)
+		rsaPrivKey, ok := privKey.(rsa.PrivateKey)
+		require.True(tt, ok)
+		assert.Equal(tt, crypto.RSAKeySize, rsaPrivKey.N.BitLen())
+		signer, err := jwx.NewJWXSigner(doc.ID, &doc.VerificationMethod[0].ID, privKey)
+		require.NoError(tt, err)
+		return *signer
+	}
+
+	for _, method := range []string{"key", "jwk"} {
+		for _, alg := range []jwa.SignatureAlgorithm{jwa.PS256, jwa.RS256} {
+			t.Run(fmt.Sprintf("did:%s with %s", method, alg), func(tt *testing.T) {
+				signer := rsaSigner(tt, method)
+				signer.ALG = alg.String()
+				signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+					Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+					Type:              []string{"VerifiableCredential"},
+					Issuer:            signer.ID,
+					IssuanceDate:      "2021-01-01T19:23:24Z",
+					CredentialSubject: map[string]any{"id": "did:example:456"},
+				})
+				require.NoError(tt, err)
+				headers, err := jwx.GetJWSHeaders(signed)
+				require.NoError(tt, err)
+				assert.Equal(tt, alg, headers.Algorithm())
+
+				ok, err := VerifyJWTCredential(context.Background(), string(signed), resolver)
+				assert.NoError(tt, err)
+				assert.True(tt, ok)
+				verifier, err := signer.ToVerifier(signer.ID)
+				require.NoError(tt, err)
+				_, _, cred, err := VerifyVerifiableCredentialJWT(*verifier, string(signed))
+				assert.NoError(tt, err)
+				assert.Equal(tt, signer.ID, cred.IssuerID())
+			})
+		}
+	}
+}
+
+func TestValidateCredentialForSigning(t *testing.T) {
+	testCredential := credential.VerifiableCredential{
+		Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:         []string{"VerifiableCredential"},
+		Issuer:       "did:example:123",
+		IssuanceDate: "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{
+			"id": "did:example:456",
+		},
+	}
+
+	t.Run("valid credential", func(tt *testing.T) {
+		assert.NoError(tt, ValidateCredentialForSigning(testCredential))
+	})
+
+	t.Run("empty credential", func(tt *testing.T) {
+		err := ValidateCredentialForSigning(credential.VerifiableCredential{})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential cannot be empty")
+	})
+
+	t.Run("missing issuance date", func(tt *testing.T) {
+		cred := testCredential
+		cred.IssuanceDate = ""
+		assert.NoError(tt, ValidateCredentialForSigning(cred))
+
+		err := ValidateCredentialForSigning(cred, RequireIssuanceDate)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential must have an issuanceDate")
+	})
+
+	t.Run("aggregates all problems", func(tt *testing.T) {
+		cred := testCredential
+		var proof crypto.Proof = map[string]any{"type": "JsonWebSignature2020"}
+		cred.Proof = &proof
+		cred.IssuanceDate = "not-a-date"
+		cred.ExpirationDate = "also-not-a-date"
+		err := ValidateCredentialForSigning(cred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential cannot already have a proof")
+		assert.Contains(tt, err.Error(), "issuanceDate<not-a-date> is not a valid RFC3339 date")
+		assert.Contains(tt, err.Error(), "setting exp value")
+
+		// signing reports the same problems before a key is used
+		_, err = SignVerifiableCredentialJWT(getTestVectorKey0Signer(tt), cred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential cannot already have a proof")
+	})
+}
+
+func TestCredentialToCanonicalJSON(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+		Context:        []any{"https://www.w3.org/2018/credentials/v1"},
+		ID:             "http://example.edu/credentials/1872",
+		Type:           []string{"VerifiableCredential"},
+		Issuer:         "did:example:123",
+		IssuanceDate:   "2021-01-01T19:23:24Z",
+		ExpirationDate: "2031-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{
+			"id":     "did:example:456",
+			"name":   "JimBobertson",
+			"degree": map[string]any{"type": "BachelorDegree", "gpa": 3.5},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("indented with sorted keys", func(tt *testing.T) {
+		canonical, err := CredentialToCanonicalJSON(string(signed))
+		require.NoError(tt, err)
+		assert.Equal(tt, `{
+  "@context": [
+    "https://www.w3.org/2018/credentials/v1"
+  ],
+  "credentialSubject": {
+    "degree": {
+      "gpa": 3.5,
+      "type": "BachelorDegree"
+    },
+    "id": "did:example:456",
+    "name": "JimBobertson"
+  },
+  "expirationDate": "2031-01-01T19:23:24Z",
+  "id": "http://example.edu/credentials/1872",
+  "issuanceDate": "2021-01-01T19:23:24Z",
+  "issuer": "did:example:123",
+  "type": [
+    "VerifiableCredential"
+  ]
+}`, string(canonical))
+	})
+
+	t.Run("reproduces the vc claim", func(tt *testing.T) {
+		canonical, err := CredentialToCanonicalJSON(string(signed))
+		require.NoError(tt, err)
+		var cred credential.VerifiableCredential
+		require.NoError(tt, json.Unmarshal(canonical, &cred))
+		reproduced, err := JWTClaimSetFromVC(cred)
+		require.NoError(tt, err)
+
+		_, original, _, err := ParseVerifiableCredentialFromJWT(string(signed))
+		require.NoError(tt, err)
+		originalVC, _ := original.Get(VCJWTProperty)
+		reproducedVC, _ := reproduced.Get(VCJWTProperty)
+		originalBytes, err := json.Marshal(originalVC)
+		require.NoError(tt, err)
+		reproducedBytes, err := json.Marshal(reproducedVC)
+		require.NoError(tt, err)
+		assert.JSONEq(tt, string(originalBytes), string(reproducedBytes))
+	})
+
+	t.Run("not a credential", func(tt *testing.T) {
+		_, err := CredentialToCanonicalJSON("not a token")
+		assert.ErrorContains(tt, err, "parsing credential token")
+	})
+}
+
+func TestValidatePresentationParameters(t *testing.T) {
+	t.Run("valid parameters", func(tt *testing.T) {
+		assert.NoError(tt, ValidatePresentationParameters(nil))
+		assert.NoError(tt, ValidatePresentationParameters(&JWTVVPParameters{}))
+		assert.NoError(tt, ValidatePresentationParameters(&JWTVVPParameters{
+			Audience:     []string{"did:example:verifier", "did:example:other"},
+			Expiration:   int(time.Now().Add(time.Hour).Unix()),
+			ProofPurpose: did.AssertionMethod,
+		}))
+	})
+
+	t.Run("aggregates all problems", func(tt *testing.T) {
+		err := ValidatePresentationParameters(&JWTVVPParameters{
+			Audience:     []string{"did:example:verifier", "", "  ", "did:example:verifier"},
+			Expiration:   -1,
+			ProofPurpose: did.KeyAgreement,
+		})
+		assert.ErrorIs(tt, err, ErrInvalidParameters)
+		assert.Contains(tt, err.Error(), "expiration<-1> cannot be negative")
+		assert.Contains(tt, err.Error(), "audience 1 cannot be empty")
+		assert.Contains(tt, err.Error(), "audience 2 cannot be empty")
+		assert.Contains(tt, err.Error(), "audience 3<did:example:verifier> is a duplicate")
+		assert.Contains(tt, err.Error(), "proof purpose<keyAgreement> is not a signing purpose")
+
+		// signing reports the same problems
+		signer := getTestVectorKey0Signer(tt)
+		_, err = SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{""}}, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		assert.ErrorIs(tt, err, ErrInvalidParameters)
+	})
+}
+
+func TestSignVerifiablePresentationJWTClampToCredentialExpiry(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	now := time.Now().Truncate(time.Second)
+	credentialExpiring := func(tt *testing.T, expiry time.Time) string {
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			ExpirationDate:    expiry.UTC().Format(time.RFC3339),
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	presentationExpiry := func(tt *testing.T, parameters *JWTVVPParameters, creds ...any) time.Time {
+		signed, err := SignVerifiablePresentationJWT(signer, parameters, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		_, token, _, err := ParseVerifiablePresentationFromJWT(string(signed))
+		require.NoError(tt, err)
+		return token.Expiration()
+	}
+	inAYear := int(now.Add(365 * 24 * time.Hour).Unix())
+	tomorrow := now.Add(24 * time.Hour)
+	// credential objects are embedded with their proof
+	proof := crypto.Proof(map[string]any{"type": "JsonWebSignature2020"})
+
+	t.Run("clamped to the earliest credential expiry", func(tt *testing.T) {
+		creds := []any{
+			credentialExpiring(tt, now.Add(48*time.Hour)),
+			credential.VerifiableCredential{ExpirationDate: tomorrow.UTC().Format(time.RFC3339), Proof: &proof},
+			CredentialHash("hashed"),
+		}
+		exp := presentationExpiry(tt, &JWTVVPParameters{Expiration: inAYear, ClampToCredentialExpiry: true}, creds...)
+		assert.Equal(tt, tomorrow.Unix(), exp.Unix())
+
+		// without an expiration one is set
+		exp = presentationExpiry(tt, &JWTVVPParameters{ClampToCredentialExpiry: true}, creds...)
+		assert.Equal(tt, tomorrow.Unix(), exp.Unix())
+
+		// and without the option the requested expiration is kept
+		exp = presentationExpiry(tt, &JWTVVPParameters{Expiration: inAYear}, creds...)
+		assert.Equal(tt, int64(inAYear), exp.Unix())
+	})
+
+	t.Run("earlier expiration is kept", func(tt *testing.T) {
+		exp := presentationExpiry(tt, &JWTVVPParameters{Expiration: int(now.Add(time.Hour).Unix()), ClampToCredentialExpiry: true},
+			credentialExpiring(tt, tomorrow))
+		assert.Equal(tt, now.Add(time.Hour).Unix(), exp.Unix())
+
+		exp = presentationExpiry(tt, &JWTVVPParameters{ClampToCredentialExpiry: true}, map[string]any{"id": "no-expiry", "proof": proof})
+		assert.True(tt, exp.IsZero())
+	})
+
+	t.Run("unreadable credential expiry", func(tt *testing.T) {
+		_, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{ClampToCredentialExpiry: true}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: []any{map[string]any{"expirationDate": "tomorrow", "proof": proof}},
+		})
+		assert.ErrorContains(tt, err, "reading expiry of credential 0")
+	})
+}
+
+func TestSignVerifiableCredentialJWTIssuanceDate(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	cred := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            "did:example:123",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	}
+
+	t.Run("defaults to now", func(tt *testing.T) {
+		before := time.Now().Truncate(time.Second)
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+		assert.Empty(tt, cred.IssuanceDate)
+
+		_, token, parsed, err := ParseVerifiableCredentialFromJWT(string(signed))
+		assert.NoError(tt, err)
+		assert.False(tt, token.IssuedAt().Before(before))
+		assert.Equal(tt, token.IssuedAt(), token.NotBefore())
+		assert.Equal(tt, token.IssuedAt().Format(time.RFC3339), parsed.IssuanceDate)
+	})
+
+	t.Run("defaulting disabled", func(tt *testing.T) {
+		_, err := SignVerifiableCredentialJWT(signer, cred, RequireIssuanceDate)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential must have an issuanceDate")
+	})
+
+	t.Run("unparseable date", func(tt *testing.T) {
+		badCred := cred
+		badCred.IssuanceDate = "January 1st, 2021"
+		_, err := SignVerifiableCredentialJWT(signer, badCred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "issuanceDate<January 1st, 2021> is not a valid RFC3339 date")
+	})
+}
+
+func TestSignVerifiableCredentialJWTDeterministicNonce(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	cred := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            signer.ID,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456", "name": "Alice"},
+	}
+
+	t.Run("same credential signs to the same token", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJWT(signer, cred, WithDeterministicNonce())
+		require.NoError(tt, err)
+		retried, err := SignVerifiableCredentialJWT(signer, cred, WithDeterministicNonce())
+		require.NoError(tt, err)
+		assert.Equal(tt, string(signed), string(retried))
+
+		_, token, _, err := ParseVerifiableCredentialFromJWT(string(signed))
+		require.NoError(tt, err)
+		nonce, ok := token.Get(NonceProperty)
+		require.True(tt, ok)
+		_, err = uuid.Parse(nonce.(string))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("different credentials get different nonces", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJWT(signer, cred, WithDeterministicNonce())
+		require.NoError(tt, err)
+		other := cred
+		other.CredentialSubject = map[string]any{"id": "did:example:456", "name": "Bob"}
+		otherSigned, err := SignVerifiableCredentialJWT(signer, other, WithDeterministicNonce())
+		require.NoError(tt, err)
+
+		_, token, _, err := ParseVerifiableCredentialFromJWT(string(signed))
+		require.NoError(tt, err)
+		_, otherToken, _, err := ParseVerifiableCredentialFromJWT(string(otherSigned))
+		require.NoError(tt, err)
+		nonce, _ := token.Get(NonceProperty)
+		otherNonce, _ := otherToken.Get(NonceProperty)
+		assert.NotEqual(tt, nonce, otherNonce)
+	})
+
+	t.Run("random nonce by default", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+		again, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+		assert.NotEqual(tt, string(signed), string(again))
+	})
+}
+
+func TestSignVerifiableCredentialJWTJSON(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	verifier, err := signer.ToVerifier(signer.ID)
+	require.NoError(t, err)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	cred := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            signer.ID,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	}
+
+	t.Run("credential in JSON serialization", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJWTJSON(signer, cred, WithDeterministicNonce())
+		require.NoError(tt, err)
+		assert.True(tt, jwx.IsJSONSerialization(signed))
+
+		// the JSON serialization carries the same signature as the compact one
+		compact, err := SignVerifiableCredentialJWT(signer, cred, WithDeterministicNonce())
+		require.NoError(tt, err)
+		converted, err := jwx.ToCompactSerialization(signed)
+		require.NoError(tt, err)
+		assert.Equal(tt, string(compact), string(converted))
+
+		_, _, parsed, err := ParseVerifiableCredentialFromJWT(string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, signer.ID, parsed.IssuerID())
+		_, _, verified, err := VerifyVerifiableCredentialJWT(*verifier, string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, parsed, verified)
+		ok, err := VerifyJWTCredential(context.Background(), string(signed), resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, ok)
+
+		result, err := VerifyAny(context.Background(), *verifier, resolver, string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, CredentialJWTType, result.Type)
+	})
+
+	t.Run("presentation in JSON serialization", func(tt *testing.T) {
+		signedCred, err := SignVerifiableCredentialJWTJSON(signer, cred)
+		require.NoError(tt, err)
+		signed, err := SignVerifiablePresentationJWT(signer, nil, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: []any{string(signedCred)},
+		}, WithJSONSerialization())
+		require.NoError(tt, err)
+		assert.True(tt, jwx.IsJSONSerialization(signed))
+
+		_, _, pres, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, signer.ID, pres.Holder)
+	})
+
+	t.Run("issuer in JSON serialization", func(tt *testing.T) {
+		issuer, err := NewIssuer(signer, WithJSONSerialization())
+		require.NoError(tt, err)
+		signed, err := issuer.Issue(cred)
+		require.NoError(tt, err)
+		assert.True(tt, jwx.IsJSONSerialization(signed))
+		_, _, _, err = VerifyVerifiableCredentialJWT(*verifier, string(signed))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("multiple signatures are not a JWT", func(tt *testing.T) {
+		multi, err := SignMultiSignatureCredential([]jwx.Signer{signer, getTestDIDKeySigner(tt)}, cred)
+		require.NoError(tt, err)
+		_, _, _, err = ParseVerifiableCredentialFromJWT(string(multi))
+		assert.ErrorContains(tt, err, "JWS has 2 signatures")
+	})
+}
+
+func TestSignVerifiableCredentialJWTParts(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	cred := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            signer.ID,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	}
+
+	t.Run("parts of the signed token", func(tt *testing.T) {
+		parts, err := SignVerifiableCredentialJWTParts(signer, cred, WithDeterministicNonce())
+		require.NoError(tt, err)
+
+		// the parts are those of the token SignVerifiableCredentialJWT returns
+		signed, err := SignVerifiableCredentialJWT(signer, cred, WithDeterministicNonce())
+		require.NoError(tt, err)
+		assert.Equal(tt, string(signed), string(parts.Token))
+		assert.Equal(tt, string(parts.Token), fmt.Sprintf("%s.%s", parts.SigningInput(),
+			base64.RawURLEncoding.EncodeToString(parts.Signature)))
+
+		var header map[string]any
+		require.NoError(tt, json.Unmarshal(parts.ProtectedHeader, &header))
+		assert.Equal(tt, signer.KID, header["kid"])
+		assert.Equal(tt, VCJWTType, header["typ"])
+		var claims map[string]any
+		require.NoError(tt, json.Unmarshal(parts.Payload, &claims))
+		assert.Equal(tt, signer.ID, claims["iss"])
+		assert.Contains(tt, claims, VCJWTProperty)
+
+		privateKey, ok := signer.PrivateKey.(ed25519.PrivateKey)
+		require.True(tt, ok)
+		assert.True(tt, ed25519.Verify(privateKey.Public().(ed25519.PublicKey), parts.SigningInput(), parts.Signature))
+	})
+
+	t.Run("JSON serialization has no compact parts", func(tt *testing.T) {
+		_, err := SignVerifiableCredentialJWTParts(signer, cred, WithJSONSerialization())
+		assert.ErrorContains(tt, err, "compact serialization")
+	})
+
+	t.Run("invalid credential", func(tt *testing.T) {
+		_, err := SignVerifiableCredentialJWTParts(signer, credential.VerifiableCredential{})
+		assert.Error(tt, err)
+	})
+}
+
+func TestVerifiableCredentialJWTClaimConsistency(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	verifier, err := signer.ToVerifier(signer.ID)
+	require.NoError(t, err)
+
+	testCredential := credential.VerifiableCredential{
+		Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:         []string{"VerifiableCredential"},
+		Issuer:       "did:example:123",
+		IssuanceDate: "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{
+			"id": "did:example:456",
+		},
+	}
+
+	t.Run("exp before iat and nbf", func(tt *testing.T) {
+		cred := testCredential
+		cred.ExpirationDate = "2020-01-01T19:23:24Z"
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+
+		_, _, _, err = VerifyVerifiableCredentialJWT(*verifier, string(signed))
+		assert.Error(tt, err)
+		assert.ErrorIs(tt, err, ErrInconsistentClaims)
+		assert.Contains(tt, err.Error(), "is not after nbf")
+	})
+
+	t.Run("exp equal to iat", func(tt *testing.T) {
+		cred := testCredential
+		cred.ExpirationDate = cred.IssuanceDate
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+
+		_, _, _, err = VerifyVerifiableCredentialJWT(*verifier, string(signed))
+		assert.ErrorIs(tt, err, ErrInconsistentClaims)
+	})
+
+	t.Run("nbf before iat", func(tt *testing.T) {
+		signed, err := signer.SignWithDefaults(map[string]any{
+			"iat":         time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC).Unix(),
+			"nbf":         time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix(),
+			VCJWTProperty: map[string]any{"@context": testCredential.Context, "type": testCredential.Type, "credentialSubject": map[string]any{}},
+		})
+		require.NoError(tt, err)
+
+		_, _, _, err = VerifyVerifiableCredentialJWT(*verifier, string(signed))
+		assert.ErrorIs(tt, err, ErrInconsistentClaims)
+		assert.Contains(tt, err.Error(), "is before iat")
+	})
+
+	t.Run("consistent claims", func(tt *testing.T) {
+		cred := testCredential
+		cred.ExpirationDate = "2031-01-01T19:23:24Z"
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+
+		_, _, _, err = VerifyVerifiableCredentialJWT(*verifier, string(signed))
+		assert.NoError(tt, err)
+	})
+}
+
 func TestVerifiablePresentationJWT(t *testing.T) {
 	t.Run("bad audience", func(tt *testing.T) {
 		signer := getTestVectorKey0Signer(tt)
@@ -119,7 +859,7 @@ func TestVerifiablePresentationJWT(t *testing.T) {
 	})
 
 	t.Run("no audience", func(tt *testing.T) {
-		signer := getTestVectorKey0Signer(tt)
+		signer := getTestDIDKeySigner(tt)
 
 		testPresentation := credential.VerifiablePresentation{
 			Context: []string{"https://www.w3.org/2018/credentials/v1",
@@ -147,7 +887,7 @@ func TestVerifiablePresentationJWT(t *testing.T) {
 	})
 
 	t.Run("no VCs", func(tt *testing.T) {
-		signer := getTestVectorKey0Signer(tt)
+		signer := getTestDIDKeySigner(tt)
 
 		testPresentation := credential.VerifiablePresentation{
 			Context: []string{"https://www.w3.org/2018/credentials/v1",
@@ -274,3 +1014,1779 @@ func getTestVectorKey0Signer(t *testing.T) jwx.Signer {
 	require.NoError(t, err)
 	return *signer
 }
+
+// getTestDIDKeySigner returns a signer for a new did:key, whose key resolves from its ID as that of presentation
+// holders must
+func getTestDIDKeySigner(t testing.TB) jwx.Signer {
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+	return *signer
+}
+
+func TestVerifiablePresentationJWTSingleCredential(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	verifier, err := signer.ToVerifier(signer.ID)
+	require.NoError(t, err)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	signedCred, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            signer.ID,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	})
+	require.NoError(t, err)
+
+	// presentations signed by other implementations may hold a single credential without an array
+	signPresentation := func(tt *testing.T, vp map[string]any) string {
+		vp["@context"] = []string{"https://www.w3.org/2018/credentials/v1"}
+		vp["type"] = []string{"VerifiablePresentation"}
+		payload, err := json.Marshal(map[string]any{jwt.IssuerKey: signer.ID, VPJWTProperty: vp})
+		require.NoError(tt, err)
+		signed, err := signer.SignJWS(payload)
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("single credential JWT", func(tt *testing.T) {
+		token := signPresentation(tt, map[string]any{"verifiableCredential": string(signedCred)})
+		_, _, pres, err := ParseVerifiablePresentationFromJWT(token)
+		require.NoError(tt, err)
+		assert.Equal(tt, []any{string(signedCred)}, pres.VerifiableCredential)
+
+		_, _, verified, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, token)
+		assert.NoError(tt, err)
+		assert.Len(tt, verified.VerifiableCredential, 1)
+	})
+
+	t.Run("single credential object", func(tt *testing.T) {
+		token := signPresentation(tt, map[string]any{"verifiableCredential": map[string]any{"id": "urn:uuid:1"}})
+		_, _, pres, err := ParseVerifiablePresentationFromJWT(token)
+		require.NoError(tt, err)
+		require.Len(tt, pres.VerifiableCredential, 1)
+		assert.Equal(tt, map[string]any{"id": "urn:uuid:1"}, pres.VerifiableCredential[0])
+	})
+
+	t.Run("array of credentials", func(tt *testing.T) {
+		token := signPresentation(tt, map[string]any{"verifiableCredential": []any{string(signedCred), string(signedCred)}})
+		_, _, pres, err := ParseVerifiablePresentationFromJWT(token)
+		require.NoError(tt, err)
+		assert.Len(tt, pres.VerifiableCredential, 2)
+	})
+
+	t.Run("no credentials", func(tt *testing.T) {
+		token := signPresentation(tt, map[string]any{})
+		_, _, pres, err := ParseVerifiablePresentationFromJWT(token)
+		require.NoError(tt, err)
+		assert.Empty(tt, pres.VerifiableCredential)
+		assert.Equal(tt, signer.ID, pres.Holder)
+	})
+}
+
+func TestVerifyAny(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	verifier, err := signer.ToVerifier(signer.ID)
+	require.NoError(t, err)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	t.Run("credential", func(tt *testing.T) {
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+
+		result, err := VerifyAny(context.Background(), *verifier, resolver, string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, CredentialJWTType, result.Type)
+		assert.NotEmpty(tt, result.Credential)
+		assert.Empty(tt, result.Presentation)
+		assert.NotEmpty(tt, result.Headers)
+		assert.NotEmpty(tt, result.Token)
+	})
+
+	t.Run("credential with header extensions", func(tt *testing.T) {
+		claims, err := JWTClaimSetFromVC(credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		headers := jws.NewHeaders()
+		require.NoError(tt, headers.Set(jws.KeyIDKey, signer.KID))
+		require.NoError(tt, headers.Set(jws.TypeKey, VCJWTType))
+		require.NoError(tt, headers.Set("ver", "2"))
+		require.NoError(tt, headers.Set("b64", true))
+		signed, err := jwt.Sign(claims, jwt.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey, jws.WithProtectedHeaders(headers)))
+		require.NoError(tt, err)
+
+		result, err := VerifyAny(context.Background(), *verifier, resolver, string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, CredentialJWTType, result.Type)
+		assert.Equal(tt, map[string]any{"ver": "2", "b64": true}, result.HeaderExtensions)
+	})
+
+	t.Run("presentation", func(tt *testing.T) {
+		signed, err := SignVerifiablePresentationJWT(signer, nil, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		require.NoError(tt, err)
+
+		result, err := VerifyAny(context.Background(), *verifier, resolver, string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, PresentationJWTType, result.Type)
+		assert.NotEmpty(tt, result.Presentation)
+		assert.Empty(tt, result.Credential)
+	})
+
+	t.Run("neither vc nor vp", func(tt *testing.T) {
+		signed, err := signer.SignWithDefaults(map[string]any{"test": "value"})
+		require.NoError(tt, err)
+
+		_, err = VerifyAny(context.Background(), *verifier, resolver, string(signed))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "neither a vc nor a vp property")
+	})
+
+	t.Run("both vc and vp", func(tt *testing.T) {
+		signed, err := signer.SignWithDefaults(map[string]any{VCJWTProperty: map[string]any{}, VPJWTProperty: map[string]any{}})
+		require.NoError(tt, err)
+
+		_, err = VerifyAny(context.Background(), *verifier, resolver, string(signed))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "cannot have both vc and vp properties")
+	})
+
+	t.Run("not a JWT", func(tt *testing.T) {
+		_, err := VerifyAny(context.Background(), *verifier, resolver, "not-a-jwt")
+		assert.Error(tt, err)
+	})
+}
+
+func TestParseVerifiableCredentialFromToken(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+		ID:                "urn:uuid:123",
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            signer.ID,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456", "degree": map[string]any{"type": "BachelorDegree"}},
+		Extensions:        map[string]any{"name": "Example Credential"},
+	})
+	require.NoError(t, err)
+	token, err := jwt.Parse(signed, jwt.WithVerify(false), jwt.WithValidate(false))
+	require.NoError(t, err)
+	claimBefore, err := json.Marshal(token.PrivateClaims()[VCJWTProperty])
+	require.NoError(t, err)
+
+	cred, err := ParseVerifiableCredentialFromToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "did:example:456", cred.CredentialSubject.GetID())
+	assert.Equal(t, "Example Credential", cred.Extensions["name"])
+
+	// the credential shares none of the claim's values, so changing it leaves the token and later parses unchanged
+	cred.CredentialSubject["degree"].(map[string]any)["type"] = "MasterDegree"
+	claimAfter, err := json.Marshal(token.PrivateClaims()[VCJWTProperty])
+	require.NoError(t, err)
+	assert.JSONEq(t, string(claimBefore), string(claimAfter))
+	reparsed, err := ParseVerifiableCredentialFromToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"type": "BachelorDegree"}, reparsed.CredentialSubject["degree"])
+}
+
+func BenchmarkParseVerifiableCredentialFromToken(b *testing.B) {
+	signer := getTestDIDKeySigner(b)
+	subject := map[string]any{"id": "did:example:456", "name": "Alice"}
+	for i := 0; i < 20; i++ {
+		subject[fmt.Sprintf("claim%d", i)] = map[string]any{"value": i, "values": []any{"a", "b", "c"}}
+	}
+	signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            signer.ID,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: subject,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	token, err := jwt.Parse(signed, jwt.WithVerify(false), jwt.WithValidate(false))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = ParseVerifiableCredentialFromToken(token); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestVerifiableCredentialJWTSubjects(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	baseCredential := credential.VerifiableCredential{
+		Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:         []any{"VerifiableCredential"},
+		Issuer:       "did:example:123",
+		IssuanceDate: "2021-01-01T19:23:24Z",
+	}
+
+	t.Run("single subject with id", func(tt *testing.T) {
+		cred := baseCredential
+		cred.CredentialSubject = map[string]any{"id": "did:example:456", "name": "JimBobertson"}
+
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+
+		_, token, parsed, err := ParseVerifiableCredentialFromJWT(string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:456", token.Subject())
+		assert.Equal(tt, cred, *parsed)
+	})
+
+	t.Run("single subject without id", func(tt *testing.T) {
+		cred := baseCredential
+		cred.CredentialSubject = map[string]any{"name": "JimBobertson"}
+
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+
+		_, token, parsed, err := ParseVerifiableCredentialFromJWT(string(signed))
+		assert.NoError(tt, err)
+		assert.Empty(tt, token.Subject())
+		assert.Equal(tt, cred, *parsed)
+	})
+
+	t.Run("array with a single subject", func(tt *testing.T) {
+		cred := baseCredential
+		cred.CredentialSubjects = []credential.CredentialSubject{{"id": "did:example:456", "name": "JimBobertson"}}
+
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+
+		_, token, parsed, err := ParseVerifiableCredentialFromJWT(string(signed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:456", token.Subject())
+		assert.Equal(tt, cred, *parsed)
+		assert.Equal(tt, "did:example:456", cred.CredentialSubjects[0].GetID())
+	})
+
+	t.Run("multiple subjects", func(tt *testing.T) {
+		cred := baseCredential
+		cred.CredentialSubjects = []credential.CredentialSubject{
+			{"id": "did:example:456", "name": "JimBobertson"},
+			{"id": "did:example:789", "name": "JaneBobertson"},
+		}
+
+		signed, err := SignVerifiableCredentialJWT(signer, cred)
+		require.NoError(tt, err)
+
+		_, token, parsed, err := ParseVerifiableCredentialFromJWT(string(signed))
+		assert.NoError(tt, err)
+		assert.Empty(tt, token.Subject())
+		assert.Equal(tt, cred, *parsed)
+		assert.NoError(tt, parsed.IsValid())
+	})
+}
+
+func TestJWTTypeHeader(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	signWithType := func(tt *testing.T, typ string, claims map[string]any) string {
+		token := jwt.New()
+		for k, v := range claims {
+			require.NoError(tt, token.Set(k, v))
+		}
+		hdrs := jws.NewHeaders()
+		require.NoError(tt, hdrs.Set(jws.TypeKey, typ))
+		signed, err := jwt.Sign(token, jwt.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	vcClaim := map[string]any{"@context": []any{"https://www.w3.org/2018/credentials/v1"}, "type": []any{"VerifiableCredential"}, "credentialSubject": map[string]any{}}
+	vpClaim := map[string]any{"@context": []any{"https://www.w3.org/2018/credentials/v1"}, "type": []any{"VerifiablePresentation"}}
+
+	t.Run("signed tokens carry their typ", func(tt *testing.T) {
+		signedVC, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		headers, _, _, err := ParseVerifiableCredentialFromJWT(string(signedVC))
+		assert.NoError(tt, err)
+		assert.Equal(tt, VCJWTType, headers.Type())
+
+		signedVP, err := SignVerifiablePresentationJWT(signer, nil, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		require.NoError(tt, err)
+		headers, _, _, err = ParseVerifiablePresentationFromJWT(string(signedVP))
+		assert.NoError(tt, err)
+		assert.Equal(tt, VPJWTType, headers.Type())
+
+		_, _, _, err = ParseVerifiableCredentialFromJWT(string(signedVP))
+		assert.Error(tt, err)
+	})
+
+	t.Run("presentation with a vc claim is not a credential", func(tt *testing.T) {
+		token := signWithType(tt, VPJWTType, map[string]any{"iss": signer.ID, VPJWTProperty: vpClaim, VCJWTProperty: vcClaim})
+
+		_, _, _, err := ParseVerifiableCredentialFromJWT(token)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "token typ<vp+jwt> does not match the expected typ<vc+jwt>")
+
+		_, _, _, err = ParseVerifiablePresentationFromJWT(token)
+		assert.NoError(tt, err)
+
+		jwtType, err := DetectJWTType(token)
+		assert.NoError(tt, err)
+		assert.Equal(tt, PresentationJWTType, jwtType)
+	})
+
+	t.Run("credential with a vp claim is not a presentation", func(tt *testing.T) {
+		token := signWithType(tt, VCJWTType, map[string]any{"iss": signer.ID, VPJWTProperty: vpClaim, VCJWTProperty: vcClaim})
+
+		_, _, _, err := ParseVerifiablePresentationFromJWT(token)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "token typ<vc+jwt> does not match the expected typ<vp+jwt>")
+
+		_, _, _, err = ParseVerifiableCredentialFromJWT(token)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("legacy token without a vc+jwt typ", func(tt *testing.T) {
+		token := signWithType(tt, "JWT", map[string]any{"iss": signer.ID, VCJWTProperty: vcClaim})
+
+		_, _, cred, err := ParseVerifiableCredentialFromJWT(token)
+		assert.NoError(tt, err)
+		assert.Equal(tt, signer.ID, cred.Issuer)
+	})
+}
+
+func TestVerifyNestedVerifiablePresentationJWT(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	newDIDKeySigner := func(tt *testing.T) *jwx.Signer {
+		privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		expanded, err := didKey.Expand()
+		require.NoError(tt, err)
+		kid := expanded.VerificationMethod[0].ID
+		signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+		require.NoError(tt, err)
+		return signer
+	}
+	signPresentation := func(tt *testing.T, signer *jwx.Signer, audience string, credentials ...any) string {
+		signed, err := SignVerifiablePresentationJWT(*signer, &JWTVVPParameters{Audience: []string{audience}}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: credentials,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	issuer := newDIDKeySigner(t)
+	signedVC, err := SignVerifiableCredentialJWT(*issuer, credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            issuer.ID,
+		IssuanceDate:      time.Now().Format(time.RFC3339),
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	})
+	require.NoError(t, err)
+
+	// the delegator presents the credential to the delegate, who presents it on to the verifier
+	delegator := newDIDKeySigner(t)
+	delegate := newDIDKeySigner(t)
+	const verifierID = "did:example:verifier"
+
+	t.Run("nested presentation", func(tt *testing.T) {
+		inner := signPresentation(tt, delegator, delegate.ID, string(signedVC))
+		outer := signPresentation(tt, delegate, verifierID, inner, string(signedVC))
+
+		verifier, err := delegate.ToVerifier(verifierID)
+		require.NoError(tt, err)
+		verified, err := VerifyNestedVerifiablePresentationJWT(context.Background(), *verifier, resolver, outer, DefaultMaxPresentationDepth)
+		assert.NoError(tt, err)
+		assert.Equal(tt, delegate.ID, verified.Presentation.Holder)
+		require.Len(tt, verified.Nested, 1)
+		assert.Equal(tt, delegator.ID, verified.Nested[0].Presentation.Holder)
+		assert.Empty(tt, verified.Nested[0].Nested)
+
+		// the nested presentation is also verified by default
+		_, _, pres, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, outer)
+		assert.NoError(tt, err)
+		assert.Len(tt, pres.VerifiableCredential, 2)
+	})
+
+	t.Run("max depth exceeded", func(tt *testing.T) {
+		inner := signPresentation(tt, delegator, delegate.ID, string(signedVC))
+		middle := signPresentation(tt, delegate, delegator.ID, inner)
+		outer := signPresentation(tt, delegator, verifierID, middle)
+
+		verifier, err := delegator.ToVerifier(verifierID)
+		require.NoError(tt, err)
+		verified, err := VerifyNestedVerifiablePresentationJWT(context.Background(), *verifier, resolver, outer, 2)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, verified.Nested[0].Nested[0])
+
+		_, err = VerifyNestedVerifiablePresentationJWT(context.Background(), *verifier, resolver, outer, 1)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "exceeds the max nesting depth of 1")
+	})
+
+	t.Run("nested presentation for another audience", func(tt *testing.T) {
+		inner := signPresentation(tt, delegator, "did:example:someone-else", string(signedVC))
+		outer := signPresentation(tt, delegate, verifierID, inner)
+
+		verifier, err := delegate.ToVerifier(verifierID)
+		require.NoError(tt, err)
+		_, err = VerifyNestedVerifiablePresentationJWT(context.Background(), *verifier, resolver, outer, DefaultMaxPresentationDepth)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifying nested presentation 0")
+		assert.Contains(tt, err.Error(), "audience mismatch")
+	})
+
+	t.Run("presentation containing itself", func(tt *testing.T) {
+		inner := signPresentation(tt, delegator, delegate.ID, string(signedVC))
+		verifier, err := delegator.ToVerifier(delegate.ID)
+		require.NoError(tt, err)
+
+		pv := presentationVerification{maxDepth: DefaultMaxPresentationDepth, seen: map[string]bool{inner: true}}
+		_, err = verifyPresentationJWT(context.Background(), *verifier, []string{delegate.ID}, resolver, inner, 1, &pv)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "presentation contains itself")
+	})
+}
+
+func TestVerifyVerifiablePresentationJWTWithoutCredentialVerification(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	// the embedded credential cannot be verified, its issuer being unresolvable
+	issuer := getTestVectorKey0Signer(t)
+	signedVC, err := SignVerifiableCredentialJWT(issuer, credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            issuer.ID,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	})
+	require.NoError(t, err)
+	signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{"did:example:verifier"}}, credential.VerifiablePresentation{
+		Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+		Type:                 []string{"VerifiablePresentation"},
+		Holder:               signer.ID,
+		VerifiableCredential: []any{string(signedVC)},
+	})
+	require.NoError(t, err)
+
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+
+	_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "verifying credential 0")
+
+	_, _, pres, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed), WithoutCredentialVerification)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.ID, pres.Holder)
+	assert.Equal(t, []any{string(signedVC)}, pres.VerifiableCredential)
+
+	// the presentation's own signature and audience are still verified
+	otherVerifier, err := signer.ToVerifier("did:example:other")
+	require.NoError(t, err)
+	_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *otherVerifier, resolver, string(signed), WithoutCredentialVerification)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "audience mismatch")
+}
+
+func TestVerifyVerifiablePresentationJWTRequireNonEmpty(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	signPresentation := func(tt *testing.T, audience string, creds ...any) string {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{audience}}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+	empty := signPresentation(t, verifier.ID)
+
+	t.Run("empty presentations are accepted by default", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, empty)
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, empty, WithRequireNonEmpty(false))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("empty presentations are rejected when required", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, empty, WithRequireNonEmpty(true))
+		assert.ErrorIs(tt, err, ErrEmptyPresentation)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, empty, WithStrictMode())
+		assert.ErrorIs(tt, err, ErrEmptyPresentation)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, empty, WithoutCredentialVerification, WithStrictMode())
+		assert.ErrorIs(tt, err, ErrEmptyPresentation)
+	})
+
+	t.Run("strict mode can allow empty presentations", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, empty, WithRequireNonEmpty(false), WithStrictMode())
+		assert.NoError(tt, err)
+	})
+
+	t.Run("nested empty presentations are rejected", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, verifier.ID, signPresentation(tt, signer.ID)), WithStrictMode())
+		assert.ErrorIs(tt, err, ErrEmptyPresentation)
+		assert.ErrorContains(tt, err, "verifying nested presentation 0")
+	})
+
+	t.Run("presentations with credentials pass", func(tt *testing.T) {
+		signedVC, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, verifier.ID, string(signedVC)), WithStrictMode())
+		assert.NoError(tt, err)
+	})
+
+	t.Run("invalid option value", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, empty, VerifyOption{Type: RequireNonEmptyOption, Value: "yes"})
+		assert.ErrorContains(tt, err, "must be a bool")
+	})
+}
+
+func TestVerifyVerifiablePresentationJWTRequireAudience(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	signPresentation := func(tt *testing.T, audience []string, creds ...any) string {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: audience}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+	unaddressed := signPresentation(t, nil)
+
+	t.Run("presentations without an audience are accepted by default", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, unaddressed)
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, unaddressed, WithRequireAudience(false))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("presentations without an audience are rejected when required", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, unaddressed, WithRequireAudience(true))
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+		assert.ErrorContains(tt, err, "no aud claim")
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, unaddressed, WithRequireNonEmpty(false), WithStrictMode())
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, unaddressed, WithRequireAudience(false), WithStrictMode(), WithRequireNonEmpty(false))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("the audience must still match", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, []string{verifier.ID}), WithRequireAudience(true))
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, []string{"did:example:other"}), WithRequireAudience(true))
+		assert.ErrorIs(tt, err, ErrAudienceMismatch)
+	})
+
+	t.Run("nested presentations need no audience", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, []string{verifier.ID}, unaddressed), WithRequireAudience(true))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("reported", func(tt *testing.T) {
+		report, err := VerifyPresentationReport(context.Background(), *verifier, resolver, unaddressed, WithRequireAudience(true))
+		require.NoError(tt, err)
+		assert.ErrorIs(tt, report.AudienceError, ErrMissingClaim)
+		assert.False(tt, report.Valid())
+	})
+
+	t.Run("invalid option value", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, unaddressed, VerifyOption{Type: RequireAudienceOption, Value: "yes"})
+		assert.ErrorContains(tt, err, "must be a bool")
+	})
+}
+
+// testPresentationStatus revokes the presentations with the given ids, failing on those with a malformed status
+type testPresentationStatus map[string]bool
+
+func (s testPresentationStatus) IsPresentationRevoked(_ context.Context, vp credential.VerifiablePresentation) (bool, error) {
+	if _, ok := vp.CredentialStatus.(map[string]any); !ok {
+		return false, errors.New("malformed credentialStatus")
+	}
+	return s[vp.ID], nil
+}
+
+func TestVerifyVerifiablePresentationJWTStatus(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	signPresentation := func(tt *testing.T, audience, id string, status any, creds ...any) string {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{audience}}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			ID:                   id,
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+			CredentialStatus:     status,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+	status := map[string]any{"id": "https://example.com/status/1#1", "type": "StatusList2021Entry"}
+	checker := testPresentationStatus{"urn:uuid:revoked": true}
+
+	t.Run("revoked presentations are rejected", func(tt *testing.T) {
+		revoked := signPresentation(tt, verifier.ID, "urn:uuid:revoked", status)
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, revoked, WithPresentationStatus(checker))
+		assert.ErrorIs(tt, err, ErrRevoked)
+
+		// the status is only checked when asked to
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, revoked)
+		assert.NoError(tt, err)
+
+		// as are nested presentations
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, verifier.ID, "urn:uuid:outer", status, signPresentation(tt, signer.ID, "urn:uuid:revoked", status)), WithPresentationStatus(checker))
+		assert.ErrorIs(tt, err, ErrRevoked)
+	})
+
+	t.Run("presentations that are not revoked or have no status pass", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, verifier.ID, "urn:uuid:valid", status), WithPresentationStatus(checker))
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, verifier.ID, "urn:uuid:revoked", nil), WithPresentationStatus(checker))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("status that cannot be checked", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, verifier.ID, "urn:uuid:valid", "malformed"), WithPresentationStatus(checker))
+		assert.ErrorContains(tt, err, "checking status of presentation: malformed credentialStatus")
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, verifier.ID, "urn:uuid:valid", nil), VerifyOption{Type: PresentationStatusOption})
+		assert.ErrorContains(tt, err, "requires a status checker")
+	})
+}
+
+func TestParseVerifiableCredentialFromJWTEpochDates(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	signWithVCClaim := func(tt *testing.T, vc any) string {
+		token := jwt.New()
+		require.NoError(tt, token.Set(jwt.IssuerKey, signer.ID))
+		require.NoError(tt, token.Set(VCJWTProperty, vc))
+		signed, err := jwt.Sign(token, jwt.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey))
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	tests := []struct {
+		name string
+		vc   any
+	}{
+		{
+			name: "epoch seconds",
+			vc: map[string]any{
+				"@context":       []any{"https://www.w3.org/2018/credentials/v1"},
+				"type":           []any{"VerifiableCredential"},
+				"issuanceDate":   1609529004,
+				"expirationDate": 2556213804.5,
+			},
+		},
+		{
+			name: "RFC3339 strings",
+			vc: map[string]any{
+				"@context":       []any{"https://www.w3.org/2018/credentials/v1"},
+				"type":           []any{"VerifiableCredential"},
+				"issuanceDate":   "2021-01-01T19:23:24Z",
+				"expirationDate": "2051-01-01T19:23:24Z",
+			},
+		},
+		{
+			name: "epoch seconds in a double encoded claim",
+			vc: base64.RawURLEncoding.EncodeToString([]byte(`{"@context":["https://www.w3.org/2018/credentials/v1"],` +
+				`"type":["VerifiableCredential"],"issuanceDate":1609529004,"expirationDate":2556213804}`)),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			_, _, cred, err := ParseVerifiableCredentialFromJWT(signWithVCClaim(tt, test.vc))
+			require.NoError(tt, err)
+			assert.Equal(tt, "2021-01-01T19:23:24Z", cred.IssuanceDate)
+			assert.Equal(tt, "2051-01-01T19:23:24Z", cred.ExpirationDate)
+		})
+	}
+
+	t.Run("the iat and exp claims take precedence", func(tt *testing.T) {
+		token := jwt.New()
+		require.NoError(tt, token.Set(jwt.IssuerKey, signer.ID))
+		require.NoError(tt, token.Set(jwt.IssuedAtKey, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)))
+		require.NoError(tt, token.Set(VCJWTProperty, map[string]any{"type": []any{"VerifiableCredential"}, "issuanceDate": 1609529004}))
+		signed, err := jwt.Sign(token, jwt.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey))
+		require.NoError(tt, err)
+		_, _, cred, err := ParseVerifiableCredentialFromJWT(string(signed))
+		require.NoError(tt, err)
+		assert.Equal(tt, "2022-01-01T00:00:00Z", cred.IssuanceDate)
+	})
+}
+
+func TestParseVerifiableCredentialFromJWTDoubleEncoded(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	vcJSON := `{"@context":["https://www.w3.org/2018/credentials/v1"],"type":["VerifiableCredential"],` +
+		`"credentialSubject":{"name":"JimBobertson"}}`
+
+	signWithVCClaim := func(tt *testing.T, vc any) string {
+		token, err := signer.SignWithDefaults(map[string]any{
+			"iss":         "did:example:123",
+			"sub":         "did:example:456",
+			VCJWTProperty: vc,
+		})
+		require.NoError(tt, err)
+		return string(token)
+	}
+
+	tests := []struct {
+		name string
+		vc   string
+	}{
+		{name: "base64url", vc: base64.RawURLEncoding.EncodeToString([]byte(vcJSON))},
+		{name: "padded base64url", vc: base64.URLEncoding.EncodeToString([]byte(vcJSON + " "))},
+		{name: "JSON text", vc: vcJSON},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			_, _, cred, err := ParseVerifiableCredentialFromJWT(signWithVCClaim(tt, test.vc))
+			assert.NoError(tt, err)
+			assert.Equal(tt, "did:example:123", cred.Issuer)
+			assert.Equal(tt, "JimBobertson", cred.CredentialSubject["name"])
+			assert.Equal(tt, "did:example:456", cred.CredentialSubject.GetID())
+		})
+	}
+
+	t.Run("signature is verified over the double encoded claim", func(tt *testing.T) {
+		verifier, err := signer.ToVerifier(signer.ID)
+		require.NoError(tt, err)
+		token := signWithVCClaim(tt, base64.RawURLEncoding.EncodeToString([]byte(vcJSON)))
+		_, _, cred, err := VerifyVerifiableCredentialJWT(*verifier, token)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "JimBobertson", cred.CredentialSubject["name"])
+	})
+
+	t.Run("malformed vc claim", func(tt *testing.T) {
+		_, _, _, err := ParseVerifiableCredentialFromJWT(signWithVCClaim(tt, "not base64url!"))
+		assert.ErrorIs(tt, err, ErrMalformedClaim)
+
+		notAnObject, err := json.Marshal([]string{"VerifiableCredential"})
+		require.NoError(tt, err)
+		_, _, _, err = ParseVerifiableCredentialFromJWT(signWithVCClaim(tt, base64.RawURLEncoding.EncodeToString(notAnObject)))
+		assert.ErrorIs(tt, err, ErrMalformedClaim)
+		assert.ErrorContains(tt, err, "decoded value is not a JSON object")
+	})
+
+	t.Run("vc claim missing", func(tt *testing.T) {
+		token, err := signer.SignWithDefaults(map[string]any{"iss": "did:example:123"})
+		require.NoError(tt, err)
+		_, _, _, err = ParseVerifiableCredentialFromJWT(string(token))
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+		assert.ErrorContains(tt, err, "did not find vc property in token")
+	})
+}
+
+func TestVerifyVerifiablePresentationJWTWithReplayProtection(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+
+	signPresentation := func(tt *testing.T, expiration int) string {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{
+			Audience:   []string{"did:example:verifier"},
+			Expiration: expiration,
+		}, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("rejects a replayed presentation", func(tt *testing.T) {
+		store := NewMemoryNonceStore()
+		signed := signPresentation(tt, 0)
+
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed, WithReplayProtection(store))
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed, WithReplayProtection(store))
+		assert.ErrorIs(tt, err, ErrNonceReplayed)
+
+		// a different presentation carries a new nonce
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, 0), WithReplayProtection(store))
+		assert.NoError(tt, err)
+
+		// presentations are not checked without the option
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("nonce is recorded until the presentation expires", func(tt *testing.T) {
+		store := NewMemoryNonceStore()
+		expiration := time.Now().Add(time.Hour)
+		signed := signPresentation(tt, int(expiration.Unix()))
+
+		_, token, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed, WithReplayProtection(store))
+		require.NoError(tt, err)
+		nonce, ok := token.Get(NonceProperty)
+		require.True(tt, ok)
+		assert.True(tt, store.Seen(nonce.(string)))
+		assert.Equal(tt, expiration.Unix(), store.nonces[nonce.(string)].Unix())
+	})
+
+	t.Run("nonce is not recorded for a presentation that fails verification", func(tt *testing.T) {
+		store := NewMemoryNonceStore()
+		otherVerifier, err := signer.ToVerifier("did:example:other")
+		require.NoError(tt, err)
+		signed := signPresentation(tt, 0)
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *otherVerifier, resolver, signed, WithReplayProtection(store))
+		assert.ErrorContains(tt, err, "audience mismatch")
+		assert.Zero(tt, store.Size())
+	})
+
+	t.Run("missing nonce store", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, 0), WithReplayProtection(nil))
+		assert.ErrorContains(tt, err, "replay protection requires a nonce store")
+	})
+}
+
+func TestVerifyVerifiablePresentationJWTWithChallenges(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+
+	answer := func(tt *testing.T, challenge *Challenge) string {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Challenge: challenge}, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("accepts a presentation answering a challenge once", func(tt *testing.T) {
+		store := NewMemoryChallengeStore(0)
+		challenge, err := store.CreateChallenge("did:example:verifier")
+		require.NoError(tt, err)
+		signed := answer(tt, challenge)
+
+		_, token, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed, WithChallenges(store))
+		require.NoError(tt, err)
+		nonce, _ := token.Get(NonceProperty)
+		assert.Equal(tt, challenge.Nonce, nonce)
+		assert.Equal(tt, []string{"did:example:verifier"}, token.Audience())
+		assert.Zero(tt, store.Size())
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed, WithChallenges(store))
+		assert.ErrorIs(tt, err, ErrUnknownChallenge)
+	})
+
+	t.Run("rejects a nonce that is not a challenge", func(tt *testing.T) {
+		store := NewMemoryChallengeStore(0)
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, answer(tt, nil), WithChallenges(store))
+		assert.ErrorIs(tt, err, ErrUnknownChallenge)
+	})
+
+	t.Run("rejects an expired challenge", func(tt *testing.T) {
+		store := NewMemoryChallengeStore(time.Minute)
+		challenge, err := store.CreateChallenge("")
+		require.NoError(tt, err)
+		signed := answer(tt, challenge)
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed,
+			WithChallenges(store), WithClock(fixedClock(time.Now().Add(2*time.Minute))))
+		assert.ErrorIs(tt, err, ErrChallengeExpired)
+	})
+
+	t.Run("rejects a presentation for another audience", func(tt *testing.T) {
+		store := NewMemoryChallengeStore(0)
+		challenge, err := store.CreateChallenge("did:example:verifier")
+		require.NoError(tt, err)
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{
+			Audience:  []string{"did:example:verifier"},
+			Challenge: &Challenge{Nonce: challenge.Nonce, Audience: "did:example:verifier"},
+		}, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		require.NoError(tt, err)
+		// the challenge's audience is not repeated in the audience of the JWT
+		_, token, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed), WithChallenges(store))
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"did:example:verifier"}, token.Audience())
+
+		challenge, err = store.CreateChallenge("did:example:other")
+		require.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, answer(tt, &Challenge{Nonce: challenge.Nonce}), WithChallenges(store))
+		assert.ErrorIs(tt, err, ErrAudienceMismatch)
+	})
+
+	t.Run("invalid challenges", func(tt *testing.T) {
+		_, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Challenge: &Challenge{}}, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		assert.ErrorIs(tt, err, ErrInvalidParameters)
+		assert.ErrorContains(tt, err, "challenge nonce cannot be empty")
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, answer(tt, nil), WithChallenges(nil))
+		assert.ErrorContains(tt, err, "challenge verification requires a challenge store")
+	})
+}
+
+func TestVerifyWithHeaderJWK(t *testing.T) {
+	privKey, didJWK, err := didjwk.GenerateDIDJWK(crypto.Ed25519)
+	require.NoError(t, err)
+	pubKey := privKey.(ed25519.PrivateKey).Public()
+
+	signWithHeaderJWK := func(tt *testing.T, issuer string, headerKey gocrypto.PublicKey) string {
+		token, err := JWTClaimSetFromVC(credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            issuer,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		headerJWK, err := jwk.FromRaw(headerKey)
+		require.NoError(tt, err)
+		headers := jws.NewHeaders()
+		require.NoError(tt, headers.Set(jws.JWKKey, headerJWK))
+		require.NoError(tt, headers.Set(jws.TypeKey, VCJWTType))
+		signed, err := jwt.Sign(token, jwt.WithKey(jwa.EdDSA, privKey, jws.WithProtectedHeaders(headers)))
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("did:jwk issuer", func(tt *testing.T) {
+		_, _, cred, err := VerifyWithHeaderJWK(signWithHeaderJWK(tt, didJWK.String(), pubKey), true)
+		assert.NoError(tt, err)
+		assert.Equal(tt, didJWK.String(), cred.Issuer)
+	})
+
+	t.Run("self-issued", func(tt *testing.T) {
+		_, _, cred, err := VerifyWithHeaderJWK(signWithHeaderJWK(tt, "https://self-issued.me/v2", pubKey), true)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://self-issued.me/v2", cred.Issuer)
+	})
+
+	t.Run("header key must be opted into", func(tt *testing.T) {
+		_, _, _, err := VerifyWithHeaderJWK(signWithHeaderJWK(tt, didJWK.String(), pubKey), false)
+		assert.ErrorContains(tt, err, "requires allowHeaderKey")
+	})
+
+	t.Run("header key that did not sign the token", func(tt *testing.T) {
+		otherPubKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		_, _, _, err = VerifyWithHeaderJWK(signWithHeaderJWK(tt, "https://self-issued.me/v2", otherPubKey), true)
+		assert.ErrorContains(tt, err, "could not verify message")
+	})
+
+	t.Run("header key that is not the did:jwk key", func(tt *testing.T) {
+		_, otherDIDJWK, err := didjwk.GenerateDIDJWK(crypto.Ed25519)
+		require.NoError(tt, err)
+		_, _, _, err = VerifyWithHeaderJWK(signWithHeaderJWK(tt, otherDIDJWK.String(), pubKey), true)
+		assert.ErrorContains(tt, err, "jwk header is not the key of issuer")
+	})
+
+	t.Run("no jwk header", func(tt *testing.T) {
+		signer := getTestVectorKey0Signer(t)
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		_, _, _, err = VerifyWithHeaderJWK(string(signed), true)
+		assert.ErrorContains(tt, err, "token has no jwk header")
+	})
+}
+
+func TestVerifyCredentialWithJWK(t *testing.T) {
+	pubKey, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	kid := "did:example:123#key-1"
+	signer, err := jwx.NewJWXSigner("did:example:123", &kid, privKey)
+	require.NoError(t, err)
+	signed, err := SignVerifiableCredentialJWT(*signer, credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            "did:example:123",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": "did:example:456"},
+	})
+	require.NoError(t, err)
+	token := string(signed)
+
+	issuerJWK := func(tt *testing.T, key gocrypto.PublicKey, keyID string, alg jwa.SignatureAlgorithm) jwk.Key {
+		k, err := jwk.FromRaw(key)
+		require.NoError(tt, err)
+		if keyID != "" {
+			require.NoError(tt, k.Set(jwk.KeyIDKey, keyID))
+		}
+		if alg != "" {
+			require.NoError(tt, k.Set(jwk.AlgorithmKey, alg))
+		}
+		return k
+	}
+
+	t.Run("verifies with the issuer key", func(tt *testing.T) {
+		for _, keyID := range []string{"", "key-1", kid} {
+			_, _, cred, err := VerifyCredentialWithJWK(token, issuerJWK(tt, pubKey, keyID, jwa.EdDSA))
+			assert.NoError(tt, err, keyID)
+			assert.Equal(tt, "did:example:123", cred.Issuer)
+		}
+	})
+
+	t.Run("key that did not sign the token", func(tt *testing.T) {
+		otherPubKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		_, _, _, err = VerifyCredentialWithJWK(token, issuerJWK(tt, otherPubKey, "", ""))
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+	})
+
+	t.Run("kid of another key", func(tt *testing.T) {
+		_, _, _, err := VerifyCredentialWithJWK(token, issuerJWK(tt, pubKey, "key-2", ""))
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+		assert.ErrorContains(tt, err, "does not name the issuer key<key-2>")
+	})
+
+	t.Run("alg of another key type", func(tt *testing.T) {
+		_, _, _, err := VerifyCredentialWithJWK(token, issuerJWK(tt, pubKey, "", jwa.ES256))
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+		assert.ErrorContains(tt, err, "is not the alg<ES256> of the issuer key")
+
+		ecPubKey, _, err := crypto.GenerateP256Key()
+		require.NoError(tt, err)
+		_, _, _, err = VerifyCredentialWithJWK(token, issuerJWK(tt, &ecPubKey, "", ""))
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+		assert.ErrorContains(tt, err, "cannot be verified with the issuer key")
+	})
+
+	t.Run("no key", func(tt *testing.T) {
+		_, _, _, err := VerifyCredentialWithJWK(token, nil)
+		assert.ErrorContains(tt, err, "issuer jwk cannot be empty")
+	})
+}
+
+func TestRenewCredentialJWT(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	signCredential := func(tt *testing.T, signer jwx.Signer, expiration time.Time) string {
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                "old-id",
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            didKey.String(),
+			IssuanceDate:      expiration.Add(-24 * time.Hour).Format(time.RFC3339),
+			ExpirationDate:    expiration.Format(time.RFC3339),
+			CredentialSubject: map[string]any{"id": "did:example:456", "name": "JimBobertson"},
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	newExpiration := time.Now().Add(365 * 24 * time.Hour).Truncate(time.Second)
+
+	t.Run("renews a credential", func(tt *testing.T) {
+		renewed, err := RenewCredentialJWT(*signer, signCredential(tt, *signer, time.Now().Add(time.Hour)), newExpiration)
+		require.NoError(tt, err)
+
+		verifier, err := signer.ToVerifier(signer.ID)
+		require.NoError(tt, err)
+		_, token, cred, err := VerifyVerifiableCredentialJWT(*verifier, string(renewed))
+		assert.NoError(tt, err)
+		assert.NotEqual(tt, "old-id", cred.ID)
+		assert.NotEmpty(tt, token.JwtID())
+		assert.Equal(tt, newExpiration.Unix(), token.Expiration().Unix())
+		assert.WithinDuration(tt, time.Now(), token.IssuedAt(), time.Minute)
+		assert.WithinDuration(tt, time.Now(), token.NotBefore(), time.Minute)
+		assert.Equal(tt, "did:example:456", cred.CredentialSubject.GetID())
+		assert.Equal(tt, "JimBobertson", cred.CredentialSubject["name"])
+		assert.Nil(tt, cred.Proof)
+	})
+
+	t.Run("renews a credential verified with a resolver", func(tt *testing.T) {
+		renewed, err := RenewCredentialJWTWithResolver(context.Background(), *signer, resolver,
+			signCredential(tt, *signer, time.Now().Add(time.Hour)), newExpiration)
+		require.NoError(tt, err)
+		ok, err := VerifyJWTCredential(context.Background(), string(renewed), resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, ok)
+	})
+
+	t.Run("forged credential", func(tt *testing.T) {
+		otherPrivKey, _, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		forger, err := jwx.NewJWXSigner(didKey.String(), &kid, otherPrivKey)
+		require.NoError(tt, err)
+		forged := signCredential(tt, *forger, time.Now().Add(time.Hour))
+
+		_, err = RenewCredentialJWT(*signer, forged, newExpiration)
+		assert.ErrorContains(tt, err, "verifying credential to renew")
+		_, err = RenewCredentialJWTWithResolver(context.Background(), *signer, resolver, forged, newExpiration)
+		assert.ErrorContains(tt, err, "verifying credential to renew")
+	})
+
+	t.Run("expired credential", func(tt *testing.T) {
+		expired := signCredential(tt, *signer, time.Now().Add(-time.Hour))
+		_, err := RenewCredentialJWT(*signer, expired, newExpiration)
+		assert.ErrorContains(tt, err, "verifying credential to renew")
+		_, err = RenewCredentialJWTWithResolver(context.Background(), *signer, resolver, expired, newExpiration)
+		assert.ErrorContains(tt, err, "verifying credential to renew")
+	})
+
+	t.Run("signer is not the issuer", func(tt *testing.T) {
+		otherSigner, err := jwx.NewJWXSigner("did:example:other", &kid, privKey)
+		require.NoError(tt, err)
+		_, err = RenewCredentialJWT(*otherSigner, signCredential(tt, *signer, time.Now().Add(time.Hour)), newExpiration)
+		assert.ErrorContains(tt, err, "does not match signer<did:example:other>")
+	})
+
+	t.Run("new expiration in the past", func(tt *testing.T) {
+		_, err := RenewCredentialJWT(*signer, signCredential(tt, *signer, time.Now().Add(time.Hour)), time.Now().Add(-time.Minute))
+		assert.ErrorContains(tt, err, "must be in the future")
+	})
+}
+
+func TestVerifiableCredentialJWTHighAssuranceCurves(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	tests := []struct {
+		kt  crypto.KeyType
+		alg string
+	}{
+		{kt: crypto.P384, alg: "ES384"},
+		{kt: crypto.P521, alg: "ES512"},
+	}
+	for _, test := range tests {
+		t.Run(test.kt.String(), func(tt *testing.T) {
+			privKey, didKey, err := key.GenerateDIDKey(test.kt)
+			require.NoError(tt, err)
+			expanded, err := didKey.Expand()
+			require.NoError(tt, err)
+			kid := expanded.VerificationMethod[0].ID
+			signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+			require.NoError(tt, err)
+
+			signed, err := SignVerifiableCredentialJWT(*signer, credential.VerifiableCredential{
+				Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+				ID:                "http://example.edu/credentials/1872",
+				Type:              []string{"VerifiableCredential"},
+				Issuer:            didKey.String(),
+				IssuanceDate:      "2021-01-01T19:23:24Z",
+				CredentialSubject: map[string]any{"id": "did:example:456", "name": "JimBobertson"},
+			})
+			require.NoError(tt, err)
+
+			headers, _, _, err := ParseVerifiableCredentialFromJWT(string(signed))
+			require.NoError(tt, err)
+			assert.Equal(tt, test.alg, headers.Algorithm().String())
+
+			ok, err := VerifyJWTCredential(context.Background(), string(signed), resolver)
+			assert.NoError(tt, err)
+			assert.True(tt, ok)
+		})
+	}
+}
+
+func TestVerifyVerifiablePresentationJWTCredentialConcurrency(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	issuer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	signCredential := func(tt *testing.T, issuerID string) string {
+		signedVC, err := SignVerifiableCredentialJWT(*issuer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            issuerID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		return string(signedVC)
+	}
+	signPresentation := func(tt *testing.T, creds []any) string {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{"did:example:verifier"}}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+
+	creds := make([]any, 20)
+	for i := range creds {
+		creds[i] = signCredential(t, didKey.String())
+	}
+
+	t.Run("verifies all credentials", func(tt *testing.T) {
+		_, _, pres, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, creds), WithCredentialConcurrency(4))
+		assert.NoError(tt, err)
+		assert.Len(tt, pres.VerifiableCredential, 20)
+	})
+
+	t.Run("reports the first credential that failed", func(tt *testing.T) {
+		invalid := make([]any, len(creds))
+		copy(invalid, creds)
+		// the issuer does not match the key that signed the credential
+		_, otherDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		invalid[5] = signCredential(tt, otherDIDKey.String())
+		invalid[15] = signCredential(tt, otherDIDKey.String())
+		signed := signPresentation(tt, invalid)
+
+		for i := 0; i < 10; i++ {
+			_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed)
+			assert.Error(tt, err)
+			assert.Contains(tt, err.Error(), "verifying credential 5")
+		}
+	})
+
+	t.Run("aborts when the context is done", func(tt *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, _, err = VerifyVerifiablePresentationJWT(ctx, *verifier, resolver, signPresentation(tt, creds))
+		assert.ErrorIs(tt, err, context.Canceled)
+	})
+
+	t.Run("invalid concurrency", func(tt *testing.T) {
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, creds), WithCredentialConcurrency(0))
+		assert.ErrorContains(tt, err, "credential concurrency<0> must be a positive number")
+	})
+}
+
+func TestVerifyVerifiablePresentationJWTMixedCredentials(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	issuer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	cred := credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []any{"VerifiableCredential"},
+		Issuer:            didKey.String(),
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": signer.ID},
+	}
+	jwtCred, err := SignVerifiableCredentialJWT(*issuer, cred)
+	require.NoError(t, err)
+	ldCred := getTestDataIntegrityCredential(t, privKey, kid, cred)
+
+	signPresentation := func(tt *testing.T, creds ...any) string {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{"did:example:verifier"}}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+
+	t.Run("verifies JWT and Data Integrity credentials", func(tt *testing.T) {
+		_, _, pres, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, string(jwtCred), ldCred))
+		require.NoError(tt, err)
+		assert.Len(tt, pres.VerifiableCredential, 2)
+	})
+
+	t.Run("rejects a tampered Data Integrity credential", func(tt *testing.T) {
+		tampered := getTestDataIntegrityCredential(tt, privKey, kid, cred)
+		tampered.IssuanceDate = "2022-01-01T19:23:24Z"
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, string(jwtCred), tampered))
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+		assert.ErrorContains(tt, err, "verifying credential 1")
+	})
+}
+
+func TestVerifyVerifiablePresentationJWTProofPurpose(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	holder, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+	verifier, err := holder.ToVerifier(holder.ID)
+	require.NoError(t, err)
+
+	signPresentation := func(tt *testing.T, parameters *JWTVVPParameters) string {
+		signed, err := SignVerifiablePresentationJWT(*holder, parameters, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  holder.ID,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("defaults to authentication", func(tt *testing.T) {
+		_, parsed, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, nil),
+			WithProofPurpose(did.Authentication))
+		assert.NoError(tt, err)
+		purpose, ok := parsed.Get(ProofPurposeProperty)
+		assert.True(tt, ok)
+		assert.Equal(tt, "authentication", purpose)
+	})
+
+	t.Run("records the given proof purpose", func(tt *testing.T) {
+		signed := signPresentation(tt, &JWTVVPParameters{ProofPurpose: did.AssertionMethod})
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed,
+			WithProofPurpose(did.AssertionMethod))
+		assert.NoError(tt, err)
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed,
+			WithProofPurpose(did.Authentication))
+		assert.ErrorIs(tt, err, ErrProofPurposeMismatch)
+
+		// the purpose is not enforced unless required
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signed)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("malformed proof purpose", func(tt *testing.T) {
+		signed, err := holder.SignWithDefaults(map[string]any{
+			"iss":                holder.ID,
+			ProofPurposeProperty: "keyAgreement",
+			VPJWTProperty: map[string]any{
+				"@context": []string{"https://www.w3.org/2018/credentials/v1"},
+				"type":     []string{"VerifiablePresentation"},
+			},
+		})
+		require.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed))
+		assert.ErrorIs(tt, err, ErrMalformedClaim)
+	})
+
+	t.Run("invalid required proof purpose", func(tt *testing.T) {
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, nil),
+			WithProofPurpose(did.KeyAgreement))
+		assert.ErrorContains(tt, err, "proof purpose<keyAgreement> is not a signing purpose")
+	})
+}
+
+func TestVerifyKIDMatchesIssuer(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	alice := getTestDIDKeySigner(t)
+	mallory := getTestDIDKeySigner(t)
+
+	signCredential := func(tt *testing.T, signer jwx.Signer, issuer string) string {
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            issuer,
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	signPresentation := func(tt *testing.T, signer jwx.Signer) string {
+		signed, err := SignVerifiablePresentationJWT(signer, nil, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("kid of another DID than the iss", func(tt *testing.T) {
+		_, err := VerifyJWTCredential(context.Background(), signCredential(tt, mallory, alice.ID), resolver)
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+		assert.ErrorContains(tt, err, "kid<"+mallory.KID+"> is not a key of issuer<"+alice.ID+">")
+
+		// mallory claims to be alice, while the kid names mallory's own key
+		impersonator := mallory
+		impersonator.ID = alice.ID
+		verifier, err := mallory.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, impersonator))
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+		assert.ErrorContains(tt, err, "kid<"+mallory.KID+"> is not a key of holder<"+alice.ID+">")
+	})
+
+	t.Run("legacy tokens signed with a key of the controller", func(tt *testing.T) {
+		const issuer, controller = "did:example:alice", "did:example:controller"
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		method, err := did.ConstructJWKVerificationMethod(controller+"#key-1", controller, pubKey, crypto.Ed25519)
+		require.NoError(tt, err)
+		legacyResolver := recordResolver{record: TrustRecord{DID: issuer, Document: did.Document{
+			ID:                 issuer,
+			VerificationMethod: []did.VerificationMethod{*method},
+		}}}
+		kid := method.ID
+		signer, err := jwx.NewJWXSigner(issuer, &kid, privKey)
+		require.NoError(tt, err)
+
+		cred := signCredential(tt, *signer, issuer)
+		_, err = VerifyJWTCredential(context.Background(), cred, legacyResolver)
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+		verified, err := VerifyJWTCredential(context.Background(), cred, legacyResolver, AllowControllerKID)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		verifier, err := signer.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+		presentation := signPresentation(tt, *signer)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, legacyResolver, presentation)
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, legacyResolver, presentation, AllowControllerKID)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("relative kid", func(tt *testing.T) {
+		assert.NoError(tt, checkKIDIssuer("#key-1", alice.ID, "issuer"))
+		assert.NoError(tt, checkKIDIssuer("key-1", alice.ID, "issuer"))
+		assert.NoError(tt, checkKIDIssuer(alice.KID, alice.ID, "issuer"))
+	})
+}
+
+func TestLenientHolderParsing(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	alice := getTestDIDKeySigner(t)
+	mallory := getTestDIDKeySigner(t)
+	verifier, err := alice.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+
+	// signPresentation signs a presentation naming its holder only in the vp claim, with the key of the signer
+	signPresentation := func(tt *testing.T, signer jwx.Signer, holder string) string {
+		claims := jwt.New()
+		require.NoError(tt, claims.Set(jwt.IssuedAtKey, time.Now().Unix()))
+		require.NoError(tt, claims.Set(VPJWTProperty, map[string]any{
+			"@context": []string{"https://www.w3.org/2018/credentials/v1"},
+			"type":     []string{"VerifiablePresentation"},
+			"holder":   holder,
+		}))
+		headers := jws.NewHeaders()
+		require.NoError(tt, headers.Set(jws.KeyIDKey, signer.KID))
+		require.NoError(tt, headers.Set(jws.TypeKey, VPJWTType))
+		signed, err := jwt.Sign(claims, jwt.WithKey(jwx.SignerAlgorithm(signer), signer.PrivateKey, jws.WithProtectedHeaders(headers)))
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("holder in the vp claim", func(tt *testing.T) {
+		presentation := signPresentation(tt, alice, alice.ID)
+		_, _, _, err := ParseVerifiablePresentationFromJWT(presentation)
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, presentation)
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+
+		_, token, pres, err := ParseVerifiablePresentationFromJWT(presentation, WithLenientHolderParsing())
+		assert.NoError(tt, err)
+		assert.Equal(tt, alice.ID, pres.Holder)
+		assert.Equal(tt, alice.ID, token.Issuer())
+		_, _, pres, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, presentation, WithLenientHolderParsing())
+		assert.NoError(tt, err)
+		assert.Equal(tt, alice.ID, pres.Holder)
+	})
+
+	t.Run("holder other than the signer", func(tt *testing.T) {
+		// mallory signs with a key of mallory, naming alice as the holder
+		malloryVerifier, err := mallory.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *malloryVerifier, resolver,
+			signPresentation(tt, mallory, alice.ID), WithLenientHolderParsing())
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+
+		// naming the kid of alice does not make the key of mallory one of the holder
+		impersonator := mallory
+		impersonator.KID = alice.KID
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *malloryVerifier, resolver,
+			signPresentation(tt, impersonator, alice.ID), WithLenientHolderParsing())
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+	})
+
+	t.Run("no holder", func(tt *testing.T) {
+		_, _, _, err := ParseVerifiablePresentationFromJWT(signPresentation(tt, alice, ""), WithLenientHolderParsing())
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+	})
+}
+
+func TestVerifyVerifiablePresentationJWTHolderKey(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	holder := getTestDIDKeySigner(t)
+	attacker := getTestDIDKeySigner(t)
+
+	signPresentation := func(tt *testing.T, signer jwx.Signer) string {
+		signed, err := SignVerifiablePresentationJWT(signer, nil, credential.VerifiablePresentation{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  signer.ID,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("signed by the holder", func(tt *testing.T) {
+		verifier, err := holder.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+		_, _, pres, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, holder))
+		assert.NoError(tt, err)
+		assert.Equal(tt, holder.ID, pres.Holder)
+	})
+
+	t.Run("signed by another key claiming to be the holder", func(tt *testing.T) {
+		impersonator := attacker
+		impersonator.ID = holder.ID
+		verifier, err := attacker.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+
+		// the signature is valid under the verifier's key, which is not the holder's
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, impersonator))
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+		assert.ErrorContains(tt, err, "is not a key of holder<"+holder.ID+">")
+
+		// as is the case when the kid names a key of the holder
+		impersonator.KID = holder.KID
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, impersonator))
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+		assert.ErrorContains(tt, err, "was not signed by a key of holder<"+holder.ID+">")
+	})
+}
+
+func TestSignVerifiablePresentationJWTEmbeddedCredentials(t *testing.T) {
+	issuer := getTestDIDKeySigner(t)
+	holder := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	verifier, err := holder.ToVerifier(holder.ID)
+	require.NoError(t, err)
+	signPresentation := func(creds ...any) ([]byte, error) {
+		return SignVerifiablePresentationJWT(holder, nil, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               holder.ID,
+			VerifiableCredential: creds,
+		})
+	}
+
+	t.Run("credential JWTs are embedded verbatim", func(tt *testing.T) {
+		first, second, third := getTestJWTCredential(tt, issuer), getTestJWTCredential(tt, issuer), getTestJWTCredential(tt, issuer)
+		signed, err := signPresentation(first, []byte(second), &third)
+		require.NoError(tt, err)
+
+		_, _, vp, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(signed))
+		require.NoError(tt, err)
+		require.Equal(tt, []any{first, second, third}, vp.VerifiableCredential)
+		for i, cred := range vp.VerifiableCredential {
+			verified, err := VerifyJWTCredential(context.Background(), cred.(string), resolver)
+			assert.NoError(tt, err, "credential %d", i)
+			assert.True(tt, verified)
+		}
+	})
+
+	t.Run("credential objects must be signed", func(tt *testing.T) {
+		unsigned := credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            issuer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": holder.ID},
+		}
+		unsignedMap, err := util.ToJSONMap(unsigned)
+		require.NoError(tt, err)
+		unsignedPresentation := credential.VerifiablePresentation{Type: []string{"VerifiablePresentation"}}
+		for name, cred := range map[string]any{
+			"credential":              unsigned,
+			"credential pointer":      &unsigned,
+			"credential map":          unsignedMap,
+			"presentation":            unsignedPresentation,
+			"presentation pointer":    &unsignedPresentation,
+			"credential after others": []any{getTestJWTCredential(tt, issuer), unsigned},
+		} {
+			creds, ok := cred.([]any)
+			if !ok {
+				creds = []any{cred}
+			}
+			_, err := signPresentation(creds...)
+			assert.ErrorIs(tt, err, ErrUnsignedCredential, name)
+			assert.ErrorContains(tt, err, fmt.Sprintf("credential %d is an object without a proof", len(creds)-1), name)
+		}
+
+		_, err = signPresentation(nil)
+		assert.ErrorIs(tt, err, ErrEmptyCredential)
+		var missing *string
+		_, err = signPresentation(missing)
+		assert.ErrorIs(tt, err, ErrEmptyCredential)
+	})
+
+	t.Run("signed credential objects are embedded", func(tt *testing.T) {
+		proof := crypto.Proof(map[string]any{"type": "JsonWebSignature2020", "jws": "eyJhbGciOiJFZERTQSJ9..c2ln"})
+		signedCred := credential.VerifiableCredential{Type: []string{"VerifiableCredential"}, Issuer: issuer.ID, Proof: &proof}
+		signed, err := signPresentation(signedCred, map[string]any{"type": "VerifiableCredential", "proof": proof})
+		require.NoError(tt, err)
+		_, _, vp, err := ParseVerifiablePresentationFromJWT(string(signed))
+		require.NoError(tt, err)
+		require.Len(tt, vp.VerifiableCredential, 2)
+		for _, cred := range vp.VerifiableCredential {
+			assert.Equal(tt, proof, cred.(map[string]any)["proof"])
+		}
+	})
+}
+
+func TestStrictModeBaseContext(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+	signCredential := func(tt *testing.T, contexts ...any) string {
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           contexts,
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	signPresentation := func(tt *testing.T, contexts []string, creds ...any) string {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{verifier.ID}}, credential.VerifiablePresentation{
+			Context:              contexts,
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	valid := signCredential(t, credential.VerifiableCredentialsLinkedDataContext, "https://w3id.org/security/suites/jws-2020/v1")
+	outOfOrder := signCredential(t, "https://w3id.org/security/suites/jws-2020/v1", credential.VerifiableCredentialsLinkedDataContext)
+
+	t.Run("credentials", func(tt *testing.T) {
+		verified, err := VerifyJWTCredential(context.Background(), valid, resolver, WithStrictMode())
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		// credentials whose base context is not first are only rejected in strict mode
+		verified, err = VerifyJWTCredential(context.Background(), outOfOrder, resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+		_, err = VerifyJWTCredential(context.Background(), outOfOrder, resolver, WithStrictMode())
+		assert.ErrorIs(tt, err, credential.ErrInvalidContext)
+		_, err = VerifyJWTCredential(context.Background(), signCredential(tt, "https://example.com/context"), resolver, WithStrictMode())
+		assert.ErrorIs(tt, err, credential.ErrInvalidContext)
+
+		// data integrity credentials are checked before their proof
+		cred := getTestCredential()
+		cred.Context = []any{"https://w3id.org/security/suites/jws-2020/v1", credential.VerifiableCredentialsLinkedDataContext}
+		proof := crypto.Proof(map[string]any{"type": "JsonWebSignature2020"})
+		cred.Proof = &proof
+		_, err = VerifyDataIntegrityCredential(context.Background(), cred, resolver, WithStrictMode())
+		assert.ErrorIs(tt, err, credential.ErrInvalidContext)
+	})
+
+	t.Run("presentations", func(tt *testing.T) {
+		v1 := []string{credential.VerifiableCredentialsLinkedDataContext}
+		_, _, _, err := VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(tt, v1, valid), WithStrictMode())
+		assert.NoError(tt, err)
+
+		misordered := signPresentation(tt, []string{"https://identity.foundation/presentation-exchange/submission/v1", credential.VerifiableCredentialsLinkedDataContext}, valid)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, misordered)
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, misordered, WithStrictMode())
+		assert.ErrorIs(tt, err, credential.ErrInvalidContext)
+
+		// the credentials in presentations are checked as well
+		withOutOfOrder := signPresentation(tt, v1, valid, outOfOrder)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, withOutOfOrder)
+		assert.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, withOutOfOrder, WithStrictMode())
+		assert.ErrorIs(tt, err, credential.ErrInvalidContext)
+		assert.ErrorContains(tt, err, "verifying credential 1")
+	})
+}
@@ -0,0 +1,86 @@
+package integrity
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultMaxTokenSize is the default MaxTokenSize, of 1MB
+	DefaultMaxTokenSize = 1 << 20
+	// DefaultMaxJSONDepth is the default MaxJSONDepth
+	DefaultMaxJSONDepth = 64
+)
+
+// MaxTokenSize is the size in bytes of the largest token parsed, in either serialization, beyond which tokens are
+// rejected with ErrTokenTooLarge before they are decoded, so that a public endpoint cannot be made to exhaust its
+// memory. A value of zero or less disables the check. It is meant to be set once, before tokens are parsed.
+var MaxTokenSize = DefaultMaxTokenSize
+
+// MaxJSONDepth is how deeply the objects and arrays of a token's header and claims may be nested before the token is
+// rejected with ErrTokenTooLarge, as decoding deeply nested JSON is costly. A value of zero or less disables the check.
+// It is meant to be set once, before tokens are parsed.
+var MaxJSONDepth = DefaultMaxJSONDepth
+
+// checkTokenSize rejects a token larger than MaxTokenSize
+func checkTokenSize(token string) error {
+	if MaxTokenSize > 0 && len(token) > MaxTokenSize {
+		return errors.Wrapf(ErrTokenTooLarge, "token of %d bytes exceeds the maximum of %d", len(token), MaxTokenSize)
+	}
+	return nil
+}
+
+// checkTokenDepth rejects a token in compact serialization whose header or claims nest JSON deeper than MaxJSONDepth.
+// Parts that cannot be decoded are left for the parser to reject.
+func checkTokenDepth(token string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	for _, part := range parts[:2] {
+		decoded, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return nil
+		}
+		if err = checkJSONDepth(decoded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkJSONDepth rejects JSON nesting objects and arrays deeper than MaxJSONDepth, without decoding it
+func checkJSONDepth(data []byte) error {
+	if MaxJSONDepth <= 0 {
+		return nil
+	}
+	depth := 0
+	inString, escaped := false, false
+	for _, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > MaxJSONDepth {
+				return errors.Wrapf(ErrTokenTooLarge, "JSON is nested deeper than the maximum depth of %d", MaxJSONDepth)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
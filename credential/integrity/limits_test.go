@@ -0,0 +1,88 @@
+package integrity
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+func TestTokenLimits(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	signCredential := func(tt *testing.T, subject map[string]any) string {
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []any{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: subject,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	nested := func(depth int) map[string]any {
+		value := map[string]any{"name": "JimBobertson"}
+		for i := 0; i < depth; i++ {
+			value = map[string]any{"nested": value}
+		}
+		return value
+	}
+	setLimits := func(tt *testing.T, size, depth int) {
+		previousSize, previousDepth := MaxTokenSize, MaxJSONDepth
+		MaxTokenSize, MaxJSONDepth = size, depth
+		tt.Cleanup(func() { MaxTokenSize, MaxJSONDepth = previousSize, previousDepth })
+	}
+
+	t.Run("rejects tokens larger than the maximum size", func(tt *testing.T) {
+		token := signCredential(tt, map[string]any{"id": "did:example:456", "bio": strings.Repeat("a", 2048)})
+		setLimits(tt, 1024, DefaultMaxJSONDepth)
+		_, _, _, err := ParseVerifiableCredentialFromJWT(token)
+		assert.ErrorIs(tt, err, ErrTokenTooLarge)
+		_, err = DetectJWTType(token)
+		assert.ErrorIs(tt, err, ErrTokenTooLarge)
+		_, _, err = ParseVerifiableCredentialFromJWS(token)
+		assert.ErrorIs(tt, err, ErrTokenTooLarge)
+
+		setLimits(tt, 0, DefaultMaxJSONDepth)
+		_, _, _, err = ParseVerifiableCredentialFromJWT(token)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("rejects claims nested deeper than the maximum depth", func(tt *testing.T) {
+		token := signCredential(tt, nested(DefaultMaxJSONDepth))
+		_, _, _, err := ParseVerifiableCredentialFromJWT(token)
+		assert.ErrorIs(tt, err, ErrTokenTooLarge)
+
+		_, _, _, err = ParseVerifiableCredentialFromJWT(signCredential(tt, nested(10)))
+		assert.NoError(tt, err)
+
+		setLimits(tt, DefaultMaxTokenSize, 0)
+		_, _, _, err = ParseVerifiableCredentialFromJWT(token)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("rejects a double encoded credential nested too deeply", func(tt *testing.T) {
+		credBytes, err := json.Marshal(map[string]any{
+			"@context":          []any{"https://www.w3.org/2018/credentials/v1"},
+			"type":              []any{"VerifiableCredential"},
+			"issuer":            "did:example:123",
+			"issuanceDate":      "2021-01-01T19:23:24Z",
+			"credentialSubject": nested(DefaultMaxJSONDepth),
+		})
+		require.NoError(tt, err)
+		token, err := signer.SignWithDefaults(map[string]any{VCJWTProperty: base64.RawURLEncoding.EncodeToString(credBytes)})
+		require.NoError(tt, err)
+		_, _, _, err = ParseVerifiableCredentialFromJWT(string(token))
+		assert.ErrorIs(tt, err, ErrTokenTooLarge)
+	})
+
+	t.Run("brackets in strings are not nesting", func(tt *testing.T) {
+		assert.NoError(tt, checkJSONDepth([]byte(`{"a": "`+strings.Repeat("[{", 100)+`\"]"}`)))
+		assert.ErrorIs(tt, checkJSONDepth([]byte(strings.Repeat("[", DefaultMaxJSONDepth+1))), ErrTokenTooLarge)
+	})
+}
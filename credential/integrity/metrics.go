@@ -0,0 +1,27 @@
+package integrity
+
+import (
+	"context"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/metrics"
+)
+
+// The kinds of verification whose time is reported to the metrics of the context given to the verification, see
+// metrics.NewContext. The credentials of a presentation are each reported as well as the presentation.
+const (
+	// JWTCredentialVerification is reported by VerifyJWTCredential and the functions verifying credentials with it
+	JWTCredentialVerification = "jwt_credential"
+	// DataIntegrityCredentialVerification is reported by VerifyDataIntegrityCredential
+	DataIntegrityCredentialVerification = "data_integrity_credential"
+	// JWTPresentationVerification is reported by VerifyNestedVerifiablePresentationJWT, and so by
+	// VerifyVerifiablePresentationJWT and VerifyJWTPresentation
+	JWTPresentationVerification = "jwt_presentation"
+	// DataIntegrityPresentationVerification is reported by VerifyDataIntegrityPresentation
+	DataIntegrityPresentationVerification = "data_integrity_presentation"
+)
+
+// observeVerification reports the time since start taken by a verification of the kind, and whether it succeeded
+func observeVerification(ctx context.Context, kind string, start time.Time, ok bool) {
+	metrics.FromContext(ctx).ObserveVerification(kind, time.Since(start), ok)
+}
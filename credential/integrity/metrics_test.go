@@ -0,0 +1,97 @@
+package integrity
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/metrics"
+)
+
+type verification struct {
+	kind string
+	ok   bool
+}
+
+type testMetrics struct {
+	mu            sync.Mutex
+	resolutions   []did.Method
+	verifications []verification
+}
+
+func (m *testMetrics) ObserveResolution(method did.Method, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resolutions = append(m.resolutions, method)
+}
+
+func (m *testMetrics) ObserveVerification(kind string, _ time.Duration, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verifications = append(m.verifications, verification{kind: kind, ok: ok})
+}
+
+func TestVerificationMetrics(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	issuer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+	jwtCred := getTestJWTCredential(t, *issuer)
+
+	t.Run("observes credential verifications and their resolutions", func(tt *testing.T) {
+		m := &testMetrics{}
+		ctx := metrics.NewContext(context.Background(), m)
+		verified, err := VerifyCredentialSignature(ctx, jwtCred, resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+
+		_, err = VerifyJWTCredential(ctx, jwtCred[:len(jwtCred)-4]+"AAAA", resolver)
+		assert.Error(tt, err)
+
+		assert.Equal(tt, []verification{{kind: JWTCredentialVerification, ok: true}, {kind: JWTCredentialVerification, ok: false}}, m.verifications)
+		assert.Equal(tt, []did.Method{did.KeyMethod, did.KeyMethod}, m.resolutions)
+	})
+
+	t.Run("observes a presentation and each of its credentials", func(tt *testing.T) {
+		signer := getTestDIDKeySigner(tt)
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{"did:example:verifier"}}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: []any{jwtCred, jwtCred},
+		})
+		require.NoError(tt, err)
+		verifier, err := signer.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+
+		m := &testMetrics{}
+		_, _, _, err = VerifyVerifiablePresentationJWT(metrics.NewContext(context.Background(), m), *verifier, resolver, string(signed))
+		require.NoError(tt, err)
+		assert.ElementsMatch(tt, []verification{
+			{kind: JWTCredentialVerification, ok: true},
+			{kind: JWTCredentialVerification, ok: true},
+			{kind: JWTPresentationVerification, ok: true},
+		}, m.verifications)
+	})
+
+	t.Run("verifies without metrics", func(tt *testing.T) {
+		verified, err := VerifyJWTCredential(context.Background(), jwtCred, resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+}
@@ -0,0 +1,129 @@
+package integrity
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+)
+
+// DefaultNonceTTL is how long the nonce of a presentation without an expiration is remembered for replay protection
+const DefaultNonceTTL = 24 * time.Hour
+
+// NonceStore remembers the nonces of verified presentations so that replayed presentations can be rejected. Stores
+// shared by concurrent verifications should also be an AtomicNonceStore, as a presentation replayed in parallel may
+// otherwise be seen by none of them.
+type NonceStore interface {
+	// Seen returns whether the nonce has been recorded and has not yet expired
+	Seen(nonce string) bool
+	// Record remembers the nonce until the given expiry
+	Record(nonce string, expiry time.Time)
+}
+
+// AtomicNonceStore is a NonceStore that checks and records a nonce at once, which replay protection uses in place of
+// Seen and Record when a store is one
+type AtomicNonceStore interface {
+	NonceStore
+	// RecordIfAbsent remembers the nonce until the given expiry unless it has been recorded and has not yet expired,
+	// returning whether it was recorded
+	RecordIfAbsent(nonce string, expiry time.Time) bool
+}
+
+// MemoryNonceStore is an in-memory NonceStore, which drops expired nonces as new ones are recorded
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	// now is replaceable for testing expiry
+	now func() time.Time
+}
+
+var _ AtomicNonceStore = (*MemoryNonceStore)(nil)
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+func (m *MemoryNonceStore) Seen(nonce string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.seen(nonce)
+}
+
+func (m *MemoryNonceStore) Record(nonce string, expiry time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record(nonce, expiry)
+}
+
+func (m *MemoryNonceStore) RecordIfAbsent(nonce string, expiry time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.seen(nonce) {
+		return false
+	}
+	m.record(nonce, expiry)
+	return true
+}
+
+// seen is Seen for callers holding the lock
+func (m *MemoryNonceStore) seen(nonce string) bool {
+	expiry, ok := m.nonces[nonce]
+	if !ok {
+		return false
+	}
+	if !m.now().Before(expiry) {
+		delete(m.nonces, nonce)
+		return false
+	}
+	return true
+}
+
+// record is Record for callers holding the lock
+func (m *MemoryNonceStore) record(nonce string, expiry time.Time) {
+	now := m.now()
+	for n, e := range m.nonces {
+		if !now.Before(e) {
+			delete(m.nonces, n)
+		}
+	}
+	m.nonces[nonce] = expiry
+}
+
+// Size returns the number of nonces held, including expired nonces that have not been dropped yet
+func (m *MemoryNonceStore) Size() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.nonces)
+}
+
+// recordNonce rejects a presentation token whose nonce has been seen in the store, otherwise recording it until the
+// token expires, at once if the store is an AtomicNonceStore
+func recordNonce(store NonceStore, token jwt.Token, now time.Time) error {
+	nonceClaim, ok := token.Get(NonceProperty)
+	if !ok {
+		return errors.Wrap(ErrMissingClaim, "presentation has no nonce to protect against replay")
+	}
+	nonce, ok := nonceClaim.(string)
+	if !ok || nonce == "" {
+		return errors.Wrapf(ErrMalformedClaim, "presentation nonce<%v> is not a valid string", nonceClaim)
+	}
+	expiry := token.Expiration()
+	if expiry.IsZero() {
+		expiry = now.Add(DefaultNonceTTL)
+	}
+	if atomicStore, ok := store.(AtomicNonceStore); ok {
+		if !atomicStore.RecordIfAbsent(nonce, expiry) {
+			return errors.Wrapf(ErrNonceReplayed, "presentation nonce<%s>", nonce)
+		}
+		return nil
+	}
+	if store.Seen(nonce) {
+		return errors.Wrapf(ErrNonceReplayed, "presentation nonce<%s>", nonce)
+	}
+	store.Record(nonce, expiry)
+	return nil
+}
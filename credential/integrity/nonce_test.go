@@ -0,0 +1,75 @@
+package integrity
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryNonceStore(t *testing.T) {
+	store := NewMemoryNonceStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	assert.False(t, store.Seen("nonce-1"))
+	store.Record("nonce-1", now.Add(time.Minute))
+	store.Record("nonce-2", now.Add(time.Hour))
+	assert.True(t, store.Seen("nonce-1"))
+	assert.True(t, store.Seen("nonce-2"))
+
+	// expired nonces are no longer seen, and are dropped when another nonce is recorded
+	now = now.Add(2 * time.Minute)
+	store.Record("nonce-3", now.Add(time.Minute))
+	assert.Equal(t, 2, store.Size())
+	assert.False(t, store.Seen("nonce-1"))
+	assert.True(t, store.Seen("nonce-2"))
+	assert.True(t, store.Seen("nonce-3"))
+}
+
+func TestMemoryNonceStoreRecordIfAbsent(t *testing.T) {
+	store := NewMemoryNonceStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	assert.True(t, store.RecordIfAbsent("nonce-1", now.Add(time.Minute)))
+	assert.False(t, store.RecordIfAbsent("nonce-1", now.Add(time.Hour)))
+	assert.Equal(t, now.Add(time.Minute), store.nonces["nonce-1"])
+
+	// an expired nonce may be recorded again
+	now = now.Add(2 * time.Minute)
+	assert.True(t, store.RecordIfAbsent("nonce-1", now.Add(time.Minute)))
+}
+
+func TestRecordNonceConcurrently(t *testing.T) {
+	store := NewMemoryNonceStore()
+	token := jwt.New()
+	require.NoError(t, token.Set(NonceProperty, "nonce-1"))
+
+	// of a presentation replayed in parallel, only one is accepted
+	const verifications = 50
+	var accepted, replayed atomic.Int32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < verifications; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			err := recordNonce(store, token, time.Now())
+			if err == nil {
+				accepted.Add(1)
+			} else if assert.ErrorIs(t, err, ErrNonceReplayed) {
+				replayed.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	assert.Equal(t, int32(1), accepted.Load())
+	assert.Equal(t, int32(verifications-1), replayed.Load())
+}
@@ -0,0 +1,74 @@
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/oliveagle/jsonpath"
+	"github.com/pkg/errors"
+)
+
+// didPattern matches a DID without a path, query, or fragment https://www.w3.org/TR/did-core/#did-syntax
+var didPattern = regexp.MustCompile(`^did:[a-z0-9]+:(?:[a-zA-Z0-9._-]|%[0-9A-Fa-f]{2}|:)*(?:[a-zA-Z0-9._-]|%[0-9A-Fa-f]{2})$`)
+
+// IsDID returns whether id is a DID without a path, query, or fragment, as opposed to another URI such as a urn, so
+// that only DIDs are resolved, e.g. a credential subject's id
+func IsDID(id string) bool {
+	return didPattern.MatchString(id)
+}
+
+// ResolveSubjectReferences resolves the DIDs referenced by a credential's subject, e.g. the employer of
+// {"id": "did:example:456", "employer": "did:web:acme.com"}, to build a trust graph from the credential. Each path is
+// a JSONPath evaluated against the credentialSubject, or against the array of subjects of a credential with many.
+// The returned map holds the document of each DID found, keyed by the path it was found at; where a path matches an
+// array, each DID in it is keyed by the path and its index, e.g. "$.employers[1]". Paths that match nothing and
+// values that are not DIDs are skipped, while a DID that cannot be resolved is an error. Signatures are not verified.
+func ResolveSubjectReferences(ctx context.Context, cred credential.VerifiableCredential, r resolution.Resolver, paths ...string) (map[string]did.Document, error) {
+	if r == nil {
+		return nil, errors.New("resolution cannot be empty")
+	}
+	var subject any = cred.CredentialSubject
+	if len(cred.CredentialSubjects) > 0 {
+		subject = cred.CredentialSubjects
+	}
+	// normalize the subject to the generic JSON values the JSONPath lookup walks
+	subjectBytes, err := JSONCodec.Marshal(subject)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential subject")
+	}
+	var subjectJSON any
+	if err = JSONCodec.Unmarshal(subjectBytes, &subjectJSON); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential subject")
+	}
+
+	references := make(map[string]did.Document)
+	for _, path := range paths {
+		value, err := jsonpath.JsonPathLookup(subjectJSON, path)
+		if err != nil {
+			continue
+		}
+		found := map[string]any{path: value}
+		if values, ok := value.([]any); ok {
+			found = make(map[string]any, len(values))
+			for i, v := range values {
+				found[fmt.Sprintf("%s[%d]", path, i)] = v
+			}
+		}
+		for key, v := range found {
+			id, ok := v.(string)
+			if !ok || !IsDID(id) {
+				continue
+			}
+			resolved, err := r.Resolve(ctx, id)
+			if err != nil {
+				return nil, errors.Wrapf(err, "resolving did<%s> referenced at %s", id, key)
+			}
+			references[key] = resolved.Document
+		}
+	}
+	return references, nil
+}
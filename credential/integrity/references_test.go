@@ -0,0 +1,67 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestResolveSubjectReferences(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	_, employer, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	_, school, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+
+	cred := credential.VerifiableCredential{
+		Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:         []string{"VerifiableCredential"},
+		Issuer:       "did:example:123",
+		IssuanceDate: "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{
+			"id":       "did:example:456",
+			"employer": employer.String(),
+			"schools":  []any{school.String(), "not a did"},
+			"website":  "https://acme.com",
+		},
+	}
+
+	t.Run("resolves the DIDs at each path", func(tt *testing.T) {
+		references, err := ResolveSubjectReferences(context.Background(), cred, resolver, "$.employer", "$.schools", "$.website", "$.missing")
+		assert.NoError(tt, err)
+		assert.Len(tt, references, 2)
+		assert.Equal(tt, employer.String(), references["$.employer"].ID)
+		assert.Equal(tt, school.String(), references["$.schools[0]"].ID)
+	})
+
+	t.Run("credential with many subjects", func(tt *testing.T) {
+		many := cred
+		many.CredentialSubject = nil
+		many.CredentialSubjects = []credential.CredentialSubject{
+			{"id": "did:example:456"},
+			{"id": "did:example:789", "employer": employer.String()},
+		}
+		references, err := ResolveSubjectReferences(context.Background(), many, resolver, "$[1].employer")
+		assert.NoError(tt, err)
+		assert.Equal(tt, employer.String(), references["$[1].employer"].ID)
+	})
+
+	t.Run("unresolvable DID", func(tt *testing.T) {
+		_, err := ResolveSubjectReferences(context.Background(), cred, resolver, "$.id")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "resolving did<did:example:456> referenced at $.id")
+	})
+
+	t.Run("no resolver", func(tt *testing.T) {
+		_, err := ResolveSubjectReferences(context.Background(), cred, nil, "$.employer")
+		assert.ErrorContains(tt, err, "resolution cannot be empty")
+	})
+}
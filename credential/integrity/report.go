@@ -0,0 +1,196 @@
+package integrity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+)
+
+// PresentationReport is the outcome of each check made by VerifyPresentationReport on a presentation JWT, where the
+// error of a check is nil if it passed
+type PresentationReport struct {
+	Headers      jws.Headers
+	Token        jwt.Token
+	Presentation *credential.VerifiablePresentation
+	// SignatureError is why the outer signature of the presentation, or its time-based claims, are not valid
+	SignatureError error
+	// AudienceError is why the presentation is not intended for the verifier
+	AudienceError error
+	// HolderError is why the key that signed the presentation is not a key of its holder
+	HolderError error
+	// Credentials holds the outcome for each entry of the presentation's verifiableCredential property, in order
+	Credentials []CredentialReport
+}
+
+// CredentialReport is the outcome of verifying an entry of a presentation's verifiableCredential property
+type CredentialReport struct {
+	Index int
+	// Credential is the entry, or the credential it references by hash once resolved
+	Credential any
+	Error      error
+}
+
+// Valid returns whether every check of the presentation and its credentials passed
+func (pr PresentationReport) Valid() bool {
+	if pr.SignatureError != nil || pr.AudienceError != nil || pr.HolderError != nil {
+		return false
+	}
+	for _, cred := range pr.Credentials {
+		if cred.Error != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the reports of the credentials that failed verification
+func (pr PresentationReport) Failed() []CredentialReport {
+	var failed []CredentialReport
+	for _, cred := range pr.Credentials {
+		if cred.Error != nil {
+			failed = append(failed, cred)
+		}
+	}
+	return failed
+}
+
+// VerifyPresentationReport verifies a presentation JWT with the same checks as VerifyVerifiablePresentationJWT, but
+// rather than stopping at the first that fails, it makes every check and reports the outcome of each: the outer
+// signature, the audience, the holder's key and every credential, which are all verified even if some fail. An
+// error is only returned if the token cannot be parsed as a presentation or the options are invalid, so that a
+// verifier can tell holders everything that is wrong with a presentation.
+// Presentations nested in the presentation are verified as VerifyNestedVerifiablePresentationJWT does, up to
+// DefaultMaxPresentationDepth levels, and reported as the credential entry they are. Supported options are
+// WithCredentialConcurrency, WithTrustRegistry, WithCredentialResolver, WithDetachedCredentials, WithClock,
+// WithClockSkew, AllowControllerKID, WithDeactivatedIssuerPolicy, WithHolderMatch, WithLenientHolderParsing and
+// WithRequireAudience; stateful options, such as replay protection, are not, as a report is not an acceptance of the presentation.
+func VerifyPresentationReport(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token string, opts ...VerifyOption) (*PresentationReport, error) {
+	if r == nil {
+		return nil, errors.New("r cannot be empty")
+	}
+	pv := presentationVerification{maxDepth: DefaultMaxPresentationDepth, seen: make(map[string]bool), concurrency: DefaultCredentialConcurrency}
+	for _, opt := range opts {
+		switch opt.Type {
+		case CredentialConcurrencyOption:
+			workers, ok := opt.Value.(int)
+			if !ok || workers < 1 {
+				return nil, fmt.Errorf("credential concurrency<%v> must be a positive number", opt.Value)
+			}
+			pv.concurrency = workers
+		case TrustRegistryOption:
+			registry, ok := opt.Value.(TrustRegistry)
+			if !ok || registry == nil {
+				return nil, errors.New("trust registry verification requires a registry")
+			}
+			pv.registry = registry
+		case CredentialResolverOption:
+			resolver, ok := opt.Value.(CredentialResolver)
+			if !ok || resolver == nil {
+				return nil, errors.New("credential resolution requires a credential resolver")
+			}
+			pv.credentials = resolver
+		case DetachedCredentialsOption:
+			detached, ok := opt.Value.(map[string]string)
+			if !ok {
+				return nil, errors.New("detached credentials must be a map of credential ids to tokens")
+			}
+			pv.detached = detached
+		case ClockOption, ClockSkewOption:
+			if _, err := pv.timing.apply(opt); err != nil {
+				return nil, err
+			}
+		case ControllerKIDOption:
+			pv.allowControllerKID = true
+		case DeactivatedIssuerPolicyOption:
+			pv.credentialPolicies = append(pv.credentialPolicies, opt)
+		case HolderMatchOption:
+			mode, err := holderMatchMode(opt)
+			if err != nil {
+				return nil, err
+			}
+			pv.holderMatch = mode
+		case LenientHolderParsingOption:
+			pv.lenientHolder = true
+		case RequireAudienceOption:
+			required, ok := opt.Value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("require audience<%v> must be a bool", opt.Value)
+			}
+			pv.requireAudience = required
+		default:
+			return nil, fmt.Errorf("unsupported verify option<%s> for a presentation report", opt.Type)
+		}
+	}
+
+	token, err := compactToken(token)
+	if err != nil {
+		return nil, err
+	}
+	headers, vpToken, vp, err := ParseVerifiablePresentationFromJWT(token, pv.presentationOptions()...)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing VP from JWT")
+	}
+	report := PresentationReport{Headers: headers, Token: vpToken, Presentation: vp}
+	if err = verifier.VerifyWithContext(ctx, token, pv.timing.parseOptions()...); err != nil {
+		report.SignatureError = errors.Wrap(verificationError(err), "verifying JWT and its signature")
+	}
+	if pv.requireAudience && len(vpToken.Audience()) == 0 {
+		report.AudienceError = errors.Wrap(ErrMissingClaim, "presentation has no aud claim")
+	} else {
+		report.AudienceError = checkAudience(vpToken, []string{verifier.ID, verifier.KID})
+	}
+	if !pv.allowControllerKID {
+		report.HolderError = checkKIDIssuer(headers.KeyID(), vpToken.Issuer(), "holder")
+	}
+	if report.HolderError == nil {
+		report.HolderError = checkHolderKey(ctx, r, verifier, headers, vpToken)
+	}
+
+	pv.seen[token] = true
+	errs := make([]error, len(vp.VerifiableCredential))
+	var credentials []int
+	for i, cred := range vp.VerifiableCredential {
+		if hash, ok := cred.(string); ok && IsCredentialHash(hash) {
+			token, err := resolveCredentialHash(hash, pv.credentials)
+			if err != nil {
+				errs[i] = errors.Wrapf(err, "resolving credential %d", i)
+				continue
+			}
+			vp.VerifiableCredential[i] = token
+			cred = token
+		}
+		if reference, ok := cred.(string); ok && IsDetachedCredentialReference(reference) {
+			token, err := resolveDetachedCredential(reference, pv.detached)
+			if err != nil {
+				errs[i] = errors.Wrapf(err, "resolving credential %d", i)
+				continue
+			}
+			vp.VerifiableCredential[i] = token
+			cred = token
+		}
+		if nestedToken, ok := cred.(string); ok {
+			if jwtType, err := DetectJWTType(nestedToken); err == nil && jwtType == PresentationJWTType {
+				if _, err = verifyNestedPresentationJWT(ctx, vp.Holder, r, nestedToken, 1, &pv); err != nil {
+					errs[i] = errors.Wrapf(err, "verifying nested presentation %d", i)
+				}
+				continue
+			}
+		}
+		credentials = append(credentials, i)
+	}
+	verifyCredentialSignatures(ctx, r, pv.registry, vp.VerifiableCredential, credentials, errs, pv.concurrency, false, pv.credentialOptions())
+	pv.checkHolders(*vp, credentials, errs)
+
+	report.Credentials = make([]CredentialReport, len(vp.VerifiableCredential))
+	for i, cred := range vp.VerifiableCredential {
+		report.Credentials[i] = CredentialReport{Index: i, Credential: cred, Error: errs[i]}
+	}
+	return &report, nil
+}
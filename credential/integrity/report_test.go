@@ -0,0 +1,123 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestVerifyPresentationReport(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	issuer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+	_, otherDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+
+	signCredential := func(tt *testing.T, issuerID string) string {
+		signedVC, err := SignVerifiableCredentialJWT(*issuer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            issuerID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		return string(signedVC)
+	}
+	signPresentation := func(tt *testing.T, creds ...any) string {
+		signed, err := SignVerifiablePresentationJWT(signer, &JWTVVPParameters{Audience: []string{"did:example:verifier"}}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	verifier, err := signer.ToVerifier("did:example:verifier")
+	require.NoError(t, err)
+
+	t.Run("every check passes", func(tt *testing.T) {
+		report, err := VerifyPresentationReport(context.Background(), *verifier, resolver,
+			signPresentation(tt, signCredential(tt, didKey.String()), signCredential(tt, didKey.String())))
+		require.NoError(tt, err)
+		assert.True(tt, report.Valid())
+		assert.Empty(tt, report.Failed())
+		assert.Equal(tt, signer.ID, report.Token.Issuer())
+		require.Len(tt, report.Credentials, 2)
+		assert.Equal(tt, 1, report.Credentials[1].Index)
+	})
+
+	t.Run("reports every credential that failed", func(tt *testing.T) {
+		// the issuer does not match the key that signed the credential
+		signed := signPresentation(tt, signCredential(tt, otherDIDKey.String()), signCredential(tt, didKey.String()),
+			signCredential(tt, otherDIDKey.String()))
+		report, err := VerifyPresentationReport(context.Background(), *verifier, resolver, signed, WithCredentialConcurrency(1))
+		require.NoError(tt, err)
+		assert.False(tt, report.Valid())
+		assert.NoError(tt, report.SignatureError)
+		assert.NoError(tt, report.AudienceError)
+		assert.NoError(tt, report.HolderError)
+
+		failed := report.Failed()
+		require.Len(tt, failed, 2)
+		assert.Equal(tt, 0, failed[0].Index)
+		assert.ErrorContains(tt, failed[0].Error, "verifying credential 0")
+		assert.Equal(tt, 2, failed[1].Index)
+		assert.ErrorContains(tt, failed[1].Error, "verifying credential 2")
+		assert.NoError(tt, report.Credentials[1].Error)
+	})
+
+	t.Run("reports an audience mismatch", func(tt *testing.T) {
+		otherVerifier, err := signer.ToVerifier("did:example:other-verifier")
+		require.NoError(tt, err)
+		report, err := VerifyPresentationReport(context.Background(), *otherVerifier, resolver, signPresentation(tt, signCredential(tt, didKey.String())))
+		require.NoError(tt, err)
+		assert.False(tt, report.Valid())
+		assert.ErrorIs(tt, report.AudienceError, ErrAudienceMismatch)
+		assert.NoError(tt, report.SignatureError)
+		assert.Empty(tt, report.Failed())
+	})
+
+	t.Run("reports an invalid outer signature", func(tt *testing.T) {
+		otherSigner := getTestDIDKeySigner(tt)
+		otherVerifier, err := otherSigner.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+		report, err := VerifyPresentationReport(context.Background(), *otherVerifier, resolver, signPresentation(tt, signCredential(tt, didKey.String())))
+		require.NoError(tt, err)
+		assert.False(tt, report.Valid())
+		assert.ErrorIs(tt, report.SignatureError, ErrSignatureInvalid)
+		assert.ErrorIs(tt, report.HolderError, ErrIssuerMismatch)
+		assert.NoError(tt, report.AudienceError)
+		assert.Empty(tt, report.Failed())
+	})
+
+	t.Run("fails on tokens that are not presentations", func(tt *testing.T) {
+		_, err := VerifyPresentationReport(context.Background(), *verifier, resolver, "not a token")
+		assert.Error(tt, err)
+
+		_, err = VerifyPresentationReport(context.Background(), *verifier, resolver, signCredential(tt, didKey.String()))
+		assert.ErrorContains(tt, err, "parsing VP from JWT")
+
+		_, err = VerifyPresentationReport(context.Background(), *verifier, nil, signPresentation(tt))
+		assert.ErrorContains(tt, err, "r cannot be empty")
+
+		_, err = VerifyPresentationReport(context.Background(), *verifier, resolver, signPresentation(tt), WithoutCredentialVerification)
+		assert.ErrorContains(tt, err, "unsupported verify option")
+	})
+}
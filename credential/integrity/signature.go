@@ -2,84 +2,124 @@ package integrity
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
 	"github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
 
 	"github.com/pkg/errors"
 )
 
-// VerifyCredentialSignature verifies the signature of a credential of any type
+// VerifyCredentialSignature verifies the signature of a credential of any type. JWT credentials are verified with
+// VerifyJWTCredential, while credential objects, or their JSON, carrying an embedded proof are verified with
+// VerifyDataIntegrityCredential. The WithClock and WithClockSkew options change the time the validity of credentials
+// is checked at. Credentials of an issuer whose DID resolves as deactivated are rejected with ErrDeactivatedDID,
+// unless accepted by the WithDeactivatedIssuerPolicy option.
 // TODO(gabe) support other types of credentials https://github.com/TBD54566975/ssi-sdk/issues/352
-func VerifyCredentialSignature(ctx context.Context, genericCred any, r resolution.Resolver) (bool, error) {
+func VerifyCredentialSignature(ctx context.Context, genericCred any, r resolution.Resolver, opts ...VerifyOption) (bool, error) {
 	if genericCred == nil {
-		return false, errors.New("credential cannot be empty")
+		return false, ErrEmptyCredential
 	}
 	if r == nil {
 		return false, errors.New("resolution cannot be empty")
 	}
 	switch typedCred := genericCred.(type) {
 	case map[string]any:
-		typedCredBytes, err := json.Marshal(typedCred)
+		typedCredBytes, err := JSONCodec.Marshal(typedCred)
 		if err != nil {
 			return false, errors.Wrap(err, "marshalling credential map")
 		}
 		var cred credential.VerifiableCredential
-		if err = json.Unmarshal(typedCredBytes, &cred); err != nil {
+		if err = JSONCodec.Unmarshal(typedCredBytes, &cred); err != nil {
 			return false, errors.Wrap(err, "unmarshalling credential object")
 		}
 		if cred.IsEmpty() {
 			return false, errors.New("map is not a valid credential")
 		}
-		return VerifyCredentialSignature(ctx, cred, r)
+		return VerifyCredentialSignature(ctx, cred, r, opts...)
 	case *credential.VerifiableCredential:
-		return VerifyDataIntegrityCredential(ctx, *typedCred, r)
+		return VerifyDataIntegrityCredential(ctx, *typedCred, r, opts...)
 	case credential.VerifiableCredential:
-		return VerifyDataIntegrityCredential(ctx, typedCred, r)
+		return VerifyDataIntegrityCredential(ctx, typedCred, r, opts...)
 	case []byte:
 		// turn it into a string and try again
-		return VerifyCredentialSignature(ctx, string(typedCred), r)
+		return VerifyCredentialSignature(ctx, string(typedCred), r, opts...)
 	case string:
 		// could be a Data Integrity credential
 		var cred credential.VerifiableCredential
-		if err := json.Unmarshal([]byte(typedCred), &cred); err == nil {
-			return VerifyCredentialSignature(ctx, cred, r)
+		if err := JSONCodec.Unmarshal([]byte(typedCred), &cred); err == nil && !cred.IsEmpty() {
+			return VerifyCredentialSignature(ctx, cred, r, opts...)
 		}
 
-		// could be a JWT
-		return VerifyJWTCredential(ctx, typedCred, r)
+		// could be a JWT, in compact or JSON serialization
+		return VerifyJWTCredential(ctx, typedCred, r, opts...)
 	}
 	return false, fmt.Errorf("invalid credential type: %s", reflect.TypeOf(genericCred).Kind().String())
 }
 
 // VerifyJWTCredential verifies the signature of a JWT credential after parsing it to resolve the issuer DID
 // The issuer DID is resolution from the provided resolution, and used to find the issuer's public key matching
-// the KID in the JWT header.
-func VerifyJWTCredential(ctx context.Context, cred string, r resolution.Resolver) (bool, error) {
+// the KID in the JWT header. The WithClock and WithClockSkew options change the time its claims are validated at.
+// A KID naming another DID than the issuer's is rejected unless the AllowControllerKID option is given, and an issuer
+// DID whose document metadata marks it deactivated is rejected with ErrDeactivatedDID, unless the
+// WithDeactivatedIssuerPolicy option accepts it. WithStrictMode checks the credential's @context begins with the base
+// context of its data model.
+func VerifyJWTCredential(ctx context.Context, cred string, r resolution.Resolver, opts ...VerifyOption) (bool, error) {
+	start := time.Now()
+	verified, err := verifyJWTCredential(ctx, cred, r, opts...)
+	observeVerification(ctx, JWTCredentialVerification, start, err == nil && verified)
+	return verified, err
+}
+
+func verifyJWTCredential(ctx context.Context, cred string, r resolution.Resolver, opts ...VerifyOption) (bool, error) {
 	if cred == "" {
-		return false, errors.New("credential cannot be empty")
+		return false, ErrEmptyCredential
 	}
 	if r == nil {
 		return false, errors.New("resolution cannot be empty")
 	}
-	headers, token, _, err := ParseVerifiableCredentialFromJWT(cred)
+	headers, token, parsed, err := parseCredentialJWT(cred)
 	if err != nil {
 		return false, errors.Wrap(err, "parsing JWT")
 	}
 
+	// the iss claim names the issuer, which is otherwise the id of the credential's issuer, a string or an object
+	issuer := token.Issuer()
+	if issuer == "" {
+		if issuer, err = parsed.GetIssuerID(); err != nil {
+			return false, errors.Wrapf(err, "getting issuer of credential<%s>", token.JwtID())
+		}
+	}
+
 	// get key to verify the credential with
 	issuerKID := headers.KeyID()
 	if issuerKID == "" {
-		return false, errors.Errorf("missing kid in header of credential<%s>", token.JwtID())
+		return false, errors.Wrapf(ErrMissingKID, "missing kid in header of credential<%s>", token.JwtID())
 	}
-	issuerDID, err := r.Resolve(ctx, token.Issuer())
+	policy, opts, err := deactivatedIssuerPolicy(opts)
+	if err != nil {
+		return false, err
+	}
+	allowControllerKID := hasVerifyOption(opts, ControllerKIDOption)
+	if allowControllerKID {
+		opts = withoutVerifyOption(opts, ControllerKIDOption)
+	} else if err = checkKIDIssuer(issuerKID, issuer, "issuer"); err != nil {
+		return false, errors.Wrapf(err, "error verifying credential<%s>", token.JwtID())
+	}
+	issuerDID, err := r.Resolve(ctx, issuer)
 	if err != nil {
-		return false, errors.Wrapf(err, "error getting issuer DID<%s> to verify credential<%s>", token.Issuer(), token.JwtID())
+		return false, errors.Wrapf(err, "error getting issuer DID<%s> to verify credential<%s>", issuer, token.JwtID())
+	}
+	if issuerDID.IsDeactivated() {
+		if err = policy.check(!token.Expiration().IsZero()); err != nil {
+			return false, errors.Wrapf(err, "issuer DID<%s> of credential<%s>", issuer, token.JwtID())
+		}
 	}
 	issuerKey, err := did.GetKeyFromVerificationMethod(issuerDID.Document, issuerKID)
 	if err != nil {
@@ -92,36 +132,376 @@ func VerifyJWTCredential(ctx context.Context, cred string, r resolution.Resolver
 		return false, errors.Wrapf(err, "error constructing verifier for credential<%s>", token.JwtID())
 	}
 	// verify the signature
-	if _, _, _, err = VerifyVerifiableCredentialJWT(*credVerifier, cred); err != nil {
+	if _, _, _, err = VerifyVerifiableCredentialJWT(*credVerifier, cred, opts...); err != nil {
 		return false, errors.Wrapf(err, "error verifying credential<%s>", token.JwtID())
 	}
 	return true, nil
 }
 
-// VerifyDataIntegrityCredential verifies the signature of a Data Integrity credential
-// TODO(gabe): https://github.com/TBD54566975/ssi-sdk/issues/196
-func VerifyDataIntegrityCredential(_ context.Context, cred credential.VerifiableCredential, _ resolution.Resolver) (bool, error) {
+// DeactivatedIssuerPolicy sets whether credentials are accepted from an issuer whose DID resolves as deactivated
+type DeactivatedIssuerPolicy int
+
+const (
+	// DeactivatedIssuerReject rejects the credentials of deactivated issuers with ErrDeactivatedDID, the default
+	DeactivatedIssuerReject DeactivatedIssuerPolicy = iota
+	// DeactivatedIssuerAcceptIfNotExpired accepts the credentials of deactivated issuers that have an expiration
+	// and have not expired, as a credential issued while its issuer was active may still be acceptable until then.
+	// Credentials without an expiration, which never expire, are rejected.
+	DeactivatedIssuerAcceptIfNotExpired
+	// DeactivatedIssuerAccept accepts the credentials of deactivated issuers as those of active ones
+	DeactivatedIssuerAccept
+)
+
+// WithDeactivatedIssuerPolicy sets whether credentials are accepted from an issuer whose DID resolves as deactivated,
+// which are rejected by default. Credentials are still subject to the rest of their verification, including that
+// they have not expired.
+func WithDeactivatedIssuerPolicy(policy DeactivatedIssuerPolicy) VerifyOption {
+	return VerifyOption{Type: DeactivatedIssuerPolicyOption, Value: policy}
+}
+
+// deactivatedIssuerPolicy returns the policy of the options, the last one given if several are, and the options
+// without it
+func deactivatedIssuerPolicy(opts []VerifyOption) (DeactivatedIssuerPolicy, []VerifyOption, error) {
+	policy := DeactivatedIssuerReject
+	for _, opt := range opts {
+		if opt.Type != DeactivatedIssuerPolicyOption {
+			continue
+		}
+		p, ok := opt.Value.(DeactivatedIssuerPolicy)
+		if !ok || p < DeactivatedIssuerReject || p > DeactivatedIssuerAccept {
+			return policy, nil, fmt.Errorf("unknown deactivated issuer policy<%v>", opt.Value)
+		}
+		policy = p
+	}
+	return policy, withoutVerifyOption(opts, DeactivatedIssuerPolicyOption), nil
+}
+
+// check returns an error wrapping ErrDeactivatedDID if the policy rejects a credential of a deactivated issuer, which
+// has an expiration or not
+func (p DeactivatedIssuerPolicy) check(expires bool) error {
+	switch p {
+	case DeactivatedIssuerAccept:
+		return nil
+	case DeactivatedIssuerAcceptIfNotExpired:
+		if expires {
+			return nil
+		}
+		return errors.Wrap(ErrDeactivatedDID, "credential has no expiration")
+	default:
+		return ErrDeactivatedDID
+	}
+}
+
+func hasVerifyOption(opts []VerifyOption, want VerifyOptionType) bool {
+	for _, opt := range opts {
+		if opt.Type == want {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutVerifyOption returns the options other than those of the given type
+func withoutVerifyOption(opts []VerifyOption, skip VerifyOptionType) []VerifyOption {
+	remaining := make([]VerifyOption, 0, len(opts))
+	for _, opt := range opts {
+		if opt.Type != skip {
+			remaining = append(remaining, opt)
+		}
+	}
+	return remaining
+}
+
+// CandidateVerificationMethods returns the verification methods a credential JWT could be verified against: those in
+// the assertionMethod relationship of the resolved issuer DID that match the kid of the credential's header, if it has
+// one, and whose key signs with the header's alg. It does not verify the credential, and is meant to show why
+// verification fails, such as when the kid is not an assertion method or the issuer has no assertion methods.
+func CandidateVerificationMethods(ctx context.Context, cred string, r resolution.Resolver) ([]did.VerificationMethod, error) {
+	if cred == "" {
+		return nil, ErrEmptyCredential
+	}
+	if r == nil {
+		return nil, errors.New("resolution cannot be empty")
+	}
+	headers, token, _, err := parseCredentialJWT(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing JWT")
+	}
+	issuerDID, err := r.Resolve(ctx, token.Issuer())
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting issuer DID<%s> of credential<%s>", token.Issuer(), token.JwtID())
+	}
+	methods, err := did.GetVerificationMethodsForPurpose(issuerDID.Document, did.AssertionMethod)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting assertion methods of issuer DID<%s>", token.Issuer())
+	}
+
+	kid := headers.KeyID()
+	alg := headers.Algorithm()
+	candidates := make([]did.VerificationMethod, 0, len(methods))
+	for _, method := range methods {
+		if kid != "" && qualifiedMethodID(issuerDID.ID, method.ID) != qualifiedMethodID(issuerDID.ID, kid) {
+			continue
+		}
+		if method.Controller == "" {
+			method.Controller = issuerDID.ID
+		}
+		// methods without a key the header's alg is used with, such as blockchain accounts, cannot verify the JWT
+		verifier, err := did.VerifierFromVerificationMethod(method)
+		if err != nil || !jwx.VerifiesAlgorithm(*verifier, alg) {
+			continue
+		}
+		candidates = append(candidates, method)
+	}
+	return candidates, nil
+}
+
+// qualifiedMethodID returns the ID of a verification method of a DID, prefixed by the DID if it is relative
+func qualifiedMethodID(id, methodID string) string {
+	if strings.HasPrefix(methodID, "#") {
+		return id + methodID
+	}
+	if !strings.Contains(methodID, ":") {
+		return id + "#" + methodID
+	}
+	return methodID
+}
+
+// VerifyDataIntegrityCredential verifies the embedded proof of a Data Integrity credential. Only JsonWebSignature2020
+// proofs are supported, verified with the key of the proof's verificationMethod, resolved from the issuer DID with r.
+// A verification method of another DID than the issuer's is rejected unless the AllowControllerKID option is given,
+// and the WithClock and WithClockSkew options change the time the issuanceDate and expirationDate are validated at.
+// WithStrictMode checks the credential's @context as VerifyJWTCredential does.
+// TODO(gabe): support other cryptosuites https://github.com/TBD54566975/ssi-sdk/issues/196
+func VerifyDataIntegrityCredential(ctx context.Context, cred credential.VerifiableCredential, r resolution.Resolver, opts ...VerifyOption) (bool, error) {
+	start := time.Now()
+	verified, err := verifyDataIntegrityCredential(ctx, cred, r, opts...)
+	observeVerification(ctx, DataIntegrityCredentialVerification, start, err == nil && verified)
+	return verified, err
+}
+
+func verifyDataIntegrityCredential(ctx context.Context, cred credential.VerifiableCredential, r resolution.Resolver, opts ...VerifyOption) (bool, error) {
 	if cred.IsEmpty() {
-		return false, errors.New("credential cannot be empty")
+		return false, ErrEmptyCredential
 	}
 	if cred.GetProof() == nil {
 		return false, errors.New("credential must have a proof")
 	}
+	if r == nil {
+		return false, errors.New("resolution cannot be empty")
+	}
+	policy, opts, err := deactivatedIssuerPolicy(opts)
+	if err != nil {
+		return false, err
+	}
+	allowControllerKID := hasVerifyOption(opts, ControllerKIDOption)
+	strict := hasVerifyOption(opts, StrictOption)
+	tv, err := credentialTimeValidation(withoutVerifyOption(withoutVerifyOption(opts, ControllerKIDOption), StrictOption))
+	if err != nil {
+		return false, err
+	}
+	if strict {
+		if err = cred.ValidateBaseContext(); err != nil {
+			return false, errors.Wrapf(err, "credential<%s>", cred.ID)
+		}
+	}
+
+	proof, err := jws2020.JSONWebSignatureProofFromGenericProof(*cred.GetProof())
+	if err != nil {
+		return false, errors.Wrapf(err, "reading proof of credential<%s>", cred.ID)
+	}
+	if proof.Type != jws2020.JSONWebSignature2020 {
+		return false, fmt.Errorf("unsupported proof type<%s> of credential<%s>", proof.Type, cred.ID)
+	}
+	methodID := proof.VerificationMethod
+	if methodID == "" {
+		return false, errors.Wrapf(ErrMissingKID, "missing verificationMethod in proof of credential<%s>", cred.ID)
+	}
+	issuer, err := cred.GetIssuerID()
+	if err != nil {
+		return false, errors.Wrapf(err, "getting issuer of credential<%s>", cred.ID)
+	}
+	if !allowControllerKID {
+		if err = checkKIDIssuer(methodID, issuer, "issuer"); err != nil {
+			return false, errors.Wrapf(err, "error verifying credential<%s>", cred.ID)
+		}
+	}
+	issuerDID, err := r.Resolve(ctx, issuer)
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting issuer DID<%s> to verify credential<%s>", issuer, cred.ID)
+	}
+	if issuerDID.IsDeactivated() {
+		if err = policy.check(cred.ExpirationDate != ""); err != nil {
+			return false, errors.Wrapf(err, "issuer DID<%s> of credential<%s>", issuer, cred.ID)
+		}
+	}
+	issuerKey, err := did.GetKeyFromVerificationMethod(issuerDID.Document, methodID)
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting key to verify credential<%s>", cred.ID)
+	}
+	issuerJWK, err := jwx.PublicKeyToPublicKeyJWK(&methodID, issuerKey)
+	if err != nil {
+		return false, errors.Wrapf(err, "converting key to verify credential<%s>", cred.ID)
+	}
+	credVerifier, err := jws2020.NewJSONWebKeyVerifier(methodID, *issuerJWK)
+	if err != nil {
+		return false, errors.Wrapf(err, "error constructing verifier for credential<%s>", cred.ID)
+	}
+	if err = jws2020.GetJSONWebSignature2020Suite().Verify(credVerifier, &cred); err != nil {
+		return false, errors.Wrapf(fmt.Errorf("%w: %w", ErrSignatureInvalid, err), "error verifying credential<%s>", cred.ID)
+	}
+	if err = checkCredentialDates(cred, *tv); err != nil {
+		return false, errors.Wrapf(err, "error verifying credential<%s>", cred.ID)
+	}
+	return true, nil
+}
+
+// VerifyDataIntegrityPresentation verifies the JsonWebSignature2020 proof of a presentation with the key of its holder,
+// resolved with r, and then verifies each of its credentials as VerifyCredentialSignature does.
+// Mirroring the nonce and aud claims of presentation JWTs, the proof must have the given challenge and domain the
+// verifier expects the presentation to be bound to. A proof with a domain is rejected unless it is the expected
+// domain, and no challenge is required if none is expected. A verification method of another DID than the holder's is
+// rejected unless the AllowControllerKID option is given, and WithStrictMode checks the presentation's @context as
+// well as those of its credentials; options are passed on to the verification of credentials.
+func VerifyDataIntegrityPresentation(ctx context.Context, pres credential.VerifiablePresentation, r resolution.Resolver, challenge, domain string, opts ...VerifyOption) (bool, error) {
+	start := time.Now()
+	verified, err := verifyDataIntegrityPresentation(ctx, pres, r, challenge, domain, opts...)
+	observeVerification(ctx, DataIntegrityPresentationVerification, start, err == nil && verified)
+	return verified, err
+}
+
+func verifyDataIntegrityPresentation(ctx context.Context, pres credential.VerifiablePresentation, r resolution.Resolver, challenge, domain string, opts ...VerifyOption) (bool, error) {
+	if pres.IsEmpty() {
+		return false, ErrEmptyPresentation
+	}
+	if pres.GetProof() == nil {
+		return false, errors.New("presentation must have a proof")
+	}
+	if r == nil {
+		return false, errors.New("resolution cannot be empty")
+	}
+	// the deactivated issuer policy is of the credentials, which are verified with all of the options
+	_, timeOpts, err := deactivatedIssuerPolicy(opts)
+	if err != nil {
+		return false, err
+	}
+	if _, err = credentialTimeValidation(withoutVerifyOption(withoutVerifyOption(timeOpts, ControllerKIDOption), StrictOption)); err != nil {
+		return false, err
+	}
+	if hasVerifyOption(opts, StrictOption) {
+		if err = pres.ValidateBaseContext(); err != nil {
+			return false, errors.Wrapf(err, "presentation<%s>", pres.ID)
+		}
+	}
 
-	return false, errors.New("not implemented")
+	proof, err := jws2020.JSONWebSignatureProofFromGenericProof(*pres.GetProof())
+	if err != nil {
+		return false, errors.Wrapf(err, "reading proof of presentation<%s>", pres.ID)
+	}
+	if proof.Type != jws2020.JSONWebSignature2020 {
+		return false, fmt.Errorf("unsupported proof type<%s> of presentation<%s>", proof.Type, pres.ID)
+	}
+	if err = checkProofBinding(*proof, challenge, domain); err != nil {
+		return false, errors.Wrapf(err, "error verifying presentation<%s>", pres.ID)
+	}
+	methodID := proof.VerificationMethod
+	if methodID == "" {
+		return false, errors.Wrapf(ErrMissingKID, "missing verificationMethod in proof of presentation<%s>", pres.ID)
+	}
+	holder := pres.Holder
+	if holder == "" {
+		return false, errors.Wrapf(ErrMissingClaim, "presentation<%s> has no holder", pres.ID)
+	}
+	if !hasVerifyOption(opts, ControllerKIDOption) {
+		if err = checkKIDIssuer(methodID, holder, "holder"); err != nil {
+			return false, errors.Wrapf(err, "error verifying presentation<%s>", pres.ID)
+		}
+	}
+	holderDID, err := r.Resolve(ctx, holder)
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting holder DID<%s> to verify presentation<%s>", holder, pres.ID)
+	}
+	if holderDID.IsDeactivated() {
+		return false, errors.Wrapf(ErrDeactivatedDID, "holder DID<%s> of presentation<%s>", holder, pres.ID)
+	}
+	holderKey, err := did.GetKeyFromVerificationMethod(holderDID.Document, methodID)
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting key to verify presentation<%s>", pres.ID)
+	}
+	holderJWK, err := jwx.PublicKeyToPublicKeyJWK(&methodID, holderKey)
+	if err != nil {
+		return false, errors.Wrapf(err, "converting key to verify presentation<%s>", pres.ID)
+	}
+	presVerifier, err := jws2020.NewJSONWebKeyVerifier(methodID, *holderJWK)
+	if err != nil {
+		return false, errors.Wrapf(err, "error constructing verifier for presentation<%s>", pres.ID)
+	}
+	if err = jws2020.GetJSONWebSignature2020Suite().Verify(presVerifier, &pres); err != nil {
+		return false, errors.Wrapf(fmt.Errorf("%w: %w", ErrSignatureInvalid, err), "error verifying presentation<%s>", pres.ID)
+	}
+
+	for i, cred := range pres.VerifiableCredential {
+		if _, err = VerifyCredentialSignature(ctx, cred, r, opts...); err != nil {
+			return false, errors.Wrapf(err, "verifying credential %d", i)
+		}
+	}
+	return true, nil
+}
+
+// checkProofBinding checks a presentation's proof has the challenge and domain the verifier expects
+func checkProofBinding(proof jws2020.JSONWebSignature2020Proof, challenge, domain string) error {
+	if challenge != "" {
+		if proof.Challenge == "" {
+			return errors.Wrap(ErrMissingClaim, "proof has no challenge")
+		}
+		if proof.Challenge != challenge {
+			return errors.Wrapf(ErrUnknownChallenge, "expected challenge<%s>, got <%s>", challenge, proof.Challenge)
+		}
+	}
+	if domain != "" && proof.Domain == "" {
+		return errors.Wrap(ErrMissingClaim, "proof has no domain")
+	}
+	if proof.Domain != domain {
+		return errors.Wrapf(ErrAudienceMismatch, "expected domain<%s>, got <%s>", domain, proof.Domain)
+	}
+	return nil
+}
+
+// checkCredentialDates checks a credential has been issued and has not expired, as of the time of the validation
+func checkCredentialDates(cred credential.VerifiableCredential, tv timeValidation) error {
+	now := tv.now()
+	if cred.IssuanceDate != "" {
+		issued, err := time.Parse(time.RFC3339, cred.IssuanceDate)
+		if err != nil {
+			return errors.Wrapf(ErrMalformedClaim, "issuanceDate<%s> is not a valid date", cred.IssuanceDate)
+		}
+		if now.Add(tv.skew).Before(issued) {
+			return errors.Wrapf(ErrClaimsNotSatisfied, "credential is not valid until %s", cred.IssuanceDate)
+		}
+	}
+	if cred.ExpirationDate != "" {
+		expires, err := time.Parse(time.RFC3339, cred.ExpirationDate)
+		if err != nil {
+			return errors.Wrapf(ErrMalformedClaim, "expirationDate<%s> is not a valid date", cred.ExpirationDate)
+		}
+		if !now.Add(-tv.skew).Before(expires) {
+			return errors.Wrapf(ErrClaimsNotSatisfied, "credential expired at %s", cred.ExpirationDate)
+		}
+	}
+	return nil
 }
 
 // VerifyJWTPresentation verifies the signature of a JWT presentation after parsing it to resolve the issuer DID
 // The issuer DID is resolution from the provided resolution, and used to find the issuer's public key matching
-// the KID in the JWT header.
-func VerifyJWTPresentation(ctx context.Context, pres string, r resolution.Resolver) (bool, error) {
+// the KID in the JWT header. Options are passed on to VerifyVerifiablePresentationJWT.
+func VerifyJWTPresentation(ctx context.Context, pres string, r resolution.Resolver, opts ...VerifyOption) (bool, error) {
 	if pres == "" {
-		return false, errors.New("presentation cannot be empty")
+		return false, ErrEmptyPresentation
 	}
 	if r == nil {
 		return false, errors.New("resolution cannot be empty")
 	}
-	headers, token, _, err := ParseVerifiablePresentationFromJWT(pres)
+	headers, token, _, err := ParseVerifiablePresentationFromJWT(pres, opts...)
 	if err != nil {
 		return false, errors.Wrap(err, "parsing JWT")
 	}
@@ -129,7 +509,7 @@ func VerifyJWTPresentation(ctx context.Context, pres string, r resolution.Resolv
 	// get key to verify the presentation with
 	issuerKID := headers.KeyID()
 	if issuerKID == "" {
-		return false, errors.Errorf("missing kid in header of presentation<%s>", token.JwtID())
+		return false, errors.Wrapf(ErrMissingKID, "missing kid in header of presentation<%s>", token.JwtID())
 	}
 	issuerDID, err := r.Resolve(ctx, token.Issuer())
 	if err != nil {
@@ -146,7 +526,7 @@ func VerifyJWTPresentation(ctx context.Context, pres string, r resolution.Resolv
 		return false, errors.Wrapf(err, "error constructing verifier for presentation<%s>", token.JwtID())
 	}
 	// verify the signature
-	if _, _, _, err = VerifyVerifiablePresentationJWT(ctx, *presVerifier, r, pres); err != nil {
+	if _, _, _, err = VerifyVerifiablePresentationJWT(ctx, *presVerifier, r, pres, opts...); err != nil {
 		return false, errors.Wrapf(err, "error verifying presentation<%s>", token.JwtID())
 	}
 
@@ -2,7 +2,9 @@ package integrity
 
 import (
 	"context"
+	gocrypto "crypto"
 	"net/http"
+	"slices"
 	"testing"
 	"time"
 
@@ -12,6 +14,8 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
 	"github.com/TBD54566975/ssi-sdk/did/key"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
 	"github.com/TBD54566975/ssi-sdk/did/web"
@@ -116,6 +120,180 @@ func TestVerifyCredentialSignature(t *testing.T) {
 		assert.NoError(tt, err)
 		assert.True(tt, verified)
 	})
+
+	t.Run("jwt credential - deactivated issuer", func(tt *testing.T) {
+		signer := getTestDIDKeySigner(tt)
+		jwtCred := getTestJWTCredential(tt, signer)
+
+		verified, err := VerifyCredentialSignature(context.Background(), jwtCred, deactivatedResolver{})
+		assert.ErrorIs(tt, err, ErrDeactivatedDID)
+		assert.ErrorContains(tt, err, signer.ID)
+		assert.False(tt, verified)
+
+		multi, err := SignMultiSignatureCredential([]jwx.Signer{signer}, getTestCredential())
+		require.NoError(tt, err)
+		_, _, err = VerifyMultiSignatureCredential(context.Background(), string(multi), deactivatedResolver{}, 1)
+		assert.ErrorContains(tt, err, ErrDeactivatedDID.Error())
+	})
+}
+
+func TestDeactivatedIssuerPolicy(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	signCredential := func(tt *testing.T, expirationDate string) string {
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			ExpirationDate:    expirationDate,
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	expiring := signCredential(t, "2051-01-01T19:23:24Z")
+	expired := signCredential(t, "2022-01-01T19:23:24Z")
+	neverExpiring := signCredential(t, "")
+
+	tests := []struct {
+		name     string
+		opts     []VerifyOption
+		accepted []string
+	}{
+		{name: "rejects by default"},
+		{name: "rejects", opts: []VerifyOption{WithDeactivatedIssuerPolicy(DeactivatedIssuerReject)}},
+		{
+			name:     "accepts if not expired",
+			opts:     []VerifyOption{WithDeactivatedIssuerPolicy(DeactivatedIssuerAcceptIfNotExpired)},
+			accepted: []string{expiring},
+		},
+		{
+			name:     "accepts",
+			opts:     []VerifyOption{WithDeactivatedIssuerPolicy(DeactivatedIssuerAccept)},
+			accepted: []string{expiring, neverExpiring},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			for _, cred := range []string{expiring, expired, neverExpiring} {
+				verified, err := VerifyJWTCredential(context.Background(), cred, deactivatedResolver{}, test.opts...)
+				if slices.Contains(test.accepted, cred) {
+					assert.NoError(tt, err)
+					assert.True(tt, verified)
+				} else {
+					assert.Error(tt, err)
+				}
+			}
+		})
+	}
+
+	t.Run("expired credentials are rejected as expired", func(tt *testing.T) {
+		_, err := VerifyJWTCredential(context.Background(), expired, deactivatedResolver{}, WithDeactivatedIssuerPolicy(DeactivatedIssuerAccept))
+		assert.ErrorIs(tt, err, ErrClaimsNotSatisfied)
+		_, err = VerifyJWTCredential(context.Background(), neverExpiring, deactivatedResolver{}, WithDeactivatedIssuerPolicy(DeactivatedIssuerAcceptIfNotExpired))
+		assert.ErrorIs(tt, err, ErrDeactivatedDID)
+		assert.ErrorContains(tt, err, "no expiration")
+	})
+
+	t.Run("data integrity credentials", func(tt *testing.T) {
+		privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		expanded, err := didKey.Expand()
+		require.NoError(tt, err)
+		cred := getTestDataIntegrityCredential(tt, privKey, expanded.VerificationMethod[0].ID, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                "urn:uuid:" + uuid.NewString(),
+			Type:              []any{"VerifiableCredential"},
+			Issuer:            didKey.String(),
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		_, err = VerifyDataIntegrityCredential(context.Background(), cred, deactivatedResolver{}, WithDeactivatedIssuerPolicy(DeactivatedIssuerAcceptIfNotExpired))
+		assert.ErrorIs(tt, err, ErrDeactivatedDID)
+		verified, err := VerifyDataIntegrityCredential(context.Background(), cred, deactivatedResolver{}, WithDeactivatedIssuerPolicy(DeactivatedIssuerAccept))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+
+	t.Run("credentials of presentations", func(tt *testing.T) {
+		holder := getTestDIDKeySigner(tt)
+		signed, err := SignVerifiablePresentationJWT(holder, &JWTVVPParameters{Audience: []string{"did:example:verifier"}}, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               holder.ID,
+			VerifiableCredential: []any{expiring},
+		})
+		require.NoError(tt, err)
+		verifier, err := holder.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, deactivatedResolver{}, string(signed))
+		assert.ErrorIs(tt, err, ErrDeactivatedDID)
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, deactivatedResolver{}, string(signed), WithDeactivatedIssuerPolicy(DeactivatedIssuerAcceptIfNotExpired))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("credentials of data integrity presentations", func(tt *testing.T) {
+		issuerPrivKey, issuerDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		issuerExpanded, err := issuerDIDKey.Expand()
+		require.NoError(tt, err)
+		cred := getTestDataIntegrityCredential(tt, issuerPrivKey, issuerExpanded.VerificationMethod[0].ID, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                "urn:uuid:" + uuid.NewString(),
+			Type:              []any{"VerifiableCredential"},
+			Issuer:            issuerDIDKey.String(),
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			ExpirationDate:    "2051-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+
+		holderPrivKey, holderDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		holderExpanded, err := holderDIDKey.Expand()
+		require.NoError(tt, err)
+		holderKID := holderExpanded.VerificationMethod[0].ID
+		_, holderJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&holderKID, holderPrivKey)
+		require.NoError(tt, err)
+		holderSigner, err := jws2020.NewJSONWebKeySigner(holderKID, *holderJWK, cryptosuite.Authentication)
+		require.NoError(tt, err)
+		pres := credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			ID:                   "urn:uuid:" + uuid.NewString(),
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               holderDIDKey.String(),
+			VerifiableCredential: []any{cred},
+		}
+		require.NoError(tt, jws2020.GetJSONWebSignature2020Suite().Sign(holderSigner, &pres))
+
+		r := deactivatedResolver{only: issuerDIDKey.String()}
+		_, err = VerifyDataIntegrityPresentation(context.Background(), pres, r, "", "")
+		assert.ErrorIs(tt, err, ErrDeactivatedDID)
+		verified, err := VerifyDataIntegrityPresentation(context.Background(), pres, r, "", "", WithDeactivatedIssuerPolicy(DeactivatedIssuerAcceptIfNotExpired))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+
+	t.Run("unknown policy", func(tt *testing.T) {
+		_, err := VerifyJWTCredential(context.Background(), expiring, deactivatedResolver{}, WithDeactivatedIssuerPolicy(DeactivatedIssuerPolicy(7)))
+		assert.ErrorContains(tt, err, "unknown deactivated issuer policy<7>")
+	})
+}
+
+// deactivatedResolver resolves did:key DIDs with document metadata marking them deactivated, only the DID only if set
+type deactivatedResolver struct {
+	key.Resolver
+	only string
+}
+
+func (r deactivatedResolver) Resolve(ctx context.Context, id string, opts ...resolution.Option) (*resolution.Result, error) {
+	resolved, err := r.Resolver.Resolve(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if r.only == "" || r.only == id {
+		resolved.DocumentMetadata = &resolution.DocumentMetadata{Deactivated: true}
+	}
+	return resolved, nil
 }
 
 func TestVerifyJWTCredential(t *testing.T) {
@@ -227,6 +405,82 @@ func TestVerifyJWTCredential(t *testing.T) {
 		assert.True(tt, verified)
 	})
 
+	t.Run("valid credential with an issuer object", func(tt *testing.T) {
+		resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+		require.NoError(tt, err)
+
+		privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		expanded, err := didKey.Expand()
+		require.NoError(tt, err)
+		kid := expanded.VerificationMethod[0].ID
+		signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+		require.NoError(tt, err)
+
+		issuer := map[string]any{"id": didKey.String(), "name": "Example University"}
+		signed, err := SignVerifiableCredentialJWT(*signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            issuer,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		verified, err := VerifyJWTCredential(context.Background(), string(signed), resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+
+		_, token, cred, err := ParseVerifiableCredentialFromJWT(string(signed))
+		require.NoError(tt, err)
+		assert.Equal(tt, didKey.String(), token.Issuer())
+		assert.Equal(tt, issuer, cred.Issuer)
+
+		_, err = SignVerifiableCredentialJWT(*signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            map[string]any{"name": "Example University"},
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		assert.ErrorContains(tt, err, "issuer object has no id")
+	})
+
+	t.Run("valid credential, verified offline with a trust list", func(tt *testing.T) {
+		anchorPubKey, anchorPrivKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		anchor, err := jwx.NewJWXSigner("did:web:anchor.example.com", nil, anchorPrivKey)
+		require.NoError(tt, err)
+
+		privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		expanded, err := didKey.Expand()
+		require.NoError(tt, err)
+		kid := expanded.VerificationMethod[0].ID
+		signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+		require.NoError(tt, err)
+		signedList, err := resolution.SignTrustList(*anchor, resolution.TrustList{Issuers: []resolution.TrustedIssuer{
+			{ID: didKey.String(), PublicKeys: []jwx.PublicKeyJWK{signer.ToPublicKeyJWK()}},
+		}})
+		require.NoError(tt, err)
+		resolver, err := resolution.LoadTrustList(signedList, anchorPubKey)
+		require.NoError(tt, err)
+
+		verified, err := VerifyJWTCredential(context.Background(), getTestJWTCredential(tt, *signer), resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		privKey, didKey, err = key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		expanded, err = didKey.Expand()
+		require.NoError(tt, err)
+		kid = expanded.VerificationMethod[0].ID
+		unlisted, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+		require.NoError(tt, err)
+		verified, err = VerifyJWTCredential(context.Background(), getTestJWTCredential(tt, *unlisted), resolver)
+		assert.ErrorIs(tt, err, resolution.ErrNotInTrustList)
+		assert.False(tt, verified)
+	})
+
 	t.Run("valid credential with long form ion did", func(t *testing.T) {
 		resolver, err := ion.NewIONResolver(http.DefaultClient, "https://ion.example.com")
 		assert.NoError(t, err)
@@ -237,6 +491,276 @@ func TestVerifyJWTCredential(t *testing.T) {
 	})
 }
 
+func TestVerifyDataIntegrityCredential(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+
+	newCredential := func() credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                "urn:uuid:" + uuid.NewString(),
+			Type:              []any{"VerifiableCredential"},
+			Issuer:            didKey.String(),
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			ExpirationDate:    "2051-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		}
+	}
+
+	t.Run("verifies a JsonWebSignature2020 proof", func(tt *testing.T) {
+		cred := getTestDataIntegrityCredential(tt, privKey, kid, newCredential())
+		verified, err := VerifyDataIntegrityCredential(context.Background(), cred, resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+
+		// as a map and as JSON, as credentials are embedded in presentations
+		credBytes, err := json.Marshal(cred)
+		require.NoError(tt, err)
+		var credMap map[string]any
+		require.NoError(tt, json.Unmarshal(credBytes, &credMap))
+		verified, err = VerifyCredentialSignature(context.Background(), credMap, resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+		verified, err = VerifyCredentialSignature(context.Background(), string(credBytes), resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+
+	t.Run("issuer object", func(tt *testing.T) {
+		cred := newCredential()
+		cred.Issuer = map[string]any{"id": didKey.String()}
+		cred = getTestDataIntegrityCredential(tt, privKey, kid, cred)
+		verified, err := VerifyCredentialSignature(context.Background(), cred, resolver)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+
+		cred.Issuer = map[string]any{"type": "Profile"}
+		_, err = VerifyCredentialSignature(context.Background(), cred, resolver)
+		assert.ErrorContains(tt, err, "issuer object has no id")
+	})
+
+	t.Run("tampered credential", func(tt *testing.T) {
+		cred := getTestDataIntegrityCredential(tt, privKey, kid, newCredential())
+		cred.CredentialSubject["id"] = "did:example:789"
+		_, err := VerifyCredentialSignature(context.Background(), cred, resolver)
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+	})
+
+	t.Run("proof by a key of another DID", func(tt *testing.T) {
+		otherPrivKey, otherDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		otherExpanded, err := otherDIDKey.Expand()
+		require.NoError(tt, err)
+		cred := getTestDataIntegrityCredential(tt, otherPrivKey, otherExpanded.VerificationMethod[0].ID, newCredential())
+		_, err = VerifyDataIntegrityCredential(context.Background(), cred, resolver)
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+	})
+
+	t.Run("validity dates", func(tt *testing.T) {
+		cred := getTestDataIntegrityCredential(tt, privKey, kid, newCredential())
+		_, err := VerifyDataIntegrityCredential(context.Background(), cred, resolver, WithClock(fixedClock(time.Date(2060, 1, 1, 0, 0, 0, 0, time.UTC))))
+		assert.ErrorIs(tt, err, ErrClaimsNotSatisfied)
+		_, err = VerifyDataIntegrityCredential(context.Background(), cred, resolver, WithClock(fixedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))))
+		assert.ErrorIs(tt, err, ErrClaimsNotSatisfied)
+		_, err = VerifyDataIntegrityCredential(context.Background(), cred, resolver,
+			WithClock(fixedClock(time.Date(2020, 12, 31, 19, 23, 24, 0, time.UTC))), WithClockSkew(24*time.Hour))
+		assert.NoError(tt, err)
+	})
+
+	t.Run("unsupported proof type", func(tt *testing.T) {
+		cred := newCredential()
+		var proof crypto.Proof = map[string]any{"type": "Ed25519Signature2020", "verificationMethod": kid}
+		cred.Proof = &proof
+		_, err := VerifyDataIntegrityCredential(context.Background(), cred, resolver)
+		assert.ErrorContains(tt, err, "unsupported proof type<Ed25519Signature2020>")
+	})
+}
+
+// getTestDataIntegrityCredential signs a credential with a JsonWebSignature2020 proof by the key
+func TestVerifyDataIntegrityPresentation(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	cred := getTestDataIntegrityCredential(t, privKey, kid, credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		ID:                "urn:uuid:" + uuid.NewString(),
+		Type:              []any{"VerifiableCredential"},
+		Issuer:            didKey.String(),
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: map[string]any{"id": didKey.String()},
+	})
+
+	signPresentation := func(tt *testing.T, challenge, domain string, creds ...any) credential.VerifiablePresentation {
+		_, privateJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, privKey)
+		require.NoError(tt, err)
+		signer, err := jws2020.NewJSONWebKeySigner(kid, *privateJWK, cryptosuite.Authentication)
+		require.NoError(tt, err)
+		signer.SetChallenge(challenge)
+		signer.SetDomain(domain)
+		pres := credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			ID:                   "urn:uuid:" + uuid.NewString(),
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               didKey.String(),
+			VerifiableCredential: creds,
+		}
+		require.NoError(tt, jws2020.GetJSONWebSignature2020Suite().Sign(signer, &pres))
+		return pres
+	}
+
+	t.Run("verifies the proof and its binding", func(tt *testing.T) {
+		pres := signPresentation(tt, "nonce-1", "verifier.example.com", cred)
+		verified, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "verifier.example.com")
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+
+		// no challenge is required if none is expected
+		_, err = VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "", "verifier.example.com")
+		assert.NoError(tt, err)
+	})
+
+	t.Run("wrong or missing challenge", func(tt *testing.T) {
+		pres := signPresentation(tt, "nonce-1", "verifier.example.com")
+		_, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-2", "verifier.example.com")
+		assert.ErrorIs(tt, err, ErrUnknownChallenge)
+
+		// an assertion proof has no challenge by default
+		pres = signPresentation(tt, "", "verifier.example.com")
+		pres.Proof = nil
+		_, privateJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, privKey)
+		require.NoError(tt, err)
+		signer, err := jws2020.NewJSONWebKeySigner(kid, *privateJWK, cryptosuite.AssertionMethod)
+		require.NoError(tt, err)
+		require.NoError(tt, jws2020.GetJSONWebSignature2020Suite().Sign(signer, &pres))
+		_, err = VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "")
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+	})
+
+	t.Run("wrong or missing domain", func(tt *testing.T) {
+		pres := signPresentation(tt, "nonce-1", "other.example.com")
+		_, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "verifier.example.com")
+		assert.ErrorIs(tt, err, ErrAudienceMismatch)
+		_, err = VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "")
+		assert.ErrorIs(tt, err, ErrAudienceMismatch)
+
+		pres = signPresentation(tt, "nonce-1", "")
+		_, err = VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "verifier.example.com")
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+	})
+
+	t.Run("tampered presentation", func(tt *testing.T) {
+		pres := signPresentation(tt, "nonce-1", "verifier.example.com")
+		pres.Type = []string{"VerifiablePresentation", "Other"}
+		_, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "verifier.example.com")
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+	})
+
+	t.Run("signed by a key of another DID than the holder", func(tt *testing.T) {
+		pres := signPresentation(tt, "nonce-1", "verifier.example.com")
+		pres.Holder = "did:example:someone-else"
+		_, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "verifier.example.com")
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+	})
+
+	t.Run("invalid credential", func(tt *testing.T) {
+		tampered := cred
+		tampered.CredentialSubject = map[string]any{"id": "did:example:789"}
+		pres := signPresentation(tt, "nonce-1", "verifier.example.com", tampered)
+		_, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "verifier.example.com")
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+		assert.ErrorContains(tt, err, "verifying credential 0")
+	})
+
+	t.Run("strict mode", func(tt *testing.T) {
+		pres := signPresentation(tt, "nonce-1", "verifier.example.com", cred)
+		verified, err := VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "verifier.example.com", WithStrictMode())
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+
+		pres.Context = []string{"https://example.com/context", "https://www.w3.org/2018/credentials/v1"}
+		_, err = VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "verifier.example.com", WithStrictMode())
+		assert.ErrorIs(tt, err, credential.ErrInvalidContext)
+	})
+
+	t.Run("bad inputs", func(tt *testing.T) {
+		_, err := VerifyDataIntegrityPresentation(context.Background(), credential.VerifiablePresentation{}, resolver, "", "")
+		assert.ErrorIs(tt, err, ErrEmptyPresentation)
+		pres := signPresentation(tt, "nonce-1", "verifier.example.com")
+		_, err = VerifyDataIntegrityPresentation(context.Background(), pres, nil, "nonce-1", "verifier.example.com")
+		assert.ErrorContains(tt, err, "resolution cannot be empty")
+		pres.Proof = nil
+		_, err = VerifyDataIntegrityPresentation(context.Background(), pres, resolver, "nonce-1", "verifier.example.com")
+		assert.ErrorContains(tt, err, "must have a proof")
+	})
+}
+
+func getTestDataIntegrityCredential(t *testing.T, privKey gocrypto.PrivateKey, kid string, cred credential.VerifiableCredential) credential.VerifiableCredential {
+	_, privateJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, privKey)
+	require.NoError(t, err)
+	signer, err := jws2020.NewJSONWebKeySigner(kid, *privateJWK, cryptosuite.AssertionMethod)
+	require.NoError(t, err)
+	require.NoError(t, jws2020.GetJSONWebSignature2020Suite().Sign(signer, &cred))
+	return cred
+}
+
+func TestCandidateVerificationMethods(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	require.NotEmpty(t, expanded.KeyAgreement)
+
+	t.Run("empty credential and resolution", func(tt *testing.T) {
+		_, err := CandidateVerificationMethods(context.Background(), "", resolver)
+		assert.ErrorIs(tt, err, ErrEmptyCredential)
+
+		_, err = CandidateVerificationMethods(context.Background(), "not-empty", nil)
+		assert.ErrorContains(tt, err, "resolution cannot be empty")
+	})
+
+	t.Run("kid of an assertion method", func(tt *testing.T) {
+		kid := expanded.VerificationMethod[0].ID
+		signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+		require.NoError(tt, err)
+
+		methods, err := CandidateVerificationMethods(context.Background(), getTestJWTCredential(tt, *signer), resolver)
+		assert.NoError(tt, err)
+		require.Len(tt, methods, 1)
+		assert.Equal(tt, kid, methods[0].ID)
+	})
+
+	t.Run("kid of an unknown key", func(tt *testing.T) {
+		// the kid defaults to the key's thumbprint, which is not a method of the DID
+		signer, err := jwx.NewJWXSigner(didKey.String(), nil, privKey)
+		require.NoError(tt, err)
+
+		methods, err := CandidateVerificationMethods(context.Background(), getTestJWTCredential(tt, *signer), resolver)
+		assert.NoError(tt, err)
+		assert.Empty(tt, methods)
+	})
+
+	t.Run("kid of a key agreement key", func(tt *testing.T) {
+		kid := expanded.KeyAgreement[0].(string)
+		signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+		require.NoError(tt, err)
+
+		methods, err := CandidateVerificationMethods(context.Background(), getTestJWTCredential(tt, *signer), resolver)
+		assert.NoError(tt, err)
+		assert.Empty(tt, methods)
+	})
+}
+
 func getTestJWTCredential(t *testing.T, signer jwx.Signer) string {
 	cred := credential.VerifiableCredential{
 		ID:           uuid.NewString(),
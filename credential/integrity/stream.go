@@ -0,0 +1,301 @@
+package integrity
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// verifiableCredentialProperty is the property of a presentation holding its credentials
+const verifiableCredentialProperty = "verifiableCredential"
+
+// CredentialCallback is called by StreamVerifyPresentationCredentials with each credential of a presentation, in
+// order, and the error of its verification, which is nil if it verified. The credential is nil if it could not be
+// parsed. Returning false stops the stream.
+type CredentialCallback func(index int, cred *credential.VerifiableCredential, err error) bool
+
+// StreamVerifyPresentationCredentials verifies a presentation JWT, then its credentials one at a time, decoding each
+// from the token's payload only once the previous one has been handed to the callback, so memory use does not grow
+// with the number of credentials in the presentation. The presentation is verified first as by
+// VerifyVerifiablePresentationJWT, with the verifier, which must hold a key of the presentation's holder, and its
+// audience checked against the verifier's ID or KID; its signature is verified over the signing input and only its
+// registered claims are decoded, and no callback is made if it does not verify. Credentials are verified as by
+// VerifyCredentialSignature; nested presentations and credentials referenced by hash are not supported, and are
+// reported to the callback as errors. An error is returned if the presentation does not verify or the token's
+// payload cannot be decoded.
+func StreamVerifyPresentationCredentials(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token string, fn CredentialCallback) error {
+	if r == nil {
+		return errors.New("resolution cannot be empty")
+	}
+	if fn == nil {
+		return errors.New("callback cannot be empty")
+	}
+	if err := checkTokenSize(token); err != nil {
+		return err
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("token is not a compact JWS")
+	}
+	if err := verifyStreamedPresentation(ctx, verifier, r, token, parts[1]); err != nil {
+		return errors.Wrap(err, "verifying presentation")
+	}
+	payload := base64.NewDecoder(base64.RawURLEncoding, strings.NewReader(parts[1]))
+	dec := json.NewDecoder(payload)
+
+	index := 0
+	return streamPresentationCredentials(dec, func(raw json.RawMessage) bool {
+		cred, err := verifyStreamedCredential(ctx, raw, r)
+		if err != nil {
+			err = errors.Wrapf(err, "verifying credential %d", index)
+		}
+		next := fn(index, cred, err)
+		index++
+		return next
+	})
+}
+
+// verifyStreamedPresentation verifies the signature of a presentation JWT with the verifier, and its registered
+// claims: its time-based claims, its audience, and that the verifier's key is one of its holder's
+func verifyStreamedPresentation(ctx context.Context, verifier jwx.Verifier, r resolution.Resolver, token, encodedPayload string) error {
+	if err := verifier.VerifyJWSWithContext(ctx, token); err != nil {
+		return verificationError(err)
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(token))
+	if err != nil {
+		return errors.Wrap(err, "getting JWT headers")
+	}
+	payload := base64.NewDecoder(base64.RawURLEncoding, strings.NewReader(encodedPayload))
+	claims, err := streamRegisteredClaims(json.NewDecoder(payload))
+	if err != nil {
+		return err
+	}
+	if err = jwt.Validate(claims); err != nil {
+		return verificationError(err)
+	}
+	if err = checkAudience(claims, []string{verifier.ID, verifier.KID}); err != nil {
+		return err
+	}
+	if err = checkKIDIssuer(headers.KeyID(), claims.Issuer(), "holder"); err != nil {
+		return err
+	}
+	return checkHolderKey(ctx, r, verifier, headers, claims)
+}
+
+// streamRegisteredClaims decodes the registered claims of a JWT, skipping others, such as the vp claim, token by token
+// without holding them in memory
+func streamRegisteredClaims(dec *json.Decoder) (jwt.Token, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, errors.Wrap(err, "decoding token claims")
+	}
+	claims := jwt.New()
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding token claims")
+		}
+		name, _ := key.(string)
+		if !slices.Contains(registeredClaims, name) {
+			if err = skipValue(dec); err != nil {
+				return nil, errors.Wrapf(err, "decoding %s claim", name)
+			}
+			continue
+		}
+		var value any
+		if err = dec.Decode(&value); err != nil {
+			return nil, errors.Wrapf(err, "decoding %s claim", name)
+		}
+		if err = claims.Set(name, value); err != nil {
+			return nil, errors.Wrapf(ErrMalformedClaim, "%s claim<%v>: %s", name, value, err)
+		}
+	}
+	return claims, nil
+}
+
+// registeredClaims are the claims of RFC 7519 that streamRegisteredClaims decodes
+var registeredClaims = []string{
+	jwt.IssuerKey, jwt.SubjectKey, jwt.AudienceKey, jwt.ExpirationKey, jwt.NotBeforeKey, jwt.IssuedAtKey, jwt.JwtIDKey,
+}
+
+// verifyStreamedCredential verifies a single credential of a presentation, returning it parsed
+func verifyStreamedCredential(ctx context.Context, raw json.RawMessage, r resolution.Resolver) (*credential.VerifiableCredential, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var tokenCred string
+	if err := JSONCodec.Unmarshal(raw, &tokenCred); err != nil {
+		var cred credential.VerifiableCredential
+		if err = JSONCodec.Unmarshal(raw, &cred); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling credential object")
+		}
+		ok, err := VerifyCredentialSignature(ctx, cred, r)
+		if err != nil {
+			return &cred, err
+		}
+		if !ok {
+			return &cred, errors.Wrap(ErrSignatureInvalid, "credential failed signature validation")
+		}
+		return &cred, nil
+	}
+
+	if IsCredentialHash(tokenCred) {
+		return nil, errors.New("credentials referenced by hash cannot be streamed")
+	}
+	if jwtType, err := DetectJWTType(tokenCred); err == nil && jwtType == PresentationJWTType {
+		return nil, errors.New("nested presentations cannot be streamed")
+	}
+	_, _, cred, err := parseCredentialJWT(tokenCred)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing credential JWT")
+	}
+	ok, err := VerifyJWTCredential(ctx, tokenCred, r)
+	if err != nil {
+		return cred, err
+	}
+	if !ok {
+		return cred, errors.Wrap(ErrSignatureInvalid, "credential failed signature validation")
+	}
+	return cred, nil
+}
+
+// streamPresentationCredentials walks the claims of a presentation JWT to the credentials of its vp claim, handing
+// each to visit until it returns false. Other claims are skipped token by token without being held in memory.
+func streamPresentationCredentials(dec *json.Decoder, visit func(json.RawMessage) bool) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return errors.Wrap(err, "decoding token claims")
+	}
+	var foundVP bool
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return errors.Wrap(err, "decoding token claims")
+		}
+		if key != VPJWTProperty {
+			if err = skipValue(dec); err != nil {
+				return errors.Wrapf(err, "decoding %s claim", key)
+			}
+			continue
+		}
+		foundVP = true
+
+		if err = expectDelim(dec, '{'); err != nil {
+			return errors.Wrapf(err, "decoding %s claim", VPJWTProperty)
+		}
+		for dec.More() {
+			property, err := dec.Token()
+			if err != nil {
+				return errors.Wrapf(err, "decoding %s claim", VPJWTProperty)
+			}
+			if property != verifiableCredentialProperty {
+				if err = skipValue(dec); err != nil {
+					return errors.Wrapf(err, "decoding %s property", property)
+				}
+				continue
+			}
+			start, err := dec.Token()
+			if err != nil {
+				return errors.Wrapf(err, "decoding %s property", verifiableCredentialProperty)
+			}
+			if start != json.Delim('[') {
+				// a single credential rather than an array of them
+				raw, err := decodeSingleValue(dec, start)
+				if err != nil {
+					return errors.Wrapf(err, "decoding %s property", verifiableCredentialProperty)
+				}
+				if raw != nil && !visit(raw) {
+					return nil
+				}
+				continue
+			}
+			for dec.More() {
+				var raw json.RawMessage
+				if err = dec.Decode(&raw); err != nil {
+					return errors.Wrapf(err, "decoding %s property", verifiableCredentialProperty)
+				}
+				if !visit(raw) {
+					return nil
+				}
+			}
+			if _, err = dec.Token(); err != nil {
+				return errors.Wrapf(err, "decoding %s property", verifiableCredentialProperty)
+			}
+		}
+		if _, err = dec.Token(); err != nil {
+			return errors.Wrapf(err, "decoding %s claim", VPJWTProperty)
+		}
+	}
+	if !foundVP {
+		return errors.Wrapf(ErrMissingClaim, "did not find %s property in token", VPJWTProperty)
+	}
+	return nil
+}
+
+// decodeSingleValue returns the value whose first token has been read, which is nil for null. The rest of an object
+// is read a property at a time and re-marshalled, so the order of its properties is not kept.
+func decodeSingleValue(dec *json.Decoder, first json.Token) (json.RawMessage, error) {
+	switch first {
+	case nil:
+		return nil, nil
+	case json.Delim('{'):
+		object := make(map[string]json.RawMessage)
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			var value json.RawMessage
+			if err = dec.Decode(&value); err != nil {
+				return nil, err
+			}
+			object[key.(string)] = value
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return json.Marshal(object)
+	case json.Delim(']'), json.Delim('}'):
+		return nil, errors.Errorf("unexpected %s", first)
+	default:
+		return json.Marshal(first)
+	}
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return errors.Errorf("expected %s, got %v", delim, token)
+	}
+	return nil
+}
+
+// skipValue consumes the next value of the decoder, reading nested objects and arrays a token at a time
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package integrity
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestStreamVerifyPresentationCredentials(t *testing.T) {
+	holder := getTestDIDKeySigner(t)
+	verifier, err := holder.ToVerifier(holder.ID)
+	require.NoError(t, err)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	issuer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	signCredential := func(tt *testing.T, subject string) string {
+		signed, err := SignVerifiableCredentialJWT(*issuer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            didKey.String(),
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": subject},
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	// signPresentationBy signs a presentation of the credentials by the holder
+	signPresentationBy := func(tt *testing.T, holder jwx.Signer, parameters *JWTVVPParameters, creds ...any) string {
+		signed, err := SignVerifiablePresentationJWT(holder, parameters, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               holder.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	signPresentation := func(tt *testing.T, creds ...any) string {
+		return signPresentationBy(tt, holder, nil, creds...)
+	}
+
+	t.Run("verifies each credential in order", func(tt *testing.T) {
+		token := signPresentation(tt, signCredential(tt, "did:example:1"), signCredential(tt, "did:example:2")+"tampered",
+			signCredential(tt, "did:example:3"))
+
+		var subjects []string
+		var errs []error
+		err := StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, token,
+			func(index int, cred *credential.VerifiableCredential, err error) bool {
+				assert.Equal(tt, len(subjects), index)
+				subjects = append(subjects, cred.CredentialSubject.GetID())
+				errs = append(errs, err)
+				return true
+			})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{"did:example:1", "did:example:2", "did:example:3"}, subjects)
+		assert.NoError(tt, errs[0])
+		assert.ErrorContains(tt, errs[1], "verifying credential 1")
+		assert.NoError(tt, errs[2])
+	})
+
+	t.Run("stops when the callback returns false", func(tt *testing.T) {
+		token := signPresentation(tt, signCredential(tt, "did:example:1"), signCredential(tt, "did:example:2"))
+
+		calls := 0
+		err := StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, token,
+			func(int, *credential.VerifiableCredential, error) bool {
+				calls++
+				return false
+			})
+		assert.NoError(tt, err)
+		assert.Equal(tt, 1, calls)
+	})
+
+	t.Run("unsupported credentials are reported", func(tt *testing.T) {
+		nested := signPresentation(tt, signCredential(tt, "did:example:1"))
+		token := signPresentation(tt, nested, CredentialHash(signCredential(tt, "did:example:2")))
+
+		var errs []error
+		err := StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, token,
+			func(_ int, cred *credential.VerifiableCredential, err error) bool {
+				assert.Nil(tt, cred)
+				errs = append(errs, err)
+				return true
+			})
+		assert.NoError(tt, err)
+		require.Len(tt, errs, 2)
+		assert.ErrorContains(tt, errs[0], "nested presentations cannot be streamed")
+		assert.ErrorContains(tt, errs[1], "credentials referenced by hash cannot be streamed")
+	})
+
+	t.Run("single credential rather than an array", func(tt *testing.T) {
+		signSingle := func(tt *testing.T, cred any) string {
+			payload, err := json.Marshal(map[string]any{
+				"iss": holder.ID,
+				VPJWTProperty: map[string]any{
+					"@context":                   []string{"https://www.w3.org/2018/credentials/v1"},
+					"type":                       []string{"VerifiablePresentation"},
+					verifiableCredentialProperty: cred,
+				},
+			})
+			require.NoError(tt, err)
+			signed, err := holder.SignJWS(payload)
+			require.NoError(tt, err)
+			return string(signed)
+		}
+		stream := func(tt *testing.T, token string) ([]*credential.VerifiableCredential, []error) {
+			var creds []*credential.VerifiableCredential
+			var errs []error
+			err := StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, token,
+				func(_ int, cred *credential.VerifiableCredential, err error) bool {
+					creds = append(creds, cred)
+					errs = append(errs, err)
+					return true
+				})
+			require.NoError(tt, err)
+			return creds, errs
+		}
+
+		creds, errs := stream(tt, signSingle(tt, signCredential(tt, "did:example:1")))
+		require.Len(tt, creds, 1)
+		assert.Equal(tt, "did:example:1", creds[0].CredentialSubject.GetID())
+		assert.NoError(tt, errs[0])
+
+		// an object credential is decoded, though this one has no proof to verify
+		creds, errs = stream(tt, signSingle(tt, map[string]any{
+			"@context":          []string{"https://www.w3.org/2018/credentials/v1"},
+			"type":              []string{"VerifiableCredential"},
+			"issuer":            didKey.String(),
+			"issuanceDate":      "2021-01-01T19:23:24Z",
+			"credentialSubject": map[string]any{"id": "did:example:2"},
+		}))
+		require.Len(tt, creds, 1)
+		require.NotNil(tt, creds[0])
+		assert.Equal(tt, "did:example:2", creds[0].CredentialSubject.GetID())
+		assert.Error(tt, errs[0])
+
+		creds, _ = stream(tt, signSingle(tt, nil))
+		assert.Empty(tt, creds)
+	})
+
+	t.Run("the presentation is verified first", func(tt *testing.T) {
+		noCallback := func(int, *credential.VerifiableCredential, error) bool {
+			tt.Error("callback made for a presentation that does not verify")
+			return true
+		}
+		cred := signCredential(tt, "did:example:1")
+
+		// credentials of another holder wrapped in a presentation that is not theirs
+		other := getTestDIDKeySigner(tt)
+		wrapped := signPresentationBy(tt, other, nil, cred)
+		err := StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, wrapped, noCallback)
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+		otherVerifier, err := other.ToVerifier(other.ID)
+		require.NoError(tt, err)
+		payload, err := json.Marshal(map[string]any{
+			"iss":         holder.ID,
+			VPJWTProperty: map[string]any{verifiableCredentialProperty: []string{cred}},
+		})
+		require.NoError(tt, err)
+		forged, err := other.SignJWS(payload)
+		require.NoError(tt, err)
+		err = StreamVerifyPresentationCredentials(context.Background(), *otherVerifier, resolver, string(forged), noCallback)
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+
+		// unsigned, expired, and for another audience
+		parts := strings.Split(signPresentation(tt, cred), ".")
+		unsigned := strings.Join([]string{parts[0], parts[1], ""}, ".")
+		err = StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, unsigned, noCallback)
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+		expired := signPresentationBy(tt, holder, &JWTVVPParameters{Expiration: int(time.Now().Add(-time.Hour).Unix())}, cred)
+		err = StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, expired, noCallback)
+		assert.ErrorIs(tt, err, ErrClaimsNotSatisfied)
+		elsewhere := signPresentationBy(tt, holder, &JWTVVPParameters{Audience: []string{"did:example:other-verifier"}}, cred)
+		err = StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, elsewhere, noCallback)
+		assert.ErrorIs(tt, err, ErrAudienceMismatch)
+	})
+
+	t.Run("token without a presentation", func(tt *testing.T) {
+		payload, err := json.Marshal(map[string]any{"iss": holder.ID})
+		require.NoError(tt, err)
+		signed, err := holder.SignJWS(payload)
+		require.NoError(tt, err)
+		err = StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, string(signed),
+			func(int, *credential.VerifiableCredential, error) bool { return true })
+		assert.ErrorIs(tt, err, ErrMissingClaim)
+
+		err = StreamVerifyPresentationCredentials(context.Background(), *verifier, resolver, "not a token",
+			func(int, *credential.VerifiableCredential, error) bool { return true })
+		assert.ErrorContains(tt, err, "token is not a compact JWS")
+	})
+}
@@ -0,0 +1,49 @@
+package integrity
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// ResolveCredentialSubject resolves the DID document of the credential's subject with r, such as to display the
+// subject's services once the credential has been verified. The credential itself is not verified. A subject id that
+// is a DID URL is resolved as the DID it is a URL of. An error wrapping ErrMissingSubjectID is returned if the subject
+// has no id, and one wrapping ErrSubjectNotDID if its id is another kind of URI. Credentials with many subjects are
+// not supported, as which subject to resolve is ambiguous.
+func ResolveCredentialSubject(ctx context.Context, cred credential.VerifiableCredential, r resolution.Resolver) (did.Document, error) {
+	if r == nil {
+		return did.Document{}, errors.New("resolver cannot be empty")
+	}
+	if len(cred.CredentialSubjects) > 0 {
+		return did.Document{}, errors.Errorf("credential<%s> has %d subjects", cred.ID, len(cred.CredentialSubjects))
+	}
+	subjectID := cred.CredentialSubject.GetID()
+	if subjectID == "" {
+		return did.Document{}, errors.Wrapf(ErrMissingSubjectID, "credential<%s>", cred.ID)
+	}
+	subjectDID, _, _ := strings.Cut(subjectID, "#")
+	if i := strings.IndexAny(subjectDID, "/?"); i >= 0 {
+		subjectDID = subjectDID[:i]
+	}
+	if !strings.HasPrefix(subjectDID, "did:") {
+		return did.Document{}, errors.Wrapf(ErrSubjectNotDID, "subject<%s> of credential<%s>", subjectID, cred.ID)
+	}
+	if _, err := resolution.GetMethodForDID(subjectDID); err != nil {
+		return did.Document{}, errors.Wrapf(ErrSubjectNotDID, "subject<%s> of credential<%s>: %s", subjectID, cred.ID, err)
+	}
+
+	resolved, err := r.Resolve(ctx, subjectDID)
+	if err != nil {
+		return did.Document{}, errors.Wrapf(err, "resolving subject<%s> of credential<%s>", subjectDID, cred.ID)
+	}
+	if resolved == nil {
+		return did.Document{}, errors.Errorf("resolving subject<%s> of credential<%s> returned no result", subjectDID, cred.ID)
+	}
+	return resolved.Document, nil
+}
@@ -0,0 +1,70 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestResolveCredentialSubject(t *testing.T) {
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+	_, subjectDID, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	withSubject := func(subject credential.CredentialSubject) credential.VerifiableCredential {
+		cred := getTestCredential()
+		cred.ID = "urn:uuid:1234"
+		cred.CredentialSubject = subject
+		return cred
+	}
+
+	t.Run("resolves the subject's DID document", func(tt *testing.T) {
+		doc, err := ResolveCredentialSubject(context.Background(), withSubject(credential.CredentialSubject{"id": subjectDID.String()}), resolver)
+		require.NoError(tt, err)
+		assert.Equal(tt, subjectDID.String(), doc.ID)
+		assert.NotEmpty(tt, doc.VerificationMethod)
+	})
+
+	t.Run("subjects that are DID URLs resolve to their DID", func(tt *testing.T) {
+		for _, suffix := range []string{"#key-1", "/path", "?service=files"} {
+			doc, err := ResolveCredentialSubject(context.Background(), withSubject(credential.CredentialSubject{"id": subjectDID.String() + suffix}), resolver)
+			require.NoError(tt, err, suffix)
+			assert.Equal(tt, subjectDID.String(), doc.ID, suffix)
+		}
+	})
+
+	t.Run("subjects without a DID", func(tt *testing.T) {
+		_, err := ResolveCredentialSubject(context.Background(), withSubject(credential.CredentialSubject{"name": "Alice"}), resolver)
+		assert.ErrorIs(tt, err, ErrMissingSubjectID)
+		_, err = ResolveCredentialSubject(context.Background(), withSubject(nil), resolver)
+		assert.ErrorIs(tt, err, ErrMissingSubjectID)
+
+		for _, id := range []string{"https://example.com/users/alice", "urn:uuid:5678", "did:incomplete"} {
+			_, err = ResolveCredentialSubject(context.Background(), withSubject(credential.CredentialSubject{"id": id}), resolver)
+			assert.ErrorIs(tt, err, ErrSubjectNotDID, id)
+		}
+	})
+
+	t.Run("resolution errors", func(tt *testing.T) {
+		_, err := ResolveCredentialSubject(context.Background(), withSubject(credential.CredentialSubject{"id": "did:example:456"}), resolver)
+		assert.ErrorContains(tt, err, "resolving subject<did:example:456> of credential<urn:uuid:1234>")
+		assert.NotErrorIs(tt, err, ErrSubjectNotDID)
+
+		_, err = ResolveCredentialSubject(context.Background(), withSubject(credential.CredentialSubject{"id": subjectDID.String()}), nil)
+		assert.ErrorContains(tt, err, "resolver cannot be empty")
+	})
+
+	t.Run("credentials with many subjects", func(tt *testing.T) {
+		cred := withSubject(nil)
+		cred.CredentialSubjects = []credential.CredentialSubject{{"id": subjectDID.String()}, {"id": "did:example:456"}}
+		_, err := ResolveCredentialSubject(context.Background(), cred, resolver)
+		assert.ErrorContains(tt, err, "has 2 subjects")
+	})
+}
@@ -0,0 +1,119 @@
+package integrity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+)
+
+// TrustRecord is a trust registry's entry for an accredited issuer
+type TrustRecord struct {
+	// DID is the issuer's DID
+	DID string
+	// Document is the issuer's DID document as managed by the registry, whose keys verify the issuer's credentials
+	Document did.Document
+	// CredentialTypes are the credential types the issuer is accredited to issue
+	CredentialTypes []string
+}
+
+// IsAuthorizedFor returns whether the issuer is accredited to issue credentials of the given type
+func (t TrustRecord) IsAuthorizedFor(credType string) bool {
+	for _, authorized := range t.CredentialTypes {
+		if authorized == credType {
+			return true
+		}
+	}
+	return false
+}
+
+// TrustRegistry is a centrally managed set of trusted issuers and their DID documents
+type TrustRegistry interface {
+	// LookupIssuer returns the record of the issuer with the given DID, or an error if the issuer is not trusted
+	LookupIssuer(did string) (*TrustRecord, error)
+}
+
+// WithTrustRegistry verifies each credential in a presentation with the issuer's keys from the given registry in
+// place of the resolver, rejecting credentials from issuers that are not accredited for the credential's types
+func WithTrustRegistry(registry TrustRegistry) VerifyOption {
+	return VerifyOption{Type: TrustRegistryOption, Value: registry}
+}
+
+// VerifyJWTCredentialWithTrustRegistry verifies the signature of a JWT credential with the key of its issuer from
+// the trust registry, and checks the issuer is accredited for each of the credential's types other than
+// VerifiableCredential. The WithClock and WithClockSkew options change the time its claims are validated at.
+func VerifyJWTCredentialWithTrustRegistry(ctx context.Context, cred string, registry TrustRegistry, opts ...VerifyOption) (bool, error) {
+	if cred == "" {
+		return false, ErrEmptyCredential
+	}
+	if registry == nil {
+		return false, errors.New("registry cannot be empty")
+	}
+	_, token, vc, err := parseCredentialJWT(cred)
+	if err != nil {
+		return false, errors.Wrap(err, "parsing JWT")
+	}
+	issuer := token.Issuer()
+	record, err := registry.LookupIssuer(issuer)
+	if err != nil {
+		return false, fmt.Errorf("%w: looking up issuer<%s>: %w", ErrUntrustedIssuer, issuer, err)
+	}
+	if record == nil {
+		return false, errors.Wrapf(ErrUntrustedIssuer, "issuer<%s> is not in the trust registry", issuer)
+	}
+	if record.DID != issuer {
+		return false, errors.Wrapf(ErrIssuerMismatch, "trust record<%s> is not for issuer<%s>", record.DID, issuer)
+	}
+
+	if _, err = VerifyJWTCredential(ctx, cred, recordResolver{record: *record}, opts...); err != nil {
+		return false, err
+	}
+
+	credTypes, err := util.InterfaceToStrings(vc.Type)
+	if err != nil {
+		return false, errors.Wrapf(err, "reading types of credential<%s>", token.JwtID())
+	}
+	for _, credType := range credTypes {
+		if credType == credential.VerifiableCredentialType {
+			continue
+		}
+		if !record.IsAuthorizedFor(credType) {
+			return false, errors.Wrapf(ErrUntrustedIssuer, "issuer<%s> is not accredited to issue %s credentials", issuer, credType)
+		}
+	}
+	return true, nil
+}
+
+// verifyTrustedCredential verifies a credential of a presentation against the trust registry, which supports JWT
+// credentials only
+func verifyTrustedCredential(ctx context.Context, genericCred any, registry TrustRegistry, opts ...VerifyOption) (bool, error) {
+	switch typedCred := genericCred.(type) {
+	case string:
+		return VerifyJWTCredentialWithTrustRegistry(ctx, typedCred, registry, opts...)
+	case []byte:
+		return VerifyJWTCredentialWithTrustRegistry(ctx, string(typedCred), registry, opts...)
+	}
+	return false, errors.New("trust registry verification requires a JWT credential")
+}
+
+// recordResolver resolves the DID of a trust record to the document held in the record
+type recordResolver struct {
+	record TrustRecord
+}
+
+var _ resolution.Resolver = recordResolver{}
+
+func (r recordResolver) Resolve(_ context.Context, id string, _ ...resolution.Option) (*resolution.Result, error) {
+	if id != r.record.DID {
+		return nil, errors.Wrapf(ErrUntrustedIssuer, "did<%s> is not in the trust registry", id)
+	}
+	return &resolution.Result{Document: r.record.Document}, nil
+}
+
+func (recordResolver) Methods() []did.Method {
+	return nil
+}
@@ -0,0 +1,129 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+type testTrustRegistry map[string]TrustRecord
+
+func (r testTrustRegistry) LookupIssuer(did string) (*TrustRecord, error) {
+	record, ok := r[did]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func TestVerifyJWTCredentialWithTrustRegistry(t *testing.T) {
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	issuer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	registry := testTrustRegistry{
+		didKey.String(): {
+			DID:             didKey.String(),
+			Document:        *expanded,
+			CredentialTypes: []string{"UniversityDegreeCredential"},
+		},
+	}
+
+	signCredential := func(tt *testing.T, signer jwx.Signer, credType string) string {
+		signed, err := SignVerifiableCredentialJWT(signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{credential.VerifiableCredentialType, credType},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456", "degree": "BSc"},
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+
+	t.Run("accredited issuer", func(tt *testing.T) {
+		ok, err := VerifyJWTCredentialWithTrustRegistry(context.Background(), signCredential(tt, *issuer, "UniversityDegreeCredential"), registry)
+		assert.NoError(tt, err)
+		assert.True(tt, ok)
+	})
+
+	t.Run("issuer not accredited for the credential type", func(tt *testing.T) {
+		_, err := VerifyJWTCredentialWithTrustRegistry(context.Background(), signCredential(tt, *issuer, "DriversLicenseCredential"), registry)
+		assert.ErrorIs(tt, err, ErrUntrustedIssuer)
+		assert.ErrorContains(tt, err, "is not accredited to issue DriversLicenseCredential credentials")
+	})
+
+	t.Run("issuer not in the registry", func(tt *testing.T) {
+		otherPrivKey, otherDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		otherSigner, err := jwx.NewJWXSigner(otherDIDKey.String(), &kid, otherPrivKey)
+		require.NoError(tt, err)
+
+		_, err = VerifyJWTCredentialWithTrustRegistry(context.Background(), signCredential(tt, *otherSigner, "UniversityDegreeCredential"), registry)
+		assert.ErrorIs(tt, err, ErrUntrustedIssuer)
+		assert.ErrorContains(tt, err, "is not in the trust registry")
+	})
+
+	t.Run("keys come from the registry", func(tt *testing.T) {
+		// the registry holds a document with a different key under the issuer's key id
+		_, otherDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		otherExpanded, err := otherDIDKey.Expand()
+		require.NoError(tt, err)
+		rotated := *expanded
+		rotated.VerificationMethod = otherExpanded.VerificationMethod
+		rotated.VerificationMethod[0].ID = kid
+		rotatedRegistry := testTrustRegistry{
+			didKey.String(): {DID: didKey.String(), Document: rotated, CredentialTypes: []string{"UniversityDegreeCredential"}},
+		}
+
+		_, err = VerifyJWTCredentialWithTrustRegistry(context.Background(), signCredential(tt, *issuer, "UniversityDegreeCredential"), rotatedRegistry)
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+	})
+
+	t.Run("presentation verified against the registry", func(tt *testing.T) {
+		holder := getTestDIDKeySigner(tt)
+		resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+		require.NoError(tt, err)
+		verifier, err := holder.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+		signPresentation := func(creds ...any) string {
+			signed, err := SignVerifiablePresentationJWT(holder, &JWTVVPParameters{Audience: []string{"did:example:verifier"}}, credential.VerifiablePresentation{
+				Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+				Type:                 []string{"VerifiablePresentation"},
+				Holder:               holder.ID,
+				VerifiableCredential: creds,
+			})
+			require.NoError(tt, err)
+			return string(signed)
+		}
+
+		degree := signCredential(tt, *issuer, "UniversityDegreeCredential")
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(degree), WithTrustRegistry(registry))
+		assert.NoError(tt, err)
+
+		license := signCredential(tt, *issuer, "DriversLicenseCredential")
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(degree, license), WithTrustRegistry(registry))
+		assert.ErrorIs(tt, err, ErrUntrustedIssuer)
+		assert.ErrorContains(tt, err, "verifying credential 1")
+
+		// the credential verifies without the registry
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(degree, license))
+		assert.NoError(tt, err)
+
+		_, _, _, err = VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation(degree), WithTrustRegistry(nil))
+		assert.ErrorContains(tt, err, "trust registry verification requires a registry")
+	})
+}
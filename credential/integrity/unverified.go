@@ -0,0 +1,25 @@
+package integrity
+
+// Unverified holds a value parsed from a token whose signature has not been verified, such as a credential a holder
+// wants to display or a verifier wants to inspect before deciding how to verify it. The value is only accessible with
+// Trust, so that code relying on an unverified value says so where it does.
+type Unverified[T any] struct {
+	value T
+}
+
+// Trust returns the unverified value. Callers acknowledge that nothing in the value can be trusted to come from the
+// party that appears to have signed it, as anyone could have made the token.
+func (u Unverified[T]) Trust() T {
+	return u.value
+}
+
+// ParseVerifiableCredentialFromJWTUnverified parses a credential JWT, in either serialization of JWS, WITHOUT
+// verifying its signature. The parsed credential must be trusted explicitly before it can be accessed; use
+// VerifyJWTCredential instead to verify a credential before relying on it.
+func ParseVerifiableCredentialFromJWTUnverified(token string) (Unverified[*ParsedCredential], error) {
+	parsed, err := ParseVerifiableCredential(token)
+	if err != nil {
+		return Unverified[*ParsedCredential]{}, err
+	}
+	return Unverified[*ParsedCredential]{value: parsed}, nil
+}
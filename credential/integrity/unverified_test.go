@@ -0,0 +1,53 @@
+package integrity
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestParseVerifiableCredentialFromJWTUnverified(t *testing.T) {
+	signer := getTestDIDKeySigner(t)
+	token := getTestJWTCredential(t, signer)
+
+	t.Run("parses as the deprecated parse does", func(tt *testing.T) {
+		parsed, err := ParseVerifiableCredentialFromJWTUnverified(token)
+		require.NoError(tt, err)
+
+		headers, jwtToken, cred, err := ParseVerifiableCredentialFromJWT(token)
+		require.NoError(tt, err)
+		trusted := parsed.Trust()
+		assert.Equal(tt, headers, trusted.Headers)
+		assert.Equal(tt, jwtToken, trusted.Token)
+		assert.Equal(tt, cred, trusted.Credential)
+		assert.Equal(tt, token, trusted.Raw)
+		assert.Equal(tt, signer.ID, trusted.Issuer())
+	})
+
+	t.Run("the signature is not verified", func(tt *testing.T) {
+		parts := strings.Split(token, ".")
+		require.Len(tt, parts, 3)
+		forged := parts[0] + "." + parts[1] + "." + strings.Repeat("A", len(parts[2]))
+
+		parsed, err := ParseVerifiableCredentialFromJWTUnverified(forged)
+		require.NoError(tt, err)
+		assert.Equal(tt, signer.ID, parsed.Trust().Issuer())
+
+		resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+		require.NoError(tt, err)
+		_, err = VerifyJWTCredential(context.Background(), forged, resolver)
+		assert.Error(tt, err)
+	})
+
+	t.Run("malformed tokens", func(tt *testing.T) {
+		parsed, err := ParseVerifiableCredentialFromJWTUnverified("not a token")
+		assert.Error(tt, err)
+		assert.Nil(tt, parsed.Trust())
+	})
+}
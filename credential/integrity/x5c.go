@@ -0,0 +1,99 @@
+package integrity
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/cert"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+// VerifyCredentialX5C verifies a credential JWT signed by the key of the leaf certificate of its x5c header, as used
+// by issuers identified by X.509 certificates rather than DIDs, and parses it. The chain must lead to one of the
+// roots, the certificates following the leaf being intermediates. The issuer of the credential is the subject of the
+// leaf certificate: an iss claim, and the issuer of the vc claim, must be the subject's distinguished name or one of its URIs, and is set to the
+// distinguished name when absent. The WithClock and WithClockSkew options change the time the chain and the claims of
+// the credential are validated at.
+func VerifyCredentialX5C(token string, roots *x509.CertPool, opts ...VerifyOption) (jws.Headers, jwt.Token, *credential.VerifiableCredential, error) {
+	if roots == nil {
+		return nil, nil, nil, errors.New("roots cannot be empty")
+	}
+	timing, err := credentialTimeValidation(opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(token))
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "getting JWT headers")
+	}
+	chain := headers.X509CertChain()
+	if chain == nil || chain.Len() == 0 {
+		return nil, nil, nil, errors.New("token has no x5c header")
+	}
+	certs := make([]*x509.Certificate, 0, chain.Len())
+	for i := 0; i < chain.Len(); i++ {
+		encoded, _ := chain.Get(i)
+		certificate, err := cert.Parse(encoded)
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "parsing certificate %d of x5c header", i)
+		}
+		certs = append(certs, certificate)
+	}
+	leaf := certs[0]
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range certs[1:] {
+		intermediates.AddCert(intermediate)
+	}
+	if _, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   timing.now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: verifying certificate chain: %w", ErrUntrustedIssuer, err)
+	}
+
+	_, parsed, _, err := parseCredentialJWT(token)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "parsing credential token")
+	}
+	issuer := leaf.Subject.String()
+	if iss := parsed.Issuer(); iss != "" && !isCertificateSubject(leaf, iss) {
+		return nil, nil, nil, errors.Wrapf(ErrIssuerMismatch, "issuer<%s> is not the subject<%s> of the certificate", iss, issuer)
+	}
+
+	verifier, err := jwx.NewJWXVerifier(issuer, nil, leaf.PublicKey)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "constructing verifier from certificate")
+	}
+	verifiedHeaders, verifiedToken, cred, err := VerifyVerifiableCredentialJWT(*verifier, token, opts...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// the vc issuer is kept when there is no iss claim, so it must name the subject too
+	if credIssuer := cred.IssuerID(); credIssuer == "" {
+		cred.Issuer = issuer
+	} else if !isCertificateSubject(leaf, credIssuer) {
+		return nil, nil, nil, errors.Wrapf(ErrIssuerMismatch, "issuer<%s> is not the subject<%s> of the certificate", credIssuer, issuer)
+	}
+	return verifiedHeaders, verifiedToken, cred, nil
+}
+
+// isCertificateSubject reports whether the id names the subject of the certificate, by its distinguished name or one
+// of its URIs
+func isCertificateSubject(certificate *x509.Certificate, id string) bool {
+	if id == certificate.Subject.String() {
+		return true
+	}
+	for _, uri := range certificate.URIs {
+		if id == uri.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package integrity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/cert"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	der         []byte
+	key         *ecdsa.PrivateKey
+}
+
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	if template.NotBefore.IsZero() {
+		template.NotBefore = time.Now().Add(-time.Hour)
+		template.NotAfter = time.Now().Add(time.Hour)
+	}
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.certificate, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return testCertificate{certificate: certificate, der: der, key: key}
+}
+
+func newTestCA(t *testing.T, name string, parent *testCertificate) testCertificate {
+	return newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, parent)
+}
+
+// signTestX5CToken signs the claims of a credential JWT with the key, with the chain of certificates as its x5c header
+func signTestX5CToken(t *testing.T, token jwt.Token, key *ecdsa.PrivateKey, chain ...testCertificate) string {
+	headers := jws.NewHeaders()
+	if len(chain) > 0 {
+		var x5c cert.Chain
+		for _, c := range chain {
+			encoded, err := cert.EncodeBase64(c.der)
+			require.NoError(t, err)
+			require.NoError(t, x5c.Add(encoded))
+		}
+		require.NoError(t, headers.Set(jws.X509CertChainKey, &x5c))
+	}
+	require.NoError(t, headers.Set(jws.TypeKey, VCJWTType))
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.ES256, key, jws.WithProtectedHeaders(headers)))
+	require.NoError(t, err)
+	return string(signed)
+}
+
+func TestVerifyCredentialX5C(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", &root)
+	issuerURI, err := url.Parse("https://issuer.example.com")
+	require.NoError(t, err)
+	leaf := newTestCertificate(t, &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "Example Issuer", Organization: []string{"Example Corp"}},
+		URIs:     []*url.URL{issuerURI},
+		KeyUsage: x509.KeyUsageDigitalSignature,
+	}, &intermediate)
+	roots := x509.NewCertPool()
+	roots.AddCert(root.certificate)
+
+	sign := func(tt *testing.T, issuer string, key *ecdsa.PrivateKey, chain ...testCertificate) string {
+		token, err := JWTClaimSetFromVC(credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            issuer,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		return signTestX5CToken(tt, token, key, chain...)
+	}
+
+	t.Run("issuer from the certificate subject", func(tt *testing.T) {
+		_, _, cred, err := VerifyCredentialX5C(sign(tt, "", leaf.key, leaf, intermediate), roots)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "CN=Example Issuer,O=Example Corp", cred.Issuer)
+
+		_, _, cred, err = VerifyCredentialX5C(sign(tt, "https://issuer.example.com", leaf.key, leaf, intermediate), roots)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://issuer.example.com", cred.Issuer)
+	})
+
+	t.Run("untrusted chain", func(tt *testing.T) {
+		otherRoot := newTestCA(tt, "Other Root", nil)
+		otherRoots := x509.NewCertPool()
+		otherRoots.AddCert(otherRoot.certificate)
+		_, _, _, err := VerifyCredentialX5C(sign(tt, "", leaf.key, leaf, intermediate), otherRoots)
+		assert.ErrorIs(tt, err, ErrUntrustedIssuer)
+
+		// without the intermediate the chain does not lead to the root
+		_, _, _, err = VerifyCredentialX5C(sign(tt, "", leaf.key, leaf), roots)
+		assert.ErrorIs(tt, err, ErrUntrustedIssuer)
+
+		// nor does it once the leaf has expired
+		_, _, _, err = VerifyCredentialX5C(sign(tt, "", leaf.key, leaf, intermediate), roots,
+			WithClock(fixedClock(time.Now().Add(2*time.Hour))))
+		assert.ErrorIs(tt, err, ErrUntrustedIssuer)
+	})
+
+	t.Run("signed by another key", func(tt *testing.T) {
+		_, _, _, err := VerifyCredentialX5C(sign(tt, "", intermediate.key, leaf, intermediate), roots)
+		assert.ErrorIs(tt, err, ErrSignatureInvalid)
+	})
+
+	t.Run("issuer that is not the certificate subject", func(tt *testing.T) {
+		_, _, _, err := VerifyCredentialX5C(sign(tt, "did:example:123", leaf.key, leaf, intermediate), roots)
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+
+		// without an iss claim, the issuer of the vc claim is checked in its place
+		token := jwt.New()
+		require.NoError(tt, token.Set(VCJWTProperty, map[string]any{
+			"@context":          []any{"https://www.w3.org/2018/credentials/v1"},
+			"type":              []any{"VerifiableCredential"},
+			"issuer":            "CN=Some Bank",
+			"issuanceDate":      "2021-01-01T19:23:24Z",
+			"credentialSubject": map[string]any{"id": "did:example:456"},
+		}))
+		_, _, _, err = VerifyCredentialX5C(signTestX5CToken(tt, token, leaf.key, leaf, intermediate), roots)
+		assert.ErrorIs(tt, err, ErrIssuerMismatch)
+		assert.ErrorContains(tt, err, "issuer<CN=Some Bank> is not the subject<CN=Example Issuer,O=Example Corp> of the certificate")
+	})
+
+	t.Run("no x5c header", func(tt *testing.T) {
+		_, _, _, err := VerifyCredentialX5C(sign(tt, "", leaf.key), roots)
+		assert.ErrorContains(tt, err, "token has no x5c header")
+
+		_, _, _, err = VerifyCredentialX5C(sign(tt, "", leaf.key, leaf), nil)
+		assert.ErrorContains(tt, err, "roots cannot be empty")
+	})
+}
@@ -1,7 +1,14 @@
 package credential
 
 import (
+	"bytes"
+	"math"
 	"reflect"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/util"
@@ -17,19 +24,295 @@ type VerifiableCredential struct {
 	Type any `json:"type" validate:"required"`
 	// either a URI or an object containing an `id` property.
 	Issuer any `json:"issuer,omitempty" validate:"required"`
+	// Holder is the party holding the credential when it is not the subject, such as the parent holding a credential
+	// about their child, which presentations of the credential may be bound to rather than the subject
+	Holder string `json:"holder,omitempty"`
 	// https://www.w3.org/TR/xmlschema11-2/#dateTimes
 	IssuanceDate     string `json:"issuanceDate,omitempty" validate:"required"`
 	ExpirationDate   string `json:"expirationDate,omitempty"`
 	CredentialStatus any    `json:"credentialStatus,omitempty" validate:"omitempty"`
 	// This is where the subject's ID *may* be present
-	CredentialSubject CredentialSubject `json:"credentialSubject" validate:"required"`
-	CredentialSchema  *CredentialSchema `json:"credentialSchema,omitempty" validate:"omitempty"`
-	RefreshService    *RefreshService   `json:"refreshService,omitempty" validate:"omitempty"`
-	TermsOfUse        []TermsOfUse      `json:"termsOfUse,omitempty" validate:"omitempty,dive"`
-	Evidence          []any             `json:"evidence,omitempty" validate:"omitempty"`
+	CredentialSubject CredentialSubject `json:"credentialSubject" validate:"required_without=CredentialSubjects"`
+	// CredentialSubjects is set instead of CredentialSubject when the credential has an array of subjects
+	// https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#credential-subject
+	CredentialSubjects []CredentialSubject `json:"-" validate:"omitempty"`
+	CredentialSchema   *CredentialSchema   `json:"credentialSchema,omitempty" validate:"omitempty"`
+	RefreshService     *RefreshService     `json:"refreshService,omitempty" validate:"omitempty"`
+	TermsOfUse         []TermsOfUse        `json:"termsOfUse,omitempty" validate:"omitempty,dive"`
+	Evidence           []any               `json:"evidence,omitempty" validate:"omitempty"`
 	// For embedded proof support
 	// Proof is a digital signature over a credential https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#proofs-signatures
 	Proof *crypto.Proof `json:"proof,omitempty"`
+	// Extensions holds any top-level properties not modeled above (e.g. from additional contexts) so that they
+	// survive unmarshalling and re-marshalling the credential
+	Extensions map[string]any `json:"-"`
+}
+
+const (
+	credentialSubjectProperty    = "credentialSubject"
+	verifiableCredentialProperty = "verifiableCredential"
+)
+
+// verifiableCredential has the same fields as VerifiableCredential without its JSON methods
+type verifiableCredential VerifiableCredential
+
+// verifiableCredentialProperties is the set of JSON property names modeled by VerifiableCredential
+var verifiableCredentialProperties = jsonPropertyNames(reflect.TypeOf(VerifiableCredential{}))
+
+func (v VerifiableCredential) MarshalJSON() ([]byte, error) {
+	credBytes, err := json.Marshal(verifiableCredential(v))
+	if err != nil {
+		return nil, err
+	}
+	if len(v.Extensions) == 0 && v.CredentialSubjects == nil {
+		return credBytes, nil
+	}
+	var credJSON map[string]any
+	if err = json.Unmarshal(credBytes, &credJSON); err != nil {
+		return nil, err
+	}
+	if v.CredentialSubjects != nil {
+		credJSON[credentialSubjectProperty] = v.CredentialSubjects
+	}
+	for k, val := range v.Extensions {
+		// modeled properties always take precedence over extensions
+		if _, ok := verifiableCredentialProperties[k]; !ok {
+			credJSON[k] = val
+		}
+	}
+	return json.Marshal(credJSON)
+}
+
+func (v *VerifiableCredential) UnmarshalJSON(data []byte) error {
+	var credJSON map[string]json.RawMessage
+	if err := json.Unmarshal(data, &credJSON); err != nil {
+		return err
+	}
+
+	// an array of subjects cannot be held by CredentialSubject, so it is decoded separately
+	var subjects []CredentialSubject
+	changed := false
+	if subject, ok := credJSON[credentialSubjectProperty]; ok && bytes.HasPrefix(bytes.TrimSpace(subject), []byte("[")) {
+		if err := json.Unmarshal(subject, &subjects); err != nil {
+			return err
+		}
+		delete(credJSON, credentialSubjectProperty)
+		changed = true
+	}
+	// dates given as epoch seconds, as some issuers do, are normalized to the RFC3339 strings the fields hold
+	for _, property := range credentialDateProperties {
+		var val any
+		if raw, ok := credJSON[property]; !ok || json.Unmarshal(raw, &val) != nil {
+			continue
+		}
+		if seconds, ok := val.(float64); ok {
+			date, err := json.Marshal(epochDate(seconds))
+			if err != nil {
+				return err
+			}
+			credJSON[property] = date
+			changed = true
+		}
+	}
+	if changed {
+		var err error
+		if data, err = json.Marshal(credJSON); err != nil {
+			return err
+		}
+	}
+
+	var cred verifiableCredential
+	if err := json.Unmarshal(data, &cred); err != nil {
+		return err
+	}
+	cred.CredentialSubjects = subjects
+	for k, raw := range credJSON {
+		if _, ok := verifiableCredentialProperties[k]; ok {
+			continue
+		}
+		var val any
+		if err := json.Unmarshal(raw, &val); err != nil {
+			return err
+		}
+		if cred.Extensions == nil {
+			cred.Extensions = make(map[string]any)
+		}
+		cred.Extensions[k] = val
+	}
+	*v = VerifiableCredential(cred)
+	return nil
+}
+
+// UnmarshalJSONMap sets the credential from a decoded JSON object, such as the vc claim of a JWT, as UnmarshalJSON does
+// from the object's encoding. An object holding only the values JSON decodes to is converted directly rather than
+// encoded and decoded again, with its values copied so that the credential shares none of them with the object.
+func (v *VerifiableCredential) UnmarshalJSONMap(credJSON map[string]any) error {
+	if cred, ok := credentialFromJSONMap(credJSON); ok {
+		*v = *cred
+		return nil
+	}
+	data, err := json.Marshal(credJSON)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalJSON(data)
+}
+
+// foldedCredentialProperties holds the lowercased JSON property names modeled by VerifiableCredential, which the
+// decoder also matches properties of another case to
+var foldedCredentialProperties = foldedPropertyNames(verifiableCredentialProperties)
+
+// credentialFromJSONMap converts a decoded JSON object to a credential, reporting false for objects it cannot convert
+// exactly as UnmarshalJSON would: those with values of types JSON does not decode to or of the wrong type for their
+// property, and those with properties that are decoded into structs or matched to a modeled property by case folding
+func credentialFromJSONMap(credJSON map[string]any) (*VerifiableCredential, bool) {
+	var cred VerifiableCredential
+	for k, val := range credJSON {
+		copied, ok := copyJSONValue(val)
+		if !ok {
+			return nil, false
+		}
+		switch k {
+		case "@context":
+			cred.Context = copied
+		case "id":
+			if cred.ID, ok = copied.(string); !ok {
+				return nil, false
+			}
+		case "type":
+			cred.Type = copied
+		case "issuer":
+			cred.Issuer = copied
+		case "holder":
+			if cred.Holder, ok = copied.(string); !ok {
+				return nil, false
+			}
+		case "issuanceDate":
+			if cred.IssuanceDate, ok = dateValue(copied); !ok {
+				return nil, false
+			}
+		case "expirationDate":
+			if cred.ExpirationDate, ok = dateValue(copied); !ok {
+				return nil, false
+			}
+		case "credentialStatus":
+			cred.CredentialStatus = copied
+		case credentialSubjectProperty:
+			switch subject := copied.(type) {
+			case nil:
+			case map[string]any:
+				cred.CredentialSubject = subject
+			case []any:
+				cred.CredentialSubjects = make([]CredentialSubject, 0, len(subject))
+				for _, s := range subject {
+					subjectMap, isMap := s.(map[string]any)
+					if !isMap && s != nil {
+						return nil, false
+					}
+					cred.CredentialSubjects = append(cred.CredentialSubjects, subjectMap)
+				}
+			default:
+				return nil, false
+			}
+		case "evidence":
+			switch evidence := copied.(type) {
+			case nil:
+			case []any:
+				cred.Evidence = evidence
+			default:
+				return nil, false
+			}
+		case "proof":
+			if copied != nil {
+				proof := crypto.Proof(copied)
+				cred.Proof = &proof
+			}
+		default:
+			if _, folded := foldedCredentialProperties[strings.ToLower(k)]; folded {
+				return nil, false
+			}
+			if cred.Extensions == nil {
+				cred.Extensions = make(map[string]any)
+			}
+			cred.Extensions[k] = copied
+		}
+	}
+	return &cred, true
+}
+
+// credentialDateProperties are the date properties of a credential, which may be given as RFC3339 strings or as
+// numeric epoch seconds
+var credentialDateProperties = []string{"issuanceDate", "expirationDate"}
+
+// epochDate returns the RFC3339 date of the epoch seconds, in UTC
+func epochDate(seconds float64) string {
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second))).UTC().Format(time.RFC3339)
+}
+
+// dateValue returns the date of a decoded JSON date property, either a string or epoch seconds
+func dateValue(val any) (string, bool) {
+	switch typed := val.(type) {
+	case string:
+		return typed, true
+	case float64:
+		return epochDate(typed), true
+	}
+	return "", false
+}
+
+// copyJSONValue deep copies a value made of the types JSON decodes to, reporting false for a value of any other type
+func copyJSONValue(val any) (any, bool) {
+	switch typed := val.(type) {
+	case nil, string, float64, bool:
+		return typed, true
+	case map[string]any:
+		copied := make(map[string]any, len(typed))
+		for k, v := range typed {
+			c, ok := copyJSONValue(v)
+			if !ok {
+				return nil, false
+			}
+			copied[k] = c
+		}
+		return copied, true
+	case []any:
+		copied := make([]any, len(typed))
+		for i, v := range typed {
+			c, ok := copyJSONValue(v)
+			if !ok {
+				return nil, false
+			}
+			copied[i] = c
+		}
+		return copied, true
+	default:
+		return nil, false
+	}
+}
+
+func foldedPropertyNames(names map[string]struct{}) map[string]struct{} {
+	folded := make(map[string]struct{}, len(names))
+	for name := range names {
+		folded[strings.ToLower(name)] = struct{}{}
+	}
+	return folded
+}
+
+// jsonPropertyNames returns the JSON property names of the exported fields of a struct type
+func jsonPropertyNames(t reflect.Type) map[string]struct{} {
+	names := make(map[string]struct{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "-" || !t.Field(i).IsExported() {
+			continue
+		}
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		names[name] = struct{}{}
+	}
+	return names
 }
 
 func (v *VerifiableCredential) GetProof() *crypto.Proof {
@@ -48,11 +331,9 @@ type DefaultCredentialStatus struct {
 
 type CredentialSubject map[string]any
 
+// GetID returns the id of the subject, which may be any URI, such as a DID or a urn, or "" if it has no string id
 func (cs CredentialSubject) GetID() string {
-	id := ""
-	if gotID, ok := cs[VerifiableCredentialIDProperty]; ok {
-		id = gotID.(string)
-	}
+	id, _ := cs[VerifiableCredentialIDProperty].(string)
 	return id
 }
 
@@ -94,28 +375,64 @@ type Prohibition struct {
 	Action   []string `json:"action,omitempty"`
 }
 
+// IsEmpty returns true if none of the modeled properties of the credential are set; extensions alone do not make
+// a credential
 func (v *VerifiableCredential) IsEmpty() bool {
 	if v == nil {
 		return true
 	}
-	return reflect.DeepEqual(v, &VerifiableCredential{})
+	modeled := *v
+	modeled.Extensions = nil
+	return reflect.DeepEqual(modeled, VerifiableCredential{})
 }
 
 func (v *VerifiableCredential) IsValid() error {
 	return util.NewValidator().Struct(v)
 }
 
+// IssuerID returns the id of the issuer, or "" if it has none, see GetIssuerID
 func (v *VerifiableCredential) IssuerID() string {
+	id, _ := v.GetIssuerID()
+	return id
+}
+
+// GetIssuerID returns the id of the issuer, which may be a URI, such as a DID, or an object with an id property
+// holding metadata of the issuer https://www.w3.org/TR/vc-data-model/#issuer. An error is returned if the issuer is
+// missing or is an object without an id.
+func (v *VerifiableCredential) GetIssuerID() (string, error) {
 	switch typedIssuer := v.Issuer.(type) {
+	case nil:
+		return "", errors.New("credential has no issuer")
 	case string:
-		return typedIssuer
+		if typedIssuer == "" {
+			return "", errors.New("credential has no issuer")
+		}
+		return typedIssuer, nil
 	case []string:
-		return typedIssuer[0]
-	case map[string]any:
-		idValue := typedIssuer["id"]
-		return idValue.(string)
+		if len(typedIssuer) == 0 || typedIssuer[0] == "" {
+			return "", errors.New("credential has no issuer")
+		}
+		return typedIssuer[0], nil
+	default:
+		issuerMap, err := util.ToJSONMap(typedIssuer)
+		if err != nil {
+			return "", errors.Wrapf(err, "issuer of type<%T> is neither a URI nor an object", typedIssuer)
+		}
+		id, ok := issuerMap[VerifiableCredentialIDProperty].(string)
+		if !ok || id == "" {
+			return "", errors.New("issuer object has no id")
+		}
+		return id, nil
+	}
+}
+
+// IsIssuerObject returns whether the issuer is an object, rather than the URI of the issuer
+func (v *VerifiableCredential) IsIssuerObject() bool {
+	switch v.Issuer.(type) {
+	case nil, string, []string:
+		return false
 	}
-	return ""
+	return true
 }
 
 // VerifiablePresentation https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#presentations-0
@@ -128,8 +445,40 @@ type VerifiablePresentation struct {
 	// an optional field as a part of https://identity.foundation/presentation-exchange/#embed-targets
 	PresentationSubmission any `json:"presentation_submission,omitempty"`
 	// Verifiable credential could be our object model, a JWT, or any other valid credential representation
-	VerifiableCredential []any         `json:"verifiableCredential,omitempty"`
-	Proof                *crypto.Proof `json:"proof,omitempty"`
+	VerifiableCredential []any `json:"verifiableCredential,omitempty"`
+	// CredentialStatus is the status of the presentation itself, as when presentations are session tokens that can
+	// be revoked https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#status
+	CredentialStatus any           `json:"credentialStatus,omitempty"`
+	Proof            *crypto.Proof `json:"proof,omitempty"`
+}
+
+// verifiablePresentation has the same fields as VerifiablePresentation without its JSON methods
+type verifiablePresentation VerifiablePresentation
+
+// UnmarshalJSON accepts a single credential as the verifiableCredential property as well as an array of them, as the
+// data model allows, normalizing it to a slice https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#presentations-0
+func (v *VerifiablePresentation) UnmarshalJSON(data []byte) error {
+	var presJSON map[string]json.RawMessage
+	if err := json.Unmarshal(data, &presJSON); err != nil {
+		return err
+	}
+
+	// a single credential is wrapped in an array so that it decodes into VerifiableCredential
+	creds, ok := presJSON[verifiableCredentialProperty]
+	if creds = bytes.TrimSpace(creds); ok && !bytes.HasPrefix(creds, []byte("[")) && !bytes.Equal(creds, []byte("null")) {
+		presJSON[verifiableCredentialProperty] = append(append([]byte("["), creds...), ']')
+		var err error
+		if data, err = json.Marshal(presJSON); err != nil {
+			return err
+		}
+	}
+
+	var pres verifiablePresentation
+	if err := json.Unmarshal(data, &pres); err != nil {
+		return err
+	}
+	*v = VerifiablePresentation(pres)
+	return nil
 }
 
 func (v *VerifiablePresentation) IsEmpty() bool {
@@ -7,6 +7,7 @@ import (
 	"github.com/goccy/go-json"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // These test vectors are taken from the vc-data-model spec example
@@ -110,3 +111,299 @@ func TestVerifiableCredential_IssuerID(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifiableCredential_GetIssuerID(t *testing.T) {
+	t.Run("string and object issuers", func(tt *testing.T) {
+		v := VerifiableCredential{Issuer: "did:example:issuer"}
+		id, err := v.GetIssuerID()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:issuer", id)
+		assert.False(tt, v.IsIssuerObject())
+
+		v.Issuer = map[string]any{"id": "did:example:issuer", "name": "Example"}
+		id, err = v.GetIssuerID()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:issuer", id)
+		assert.True(tt, v.IsIssuerObject())
+	})
+
+	t.Run("issuer object without an id", func(tt *testing.T) {
+		v := VerifiableCredential{Issuer: map[string]any{"name": "Example"}}
+		_, err := v.GetIssuerID()
+		assert.ErrorContains(tt, err, "issuer object has no id")
+		assert.Empty(tt, v.IssuerID())
+	})
+
+	t.Run("no issuer", func(tt *testing.T) {
+		var v VerifiableCredential
+		_, err := v.GetIssuerID()
+		assert.Error(tt, err)
+	})
+}
+
+func TestVerifiableCredentialEpochDates(t *testing.T) {
+	t.Run("numeric dates are normalized", func(tt *testing.T) {
+		var cred VerifiableCredential
+		err := json.Unmarshal([]byte(`{"@context":"https://www.w3.org/2018/credentials/v1","type":"VerifiableCredential",`+
+			`"issuanceDate":1609529004,"expirationDate":2556213804,"credentialSubject":{"id":"did:example:456"}}`), &cred)
+		require.NoError(tt, err)
+		assert.Equal(tt, "2021-01-01T19:23:24Z", cred.IssuanceDate)
+		assert.Equal(tt, "2051-01-01T19:23:24Z", cred.ExpirationDate)
+		assert.Empty(tt, cred.Extensions)
+	})
+
+	t.Run("string and missing dates are kept", func(tt *testing.T) {
+		var cred VerifiableCredential
+		err := json.Unmarshal([]byte(`{"type":"VerifiableCredential","issuanceDate":"2021-01-01T19:23:24Z","expirationDate":null}`), &cred)
+		require.NoError(tt, err)
+		assert.Equal(tt, "2021-01-01T19:23:24Z", cred.IssuanceDate)
+		assert.Empty(tt, cred.ExpirationDate)
+	})
+
+	t.Run("from a JSON map", func(tt *testing.T) {
+		var cred VerifiableCredential
+		require.NoError(tt, cred.UnmarshalJSONMap(map[string]any{"type": "VerifiableCredential", "issuanceDate": float64(1609529004)}))
+		assert.Equal(tt, "2021-01-01T19:23:24Z", cred.IssuanceDate)
+	})
+
+	t.Run("other types still fail", func(tt *testing.T) {
+		var cred VerifiableCredential
+		assert.Error(tt, json.Unmarshal([]byte(`{"type":"VerifiableCredential","issuanceDate":true}`), &cred))
+	})
+}
+
+func TestVerifiableCredentialExtensions(t *testing.T) {
+	credJSON := `{
+		"@context": ["https://www.w3.org/2018/credentials/v1"],
+		"type": ["VerifiableCredential"],
+		"issuer": "did:example:123",
+		"issuanceDate": "2021-01-01T19:23:24Z",
+		"credentialSubject": {"id": "did:example:456"},
+		"renderMethod": {"type": "SvgRenderingTemplate2023", "id": "https://example.com/template.svg"},
+		"name": "Example Credential"
+	}`
+
+	var vc VerifiableCredential
+	assert.NoError(t, json.Unmarshal([]byte(credJSON), &vc))
+	assert.Equal(t, "did:example:123", vc.Issuer)
+	assert.Len(t, vc.Extensions, 2)
+	assert.Equal(t, "Example Credential", vc.Extensions["name"])
+
+	vcBytes, err := json.Marshal(vc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, credJSON, string(vcBytes))
+
+	// modeled properties take precedence over extensions with the same name
+	vc.Extensions["issuer"] = "did:example:789"
+	vcBytes, err = json.Marshal(vc)
+	assert.NoError(t, err)
+	assert.Contains(t, string(vcBytes), `"issuer":"did:example:123"`)
+
+	// extensions alone do not make a credential
+	assert.True(t, (&VerifiableCredential{Extensions: map[string]any{"name": "test"}}).IsEmpty())
+}
+
+func TestVerifiableCredentialSubjects(t *testing.T) {
+	credJSON := `{
+		"@context": ["https://www.w3.org/2018/credentials/v1"],
+		"type": ["VerifiableCredential"],
+		"issuer": "did:example:123",
+		"issuanceDate": "2021-01-01T19:23:24Z",
+		"credentialSubject": [{"id": "did:example:456"}, {"id": "did:example:789"}]
+	}`
+
+	var vc VerifiableCredential
+	assert.NoError(t, json.Unmarshal([]byte(credJSON), &vc))
+	assert.Nil(t, vc.CredentialSubject)
+	assert.Len(t, vc.CredentialSubjects, 2)
+	assert.Equal(t, "did:example:789", vc.CredentialSubjects[1].GetID())
+	assert.Empty(t, vc.Extensions)
+	assert.NoError(t, vc.IsValid())
+
+	vcBytes, err := json.Marshal(vc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, credJSON, string(vcBytes))
+
+	// a credential needs at least one form of subject
+	vc.CredentialSubjects = nil
+	assert.Error(t, vc.IsValid())
+}
+
+func TestVerifiableCredentialUnmarshalJSONMap(t *testing.T) {
+	corpus := map[string]string{
+		"extensions and an object issuer": `{
+			"@context": ["https://www.w3.org/2018/credentials/v1", {"@vocab": "https://example.com/#"}],
+			"id": "urn:uuid:123",
+			"type": ["VerifiableCredential", "ExampleCredential"],
+			"issuer": {"id": "did:example:123", "name": "Example"},
+			"issuanceDate": "2021-01-01T19:23:24Z",
+			"expirationDate": "2031-01-01T19:23:24Z",
+			"credentialStatus": {"id": "https://example.com/status/1#94", "type": "StatusList2021Entry"},
+			"credentialSubject": {"id": "did:example:456", "age": 42, "height": 1.85, "verified": true, "nickname": null},
+			"evidence": [{"type": ["DocumentVerification"], "verifier": "https://example.edu/issuers/14"}],
+			"proof": {"type": "JsonWebSignature2020", "jws": "eyJ..."},
+			"name": "Example Credential",
+			"renderMethod": [{"type": "SvgRenderingTemplate2023"}]
+		}`,
+		"array of subjects": `{
+			"@context": "https://www.w3.org/2018/credentials/v1",
+			"type": "VerifiableCredential",
+			"issuer": "did:example:123",
+			"credentialSubject": [{"id": "did:example:456"}, null, {"nested": {"values": [1, "two", [3]]}}]
+		}`,
+		"empty array of subjects": `{"type": ["VerifiableCredential"], "credentialSubject": []}`,
+		"null values":             `{"@context": null, "type": null, "credentialSubject": null, "evidence": null, "proof": null}`,
+		"struct properties": `{
+			"type": ["VerifiableCredential"],
+			"credentialSubject": {"id": "did:example:456"},
+			"credentialSchema": {"id": "https://example.com/schema", "type": "JsonSchema"},
+			"refreshService": {"id": "https://example.com/refresh", "type": "ManualRefreshService2018"},
+			"termsOfUse": [{"type": "IssuerPolicy", "prohibition": [{"action": ["Archival"]}]}]
+		}`,
+		"property in another case": `{"type": ["VerifiableCredential"], "Issuer": "did:example:123", "ID": "urn:uuid:123"}`,
+		"null string values":       `{"id": null, "issuanceDate": null}`,
+	}
+	for _, tv := range vcTestVectors {
+		gotTestVector, err := getTestVector(tv)
+		assert.NoError(t, err)
+		corpus[tv] = gotTestVector
+	}
+
+	for name, credJSON := range corpus {
+		t.Run(name, func(tt *testing.T) {
+			var want VerifiableCredential
+			require.NoError(tt, json.Unmarshal([]byte(credJSON), &want))
+
+			var credMap map[string]any
+			require.NoError(tt, json.Unmarshal([]byte(credJSON), &credMap))
+			var got VerifiableCredential
+			require.NoError(tt, got.UnmarshalJSONMap(credMap))
+			assert.Equal(tt, want, got)
+
+			wantBytes, err := json.Marshal(want)
+			require.NoError(tt, err)
+			gotBytes, err := json.Marshal(got)
+			require.NoError(tt, err)
+			assert.Equal(tt, string(wantBytes), string(gotBytes))
+		})
+	}
+
+	t.Run("objects converted directly", func(tt *testing.T) {
+		for name, direct := range map[string]bool{
+			"extensions and an object issuer": true,
+			"array of subjects":               true,
+			"null values":                     true,
+			VCTestVector1:                     true,
+			"struct properties":               false,
+			"property in another case":        false,
+			"null string values":              false,
+		} {
+			var credMap map[string]any
+			require.NoError(tt, json.Unmarshal([]byte(corpus[name]), &credMap))
+			_, ok := credentialFromJSONMap(credMap)
+			assert.Equal(tt, direct, ok, name)
+		}
+	})
+
+	t.Run("values are copied", func(tt *testing.T) {
+		credMap := map[string]any{
+			"type":              []any{"VerifiableCredential"},
+			"credentialSubject": map[string]any{"id": "did:example:456"},
+		}
+		var cred VerifiableCredential
+		require.NoError(tt, cred.UnmarshalJSONMap(credMap))
+		cred.CredentialSubject["id"] = "did:example:789"
+		cred.Type.([]any)[0] = "Changed"
+		assert.Equal(tt, map[string]any{"id": "did:example:456"}, credMap["credentialSubject"])
+		assert.Equal(tt, []any{"VerifiableCredential"}, credMap["type"])
+	})
+
+	t.Run("values that are not decoded JSON", func(tt *testing.T) {
+		credMap := map[string]any{
+			"type":              []string{"VerifiableCredential"},
+			"credentialSubject": CredentialSubject{"id": "did:example:456", "age": 42},
+		}
+		var cred VerifiableCredential
+		require.NoError(tt, cred.UnmarshalJSONMap(credMap))
+		assert.Equal(tt, []any{"VerifiableCredential"}, cred.Type)
+		assert.Equal(tt, CredentialSubject{"id": "did:example:456", "age": float64(42)}, cred.CredentialSubject)
+
+		assert.Error(tt, cred.UnmarshalJSONMap(map[string]any{"id": 123}))
+	})
+}
+
+func TestVerifiablePresentationCredentials(t *testing.T) {
+	presJSON := func(creds string) []byte {
+		pres := `{"@context": ["https://www.w3.org/2018/credentials/v1"], "type": ["VerifiablePresentation"], ` +
+			`"holder": "did:example:123"`
+		if creds != "" {
+			pres += `, "verifiableCredential": ` + creds
+		}
+		return []byte(pres + "}")
+	}
+
+	t.Run("single credential object", func(tt *testing.T) {
+		var vp VerifiablePresentation
+		require.NoError(tt, json.Unmarshal(presJSON(`{"id": "urn:uuid:1", "type": ["VerifiableCredential"]}`), &vp))
+		require.Len(tt, vp.VerifiableCredential, 1)
+		cred, ok := vp.VerifiableCredential[0].(map[string]any)
+		require.True(tt, ok)
+		assert.Equal(tt, "urn:uuid:1", cred["id"])
+		assert.Equal(tt, "did:example:123", vp.Holder)
+
+		// the credential is re-marshalled as an array
+		vpBytes, err := json.Marshal(vp)
+		require.NoError(tt, err)
+		assert.JSONEq(tt, string(presJSON(`[{"id": "urn:uuid:1", "type": ["VerifiableCredential"]}]`)), string(vpBytes))
+	})
+
+	t.Run("single credential JWT", func(tt *testing.T) {
+		var vp VerifiablePresentation
+		require.NoError(tt, json.Unmarshal(presJSON(`"header.payload.signature"`), &vp))
+		assert.Equal(tt, []any{"header.payload.signature"}, vp.VerifiableCredential)
+	})
+
+	t.Run("array of credentials", func(tt *testing.T) {
+		var vp VerifiablePresentation
+		require.NoError(tt, json.Unmarshal(presJSON(`["header.payload.signature", {"id": "urn:uuid:1"}]`), &vp))
+		require.Len(tt, vp.VerifiableCredential, 2)
+		assert.Equal(tt, "header.payload.signature", vp.VerifiableCredential[0])
+	})
+
+	t.Run("no credentials", func(tt *testing.T) {
+		for _, creds := range []string{"", "null", "[]"} {
+			var vp VerifiablePresentation
+			require.NoError(tt, json.Unmarshal(presJSON(creds), &vp))
+			assert.Empty(tt, vp.VerifiableCredential)
+			assert.Equal(tt, "did:example:123", vp.Holder)
+		}
+	})
+
+	t.Run("invalid credentials", func(tt *testing.T) {
+		var vp VerifiablePresentation
+		assert.Error(tt, json.Unmarshal(presJSON(`[{"id": }]`), &vp))
+	})
+}
+
+func TestCredentialSubjectGetID(t *testing.T) {
+	assert.Equal(t, "did:example:456", CredentialSubject{"id": "did:example:456"}.GetID())
+	assert.Equal(t, "urn:uuid:7d3f9c2e-5b1a-4c8e-9f0d-2a6b4e8c1d3f", CredentialSubject{"id": "urn:uuid:7d3f9c2e-5b1a-4c8e-9f0d-2a6b4e8c1d3f"}.GetID())
+	assert.Empty(t, CredentialSubject{"name": "JimBobertson"}.GetID())
+	assert.Empty(t, CredentialSubject{"id": map[string]any{"type": "Person"}}.GetID())
+}
+
+func TestVerifiableCredentialHolder(t *testing.T) {
+	credJSON := `{"type":"VerifiableCredential","holder":"did:example:parent","credentialSubject":{"id":"did:example:child"}}`
+	var cred VerifiableCredential
+	require.NoError(t, json.Unmarshal([]byte(credJSON), &cred))
+	assert.Equal(t, "did:example:parent", cred.Holder)
+	assert.Empty(t, cred.Extensions)
+
+	var fromMap VerifiableCredential
+	require.NoError(t, fromMap.UnmarshalJSONMap(map[string]any{"type": "VerifiableCredential", "holder": "did:example:parent"}))
+	assert.Equal(t, "did:example:parent", fromMap.Holder)
+
+	marshalled, err := json.Marshal(cred)
+	require.NoError(t, err)
+	assert.Contains(t, string(marshalled), `"holder":"did:example:parent"`)
+}
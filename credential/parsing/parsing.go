@@ -41,7 +41,12 @@ func ToCredential(genericCred any) (jws.Headers, jwt.Token, *credential.Verifiab
 		}
 
 		// next try it as a JWT
-		return integrity.ParseVerifiableCredentialFromJWT(typedCred)
+		parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(typedCred)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		jwtCred := parsed.Trust()
+		return jwtCred.Headers, jwtCred.Token, jwtCred.Credential, nil
 	case map[string]any:
 		// VC or JWTVC JSON
 		credMapBytes, err := json.Marshal(typedCred)
@@ -91,10 +96,11 @@ func ToCredentialJSONMap(genericCred any) (map[string]any, error) {
 		}
 
 		// next try it as a JWT
-		_, token, _, err := integrity.ParseVerifiableCredentialFromJWT(typedCred)
+		parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(typedCred)
 		if err != nil {
 			return nil, errors.Wrap(err, "parsing credential from JWT")
 		}
+		token := parsed.Trust().Token
 		// marshal it into a JSON map
 		tokenJSONBytes, err := json.Marshal(token)
 		if err != nil {
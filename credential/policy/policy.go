@@ -0,0 +1,415 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/credential/validation"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// Check names one of the checks a VerifyPolicy runs on a credential or presentation
+type Check string
+
+const (
+	// SignatureCheck verifies the signature of a credential or presentation; it is always run
+	SignatureCheck Check = "signature"
+	// ExpirationCheck validates the exp, nbf, and iat claims of JWTs and the expirationDate of credentials
+	ExpirationCheck Check = "expiration"
+	// StatusCheck checks that a credential with a credentialStatus property has not been revoked
+	StatusCheck Check = "status"
+	// SchemaCheck validates a credential against the JSON schema of the policy
+	SchemaCheck Check = "schema"
+	// TrustedIssuerCheck checks that the issuer of a credential is trusted
+	TrustedIssuerCheck Check = "trustedIssuer"
+	// HolderBindingCheck checks that the subject of each credential of a presentation is its holder
+	HolderBindingCheck Check = "holderBinding"
+	// TypeCheck checks that a credential has each of the types the policy requires
+	TypeCheck Check = "type"
+)
+
+// ErrRevoked is the error of a StatusCheck for a credential that has been revoked
+var ErrRevoked = errors.New("credential revoked")
+
+// StatusChecker tells whether a credential has been revoked, as status.StatusChecker does
+type StatusChecker interface {
+	IsRevoked(ctx context.Context, cred credential.VerifiableCredential) (bool, error)
+}
+
+// VerifyPolicy aggregates the checks to verify credentials and presentations with. The checks are configured by the
+// JSON properties of the policy, so that a service can load its verification rules from configuration; the clock,
+// status checker, and trust registry the checks use are set by the caller after loading the policy.
+type VerifyPolicy struct {
+	// Expiration runs the ExpirationCheck
+	Expiration bool `json:"expiration,omitempty"`
+	// ClockSkewSeconds is the difference between the clocks of signers and the verifier the ExpirationCheck tolerates
+	ClockSkewSeconds int `json:"clockSkewSeconds,omitempty"`
+	// Status runs the StatusCheck, which requires a StatusChecker
+	Status bool `json:"status,omitempty"`
+	// Schema is a Verifiable Credential JSON Schema credentials must be valid against, running the SchemaCheck
+	Schema json.RawMessage `json:"schema,omitempty"`
+	// TrustedIssuers are the DIDs of the issuers whose credentials are accepted, running the TrustedIssuerCheck
+	TrustedIssuers []string `json:"trustedIssuers,omitempty"`
+	// HolderBinding runs the HolderBindingCheck on the credentials of presentations
+	HolderBinding bool `json:"holderBinding,omitempty"`
+	// RequiredTypes are types every credential must have, running the TypeCheck
+	RequiredTypes []string `json:"requiredTypes,omitempty"`
+
+	// Clock is the clock the ExpirationCheck validates at, the system's if unset
+	Clock integrity.Clock `json:"-"`
+	// StatusChecker is used by the StatusCheck to tell whether credentials have been revoked
+	StatusChecker StatusChecker `json:"-"`
+	// TrustRegistry, if set, also runs the TrustedIssuerCheck, which then requires issuers to be accredited by the
+	// registry for each of the types of their credentials
+	TrustRegistry integrity.TrustRegistry `json:"-"`
+}
+
+// IsValid returns an error if the policy cannot be run, such as when it checks status without a StatusChecker
+func (p VerifyPolicy) IsValid() error {
+	if p.ClockSkewSeconds < 0 {
+		return fmt.Errorf("clock skew<%ds> cannot be negative", p.ClockSkewSeconds)
+	}
+	if p.Status && p.StatusChecker == nil {
+		return errors.New("status check requires a status checker")
+	}
+	if len(p.Schema) > 0 && !json.Valid(p.Schema) {
+		return errors.New("schema is not valid JSON")
+	}
+	return nil
+}
+
+// CheckResult is the outcome of a single check of a policy
+type CheckResult struct {
+	Check Check
+	// Err is why the check failed, and nil if it passed
+	Err error
+}
+
+// Passed returns whether the check passed
+func (c CheckResult) Passed() bool {
+	return c.Err == nil
+}
+
+// CredentialResult reports the checks a policy ran on a credential, which each passed or failed, so that a caller can
+// decide which failures to tolerate
+type CredentialResult struct {
+	// Credential is the credential checked, which is nil if it could not be parsed
+	Credential *credential.VerifiableCredential
+	Checks     []CheckResult
+}
+
+// Passed returns whether every check run on the credential passed
+func (r CredentialResult) Passed() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the checks run on the credential that failed
+func (r CredentialResult) Failed() []CheckResult {
+	return failedChecks(r.Checks)
+}
+
+// Result returns the result of the given check, and false if it was not run on the credential
+func (r CredentialResult) Result(check Check) (CheckResult, bool) {
+	return findCheck(r.Checks, check)
+}
+
+// PresentationResult reports the checks a policy ran on a presentation and on each of its credentials
+type PresentationResult struct {
+	// Presentation is the presentation checked
+	Presentation *credential.VerifiablePresentation
+	// Checks are those run on the presentation itself, its SignatureCheck and ExpirationCheck
+	Checks []CheckResult
+	// Credentials are the results of the credentials of the presentation, in order
+	Credentials []CredentialResult
+}
+
+// Passed returns whether every check run on the presentation and its credentials passed
+func (r PresentationResult) Passed() bool {
+	if len(r.Failed()) > 0 {
+		return false
+	}
+	for _, cred := range r.Credentials {
+		if !cred.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the checks run on the presentation itself that failed
+func (r PresentationResult) Failed() []CheckResult {
+	return failedChecks(r.Checks)
+}
+
+// Result returns the result of the given check of the presentation itself, and false if it was not run
+func (r PresentationResult) Result(check Check) (CheckResult, bool) {
+	return findCheck(r.Checks, check)
+}
+
+// VerifyCredentialWithPolicy runs the checks of the policy on a credential, which is a JWT or the JSON of a Data
+// Integrity credential, and reports the outcome of each. Failed checks do not stop the others from running; an error
+// is returned only if the policy is invalid or the credential cannot be parsed.
+func VerifyCredentialWithPolicy(ctx context.Context, token string, r resolution.Resolver, policy VerifyPolicy) (*CredentialResult, error) {
+	if r == nil {
+		return nil, errors.New("resolution cannot be empty")
+	}
+	if err := policy.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid policy")
+	}
+	cred, err := parseCredential(token)
+	if err != nil {
+		return nil, err
+	}
+	result := verifyCredential(ctx, token, cred, r, policy)
+	return &result, nil
+}
+
+// VerifyPresentationWithPolicy runs the checks of the policy on a presentation JWT and each of its credentials, and
+// reports the outcome of each. The presentation is verified with the key of its holder's resolved DID. A credential of
+// the presentation that cannot be parsed is reported as failing its SignatureCheck. An error is returned only if the
+// policy is invalid or the presentation cannot be parsed.
+func VerifyPresentationWithPolicy(ctx context.Context, token string, r resolution.Resolver, policy VerifyPolicy) (*PresentationResult, error) {
+	if r == nil {
+		return nil, errors.New("resolution cannot be empty")
+	}
+	if err := policy.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid policy")
+	}
+	_, _, pres, err := integrity.ParseVerifiablePresentationFromJWT(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing presentation")
+	}
+
+	result := PresentationResult{Presentation: pres}
+	opts := append(policy.verifyOptions(), integrity.WithoutCredentialVerification)
+	_, err = integrity.VerifyJWTPresentation(ctx, token, r, opts...)
+	result.Checks = signatureAndExpiration(err, policy)
+
+	for i, genericCred := range pres.VerifiableCredential {
+		credToken, err := credentialToken(genericCred)
+		if err != nil {
+			result.Credentials = append(result.Credentials, CredentialResult{
+				Checks: []CheckResult{{Check: SignatureCheck, Err: errors.Wrapf(err, "reading credential %d", i)}},
+			})
+			continue
+		}
+		cred, err := parseCredential(credToken)
+		if err != nil {
+			result.Credentials = append(result.Credentials, CredentialResult{
+				Checks: []CheckResult{{Check: SignatureCheck, Err: errors.Wrapf(err, "parsing credential %d", i)}},
+			})
+			continue
+		}
+		credResult := verifyCredential(ctx, credToken, cred, r, policy)
+		if policy.HolderBinding {
+			credResult.Checks = append(credResult.Checks, CheckResult{Check: HolderBindingCheck, Err: checkHolderBinding(*cred, pres.Holder)})
+		}
+		result.Credentials = append(result.Credentials, credResult)
+	}
+	return &result, nil
+}
+
+// verifyCredential runs the checks of the policy on a parsed credential
+func verifyCredential(ctx context.Context, token string, cred *credential.VerifiableCredential, r resolution.Resolver, policy VerifyPolicy) CredentialResult {
+	_, err := integrity.VerifyCredentialSignature(ctx, token, r, policy.verifyOptions()...)
+	checks := signatureAndExpiration(err, policy)
+	if policy.Expiration && checks[1].Passed() {
+		checks[1].Err = checkExpirationDate(*cred, policy)
+	}
+	if policy.Status {
+		checks = append(checks, CheckResult{Check: StatusCheck, Err: checkStatus(ctx, *cred, policy.StatusChecker)})
+	}
+	if len(policy.Schema) > 0 {
+		checks = append(checks, CheckResult{Check: SchemaCheck, Err: validation.ValidateJSONSchema(*cred, validation.WithSchema(string(policy.Schema)))})
+	}
+	if len(policy.TrustedIssuers) > 0 || policy.TrustRegistry != nil {
+		checks = append(checks, CheckResult{Check: TrustedIssuerCheck, Err: checkTrustedIssuer(*cred, policy)})
+	}
+	if len(policy.RequiredTypes) > 0 {
+		checks = append(checks, CheckResult{Check: TypeCheck, Err: checkTypes(*cred, policy.RequiredTypes)})
+	}
+	return CredentialResult{Credential: cred, Checks: checks}
+}
+
+// signatureAndExpiration splits the error of verifying a token into the results of its SignatureCheck and, if the
+// policy runs it, its ExpirationCheck, in that order: a token whose claims are not satisfied is signed correctly
+func signatureAndExpiration(err error, policy VerifyPolicy) []CheckResult {
+	expired := errors.Is(err, integrity.ErrClaimsNotSatisfied)
+	signature := CheckResult{Check: SignatureCheck}
+	if err != nil && !expired {
+		signature.Err = err
+	}
+	checks := []CheckResult{signature}
+	if policy.Expiration {
+		expiration := CheckResult{Check: ExpirationCheck}
+		if expired {
+			expiration.Err = err
+		}
+		checks = append(checks, expiration)
+	}
+	return checks
+}
+
+// verifyOptions returns the options verifying tokens with the clock and skew of the policy. Verification always
+// validates the claims of tokens, but without an ExpirationCheck their failure is ignored.
+func (p VerifyPolicy) verifyOptions() []integrity.VerifyOption {
+	opts := []integrity.VerifyOption{integrity.WithClockSkew(time.Duration(p.ClockSkewSeconds) * time.Second)}
+	if p.Clock != nil {
+		opts = append(opts, integrity.WithClock(p.Clock))
+	}
+	return opts
+}
+
+func (p VerifyPolicy) now() time.Time {
+	if p.Clock == nil {
+		return time.Now()
+	}
+	return p.Clock.Now()
+}
+
+// checkExpirationDate checks the expirationDate of a credential, which Data Integrity credentials carry in place of
+// an exp claim
+func checkExpirationDate(cred credential.VerifiableCredential, policy VerifyPolicy) error {
+	if cred.ExpirationDate == "" {
+		return nil
+	}
+	expiration, err := time.Parse(time.RFC3339, cred.ExpirationDate)
+	if err != nil {
+		return errors.Wrapf(err, "parsing expiration date<%s>", cred.ExpirationDate)
+	}
+	skew := time.Duration(policy.ClockSkewSeconds) * time.Second
+	if policy.now().After(expiration.Add(skew)) {
+		return errors.Errorf("credential expired as of %s", cred.ExpirationDate)
+	}
+	return nil
+}
+
+// checkStatus checks that a credential has not been revoked. Credentials without a credentialStatus property have no
+// status to check, and pass.
+func checkStatus(ctx context.Context, cred credential.VerifiableCredential, checker StatusChecker) error {
+	if cred.CredentialStatus == nil {
+		return nil
+	}
+	revoked, err := checker.IsRevoked(ctx, cred)
+	if err != nil {
+		return errors.Wrap(err, "checking credential status")
+	}
+	if revoked {
+		return errors.Wrapf(ErrRevoked, "credential<%s>", cred.ID)
+	}
+	return nil
+}
+
+// checkTrustedIssuer checks that the issuer of a credential is one of the trusted issuers of the policy, and that it
+// is accredited by the policy's trust registry for each of the credential's types other than VerifiableCredential
+func checkTrustedIssuer(cred credential.VerifiableCredential, policy VerifyPolicy) error {
+	issuer := cred.IssuerID()
+	if len(policy.TrustedIssuers) > 0 && !util.Contains(issuer, policy.TrustedIssuers) {
+		return errors.Wrapf(integrity.ErrUntrustedIssuer, "issuer<%s> is not a trusted issuer", issuer)
+	}
+	if policy.TrustRegistry == nil {
+		return nil
+	}
+	record, err := policy.TrustRegistry.LookupIssuer(issuer)
+	if err != nil {
+		return fmt.Errorf("%w: looking up issuer<%s>: %w", integrity.ErrUntrustedIssuer, issuer, err)
+	}
+	if record == nil {
+		return errors.Wrapf(integrity.ErrUntrustedIssuer, "issuer<%s> is not in the trust registry", issuer)
+	}
+	credTypes, err := util.InterfaceToStrings(cred.Type)
+	if err != nil {
+		return errors.Wrap(err, "reading credential types")
+	}
+	for _, credType := range credTypes {
+		if credType != credential.VerifiableCredentialType && !record.IsAuthorizedFor(credType) {
+			return errors.Wrapf(integrity.ErrUntrustedIssuer, "issuer<%s> is not accredited to issue %s credentials", issuer, credType)
+		}
+	}
+	return nil
+}
+
+// checkTypes checks that a credential has each of the required types
+func checkTypes(cred credential.VerifiableCredential, required []string) error {
+	credTypes, err := util.InterfaceToStrings(cred.Type)
+	if err != nil {
+		return errors.Wrap(err, "reading credential types")
+	}
+	for _, requiredType := range required {
+		if !util.Contains(requiredType, credTypes) {
+			return errors.Errorf("credential is not of the required type<%s>", requiredType)
+		}
+	}
+	return nil
+}
+
+// checkHolderBinding checks that every subject of a credential is the holder of the presentation it is presented in
+func checkHolderBinding(cred credential.VerifiableCredential, holder string) error {
+	if holder == "" {
+		return errors.New("presentation has no holder to bind credentials to")
+	}
+	subjects := cred.CredentialSubjects
+	if subjects == nil {
+		subjects = []credential.CredentialSubject{cred.CredentialSubject}
+	}
+	for _, subject := range subjects {
+		if id := subject.GetID(); id != holder {
+			return errors.Errorf("credential subject<%s> is not the holder<%s>", id, holder)
+		}
+	}
+	return nil
+}
+
+// parseCredential parses a credential JWT, in either serialization, or the JSON of a Data Integrity credential
+func parseCredential(token string) (*credential.VerifiableCredential, error) {
+	var cred credential.VerifiableCredential
+	if err := json.Unmarshal([]byte(token), &cred); err == nil && !cred.IsEmpty() && cred.Proof != nil {
+		return &cred, nil
+	}
+	parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing credential")
+	}
+	return parsed.Trust().Credential, nil
+}
+
+// credentialToken returns a credential of a presentation as a token VerifyCredentialSignature verifies
+func credentialToken(genericCred any) (string, error) {
+	switch typedCred := genericCred.(type) {
+	case string:
+		return typedCred, nil
+	case []byte:
+		return string(typedCred), nil
+	}
+	credBytes, err := json.Marshal(genericCred)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling credential")
+	}
+	return string(credBytes), nil
+}
+
+func failedChecks(checks []CheckResult) []CheckResult {
+	var failed []CheckResult
+	for _, check := range checks {
+		if !check.Passed() {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+func findCheck(checks []CheckResult, check Check) (CheckResult, bool) {
+	for _, result := range checks {
+		if result.Check == check {
+			return result, true
+		}
+	}
+	return CheckResult{}, false
+}
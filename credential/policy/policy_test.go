@@ -0,0 +1,297 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// revokedCredentials is a StatusChecker revoking the credentials with the given IDs
+type revokedCredentials map[string]bool
+
+func (r revokedCredentials) IsRevoked(_ context.Context, cred credential.VerifiableCredential) (bool, error) {
+	return r[cred.ID], nil
+}
+
+type trustRegistry map[string]integrity.TrustRecord
+
+func (r trustRegistry) LookupIssuer(id string) (*integrity.TrustRecord, error) {
+	record, ok := r[id]
+	if !ok {
+		return nil, errors.Errorf("issuer<%s> not found", id)
+	}
+	return &record, nil
+}
+
+const emailSchema = `{
+  "$id": "https://example.com/schemas/email.json",
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "name": "EmailCredential",
+  "type": "object",
+  "properties": {
+    "credentialSubject": {
+      "type": "object",
+      "properties": {
+        "emailAddress": {"type": "string", "format": "email"}
+      },
+      "required": ["emailAddress"]
+    }
+  }
+}`
+
+func newDIDKeySigner(t *testing.T) jwx.Signer {
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner(didKey.String(), &expanded.VerificationMethod[0].ID, privKey)
+	require.NoError(t, err)
+	return *signer
+}
+
+func TestVerifyCredentialWithPolicy(t *testing.T) {
+	issuer := newDIDKeySigner(t)
+	holder := newDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	signCredential := func(tt *testing.T, id string, subject map[string]any) string {
+		subject["id"] = holder.ID
+		signed, err := integrity.SignVerifiableCredentialJWT(issuer, credential.VerifiableCredential{
+			Context:          []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:               id,
+			Type:             []string{credential.VerifiableCredentialType, "EmailCredential"},
+			Issuer:           issuer.ID,
+			IssuanceDate:     "2021-01-01T00:00:00Z",
+			ExpirationDate:   "2022-01-01T00:00:00Z",
+			CredentialStatus: map[string]any{"id": id + "#status"},
+			CredentialSchema: &credential.CredentialSchema{
+				ID:   "https://example.com/schemas/email.json",
+				Type: "JsonSchema",
+			},
+			CredentialSubject: subject,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	policy := VerifyPolicy{
+		Expiration:     true,
+		Status:         true,
+		Schema:         json.RawMessage(emailSchema),
+		TrustedIssuers: []string{issuer.ID},
+		RequiredTypes:  []string{"EmailCredential"},
+		Clock:          fixedClock(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)),
+		StatusChecker:  revokedCredentials{"urn:uuid:revoked": true},
+		TrustRegistry: trustRegistry{issuer.ID: integrity.TrustRecord{
+			DID:             issuer.ID,
+			CredentialTypes: []string{"EmailCredential"},
+		}},
+	}
+
+	t.Run("every check passes", func(tt *testing.T) {
+		token := signCredential(tt, "urn:uuid:1", map[string]any{"emailAddress": "holder@example.com"})
+		result, err := VerifyCredentialWithPolicy(context.Background(), token, resolver, policy)
+		require.NoError(tt, err)
+		assert.True(tt, result.Passed())
+		assert.Empty(tt, result.Failed())
+		assert.Equal(tt, "urn:uuid:1", result.Credential.ID)
+
+		var checks []Check
+		for _, check := range result.Checks {
+			checks = append(checks, check.Check)
+		}
+		assert.Equal(tt, []Check{SignatureCheck, ExpirationCheck, StatusCheck, SchemaCheck, TrustedIssuerCheck, TypeCheck}, checks)
+		_, ok := result.Result(HolderBindingCheck)
+		assert.False(tt, ok)
+	})
+
+	t.Run("each failed check is reported", func(tt *testing.T) {
+		token := signCredential(tt, "urn:uuid:revoked", map[string]any{"name": "no email address"})
+		strict := policy
+		strict.Clock = fixedClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+		strict.TrustedIssuers = []string{holder.ID}
+		strict.RequiredTypes = []string{"DegreeCredential"}
+
+		result, err := VerifyCredentialWithPolicy(context.Background(), token, resolver, strict)
+		require.NoError(tt, err)
+		assert.False(tt, result.Passed())
+
+		signature, _ := result.Result(SignatureCheck)
+		assert.True(tt, signature.Passed())
+		expiration, _ := result.Result(ExpirationCheck)
+		assert.ErrorIs(tt, expiration.Err, integrity.ErrClaimsNotSatisfied)
+		status, _ := result.Result(StatusCheck)
+		assert.ErrorIs(tt, status.Err, ErrRevoked)
+		schema, _ := result.Result(SchemaCheck)
+		assert.ErrorContains(tt, schema.Err, "emailAddress")
+		trusted, _ := result.Result(TrustedIssuerCheck)
+		assert.ErrorIs(tt, trusted.Err, integrity.ErrUntrustedIssuer)
+		types, _ := result.Result(TypeCheck)
+		assert.ErrorContains(tt, types.Err, "required type<DegreeCredential>")
+		assert.Len(tt, result.Failed(), 5)
+	})
+
+	t.Run("invalid signature", func(tt *testing.T) {
+		token := signCredential(tt, "urn:uuid:1", map[string]any{"emailAddress": "holder@example.com"})
+		result, err := VerifyCredentialWithPolicy(context.Background(), token+"tampered", resolver, VerifyPolicy{})
+		require.NoError(tt, err)
+		require.Len(tt, result.Failed(), 1)
+		assert.Equal(tt, SignatureCheck, result.Failed()[0].Check)
+	})
+
+	t.Run("expiration is not checked by default", func(tt *testing.T) {
+		token := signCredential(tt, "urn:uuid:1", map[string]any{"emailAddress": "holder@example.com"})
+		result, err := VerifyCredentialWithPolicy(context.Background(), token, resolver, VerifyPolicy{})
+		require.NoError(tt, err)
+		assert.True(tt, result.Passed())
+		assert.Len(tt, result.Checks, 1)
+	})
+
+	t.Run("accredited by the trust registry", func(tt *testing.T) {
+		token := signCredential(tt, "urn:uuid:1", map[string]any{"emailAddress": "holder@example.com"})
+		result, err := VerifyCredentialWithPolicy(context.Background(), token, resolver, VerifyPolicy{
+			TrustRegistry: trustRegistry{issuer.ID: integrity.TrustRecord{DID: issuer.ID}},
+		})
+		require.NoError(tt, err)
+		trusted, ok := result.Result(TrustedIssuerCheck)
+		require.True(tt, ok)
+		assert.ErrorContains(tt, trusted.Err, "not accredited to issue EmailCredential credentials")
+	})
+
+	t.Run("policy loaded from JSON", func(tt *testing.T) {
+		config := `{"expiration": true, "clockSkewSeconds": 60, "trustedIssuers": ["` + issuer.ID + `"], ` +
+			`"requiredTypes": ["EmailCredential"], "schema": ` + emailSchema + `}`
+		var loaded VerifyPolicy
+		require.NoError(tt, json.Unmarshal([]byte(config), &loaded))
+		loaded.Clock = policy.Clock
+		assert.Equal(tt, 60, loaded.ClockSkewSeconds)
+
+		token := signCredential(tt, "urn:uuid:1", map[string]any{"emailAddress": "holder@example.com"})
+		result, err := VerifyCredentialWithPolicy(context.Background(), token, resolver, loaded)
+		require.NoError(tt, err)
+		assert.True(tt, result.Passed())
+		assert.Len(tt, result.Checks, 5)
+
+		// the runtime dependencies of the policy are not serialized
+		policyJSON, err := json.Marshal(policy)
+		require.NoError(tt, err)
+		assert.NotContains(tt, string(policyJSON), "urn:uuid:revoked")
+		var roundTripped VerifyPolicy
+		require.NoError(tt, json.Unmarshal(policyJSON, &roundTripped))
+		assert.Equal(tt, policy.TrustedIssuers, roundTripped.TrustedIssuers)
+		assert.True(tt, roundTripped.Status)
+	})
+
+	t.Run("invalid policy or credential", func(tt *testing.T) {
+		token := signCredential(tt, "urn:uuid:1", map[string]any{"emailAddress": "holder@example.com"})
+		_, err := VerifyCredentialWithPolicy(context.Background(), token, resolver, VerifyPolicy{Status: true})
+		assert.ErrorContains(tt, err, "status check requires a status checker")
+		_, err = VerifyCredentialWithPolicy(context.Background(), token, resolver, VerifyPolicy{ClockSkewSeconds: -1})
+		assert.ErrorContains(tt, err, "clock skew<-1s> cannot be negative")
+		_, err = VerifyCredentialWithPolicy(context.Background(), "not a credential", resolver, VerifyPolicy{})
+		assert.ErrorContains(tt, err, "parsing credential")
+		_, err = VerifyCredentialWithPolicy(context.Background(), token, nil, VerifyPolicy{})
+		assert.ErrorContains(tt, err, "resolution cannot be empty")
+	})
+}
+
+func TestVerifyPresentationWithPolicy(t *testing.T) {
+	issuer := newDIDKeySigner(t)
+	holder := newDIDKeySigner(t)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	signCredential := func(tt *testing.T, subject string) string {
+		signed, err := integrity.SignVerifiableCredentialJWT(issuer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{credential.VerifiableCredentialType},
+			Issuer:            issuer.ID,
+			IssuanceDate:      "2021-01-01T00:00:00Z",
+			CredentialSubject: map[string]any{"id": subject},
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	signPresentation := func(tt *testing.T, signer jwx.Signer, creds ...any) string {
+		signed, err := integrity.SignVerifiablePresentationJWT(signer, nil, credential.VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               signer.ID,
+			VerifiableCredential: creds,
+		})
+		require.NoError(tt, err)
+		return string(signed)
+	}
+	policy := VerifyPolicy{Expiration: true, HolderBinding: true, TrustedIssuers: []string{issuer.ID}}
+
+	t.Run("credentials bound to the holder", func(tt *testing.T) {
+		token := signPresentation(tt, holder, signCredential(tt, holder.ID), signCredential(tt, holder.ID))
+		result, err := VerifyPresentationWithPolicy(context.Background(), token, resolver, policy)
+		require.NoError(tt, err)
+		assert.True(tt, result.Passed())
+		assert.Equal(tt, holder.ID, result.Presentation.Holder)
+		assert.Len(tt, result.Checks, 2)
+		require.Len(tt, result.Credentials, 2)
+		binding, ok := result.Credentials[0].Result(HolderBindingCheck)
+		require.True(tt, ok)
+		assert.True(tt, binding.Passed())
+	})
+
+	t.Run("failures of credentials are reported separately", func(tt *testing.T) {
+		other := newDIDKeySigner(tt)
+		token := signPresentation(tt, holder, signCredential(tt, holder.ID), signCredential(tt, other.ID),
+			signCredential(tt, holder.ID)+"tampered", 42)
+		result, err := VerifyPresentationWithPolicy(context.Background(), token, resolver, policy)
+		require.NoError(tt, err)
+		assert.False(tt, result.Passed())
+		assert.Empty(tt, result.Failed())
+
+		require.Len(tt, result.Credentials, 4)
+		assert.True(tt, result.Credentials[0].Passed())
+		binding, _ := result.Credentials[1].Result(HolderBindingCheck)
+		assert.ErrorContains(tt, binding.Err, "is not the holder")
+		signature, _ := result.Credentials[2].Result(SignatureCheck)
+		assert.False(tt, signature.Passed())
+		assert.Nil(tt, result.Credentials[3].Credential)
+		assert.Equal(tt, SignatureCheck, result.Credentials[3].Failed()[0].Check)
+	})
+
+	t.Run("presentation signed by another key", func(tt *testing.T) {
+		token := signPresentation(tt, holder, signCredential(tt, holder.ID))
+		result, err := VerifyPresentationWithPolicy(context.Background(), token[:len(token)-4]+"AAAA", resolver, policy)
+		require.NoError(tt, err)
+		signature, _ := result.Result(SignatureCheck)
+		assert.False(tt, signature.Passed())
+		assert.True(tt, result.Credentials[0].Passed())
+	})
+
+	t.Run("expired presentation", func(tt *testing.T) {
+		token := signPresentation(tt, holder, signCredential(tt, holder.ID))
+		expired := policy
+		expired.Clock = fixedClock(time.Now().Add(-24 * time.Hour))
+		result, err := VerifyPresentationWithPolicy(context.Background(), token, resolver, expired)
+		require.NoError(tt, err)
+		signature, _ := result.Result(SignatureCheck)
+		assert.True(tt, signature.Passed())
+		expiration, _ := result.Result(ExpirationCheck)
+		assert.ErrorIs(tt, expiration.Err, integrity.ErrClaimsNotSatisfied)
+	})
+}
@@ -0,0 +1,196 @@
+// Package predicate proves that an attribute of a credential satisfies a predicate, such as a birth date being at
+// least 18 years ago, without disclosing the attribute. Issuers put a Pedersen commitment over the BLS12-381 G1 group
+// in the credential in place of the attribute, see CommitAttribute, and give its opening to the holder alongside the
+// credential. Holders prove predicates of the committed attribute with DerivePredicateProof, which verifiers check
+// against the commitment in the credential with VerifyPredicateProof.
+//
+// The commitment itself is the same in every presentation of a credential, so unlike BBS+ derived proofs, proofs of
+// the same credential can be linked to each other.
+package predicate
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+const (
+	// CommitmentType is the type of committed attributes in credentials
+	CommitmentType = "Bls12381G1PedersenCommitment"
+)
+
+var (
+	// ErrPredicateNotSatisfied is returned when deriving a proof of a predicate the attribute does not satisfy
+	ErrPredicateNotSatisfied = errors.New("predicate not satisfied")
+	// ErrInvalidOpening is returned when an opening does not open the commitment in the credential
+	ErrInvalidOpening = errors.New("invalid opening")
+	// ErrInvalidPredicateProof is returned when a predicate proof does not verify
+	ErrInvalidPredicateProof = errors.New("invalid predicate proof")
+)
+
+// Operator compares an attribute to the value of a predicate
+type Operator string
+
+const (
+	GreaterOrEqual Operator = ">="
+	LessOrEqual    Operator = "<="
+	Equal          Operator = "=="
+)
+
+// Predicate is a comparison of an attribute to a value, such as "is at least 18"
+type Predicate struct {
+	Operator Operator `json:"operator"`
+	Value    int64    `json:"value"`
+}
+
+// IsSatisfiedBy returns whether the value satisfies the predicate
+func (p Predicate) IsSatisfiedBy(value int64) bool {
+	switch p.Operator {
+	case GreaterOrEqual:
+		return value >= p.Value
+	case LessOrEqual:
+		return value <= p.Value
+	case Equal:
+		return value == p.Value
+	}
+	return false
+}
+
+// IsValid returns whether the predicate has a known operator
+func (p Predicate) IsValid() bool {
+	switch p.Operator {
+	case GreaterOrEqual, LessOrEqual, Equal:
+		return true
+	}
+	return false
+}
+
+// PredicateRequest is a verifier's request for a proof that an attribute of a credential's subject satisfies a
+// predicate. The nonce binds proofs to the request, so that they cannot be replayed to other verifiers.
+type PredicateRequest struct {
+	Attribute string    `json:"attribute"`
+	Predicate Predicate `json:"predicate"`
+	Nonce     string    `json:"nonce,omitempty"`
+}
+
+// EncodeDate encodes the date of the time, in UTC, as the number of days since the Unix epoch, which is how dates such
+// as birth dates are committed to so that they can be compared
+func EncodeDate(t time.Time) int64 {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / int64(24*time.Hour/time.Second)
+}
+
+// MinimumAgeRequest requests a proof that the subject was at least the given number of years old at the given time,
+// from a birth date attribute committed to as encoded by EncodeDate
+func MinimumAgeRequest(attribute string, years int, at time.Time, nonce string) PredicateRequest {
+	return PredicateRequest{
+		Attribute: attribute,
+		Predicate: Predicate{Operator: LessOrEqual, Value: EncodeDate(at.UTC().AddDate(-years, 0, 0))},
+		Nonce:     nonce,
+	}
+}
+
+// CommittedAttribute is the value of an attribute of a credential's subject that has been committed to
+type CommittedAttribute struct {
+	Type       string `json:"type"`
+	Commitment string `json:"commitment"`
+}
+
+// AttributeOpening opens a committed attribute, which only the holder of the credential should know
+type AttributeOpening struct {
+	Value    int64  `json:"value"`
+	Blinding string `json:"blinding"`
+}
+
+// CommitAttribute sets the attribute of the subject to a commitment to the value, returning the opening of the
+// commitment to give to the holder of the credential
+func CommitAttribute(subject credential.CredentialSubject, attribute string, value int64) (*AttributeOpening, error) {
+	if subject == nil {
+		return nil, errors.New("subject cannot be empty")
+	}
+	if attribute == "" {
+		return nil, errors.New("attribute cannot be empty")
+	}
+	var blinding bls12381.Scalar
+	if err := blinding.Random(rand.Reader); err != nil {
+		return nil, errors.Wrap(err, "generating blinding")
+	}
+	commitment := commit(scalarFromInt(value), &blinding)
+	subject[attribute] = CommittedAttribute{Type: CommitmentType, Commitment: encodePoint(commitment)}
+	return &AttributeOpening{Value: value, Blinding: encodeScalar(&blinding)}, nil
+}
+
+// attributeCommitment returns the commitment to the attribute of the credential's subject
+func attributeCommitment(cred credential.VerifiableCredential, attribute string) (*bls12381.G1, error) {
+	subject := cred.CredentialSubject
+	if len(cred.CredentialSubjects) > 0 {
+		return nil, errors.New("credentials with many subjects are not supported")
+	}
+	value, ok := subject[attribute]
+	if !ok {
+		return nil, fmt.Errorf("credential has no attribute<%s>", attribute)
+	}
+	attrBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshalling attribute<%s>", attribute)
+	}
+	var committed CommittedAttribute
+	if err = json.Unmarshal(attrBytes, &committed); err != nil || committed.Type != CommitmentType {
+		return nil, fmt.Errorf("attribute<%s> is not a %s", attribute, CommitmentType)
+	}
+	commitment, err := decodePoint(committed.Commitment)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding commitment of attribute<%s>", attribute)
+	}
+	return commitment, nil
+}
+
+// encodePoint encodes a point in its compressed form
+func encodePoint(p *bls12381.G1) string {
+	return base64.RawURLEncoding.EncodeToString(p.BytesCompressed())
+}
+
+// decodePoint decodes a point, which must be in G1
+func decodePoint(encoded string) (*bls12381.G1, error) {
+	pointBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(pointBytes) != bls12381.G1SizeCompressed {
+		return nil, fmt.Errorf("point must be %d bytes", bls12381.G1SizeCompressed)
+	}
+	var p bls12381.G1
+	if err = p.SetBytes(pointBytes); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// encodeScalar encodes a scalar in big-endian order
+func encodeScalar(s *bls12381.Scalar) string {
+	scalarBytes, _ := s.MarshalBinary()
+	return base64.RawURLEncoding.EncodeToString(scalarBytes)
+}
+
+// decodeScalar decodes a scalar, which must be less than the order of the group
+func decodeScalar(encoded string) (*bls12381.Scalar, error) {
+	scalarBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(scalarBytes) != bls12381.ScalarSize {
+		return nil, fmt.Errorf("scalar must be %d bytes", bls12381.ScalarSize)
+	}
+	var s bls12381.Scalar
+	if err = s.UnmarshalBinary(scalarBytes); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,109 @@
+package predicate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+func TestPredicateIsSatisfiedBy(t *testing.T) {
+	tests := []struct {
+		predicate Predicate
+		value     int64
+		satisfied bool
+	}{
+		{Predicate{Operator: GreaterOrEqual, Value: 18}, 18, true},
+		{Predicate{Operator: GreaterOrEqual, Value: 18}, 17, false},
+		{Predicate{Operator: LessOrEqual, Value: -3}, -3, true},
+		{Predicate{Operator: LessOrEqual, Value: -3}, -2, false},
+		{Predicate{Operator: Equal, Value: 7}, 7, true},
+		{Predicate{Operator: Equal, Value: 7}, 8, false},
+		{Predicate{Operator: "!=", Value: 7}, 8, false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.satisfied, test.predicate.IsSatisfiedBy(test.value), "%d %s %d", test.value, test.predicate.Operator, test.predicate.Value)
+	}
+	assert.False(t, Predicate{Operator: "!="}.IsValid())
+	assert.True(t, Predicate{Operator: Equal}.IsValid())
+}
+
+func TestEncodeDate(t *testing.T) {
+	assert.Equal(t, int64(0), EncodeDate(time.Date(1970, 1, 1, 23, 59, 0, 0, time.UTC)))
+	assert.Equal(t, int64(-1), EncodeDate(time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, int64(18628), EncodeDate(time.Date(2021, 1, 1, 19, 23, 24, 0, time.UTC)))
+	// the date is that of the time in UTC, which is already the next day
+	assert.Equal(t, int64(18629), EncodeDate(time.Date(2021, 1, 1, 22, 0, 0, 0, time.FixedZone("UTC-5", -5*60*60))))
+}
+
+func TestMinimumAgeRequest(t *testing.T) {
+	at := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	request := MinimumAgeRequest("birthDate", 18, at, "nonce")
+	assert.Equal(t, PredicateRequest{
+		Attribute: "birthDate",
+		Predicate: Predicate{Operator: LessOrEqual, Value: EncodeDate(time.Date(2008, 10, 14, 0, 0, 0, 0, time.UTC))},
+		Nonce:     "nonce",
+	}, request)
+	assert.True(t, request.Predicate.IsSatisfiedBy(EncodeDate(time.Date(2008, 10, 14, 0, 0, 0, 0, time.UTC))))
+	assert.False(t, request.Predicate.IsSatisfiedBy(EncodeDate(time.Date(2008, 10, 15, 0, 0, 0, 0, time.UTC))))
+}
+
+func TestCommitAttribute(t *testing.T) {
+	t.Run("commits to the attribute", func(tt *testing.T) {
+		subject := credential.CredentialSubject{"id": "did:example:123"}
+		opening, err := CommitAttribute(subject, "birthDate", -4000)
+		require.NoError(tt, err)
+		assert.Equal(tt, int64(-4000), opening.Value)
+
+		committed, ok := subject["birthDate"].(CommittedAttribute)
+		require.True(tt, ok)
+		assert.Equal(tt, CommitmentType, committed.Type)
+
+		// the commitment is read back from credentials that have been through JSON
+		credBytes, err := json.Marshal(credential.VerifiableCredential{CredentialSubject: subject})
+		require.NoError(tt, err)
+		var cred credential.VerifiableCredential
+		require.NoError(tt, json.Unmarshal(credBytes, &cred))
+		commitment, err := attributeCommitment(cred, "birthDate")
+		require.NoError(tt, err)
+		blinding, err := decodeScalar(opening.Blinding)
+		require.NoError(tt, err)
+		assert.True(tt, commit(scalarFromInt(opening.Value), blinding).IsEqual(commitment))
+	})
+
+	t.Run("commitments are hiding", func(tt *testing.T) {
+		first, second := credential.CredentialSubject{}, credential.CredentialSubject{}
+		_, err := CommitAttribute(first, "age", 21)
+		require.NoError(tt, err)
+		_, err = CommitAttribute(second, "age", 21)
+		require.NoError(tt, err)
+		assert.NotEqual(tt, first["age"], second["age"])
+	})
+
+	t.Run("bad input", func(tt *testing.T) {
+		_, err := CommitAttribute(nil, "age", 21)
+		assert.ErrorContains(tt, err, "subject cannot be empty")
+		_, err = CommitAttribute(credential.CredentialSubject{}, "", 21)
+		assert.ErrorContains(tt, err, "attribute cannot be empty")
+	})
+
+	t.Run("attributes that are not committed to", func(tt *testing.T) {
+		cred := credential.VerifiableCredential{CredentialSubject: credential.CredentialSubject{
+			"age":   21,
+			"other": CommittedAttribute{Type: "OtherCommitment", Commitment: "AAAA"},
+			"bad":   CommittedAttribute{Type: CommitmentType, Commitment: "AAAA"},
+		}}
+		_, err := attributeCommitment(cred, "missing")
+		assert.ErrorContains(tt, err, "credential has no attribute<missing>")
+		_, err = attributeCommitment(cred, "age")
+		assert.ErrorContains(tt, err, "is not a "+CommitmentType)
+		_, err = attributeCommitment(cred, "other")
+		assert.ErrorContains(tt, err, "is not a "+CommitmentType)
+		_, err = attributeCommitment(cred, "bad")
+		assert.ErrorContains(tt, err, "decoding commitment of attribute<bad>")
+	})
+}
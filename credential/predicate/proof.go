@@ -0,0 +1,392 @@
+package predicate
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+const (
+	// ProofType is the type of predicate proofs
+	ProofType = "Bls12381G1PedersenPredicateProof"
+
+	// rangeBits is the number of bits of the difference between an attribute and the value of a predicate proven to
+	// be non-negative, which covers the difference of any two int64 values
+	rangeBits = 64
+)
+
+var (
+	// transcriptDST separates the hashes of this package from those of other protocols
+	transcriptDST = []byte("SSI-SDK-PREDICATE-PROOF-V1_BLS12381G1_XMD:SHA-256_SSWU_RO_")
+
+	generatorG = bls12381.G1Generator()
+	// generatorH is the blinding generator of commitments, hashed to the curve so that its discrete logarithm with
+	// respect to generatorG is unknown
+	generatorH = hashGenerator()
+)
+
+// PredicateProof proves the attribute of a credential satisfies the predicate without disclosing it. Proofs of
+// inequalities prove the difference between the attribute and the value of the predicate is non-negative, as a
+// commitment to each of its bits with a proof that it commits to 0 or 1. Proofs of equalities prove knowledge of the
+// blinding of the commitment to the attribute less the value.
+type PredicateProof struct {
+	Type      string    `json:"type"`
+	Attribute string    `json:"attribute"`
+	Predicate Predicate `json:"predicate"`
+	Challenge string    `json:"challenge"`
+	// Response is the response of the proof of an equality
+	Response string `json:"response,omitempty"`
+	// Bits are the proofs of the bits of an inequality, least significant first
+	Bits []BitProof `json:"bits,omitempty"`
+}
+
+// BitProof proves a commitment is to 0 or 1, as a proof that either the commitment, or the commitment less the
+// generator, is a multiple of the blinding generator. The challenge of the second branch is the challenge of the
+// proof less Challenge0.
+type BitProof struct {
+	Commitment string `json:"commitment"`
+	Challenge0 string `json:"challenge0"`
+	Response0  string `json:"response0"`
+	Response1  string `json:"response1"`
+}
+
+// DerivePredicateProof proves the attribute of the credential the request is for satisfies the predicate of the
+// request, with the opening of the attribute's commitment given to the holder by the issuer. The proof does not
+// disclose the attribute. Proofs are only derived for predicates the attribute satisfies.
+func DerivePredicateProof(cred credential.VerifiableCredential, opening AttributeOpening, request PredicateRequest) (*PredicateProof, error) {
+	if !request.Predicate.IsValid() {
+		return nil, fmt.Errorf("unknown operator<%s>", request.Predicate.Operator)
+	}
+	commitment, err := attributeCommitment(cred, request.Attribute)
+	if err != nil {
+		return nil, err
+	}
+	blinding, err := decodeScalar(opening.Blinding)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidOpening, "decoding blinding: %s", err)
+	}
+	if !commit(scalarFromInt(opening.Value), blinding).IsEqual(commitment) {
+		return nil, errors.Wrapf(ErrInvalidOpening, "opening does not open the commitment of attribute<%s>", request.Attribute)
+	}
+	if !request.Predicate.IsSatisfiedBy(opening.Value) {
+		return nil, errors.Wrapf(ErrPredicateNotSatisfied, "attribute<%s> %s %d", request.Attribute, request.Predicate.Operator, request.Predicate.Value)
+	}
+
+	proof := PredicateProof{Type: ProofType, Attribute: request.Attribute, Predicate: request.Predicate}
+	switch request.Predicate.Operator {
+	case Equal:
+		err = proveEquality(&proof, request, commitment, blinding)
+	case GreaterOrEqual:
+		err = proveRange(&proof, request, commitment, uint64(opening.Value)-uint64(request.Predicate.Value), blinding)
+	case LessOrEqual:
+		blinding.Neg()
+		err = proveRange(&proof, request, commitment, uint64(request.Predicate.Value)-uint64(opening.Value), blinding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// VerifyPredicateProof verifies the proof proves the attribute of the credential satisfies the predicate of the
+// request, returning an error wrapping ErrInvalidPredicateProof if not. The credential itself must be verified
+// separately, e.g. with integrity.VerifyJWTCredential, for the commitment to be trusted to come from its issuer.
+func VerifyPredicateProof(cred credential.VerifiableCredential, request PredicateRequest, proof PredicateProof) error {
+	if proof.Type != ProofType {
+		return errors.Wrapf(ErrInvalidPredicateProof, "unsupported proof type<%s>", proof.Type)
+	}
+	if proof.Attribute != request.Attribute || proof.Predicate != request.Predicate {
+		return errors.Wrap(ErrInvalidPredicateProof, "proof is not of the requested predicate")
+	}
+	if !request.Predicate.IsValid() {
+		return fmt.Errorf("unknown operator<%s>", request.Predicate.Operator)
+	}
+	commitment, err := attributeCommitment(cred, request.Attribute)
+	if err != nil {
+		return err
+	}
+	challenge, err := decodeScalar(proof.Challenge)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidPredicateProof, "decoding challenge: %s", err)
+	}
+
+	target := statementTarget(commitment, request.Predicate)
+	var points []*bls12381.G1
+	if request.Predicate.Operator == Equal {
+		points, err = equalityCommitments(proof, target, challenge)
+	} else {
+		points, err = rangeCommitments(proof, target, challenge)
+	}
+	if err != nil {
+		return err
+	}
+	if transcriptChallenge(request, commitment, points...).IsEqual(challenge) != 1 {
+		return errors.Wrap(ErrInvalidPredicateProof, "challenge does not match")
+	}
+	return nil
+}
+
+// statementTarget returns the commitment the proof of the predicate is about: to the attribute less the value for
+// equalities and lower bounds, and to the value less the attribute for upper bounds
+func statementTarget(commitment *bls12381.G1, predicate Predicate) *bls12381.G1 {
+	bound := mul(scalarFromInt(predicate.Value), generatorG)
+	if predicate.Operator == LessOrEqual {
+		return sub(bound, commitment)
+	}
+	return sub(commitment, bound)
+}
+
+// proveEquality proves knowledge of the blinding of the target, which is a multiple of the blinding generator only
+// when the attribute equals the value
+func proveEquality(proof *PredicateProof, request PredicateRequest, commitment *bls12381.G1, blinding *bls12381.Scalar) error {
+	target := statementTarget(commitment, request.Predicate)
+	secret, err := randomScalar()
+	if err != nil {
+		return err
+	}
+	challenge := transcriptChallenge(request, commitment, target, mul(secret, generatorH))
+	var response bls12381.Scalar
+	response.Mul(challenge, blinding)
+	response.Add(&response, secret)
+	proof.Challenge, proof.Response = encodeScalar(challenge), encodeScalar(&response)
+	return nil
+}
+
+// equalityCommitments returns the points of the transcript of an equality proof
+func equalityCommitments(proof PredicateProof, target *bls12381.G1, challenge *bls12381.Scalar) ([]*bls12381.G1, error) {
+	if len(proof.Bits) > 0 {
+		return nil, errors.Wrap(ErrInvalidPredicateProof, "proofs of equalities have no bits")
+	}
+	response, err := decodeScalar(proof.Response)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidPredicateProof, "decoding response: %s", err)
+	}
+	return []*bls12381.G1{target, sub(mul(response, generatorH), mul(challenge, target))}, nil
+}
+
+// proveRange proves the target of the request commits to the non-negative difference with the blinding, by
+// committing to each bit of the difference with blindings whose sum, weighted by the powers of two of the bits, is
+// the blinding of the target
+func proveRange(proof *PredicateProof, request PredicateRequest, commitment *bls12381.G1, difference uint64, blinding *bls12381.Scalar) error {
+	target := statementTarget(commitment, request.Predicate)
+	bitCommitments := make([]*bls12381.G1, rangeBits)
+	bitBlindings := make([]*bls12381.Scalar, rangeBits)
+	var weighted bls12381.Scalar
+	for i := 0; i < rangeBits-1; i++ {
+		r, err := randomScalar()
+		if err != nil {
+			return err
+		}
+		bitBlindings[i] = r
+		var term bls12381.Scalar
+		term.Mul(r, powerOfTwo(i))
+		weighted.Add(&weighted, &term)
+	}
+	// the blinding of the most significant bit makes the weighted sum of the blindings that of the target
+	var last, inverse bls12381.Scalar
+	last.Sub(blinding, &weighted)
+	inverse.Inv(powerOfTwo(rangeBits - 1))
+	last.Mul(&last, &inverse)
+	bitBlindings[rangeBits-1] = &last
+
+	// each bit is proven to be 0 or 1 by a proof of either branch, the other branch being simulated
+	type bitState struct {
+		bit                          uint64
+		secret                       *bls12381.Scalar
+		simChallenge, simResponse    *bls12381.Scalar
+		announcement0, announcement1 *bls12381.G1
+	}
+	states := make([]bitState, rangeBits)
+	points := []*bls12381.G1{target}
+	for i := range states {
+		bit := (difference >> i) & 1
+		var bitScalar bls12381.Scalar
+		bitScalar.SetUint64(bit)
+		bitCommitments[i] = commit(&bitScalar, bitBlindings[i])
+
+		secret, err := randomScalar()
+		if err != nil {
+			return err
+		}
+		simChallenge, err := randomScalar()
+		if err != nil {
+			return err
+		}
+		simResponse, err := randomScalar()
+		if err != nil {
+			return err
+		}
+		announcement := mul(secret, generatorH)
+		simulated := simulatedCommitment(simResponse, simChallenge, bitBranch(bitCommitments[i], 1-bit))
+		state := bitState{bit: bit, secret: secret, simChallenge: simChallenge, simResponse: simResponse}
+		if bit == 0 {
+			state.announcement0, state.announcement1 = announcement, simulated
+		} else {
+			state.announcement0, state.announcement1 = simulated, announcement
+		}
+		states[i] = state
+		points = append(points, bitCommitments[i], state.announcement0, state.announcement1)
+	}
+
+	challenge := transcriptChallenge(request, commitment, points...)
+	proof.Challenge = encodeScalar(challenge)
+	proof.Bits = make([]BitProof, rangeBits)
+	for i, state := range states {
+		var realChallenge, realResponse bls12381.Scalar
+		realChallenge.Sub(challenge, state.simChallenge)
+		realResponse.Mul(&realChallenge, bitBlindings[i])
+		realResponse.Add(&realResponse, state.secret)
+
+		bitProof := BitProof{Commitment: encodePoint(bitCommitments[i])}
+		if state.bit == 0 {
+			bitProof.Challenge0 = encodeScalar(&realChallenge)
+			bitProof.Response0, bitProof.Response1 = encodeScalar(&realResponse), encodeScalar(state.simResponse)
+		} else {
+			bitProof.Challenge0 = encodeScalar(state.simChallenge)
+			bitProof.Response0, bitProof.Response1 = encodeScalar(state.simResponse), encodeScalar(&realResponse)
+		}
+		proof.Bits[i] = bitProof
+	}
+	return nil
+}
+
+// rangeCommitments returns the points of the transcript of a range proof, checking the bit commitments sum to the
+// target
+func rangeCommitments(proof PredicateProof, target *bls12381.G1, challenge *bls12381.Scalar) ([]*bls12381.G1, error) {
+	if len(proof.Bits) != rangeBits {
+		return nil, errors.Wrapf(ErrInvalidPredicateProof, "proofs of inequalities must have %d bits", rangeBits)
+	}
+	if proof.Response != "" {
+		return nil, errors.Wrap(ErrInvalidPredicateProof, "proofs of inequalities have no response")
+	}
+	sum := new(bls12381.G1)
+	sum.SetIdentity()
+	points := []*bls12381.G1{target}
+	for i, bitProof := range proof.Bits {
+		bitCommitment, err := decodePoint(bitProof.Commitment)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidPredicateProof, "decoding commitment of bit %d: %s", i, err)
+		}
+		challenge0, err := decodeScalar(bitProof.Challenge0)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidPredicateProof, "decoding challenge of bit %d: %s", i, err)
+		}
+		response0, err := decodeScalar(bitProof.Response0)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidPredicateProof, "decoding response of bit %d: %s", i, err)
+		}
+		response1, err := decodeScalar(bitProof.Response1)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidPredicateProof, "decoding response of bit %d: %s", i, err)
+		}
+		var challenge1 bls12381.Scalar
+		challenge1.Sub(challenge, challenge0)
+
+		sum.Add(sum, mul(powerOfTwo(i), bitCommitment))
+		points = append(points, bitCommitment,
+			simulatedCommitment(response0, challenge0, bitBranch(bitCommitment, 0)),
+			simulatedCommitment(response1, &challenge1, bitBranch(bitCommitment, 1)))
+	}
+	if !sum.IsEqual(target) {
+		return nil, errors.Wrap(ErrInvalidPredicateProof, "bit commitments do not sum to the commitment")
+	}
+	return points, nil
+}
+
+// bitBranch returns the point which is a multiple of the blinding generator if the commitment is to the bit
+func bitBranch(commitment *bls12381.G1, bit uint64) *bls12381.G1 {
+	if bit == 0 {
+		return commitment
+	}
+	return sub(commitment, generatorG)
+}
+
+// simulatedCommitment returns the commitment of a proof of knowledge of the discrete logarithm of the point with
+// respect to the blinding generator, given its response and challenge
+func simulatedCommitment(response, challenge *bls12381.Scalar, point *bls12381.G1) *bls12381.G1 {
+	return sub(mul(response, generatorH), mul(challenge, point))
+}
+
+// transcriptChallenge hashes the request, the commitment to the attribute, and the points of a proof to its challenge
+func transcriptChallenge(request PredicateRequest, commitment *bls12381.G1, points ...*bls12381.G1) *bls12381.Scalar {
+	h := sha512.New()
+	write := func(data []byte) {
+		_ = binary.Write(h, binary.BigEndian, uint64(len(data)))
+		_, _ = h.Write(data)
+	}
+	write(transcriptDST)
+	write([]byte(ProofType))
+	write([]byte(request.Attribute))
+	write([]byte(request.Predicate.Operator))
+	write([]byte(strconv.FormatInt(request.Predicate.Value, 10)))
+	write([]byte(request.Nonce))
+	write(commitment.BytesCompressed())
+	for _, p := range points {
+		write(p.BytesCompressed())
+	}
+	var challenge bls12381.Scalar
+	challenge.SetBytes(h.Sum(nil))
+	return &challenge
+}
+
+// commit returns the Pedersen commitment to the value with the blinding
+func commit(value, blinding *bls12381.Scalar) *bls12381.G1 {
+	c := mul(value, generatorG)
+	c.Add(c, mul(blinding, generatorH))
+	return c
+}
+
+func hashGenerator() *bls12381.G1 {
+	var h bls12381.G1
+	h.Hash([]byte("blinding generator"), transcriptDST)
+	return &h
+}
+
+func mul(k *bls12381.Scalar, p *bls12381.G1) *bls12381.G1 {
+	var product bls12381.G1
+	product.ScalarMult(k, p)
+	return &product
+}
+
+// sub returns p - q
+func sub(p, q *bls12381.G1) *bls12381.G1 {
+	negated := *q
+	negated.Neg()
+	var difference bls12381.G1
+	difference.Add(p, &negated)
+	return &difference
+}
+
+func randomScalar() (*bls12381.Scalar, error) {
+	var s bls12381.Scalar
+	if err := s.Random(rand.Reader); err != nil {
+		return nil, errors.Wrap(err, "generating random scalar")
+	}
+	return &s, nil
+}
+
+// scalarFromInt returns the scalar of the value, negative values being their additive inverse
+func scalarFromInt(value int64) *bls12381.Scalar {
+	var s bls12381.Scalar
+	if value >= 0 {
+		s.SetUint64(uint64(value))
+		return &s
+	}
+	// negating in unsigned arithmetic handles the smallest int64, whose negation overflows as an int64
+	s.SetUint64(-uint64(value))
+	s.Neg()
+	return &s
+}
+
+func powerOfTwo(i int) *bls12381.Scalar {
+	var s bls12381.Scalar
+	s.SetUint64(1 << uint(i))
+	return &s
+}
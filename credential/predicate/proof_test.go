@@ -0,0 +1,211 @@
+package predicate
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// getTestCommittedCredential returns a credential with the attribute committed to the value, and its opening
+func getTestCommittedCredential(t *testing.T, attribute string, value int64) (credential.VerifiableCredential, AttributeOpening) {
+	subject := credential.CredentialSubject{"id": "did:example:123"}
+	opening, err := CommitAttribute(subject, attribute, value)
+	require.NoError(t, err)
+	return credential.VerifiableCredential{
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            "did:example:issuer",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: subject,
+	}, *opening
+}
+
+func TestPredicateProof(t *testing.T) {
+	t.Run("proofs of satisfied predicates verify", func(tt *testing.T) {
+		tests := []struct {
+			value     int64
+			predicate Predicate
+		}{
+			{21, Predicate{Operator: GreaterOrEqual, Value: 18}},
+			{18, Predicate{Operator: GreaterOrEqual, Value: 18}},
+			{-5, Predicate{Operator: GreaterOrEqual, Value: -10}},
+			{math.MaxInt64, Predicate{Operator: GreaterOrEqual, Value: math.MinInt64}},
+			{17, Predicate{Operator: LessOrEqual, Value: 18}},
+			{18, Predicate{Operator: LessOrEqual, Value: 18}},
+			{math.MinInt64, Predicate{Operator: LessOrEqual, Value: math.MaxInt64}},
+			{42, Predicate{Operator: Equal, Value: 42}},
+			{-42, Predicate{Operator: Equal, Value: -42}},
+		}
+		for _, test := range tests {
+			cred, opening := getTestCommittedCredential(tt, "value", test.value)
+			request := PredicateRequest{Attribute: "value", Predicate: test.predicate, Nonce: uuid.NewString()}
+			proof, err := DerivePredicateProof(cred, opening, request)
+			require.NoError(tt, err, "%d %s %d", test.value, test.predicate.Operator, test.predicate.Value)
+			assert.NoError(tt, VerifyPredicateProof(cred, request, *proof), "%d %s %d", test.value, test.predicate.Operator, test.predicate.Value)
+		}
+	})
+
+	t.Run("proofs do not disclose the attribute", func(tt *testing.T) {
+		cred, opening := getTestCommittedCredential(tt, "age", 21)
+		request := PredicateRequest{Attribute: "age", Predicate: Predicate{Operator: GreaterOrEqual, Value: 18}}
+		first, err := DerivePredicateProof(cred, opening, request)
+		require.NoError(tt, err)
+		second, err := DerivePredicateProof(cred, opening, request)
+		require.NoError(tt, err)
+		assert.NotEqual(tt, first.Bits[0].Commitment, second.Bits[0].Commitment)
+		assert.NotEqual(tt, first.Challenge, second.Challenge)
+	})
+
+	t.Run("unsatisfied predicates cannot be proven", func(tt *testing.T) {
+		cred, opening := getTestCommittedCredential(tt, "age", 17)
+		for _, predicate := range []Predicate{
+			{Operator: GreaterOrEqual, Value: 18},
+			{Operator: LessOrEqual, Value: 16},
+			{Operator: Equal, Value: 18},
+		} {
+			_, err := DerivePredicateProof(cred, opening, PredicateRequest{Attribute: "age", Predicate: predicate})
+			assert.ErrorIs(tt, err, ErrPredicateNotSatisfied)
+		}
+		_, err := DerivePredicateProof(cred, opening, PredicateRequest{Attribute: "age", Predicate: Predicate{Operator: "!="}})
+		assert.ErrorContains(tt, err, "unknown operator<!=>")
+	})
+
+	t.Run("openings must open the commitment", func(tt *testing.T) {
+		cred, opening := getTestCommittedCredential(tt, "age", 17)
+		request := PredicateRequest{Attribute: "age", Predicate: Predicate{Operator: GreaterOrEqual, Value: 18}}
+		lied := opening
+		lied.Value = 21
+		_, err := DerivePredicateProof(cred, lied, request)
+		assert.ErrorIs(tt, err, ErrInvalidOpening)
+
+		_, other := getTestCommittedCredential(tt, "age", 17)
+		_, err = DerivePredicateProof(cred, other, request)
+		assert.ErrorIs(tt, err, ErrInvalidOpening)
+
+		_, err = DerivePredicateProof(cred, AttributeOpening{Value: 17, Blinding: "not a scalar"}, request)
+		assert.ErrorIs(tt, err, ErrInvalidOpening)
+	})
+
+	t.Run("proofs are bound to the request", func(tt *testing.T) {
+		cred, opening := getTestCommittedCredential(tt, "age", 21)
+		request := PredicateRequest{Attribute: "age", Predicate: Predicate{Operator: GreaterOrEqual, Value: 18}, Nonce: "nonce"}
+		proof, err := DerivePredicateProof(cred, opening, request)
+		require.NoError(tt, err)
+
+		replayed := request
+		replayed.Nonce = "other nonce"
+		assert.ErrorIs(tt, VerifyPredicateProof(cred, replayed, *proof), ErrInvalidPredicateProof)
+
+		stronger := request
+		stronger.Predicate.Value = 21
+		assert.ErrorIs(tt, VerifyPredicateProof(cred, stronger, *proof), ErrInvalidPredicateProof)
+		// claiming the proof is of the stronger predicate does not help
+		relabeled := *proof
+		relabeled.Predicate = stronger.Predicate
+		assert.ErrorIs(tt, VerifyPredicateProof(cred, stronger, relabeled), ErrInvalidPredicateProof)
+
+		// nor does presenting it with another credential
+		otherCred, _ := getTestCommittedCredential(tt, "age", 21)
+		assert.ErrorIs(tt, VerifyPredicateProof(otherCred, request, *proof), ErrInvalidPredicateProof)
+	})
+
+	t.Run("tampered proofs do not verify", func(tt *testing.T) {
+		cred, opening := getTestCommittedCredential(tt, "age", 21)
+		request := PredicateRequest{Attribute: "age", Predicate: Predicate{Operator: GreaterOrEqual, Value: 18}}
+		proof, err := DerivePredicateProof(cred, opening, request)
+		require.NoError(tt, err)
+		equality := PredicateRequest{Attribute: "age", Predicate: Predicate{Operator: Equal, Value: 21}}
+		equalityProof, err := DerivePredicateProof(cred, opening, equality)
+		require.NoError(tt, err)
+
+		tampered := func(change func(p *PredicateProof)) PredicateProof {
+			copied := *proof
+			copied.Bits = append([]BitProof(nil), proof.Bits...)
+			change(&copied)
+			return copied
+		}
+		for name, bad := range map[string]PredicateProof{
+			"swapped bits":       tampered(func(p *PredicateProof) { p.Bits[0], p.Bits[1] = p.Bits[1], p.Bits[0] }),
+			"missing bit":        tampered(func(p *PredicateProof) { p.Bits = p.Bits[1:] }),
+			"changed response":   tampered(func(p *PredicateProof) { p.Bits[3].Response0 = proof.Bits[3].Response1 }),
+			"changed challenge":  tampered(func(p *PredicateProof) { p.Challenge = proof.Bits[0].Challenge0 }),
+			"bad challenge":      tampered(func(p *PredicateProof) { p.Challenge = "AAAA" }),
+			"bad commitment":     tampered(func(p *PredicateProof) { p.Bits[2].Commitment = "AAAA" }),
+			"equality response":  tampered(func(p *PredicateProof) { p.Response = equalityProof.Response }),
+			"unknown proof type": tampered(func(p *PredicateProof) { p.Type = "OtherProof" }),
+		} {
+			assert.ErrorIs(tt, VerifyPredicateProof(cred, request, bad), ErrInvalidPredicateProof, name)
+		}
+
+		badEquality := *equalityProof
+		badEquality.Response = proof.Bits[0].Response0
+		assert.ErrorIs(tt, VerifyPredicateProof(cred, equality, badEquality), ErrInvalidPredicateProof)
+		badEquality = *equalityProof
+		badEquality.Bits = proof.Bits
+		assert.ErrorIs(tt, VerifyPredicateProof(cred, equality, badEquality), ErrInvalidPredicateProof)
+	})
+}
+
+func TestMinimumAgeProof(t *testing.T) {
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	issuer, err := jwx.NewJWXSigner(didKey.String(), &expanded.VerificationMethod[0].ID, privKey)
+	require.NoError(t, err)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	// the issuer commits to the birth date in place of disclosing it, giving the opening to the holder
+	subject := credential.CredentialSubject{"id": "did:example:holder"}
+	opening, err := CommitAttribute(subject, "birthDate", EncodeDate(time.Date(2004, 2, 29, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	token, err := integrity.SignVerifiableCredentialJWT(*issuer, credential.VerifiableCredential{
+		ID:                uuid.NewString(),
+		Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            issuer.ID,
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: subject,
+	})
+	require.NoError(t, err)
+	openingBytes, err := json.Marshal(opening)
+	require.NoError(t, err)
+
+	// the holder proves they are old enough to the verifier, who verifies the credential and the proof
+	var heldOpening AttributeOpening
+	require.NoError(t, json.Unmarshal(openingBytes, &heldOpening))
+	parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(string(token))
+	require.NoError(t, err)
+	at := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	request := MinimumAgeRequest("birthDate", 18, at, uuid.NewString())
+	proof, err := DerivePredicateProof(*parsed.Trust().Credential, heldOpening, request)
+	require.NoError(t, err)
+	proofBytes, err := json.Marshal(proof)
+	require.NoError(t, err)
+	assert.NotContains(t, string(proofBytes), "2004")
+
+	verified, err := integrity.VerifyJWTCredential(context.Background(), string(token), resolver)
+	require.NoError(t, err)
+	require.True(t, verified)
+	var presented PredicateProof
+	require.NoError(t, json.Unmarshal(proofBytes, &presented))
+	assert.NoError(t, VerifyPredicateProof(*parsed.Trust().Credential, request, presented))
+
+	// the holder is not old enough for a higher minimum age
+	_, err = DerivePredicateProof(*parsed.Trust().Credential, heldOpening, MinimumAgeRequest("birthDate", 25, at, "nonce"))
+	assert.ErrorIs(t, err, ErrPredicateNotSatisfied)
+}
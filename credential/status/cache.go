@@ -0,0 +1,210 @@
+package status
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/bits-and-blooms/bitset"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// StatusList is a decoded status list credential
+type StatusList struct {
+	// ID is the URL the status list credential was fetched from
+	ID            string
+	Issuer        string
+	StatusPurpose StatusPurpose
+	bits          *bitset.BitSet
+}
+
+// IsSet returns whether the bit at the given index of the status list is set
+func (s StatusList) IsSet(index uint) bool {
+	return s.bits.Test(index)
+}
+
+type cachedStatusList struct {
+	list      *StatusList
+	expiresAt time.Time
+}
+
+// StatusListCache fetches, verifies, and decodes status list credentials, keeping each decoded list in memory until
+// its TTL expires so that many credentials referencing the same list can be checked with a single fetch.
+type StatusListCache struct {
+	resolver resolution.Resolver
+	client   *http.Client
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	lists map[string]cachedStatusList
+	// now is replaceable for testing expiry
+	now func() time.Time
+}
+
+// NewStatusListCache creates a cache which fetches status list credentials with the given client, verifies them
+// against their issuer's DID resolved with the given resolver, and keeps them for the given TTL.
+func NewStatusListCache(resolver resolution.Resolver, client *http.Client, ttl time.Duration) (*StatusListCache, error) {
+	if resolver == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	if client == nil {
+		return nil, errors.New("client cannot be empty")
+	}
+	if ttl <= 0 {
+		return nil, errors.Errorf("cache ttl<%s> must be positive", ttl)
+	}
+	return &StatusListCache{
+		resolver: resolver,
+		client:   client,
+		ttl:      ttl,
+		lists:    make(map[string]cachedStatusList),
+		now:      time.Now,
+	}, nil
+}
+
+// Get returns the decoded status list credential at the given URL, fetching and verifying it if it is not cached or
+// its cache entry has expired.
+func (c *StatusListCache) Get(ctx context.Context, url string) (*StatusList, error) {
+	c.mu.Lock()
+	cached, ok := c.lists[url]
+	c.mu.Unlock()
+	if ok && c.now().Before(cached.expiresAt) {
+		return cached.list, nil
+	}
+
+	list, err := c.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.lists[url] = cachedStatusList{list: list, expiresAt: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return list, nil
+}
+
+// fetch gets the status list credential at the given URL, in JWT or JSON form, verifies its signature, and decodes
+// its bitstring
+func (c *StatusListCache) fetch(ctx context.Context, url string) (*StatusList, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting status list credential<%s>", url)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("getting status list credential<%s>, status code: %d", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading status list credential<%s>", url)
+	}
+
+	statusCredential, err := c.parseAndVerify(ctx, strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "verifying status list credential<%s>", url)
+	}
+	subject, err := getStatusListSubject(*statusCredential)
+	if err != nil {
+		return nil, err
+	}
+	bits, err := expandBitstring(subject.EncodedList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not expand compressed bitstring of status credential<%s>", url)
+	}
+	return &StatusList{
+		ID:            url,
+		Issuer:        statusCredential.IssuerID(),
+		StatusPurpose: subject.StatusPurpose,
+		bits:          bits,
+	}, nil
+}
+
+func (c *StatusListCache) parseAndVerify(ctx context.Context, token string) (*credential.VerifiableCredential, error) {
+	if _, err := integrity.VerifyCredentialSignature(ctx, token, c.resolver); err != nil {
+		return nil, err
+	}
+	var statusCredential credential.VerifiableCredential
+	if err := json.Unmarshal([]byte(token), &statusCredential); err == nil {
+		return &statusCredential, nil
+	}
+	parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing status list credential")
+	}
+	return parsed.Trust().Credential, nil
+}
+
+// StatusChecker checks the status of credentials against their StatusList2021 status list credentials, which are
+// cached for a configurable TTL
+type StatusChecker struct {
+	cache *StatusListCache
+}
+
+// NewStatusChecker creates a StatusChecker backed by a StatusListCache with the given resolver, client, and TTL
+func NewStatusChecker(resolver resolution.Resolver, client *http.Client, cacheTTL time.Duration) (*StatusChecker, error) {
+	cache, err := NewStatusListCache(resolver, client, cacheTTL)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating status list cache")
+	}
+	return &StatusChecker{cache: cache}, nil
+}
+
+// IsRevoked returns whether the credential has been revoked according to the revocation status list referenced by
+// its StatusList2021Entry credentialStatus property.
+// NOTE: this method does not perform signature verification of the credential itself
+func (s *StatusChecker) IsRevoked(ctx context.Context, cred credential.VerifiableCredential) (bool, error) {
+	return s.isRevoked(ctx, "credential", cred.ID, cred.IssuerID(), cred.CredentialStatus)
+}
+
+// IsPresentationRevoked returns whether the presentation has been revoked according to the revocation status list
+// referenced by its StatusList2021Entry credentialStatus property, which must be issued by the presentation's holder.
+// It is an integrity.PresentationStatusChecker, so presentations can be checked as they are verified.
+// NOTE: this method does not perform signature verification of the presentation itself
+func (s *StatusChecker) IsPresentationRevoked(ctx context.Context, vp credential.VerifiablePresentation) (bool, error) {
+	return s.isRevoked(ctx, "presentation", vp.ID, vp.Holder, vp.CredentialStatus)
+}
+
+var _ integrity.PresentationStatusChecker = (*StatusChecker)(nil)
+
+// isRevoked checks the credentialStatus property of the credential or presentation, of the given kind, id and issuer
+func (s *StatusChecker) isRevoked(ctx context.Context, kind, id, issuer string, credentialStatus any) (bool, error) {
+	entry, err := getStatusEntry(credentialStatus)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s<%s> not using the StatusList2021 credentialStatus property", kind, id)
+	}
+	if entry.StatusPurpose != StatusRevocation {
+		return false, errors.Errorf("%s<%s> has a status purpose<%s>, not %s", kind, id, entry.StatusPurpose, StatusRevocation)
+	}
+	index, err := strconv.ParseUint(entry.StatusListIndex, 10, 0)
+	if err != nil {
+		return false, errors.Errorf("invalid status list index value, not a valid positive integer: %s", entry.StatusListIndex)
+	}
+
+	list, err := s.cache.Get(ctx, entry.StatusListCredential)
+	if err != nil {
+		return false, err
+	}
+	if list.StatusPurpose != entry.StatusPurpose {
+		return false, errors.Errorf("purpose of %s to validate<%s>: %s, did not match purpose of status "+
+			"credential<%s>: %s", kind, id, entry.StatusPurpose, list.ID, list.StatusPurpose)
+	}
+	if list.Issuer != issuer {
+		return false, errors.Errorf("issuer<%s> of status credential<%s> does not match issuer<%s> of %s<%s>",
+			list.Issuer, list.ID, issuer, kind, id)
+	}
+	return list.IsSet(uint(index)), nil
+}
@@ -0,0 +1,194 @@
+package status
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestStatusChecker(t *testing.T) {
+	const statusListURL = "https://example.com/status/1"
+
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	privKey, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	kid := expanded.VerificationMethod[0].ID
+	signer, err := jwx.NewJWXSigner(didKey.String(), &kid, privKey)
+	require.NoError(t, err)
+
+	newCredential := func(index string, purpose StatusPurpose) credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                "test-credential-" + index,
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "test-vc-id"},
+			CredentialStatus: StatusList2021Entry{
+				ID:                   statusListURL + "#" + index,
+				Type:                 StatusList2021EntryType,
+				StatusPurpose:        purpose,
+				StatusListIndex:      index,
+				StatusListCredential: statusListURL,
+			},
+		}
+	}
+	signStatusList := func(revoked ...credential.VerifiableCredential) string {
+		statusListCredential, err := GenerateStatusList2021Credential(statusListURL, signer.ID, StatusRevocation, revoked)
+		require.NoError(t, err)
+		signed, err := integrity.SignVerifiableCredentialJWT(*signer, *statusListCredential)
+		require.NoError(t, err)
+		return string(signed)
+	}
+
+	revokedCred := newCredential("123", StatusRevocation)
+	validCred := newCredential("456", StatusRevocation)
+
+	t.Run("answers many queries from one fetch", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Times(1).Reply(200).BodyString(signStatusList(revokedCred))
+
+		checker, err := NewStatusChecker(resolver, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+
+		revoked, err := checker.IsRevoked(context.Background(), revokedCred)
+		assert.NoError(tt, err)
+		assert.True(tt, revoked)
+
+		revoked, err = checker.IsRevoked(context.Background(), validCred)
+		assert.NoError(tt, err)
+		assert.False(tt, revoked)
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("refetches after the ttl expires", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Times(1).Reply(200).BodyString(signStatusList(revokedCred))
+		gock.New("https://example.com").Get("/status/1").Times(1).Reply(200).BodyString(signStatusList(revokedCred, validCred))
+
+		checker, err := NewStatusChecker(resolver, http.DefaultClient, time.Minute)
+		require.NoError(tt, err)
+		now := time.Now()
+		checker.cache.now = func() time.Time { return now }
+
+		revoked, err := checker.IsRevoked(context.Background(), validCred)
+		assert.NoError(tt, err)
+		assert.False(tt, revoked)
+
+		now = now.Add(2 * time.Minute)
+		revoked, err = checker.IsRevoked(context.Background(), validCred)
+		assert.NoError(tt, err)
+		assert.True(tt, revoked)
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("status list that fails verification", func(tt *testing.T) {
+		defer gock.Off()
+		otherPrivKey, _, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		// signed with a key that is not in the issuer's DID document
+		otherSigner, err := jwx.NewJWXSigner(didKey.String(), &kid, otherPrivKey)
+		require.NoError(tt, err)
+		statusListCredential, err := GenerateStatusList2021Credential(statusListURL, otherSigner.ID, StatusRevocation, nil)
+		require.NoError(tt, err)
+		signed, err := integrity.SignVerifiableCredentialJWT(*otherSigner, *statusListCredential)
+		require.NoError(tt, err)
+		gock.New("https://example.com").Get("/status/1").Reply(200).BodyString(string(signed))
+
+		checker, err := NewStatusChecker(resolver, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		_, err = checker.IsRevoked(context.Background(), validCred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifying status list credential<https://example.com/status/1>")
+	})
+
+	t.Run("status list not found", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Reply(404)
+
+		checker, err := NewStatusChecker(resolver, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		_, err = checker.IsRevoked(context.Background(), validCred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "status code: 404")
+	})
+
+	t.Run("credential without a revocation status", func(tt *testing.T) {
+		checker, err := NewStatusChecker(resolver, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+
+		_, err = checker.IsRevoked(context.Background(), newCredential("123", StatusSuspension))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has a status purpose<suspension>")
+
+		noStatus := validCred
+		noStatus.CredentialStatus = nil
+		_, err = checker.IsRevoked(context.Background(), noStatus)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "not using the StatusList2021 credentialStatus property")
+	})
+
+	t.Run("checks the status of presentations", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://example.com").Get("/status/1").Times(1).Reply(200).BodyString(signStatusList(revokedCred))
+
+		checker, err := NewStatusChecker(resolver, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		verifier, err := signer.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+		signPresentation := func(index string) string {
+			signed, err := integrity.SignVerifiablePresentationJWT(*signer, &integrity.JWTVVPParameters{Audience: []string{verifier.ID}}, credential.VerifiablePresentation{
+				Context: []string{"https://www.w3.org/2018/credentials/v1"},
+				ID:      "test-presentation-" + index,
+				Type:    []string{"VerifiablePresentation"},
+				Holder:  signer.ID,
+				CredentialStatus: StatusList2021Entry{
+					ID:                   statusListURL + "#" + index,
+					Type:                 StatusList2021EntryType,
+					StatusPurpose:        StatusRevocation,
+					StatusListIndex:      index,
+					StatusListCredential: statusListURL,
+				},
+			})
+			require.NoError(tt, err)
+			return string(signed)
+		}
+
+		_, _, _, err = integrity.VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation("123"), integrity.WithPresentationStatus(checker))
+		assert.ErrorIs(tt, err, integrity.ErrRevoked)
+		_, _, vp, err := integrity.VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, signPresentation("456"), integrity.WithPresentationStatus(checker))
+		assert.NoError(tt, err)
+		assert.NotNil(tt, vp.CredentialStatus)
+
+		// the status list must be issued by the holder
+		other := credential.VerifiablePresentation{ID: "test-presentation-456", Holder: "did:example:other", CredentialStatus: vp.CredentialStatus}
+		_, err = checker.IsPresentationRevoked(context.Background(), other)
+		assert.ErrorContains(tt, err, "does not match issuer<did:example:other> of presentation")
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("invalid arguments", func(tt *testing.T) {
+		_, err := NewStatusChecker(nil, http.DefaultClient, time.Hour)
+		assert.ErrorContains(tt, err, "resolver cannot be empty")
+		_, err = NewStatusChecker(resolver, nil, time.Hour)
+		assert.ErrorContains(tt, err, "client cannot be empty")
+		_, err = NewStatusChecker(resolver, http.DefaultClient, 0)
+		assert.ErrorContains(tt, err, "must be positive")
+	})
+}
@@ -66,6 +66,66 @@ func GenerateStatusList2021Credential(id string, issuer string, purpose StatusPu
 		return nil, errors.Wrap(err, "generating bitstring for status list credential")
 	}
 
+	return buildStatusListCredential(id, issuer, purpose, bitString)
+}
+
+// BuildStatusListCredential builds a revocation status list credential, hosted at the listID, in which the bits at
+// the revokedIndices are set, ready to be signed by the issuer with integrity.SignVerifiableCredentialJWT. The list
+// has size entries, its bitstring being no shorter than the 16KB of those GenerateStatusList2021Credential generates.
+func BuildStatusListCredential(issuerDID, listID string, revokedIndices []int, size int) (*credential.VerifiableCredential, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid status list size<%d>, must be positive", size)
+	}
+	b := bitset.New(uint(max(size, 16*KB)))
+	for _, index := range revokedIndices {
+		if index < 0 || index >= size {
+			return nil, fmt.Errorf("status list index<%d> out of range for a list of size %d", index, size)
+		}
+		if b.Test(uint(index)) {
+			return nil, fmt.Errorf("duplicate status list index value found: %d", index)
+		}
+		b.Set(uint(index))
+	}
+	bitString, err := compressBitstring(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating bitstring for status list credential")
+	}
+	return buildStatusListCredential(listID, issuerDID, StatusRevocation, bitString)
+}
+
+// SetRevoked returns a copy of the status list credential in which the bit at the index is set, to be signed again
+// by the issuer. Any proof of the existing list is removed, as it no longer holds for the new list.
+func SetRevoked(existingList credential.VerifiableCredential, index int) (*credential.VerifiableCredential, error) {
+	subject, err := getStatusListSubject(existingList)
+	if err != nil {
+		return nil, err
+	}
+	b, err := expandBitstring(subject.EncodedList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not expand compressed bitstring of status credential<%s>", existingList.ID)
+	}
+	if index < 0 || uint(index) >= b.Len() {
+		return nil, fmt.Errorf("status list index<%d> out of range for a list of size %d", index, b.Len())
+	}
+	b.Set(uint(index))
+	bitString, err := compressBitstring(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating bitstring for status list credential")
+	}
+
+	updatedSubject := make(credential.CredentialSubject, len(existingList.CredentialSubject))
+	for k, v := range existingList.CredentialSubject {
+		updatedSubject[k] = v
+	}
+	updatedSubject["encodedList"] = bitString
+	updated := existingList
+	updated.CredentialSubject = updatedSubject
+	updated.Proof = nil
+	return &updated, nil
+}
+
+// buildStatusListCredential wraps an encoded bitstring in a status list credential
+func buildStatusListCredential(id string, issuer string, purpose StatusPurpose, bitString string) (*credential.VerifiableCredential, error) {
 	rlc := StatusList2021Credential{
 		ID:            id,
 		Type:          StatusList2021Type,
@@ -75,16 +135,16 @@ func GenerateStatusList2021Credential(id string, issuer string, purpose StatusPu
 
 	builder := credential.NewVerifiableCredentialBuilder(credential.GenerateIDValue)
 	errMsgFragment := "could not generate status list credential: error setting "
-	if err = builder.SetID(id); err != nil {
+	if err := builder.SetID(id); err != nil {
 		return nil, errors.Wrap(err, errMsgFragment+"id")
 	}
-	if err = builder.SetIssuer(issuer); err != nil {
+	if err := builder.SetIssuer(issuer); err != nil {
 		return nil, errors.Wrap(err, errMsgFragment+"issuer")
 	}
-	if err = builder.AddContext(StatusList2021Context); err != nil {
+	if err := builder.AddContext(StatusList2021Context); err != nil {
 		return nil, errors.Wrap(err, errMsgFragment+"context")
 	}
-	if err = builder.AddType(StatusList2021CreddentialType); err != nil {
+	if err := builder.AddType(StatusList2021CreddentialType); err != nil {
 		return nil, errors.Wrap(err, errMsgFragment+"type")
 	}
 	rlcJSON, err := util.ToJSONMap(rlc)
@@ -183,13 +243,19 @@ func bitstringGeneration(statusListCredentialIndices []string) (string, error) {
 		b.Set(indexValue)
 	}
 
+	// 3. Generate a compressed bitstring by using the GZIP compression algorithm [RFC1952] on the bitstring and then
+	// base64-encoding [RFC4648] the result.
+	// 4. Return the compressed bitstring.
+	return compressBitstring(b)
+}
+
+// compressBitstring GZIP compresses and base64 encodes the bitstring of a status list
+func compressBitstring(b *bitset.BitSet) (string, error) {
 	bitstringBinary, err := b.MarshalBinary()
 	if err != nil {
 		return "", errors.Wrap(err, "generating bitstring binary representation")
 	}
 
-	// 3. Generate a compressed bitstring by using the GZIP compression algorithm [RFC1952] on the bitstring and then
-	// base64-encoding [RFC4648] the result.
 	var buf bytes.Buffer
 	zw := gzip.NewWriter(&buf)
 	if _, err = zw.Write(bitstringBinary); err != nil {
@@ -200,14 +266,29 @@ func bitstringGeneration(statusListCredentialIndices []string) (string, error) {
 		return "", errors.Wrap(err, "closing gzip writer")
 	}
 
-	base64Bitstring := base64.StdEncoding.EncodeToString(buf.Bytes())
-
-	// 4. Return the compressed bitstring.
-	return base64Bitstring, nil
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
 }
 
 // https://w3c-ccg.github.io/vc-status-list-2021/#bitstring-expansion-algorithm
 func bitstringExpansion(compressedBitstring string) ([]string, error) {
+	b, err := expandBitstring(compressedBitstring)
+	if err != nil {
+		return nil, err
+	}
+
+	// find set bits to reconstruct the status list indices
+	var expanded []string
+	var i uint
+	for i = 0; i < b.Len(); i++ {
+		if b.Test(i) {
+			expanded = append(expanded, strconv.FormatUint(uint64(i), 10))
+		}
+	}
+	return expanded, nil
+}
+
+// expandBitstring decodes and decompresses a status list bitstring into a bitset
+func expandBitstring(compressedBitstring string) (*bitset.BitSet, error) {
 	// 1. Let compressed bitstring be a compressed status list bitstring.
 
 	// 2. Generate an uncompressed bitstring by using the base64-decoding [RFC4648] algorithm on the compressed
@@ -236,16 +317,7 @@ func bitstringExpansion(compressedBitstring string) ([]string, error) {
 	if err := b.UnmarshalBinary(unzipped); err != nil {
 		return nil, errors.Wrap(err, "unmarshaling binary bitstring")
 	}
-
-	// find set bits to reconstruct the status list indices
-	var expanded []string
-	var i uint
-	for i = 0; i < b.Len(); i++ {
-		if b.Test(i) {
-			expanded = append(expanded, strconv.FormatUint(uint64(i), 10))
-		}
-	}
-	return expanded, nil
+	return b, nil
 }
 
 // ValidateCredentialInStatusList determines whether a credential is contained in a status list 2021 credential
@@ -266,17 +338,9 @@ func ValidateCredentialInStatusList(credentialToValidate credential.VerifiableCr
 	// NOTE: this step is assumed to be done *external* to this method call
 
 	// 4. Verify that the status purpose matches the statusPurpose value in the statusListCredential.
-	var statusCredentialValue StatusList2021Credential
-	subjectBytes, err := json.Marshal(statusCredential.CredentialSubject)
+	statusCredentialValue, err := getStatusListSubject(statusCredential)
 	if err != nil {
-		return false, errors.Wrapf(err, "could not marshal status credential<%s> subject value", statusCredential.ID)
-	}
-	if err = json.Unmarshal(subjectBytes, &statusCredentialValue); err != nil {
-		return false, errors.Wrapf(err, "could not unmarshal status credential<%s> subject value into "+
-			"StatusList2021Credential", statusCredential.ID)
-	}
-	if err = util.IsValidStruct(statusCredentialValue); err != nil {
-		return false, errors.Wrapf(err, "credential<%s> is not a valid status credential", statusCredential.ID)
+		return false, err
 	}
 	if statusPurpose != statusCredentialValue.StatusPurpose {
 		return false, fmt.Errorf("purpose of credential to validate<%s>: %s, did not match purpose of status "+
@@ -305,6 +369,23 @@ func ValidateCredentialInStatusList(credentialToValidate credential.VerifiableCr
 	return false, nil
 }
 
+// getStatusListSubject returns the validated StatusList2021Credential subject of a status list credential
+func getStatusListSubject(statusCredential credential.VerifiableCredential) (*StatusList2021Credential, error) {
+	var statusCredentialValue StatusList2021Credential
+	subjectBytes, err := json.Marshal(statusCredential.CredentialSubject)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not marshal status credential<%s> subject value", statusCredential.ID)
+	}
+	if err = json.Unmarshal(subjectBytes, &statusCredentialValue); err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal status credential<%s> subject value into "+
+			"StatusList2021Credential", statusCredential.ID)
+	}
+	if err = util.IsValidStruct(statusCredentialValue); err != nil {
+		return nil, errors.Wrapf(err, "credential<%s> is not a valid status credential", statusCredential.ID)
+	}
+	return &statusCredentialValue, nil
+}
+
 func toStatusList2021Entry(credStatus any) (*StatusList2021Entry, bool) {
 	statusListEntryValue, ok := credStatus.(StatusList2021Entry)
 	if ok {
@@ -6,8 +6,10 @@ import (
 
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
 )
 
 func TestGenerateStatusList2021Credential(t *testing.T) {
@@ -240,6 +242,81 @@ func TestGenerateStatusList2021Credential(t *testing.T) {
 	})
 }
 
+func TestBuildStatusListCredential(t *testing.T) {
+	listID := "https://example.com/status/1"
+	testIssuer := "did:example:issuer"
+	credWithIndex := func(index string) credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			ID: "test-cred-" + index,
+			CredentialStatus: StatusList2021Entry{
+				ID:                   listID + "#" + index,
+				Type:                 StatusList2021EntryType,
+				StatusPurpose:        StatusRevocation,
+				StatusListIndex:      index,
+				StatusListCredential: listID,
+			},
+		}
+	}
+
+	t.Run("revoked indices are set", func(tt *testing.T) {
+		statusListCredential, err := BuildStatusListCredential(testIssuer, listID, []int{3, 100}, 1000)
+		require.NoError(tt, err)
+		assert.Equal(tt, listID, statusListCredential.ID)
+		assert.Equal(tt, testIssuer, statusListCredential.Issuer)
+		assert.Contains(tt, statusListCredential.Type, StatusList2021CreddentialType)
+		assert.Contains(tt, statusListCredential.Context, StatusList2021Context)
+		assert.Equal(tt, string(StatusRevocation), statusListCredential.CredentialSubject["statusPurpose"])
+
+		for index, revoked := range map[string]bool{"3": true, "100": true, "4": false, "999": false} {
+			valid, err := ValidateCredentialInStatusList(credWithIndex(index), *statusListCredential)
+			assert.NoError(tt, err)
+			assert.Equal(tt, revoked, valid, index)
+		}
+	})
+
+	t.Run("larger than the minimum size", func(tt *testing.T) {
+		statusListCredential, err := BuildStatusListCredential(testIssuer, listID, []int{100000}, 200000)
+		require.NoError(tt, err)
+		valid, err := ValidateCredentialInStatusList(credWithIndex("100000"), *statusListCredential)
+		assert.NoError(tt, err)
+		assert.True(tt, valid)
+	})
+
+	t.Run("invalid indices", func(tt *testing.T) {
+		_, err := BuildStatusListCredential(testIssuer, listID, []int{1000}, 1000)
+		assert.ErrorContains(tt, err, "status list index<1000> out of range for a list of size 1000")
+		_, err = BuildStatusListCredential(testIssuer, listID, []int{-1}, 1000)
+		assert.ErrorContains(tt, err, "status list index<-1> out of range")
+		_, err = BuildStatusListCredential(testIssuer, listID, []int{5, 5}, 1000)
+		assert.ErrorContains(tt, err, "duplicate status list index value found: 5")
+		_, err = BuildStatusListCredential(testIssuer, listID, nil, 0)
+		assert.ErrorContains(tt, err, "invalid status list size<0>")
+	})
+
+	t.Run("set revoked", func(tt *testing.T) {
+		statusListCredential, err := BuildStatusListCredential(testIssuer, listID, []int{3}, 1000)
+		require.NoError(tt, err)
+		var proof crypto.Proof = map[string]any{"type": "JsonWebSignature2020"}
+		statusListCredential.Proof = &proof
+		encodedList := statusListCredential.CredentialSubject["encodedList"]
+
+		updated, err := SetRevoked(*statusListCredential, 7)
+		require.NoError(tt, err)
+		assert.Nil(tt, updated.Proof)
+		assert.Equal(tt, encodedList, statusListCredential.CredentialSubject["encodedList"])
+		for index, revoked := range map[string]bool{"3": true, "7": true, "8": false} {
+			valid, err := ValidateCredentialInStatusList(credWithIndex(index), *updated)
+			assert.NoError(tt, err)
+			assert.Equal(tt, revoked, valid, index)
+		}
+
+		_, err = SetRevoked(*statusListCredential, 16*KB)
+		assert.ErrorContains(tt, err, "out of range for a list of size 16384")
+		_, err = SetRevoked(credential.VerifiableCredential{ID: "not-a-list"}, 1)
+		assert.ErrorContains(tt, err, "credential<not-a-list> is not a valid status credential")
+	})
+}
+
 func TestValidateCredentialInStatusList(t *testing.T) {
 	t.Run("happy path validation", func(tt *testing.T) {
 		revocationID := "revocation-id"
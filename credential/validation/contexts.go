@@ -0,0 +1,184 @@
+package validation
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+const (
+	ContextLoaderOption OptionKey = "contextLoader"
+
+	credentialSubjectProperty = "credentialSubject"
+)
+
+// WithContextLoader provides the document loader ValidateContexts loads the credential's contexts with as a
+// validation option
+func WithContextLoader(loader ld.DocumentLoader) Option {
+	return Option{
+		ID:     ContextLoaderOption,
+		Option: loader,
+	}
+}
+
+// ValidateContexts verifies that every property of a credential's subject, including those of nested objects, is
+// defined by the credential's @context, so that terms an issuer did not define cannot silently pass as data the
+// contexts do not describe. The contexts are loaded with the loader of the WithContextLoader option, or by default
+// with util.OfflineLDDocumentLoader, which only knows the contexts bundled with this module and never fetches remote
+// ones. This check is not one of the known verifiers, and is meant for credentials from untrusted issuers.
+func ValidateContexts(cred credential.VerifiableCredential, opts ...Option) error {
+	loader, err := contextLoader(opts)
+	if err != nil {
+		return err
+	}
+	ldOptions := ld.NewJsonLdOptions("")
+	ldOptions.ProcessingMode = ld.JsonLd_1_1
+	ldOptions.DocumentLoader = loader
+
+	credContext, err := util.AnyToJSONInterface(cred.Context)
+	if err != nil {
+		return errors.Wrap(err, "converting credential context")
+	}
+	activeCtx, err := ld.NewContext(nil, ldOptions).Parse(credContext)
+	if err != nil {
+		return errors.Wrap(err, "processing credential context")
+	}
+
+	// the contexts scoped to the credential's types only apply to its own properties, so only credentialSubject is
+	// read from them
+	credTypes, _ := util.InterfaceToStrings(cred.Type)
+	typesCtx, err := applyTypeScopedContexts(activeCtx, credTypes)
+	if err != nil {
+		return err
+	}
+	if subjectCtx := typesCtx.GetTermDefinition(credentialSubjectProperty)["@context"]; subjectCtx != nil {
+		if activeCtx, err = activeCtx.Parse(subjectCtx); err != nil {
+			return errors.Wrapf(err, "processing context of %s", credentialSubjectProperty)
+		}
+	}
+
+	subject, err := util.AnyToJSONInterface(map[string]any(cred.CredentialSubject))
+	if err != nil {
+		return errors.Wrap(err, "converting credential subject")
+	}
+	undefined, err := undefinedTerms(activeCtx, subject, credentialSubjectProperty)
+	if err != nil {
+		return err
+	}
+	if len(undefined) > 0 {
+		sort.Strings(undefined)
+		return errors.Errorf("properties not defined by the credential's contexts: %s", strings.Join(undefined, ", "))
+	}
+	return nil
+}
+
+func contextLoader(opts []Option) (ld.DocumentLoader, error) {
+	maybeLoader, err := GetValidationOption(opts, ContextLoaderOption)
+	if err != nil {
+		return util.NewOfflineLDDocumentLoader()
+	}
+	loader, ok := maybeLoader.(ld.DocumentLoader)
+	if !ok || loader == nil {
+		return nil, errors.New("the option provided must be created with WithContextLoader")
+	}
+	return loader, nil
+}
+
+// undefinedTerms returns the paths of the properties of a value, which is a node object or an array of them, that do
+// not expand to an IRI or keyword under the active context
+func undefinedTerms(activeCtx *ld.Context, value any, path string) ([]string, error) {
+	if values, ok := value.([]any); ok {
+		var undefined []string
+		for _, v := range values {
+			terms, err := undefinedTerms(activeCtx, v, path)
+			if err != nil {
+				return nil, err
+			}
+			undefined = append(undefined, terms...)
+		}
+		return undefined, nil
+	}
+	node, ok := value.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+
+	var err error
+	if embedded, ok := node["@context"]; ok {
+		if activeCtx, err = activeCtx.Parse(embedded); err != nil {
+			return nil, errors.Wrapf(err, "processing context of %s", path)
+		}
+	}
+	nodeCtx, err := applyTypeScopedContexts(activeCtx, nodeTypes(activeCtx, node))
+	if err != nil {
+		return nil, err
+	}
+
+	var undefined []string
+	for property, v := range node {
+		if property == "@context" {
+			continue
+		}
+		propertyPath := path + "." + property
+		expanded, err := nodeCtx.ExpandIri(property, false, true, nil, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "expanding %s", propertyPath)
+		}
+		if expanded == "" || (!strings.Contains(expanded, ":") && !ld.IsKeyword(expanded)) {
+			undefined = append(undefined, propertyPath)
+			continue
+		}
+		if ld.IsKeyword(expanded) {
+			continue
+		}
+
+		// values are processed with the context of their property, without the node's type-scoped contexts, which
+		// do not propagate
+		valueCtx := activeCtx
+		if scoped := nodeCtx.GetTermDefinition(property)["@context"]; scoped != nil {
+			if valueCtx, err = activeCtx.Parse(scoped); err != nil {
+				return nil, errors.Wrapf(err, "processing context of %s", propertyPath)
+			}
+		}
+		terms, err := undefinedTerms(valueCtx, v, propertyPath)
+		if err != nil {
+			return nil, err
+		}
+		undefined = append(undefined, terms...)
+	}
+	return undefined, nil
+}
+
+// nodeTypes returns the types of a node, under whichever property the active context aliases @type to
+func nodeTypes(activeCtx *ld.Context, node map[string]any) []string {
+	var types []string
+	for property, v := range node {
+		if expanded, err := activeCtx.ExpandIri(property, false, true, nil, nil); err == nil && expanded == "@type" {
+			vTypes, _ := util.InterfaceToStrings(v)
+			types = append(types, vTypes...)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// applyTypeScopedContexts applies the contexts scoped to each of the types, in lexical order
+func applyTypeScopedContexts(activeCtx *ld.Context, types []string) (*ld.Context, error) {
+	scopedCtx := activeCtx
+	for _, t := range types {
+		scoped := activeCtx.GetTermDefinition(t)["@context"]
+		if scoped == nil {
+			continue
+		}
+		var err error
+		if scopedCtx, err = scopedCtx.Parse(scoped); err != nil {
+			return nil, errors.Wrapf(err, "processing context of type<%s>", t)
+		}
+	}
+	return scopedCtx, nil
+}
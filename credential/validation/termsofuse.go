@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/pkg/errors"
+)
+
+const (
+	TermsOfUseOption OptionKey = "termsOfUse"
+
+	// IssuerPolicyType is the termsOfUse type for policies imposed by the issuer of a credential
+	IssuerPolicyType = "IssuerPolicy"
+	// HolderPolicyType is the termsOfUse type for policies imposed by the holder of a credential
+	HolderPolicyType = "HolderPolicy"
+	// AllVerifiers is the prohibition assignee that applies to every verifier
+	AllVerifiers = "AllVerifiers"
+)
+
+// TermsOfUseContext describes how a verifier intends to use a credential, which termsOfUse are evaluated against
+type TermsOfUseContext struct {
+	// VerifierID identifies the party verifying the credential
+	VerifierID string
+	// Purpose is the action the verifier intends to take with the credential, e.g. "Archival"
+	Purpose string
+}
+
+// TermsOfUseEvaluator evaluates termsOfUse entries of one or more types
+type TermsOfUseEvaluator interface {
+	// Supports returns whether the evaluator understands termsOfUse of the given type
+	Supports(termsType string) bool
+	// Evaluate returns an error if the terms do not permit the credential to be used as described by the context
+	Evaluate(cred credential.VerifiableCredential, terms credential.TermsOfUse, ctx TermsOfUseContext) error
+}
+
+type termsOfUseEvaluation struct {
+	ctx        TermsOfUseContext
+	evaluators []TermsOfUseEvaluator
+}
+
+// WithTermsOfUse provides the verification context and the evaluators to run against a credential's termsOfUse as a
+// validation option. If no evaluators are given the HolderOfEvaluator is used.
+func WithTermsOfUse(ctx TermsOfUseContext, evaluators ...TermsOfUseEvaluator) Option {
+	if len(evaluators) == 0 {
+		evaluators = []TermsOfUseEvaluator{HolderOfEvaluator{}}
+	}
+	return Option{
+		ID:     TermsOfUseOption,
+		Option: termsOfUseEvaluation{ctx: ctx, evaluators: evaluators},
+	}
+}
+
+// ValidateTermsOfUse evaluates each of a credential's termsOfUse with the evaluators provided by the WithTermsOfUse
+// option. Without the option the check is skipped; with it, every entry must be understood by one of the evaluators.
+func ValidateTermsOfUse(cred credential.VerifiableCredential, opts ...Option) error {
+	if len(cred.TermsOfUse) == 0 {
+		return nil
+	}
+	maybeEvaluation, err := GetValidationOption(opts, TermsOfUseOption)
+	if err != nil {
+		return nil
+	}
+	evaluation, ok := maybeEvaluation.(termsOfUseEvaluation)
+	if !ok {
+		return errors.New("the option provided must be created with WithTermsOfUse")
+	}
+	for _, terms := range cred.TermsOfUse {
+		evaluator := findTermsOfUseEvaluator(evaluation.evaluators, terms.Type)
+		if evaluator == nil {
+			return errors.Errorf("no evaluator for termsOfUse type<%s>", terms.Type)
+		}
+		if err = evaluator.Evaluate(cred, terms, evaluation.ctx); err != nil {
+			return errors.Wrapf(err, "evaluating termsOfUse<%s>", terms.Type)
+		}
+	}
+	return nil
+}
+
+func findTermsOfUseEvaluator(evaluators []TermsOfUseEvaluator, termsType string) TermsOfUseEvaluator {
+	for _, evaluator := range evaluators {
+		if evaluator.Supports(termsType) {
+			return evaluator
+		}
+	}
+	return nil
+}
+
+// HolderOfEvaluator enforces the prohibitions of IssuerPolicy and HolderPolicy termsOfUse. A prohibition applies when
+// its assignee is the verifier or AllVerifiers, and its target, if set, is the credential. It is violated when it has
+// no actions, which prohibits any use, or when one of its actions is the verifier's purpose.
+type HolderOfEvaluator struct{}
+
+var _ TermsOfUseEvaluator = HolderOfEvaluator{}
+
+func (HolderOfEvaluator) Supports(termsType string) bool {
+	return termsType == IssuerPolicyType || termsType == HolderPolicyType
+}
+
+func (HolderOfEvaluator) Evaluate(cred credential.VerifiableCredential, terms credential.TermsOfUse, ctx TermsOfUseContext) error {
+	for _, prohibition := range terms.Prohibition {
+		if prohibition.Assignee != AllVerifiers && prohibition.Assignee != ctx.VerifierID {
+			continue
+		}
+		if prohibition.Target != "" && prohibition.Target != cred.ID {
+			continue
+		}
+		if len(prohibition.Action) == 0 {
+			return errors.Errorf("verifier<%s> is prohibited from using credential<%s>", ctx.VerifierID, cred.ID)
+		}
+		for _, action := range prohibition.Action {
+			if action == ctx.Purpose {
+				return errors.Errorf("verifier<%s> is prohibited from action<%s> on credential<%s>", ctx.VerifierID, action, cred.ID)
+			}
+		}
+	}
+	return nil
+}
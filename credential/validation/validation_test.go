@@ -5,6 +5,7 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	credschema "github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -97,12 +98,175 @@ func TestValidator(t *testing.T) {
 		err = validator.ValidateCredential(sampleCredential, WithSchema(knownSchema))
 		assert.NoError(tt, err)
 	})
+
+	t.Run("Terms of Use Validator", func(tt *testing.T) {
+		termsOfUse := Validator{
+			ID:           "Terms of Use",
+			ValidateFunc: ValidateTermsOfUse,
+		}
+		validator, err := NewCredentialValidator([]Validator{termsOfUse})
+		assert.NoError(tt, err)
+
+		sampleCredential := getSampleCredential()
+		sampleCredential.TermsOfUse = []credential.TermsOfUse{
+			{
+				Type: IssuerPolicyType,
+				Prohibition: []credential.Prohibition{{
+					Assigner: "test-issuer",
+					Assignee: AllVerifiers,
+					Target:   "test-verifiable-credential",
+					Action:   []string{"Archival"},
+				}},
+			},
+			{
+				Type: HolderPolicyType,
+				Prohibition: []credential.Prohibition{{
+					Assigner: "test-vc-id",
+					Assignee: "did:example:tracker",
+				}},
+			},
+		}
+
+		// no option passed in, terms are not evaluated
+		err = validator.ValidateCredential(sampleCredential)
+		assert.NoError(tt, err)
+
+		// purpose not prohibited
+		err = validator.ValidateCredential(sampleCredential, WithTermsOfUse(TermsOfUseContext{
+			VerifierID: "did:example:verifier",
+			Purpose:    "Verification",
+		}))
+		assert.NoError(tt, err)
+
+		// purpose prohibited for all verifiers by the issuer
+		err = validator.ValidateCredential(sampleCredential, WithTermsOfUse(TermsOfUseContext{
+			VerifierID: "did:example:verifier",
+			Purpose:    "Archival",
+		}))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifier<did:example:verifier> is prohibited from action<Archival> on credential<test-verifiable-credential>")
+
+		// any use prohibited for one verifier by the holder
+		err = validator.ValidateCredential(sampleCredential, WithTermsOfUse(TermsOfUseContext{
+			VerifierID: "did:example:tracker",
+			Purpose:    "Verification",
+		}, HolderOfEvaluator{}))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "evaluating termsOfUse<HolderPolicy>")
+
+		// prohibition targeting another credential does not apply
+		sampleCredential.TermsOfUse[0].Prohibition[0].Target = "other-credential"
+		err = validator.ValidateCredential(sampleCredential, WithTermsOfUse(TermsOfUseContext{
+			VerifierID: "did:example:verifier",
+			Purpose:    "Archival",
+		}))
+		assert.NoError(tt, err)
+
+		// terms no evaluator understands
+		sampleCredential.TermsOfUse = append(sampleCredential.TermsOfUse, credential.TermsOfUse{Type: "TrustFrameworkPolicy"})
+		err = validator.ValidateCredential(sampleCredential, WithTermsOfUse(TermsOfUseContext{
+			VerifierID: "did:example:verifier",
+			Purpose:    "Verification",
+		}))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "no evaluator for termsOfUse type<TrustFrameworkPolicy>")
+
+		// custom evaluator
+		err = validator.ValidateCredential(sampleCredential, WithTermsOfUse(TermsOfUseContext{
+			VerifierID: "did:example:verifier",
+			Purpose:    "Verification",
+		}, HolderOfEvaluator{}, trustFrameworkEvaluator{}))
+		assert.NoError(tt, err)
+	})
+}
+
+type trustFrameworkEvaluator struct{}
+
+func (trustFrameworkEvaluator) Supports(termsType string) bool {
+	return termsType == "TrustFrameworkPolicy"
+}
+
+func (trustFrameworkEvaluator) Evaluate(_ credential.VerifiableCredential, _ credential.TermsOfUse, _ TermsOfUseContext) error {
+	return nil
 }
 
 func NoOpValidator(_ credential.VerifiableCredential, _ ...Option) error {
 	return nil
 }
 
+func TestValidateContexts(t *testing.T) {
+	degreeCredential := func(subject map[string]any, contexts ...any) credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			Context: append([]any{"https://www.w3.org/2018/credentials/v1",
+				"https://www.w3.org/2018/credentials/examples/v1"}, contexts...),
+			ID:                "test-verifiable-credential",
+			Type:              []string{"VerifiableCredential", "UniversityDegreeCredential"},
+			Issuer:            "test-issuer",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: subject,
+		}
+	}
+
+	t.Run("defined properties", func(tt *testing.T) {
+		cred := degreeCredential(map[string]any{
+			"id":       "did:example:123",
+			"alumniOf": "Example University",
+			"degree": map[string]any{
+				"type": "BachelorDegree",
+				"name": "Bachelor of Science and Arts",
+			},
+		})
+		assert.NoError(tt, ValidateContexts(cred))
+	})
+
+	t.Run("undefined properties", func(tt *testing.T) {
+		cred := degreeCredential(map[string]any{
+			"id":  "did:example:123",
+			"ssn": "123-45-6789",
+			"degree": []any{map[string]any{
+				"type": "BachelorDegree",
+				"gpa":  "4.0",
+			}},
+		})
+		err := ValidateContexts(cred)
+		assert.ErrorContains(tt, err, "properties not defined by the credential's contexts: credentialSubject.degree.gpa, credentialSubject.ssn")
+
+		validator, err := NewCredentialValidator([]Validator{{ID: "Contexts", ValidateFunc: ValidateContexts}})
+		assert.NoError(tt, err)
+		assert.Error(tt, validator.ValidateCredential(cred))
+		assert.Error(tt, ValidateContexts(getSampleCredential()))
+	})
+
+	t.Run("properties defined by embedded contexts", func(tt *testing.T) {
+		cred := degreeCredential(map[string]any{
+			"@context": map[string]any{"ssn": "ex:ssn"},
+			"id":       "did:example:123",
+			"ssn":      "123-45-6789",
+		})
+		assert.NoError(tt, ValidateContexts(cred))
+
+		cred = degreeCredential(map[string]any{"id": "did:example:123", "ssn": "123-45-6789"},
+			map[string]any{"@vocab": "https://example.com/vocab#"})
+		assert.NoError(tt, ValidateContexts(cred))
+	})
+
+	t.Run("unknown contexts are not fetched", func(tt *testing.T) {
+		cred := degreeCredential(map[string]any{"id": "did:example:123"}, "https://example.com/contexts/v1")
+		err := ValidateContexts(cred)
+		assert.ErrorContains(tt, err, "context<https://example.com/contexts/v1> is not known offline")
+	})
+
+	t.Run("bad loader option", func(tt *testing.T) {
+		cred := degreeCredential(map[string]any{"id": "did:example:123"})
+		err := ValidateContexts(cred, Option{ID: ContextLoaderOption, Option: "loader"})
+		assert.ErrorContains(tt, err, "must be created with WithContextLoader")
+
+		loader, err := util.NewOfflineLDDocumentLoader()
+		assert.NoError(tt, err)
+		assert.NoError(tt, ValidateContexts(cred, WithContextLoader(loader)))
+	})
+}
+
 func getSampleCredential() credential.VerifiableCredential {
 	return credential.VerifiableCredential{
 		Context: []any{"https://www.w3.org/2018/credentials/v1",
@@ -93,5 +93,9 @@ func GetKnownVerifiers() []Validator {
 			ID:           "VC JSON Schema",
 			ValidateFunc: ValidateJSONSchema,
 		},
+		{
+			ID:           "Terms of Use",
+			ValidateFunc: ValidateTermsOfUse,
+		},
 	}
 }
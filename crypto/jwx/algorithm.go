@@ -0,0 +1,101 @@
+package jwx
+
+import (
+	"sync"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+)
+
+var (
+	algorithmsLock sync.RWMutex
+	// algorithms maps key types to the JSON Web Algorithm used to sign and verify with them
+	algorithms = map[crypto.KeyType]jwa.SignatureAlgorithm{
+		// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
+		crypto.Ed25519:        jwa.EdDSA,
+		crypto.SECP256k1:      jwa.ES256K,
+		crypto.SECP256k1ECDSA: jwa.ES256K,
+		crypto.P256:           jwa.ES256,
+		crypto.P384:           jwa.ES384,
+		crypto.P521:           jwa.ES512,
+		crypto.RSA:            jwa.PS256,
+		crypto.Dilithium2:     DilithiumMode2Alg,
+		crypto.Dilithium3:     DilithiumMode3Alg,
+		crypto.Dilithium5:     DilithiumMode5Alg,
+	}
+)
+
+// RegisterAlgorithm sets the JSON Web Algorithm used to sign and verify with keys of the given type, replacing any
+// existing registration for the key type. The algorithm must also be known to the jwx library, e.g. via
+// jws.RegisterSigner and jws.RegisterVerifier.
+func RegisterAlgorithm(keyType crypto.KeyType, alg jwa.SignatureAlgorithm) {
+	algorithmsLock.Lock()
+	defer algorithmsLock.Unlock()
+	algorithms[keyType] = alg
+}
+
+// AlgorithmForKeyType returns the JSON Web Algorithm registered for the given key type, if any
+func AlgorithmForKeyType(keyType crypto.KeyType) (jwa.SignatureAlgorithm, bool) {
+	algorithmsLock.RLock()
+	defer algorithmsLock.RUnlock()
+	alg, ok := algorithms[keyType]
+	return alg, ok
+}
+
+// SignerAlgorithm returns the JSON Web Algorithm to sign with, as registered for the type of the signer's private
+// key, except that RSA keys sign with RS256 when it is the signer's ALG. The signer's ALG is used as is when its key
+// type has no registered algorithm.
+func SignerAlgorithm(signer Signer) jwa.SignatureAlgorithm {
+	if keyType, err := crypto.GetKeyTypeFromPrivateKey(signer.PrivateKey); err == nil {
+		if keyType == crypto.RSA && signer.ALG == jwa.RS256.String() {
+			return jwa.RS256
+		}
+		if alg, ok := AlgorithmForKeyType(keyType); ok {
+			return alg
+		}
+	}
+	return jwa.SignatureAlgorithm(signer.ALG)
+}
+
+// VerifierAlgorithm returns the JSON Web Algorithm to verify with, as registered for the type of the verifier's
+// public key, except that RSA keys verify with RS256 when it is the verifier's ALG. The verifier's ALG is used as is
+// when its key type has no registered algorithm.
+func VerifierAlgorithm(verifier Verifier) jwa.SignatureAlgorithm {
+	// the curve names of OKP and EC keys and the RSA key type match the names of their key types
+	keyType := crypto.KeyType(verifier.KTY)
+	if verifier.CRV != "" {
+		keyType = crypto.KeyType(verifier.CRV)
+	}
+	if keyType == crypto.RSA && verifier.ALG == jwa.RS256.String() {
+		return jwa.RS256
+	}
+	if alg, ok := AlgorithmForKeyType(keyType); ok {
+		return alg
+	}
+	return jwa.SignatureAlgorithm(verifier.ALG)
+}
+
+// VerifierAlgorithms returns the JSON Web Algorithms the verifier verifies with: that of VerifierAlgorithm, and for
+// RSA keys that declare no algorithm both PS256 and RS256, since issuers with RSA keys commonly sign with RS256. An
+// RSA key that declares its algorithm verifies with that one only https://www.rfc-editor.org/rfc/rfc7517#section-4.4
+func VerifierAlgorithms(verifier Verifier) []jwa.SignatureAlgorithm {
+	algs := []jwa.SignatureAlgorithm{VerifierAlgorithm(verifier)}
+	if verifier.KTY == jwa.RSA.String() && verifier.ALG == "" {
+		for _, rsaAlg := range []jwa.SignatureAlgorithm{jwa.PS256, jwa.RS256} {
+			if rsaAlg != algs[0] {
+				algs = append(algs, rsaAlg)
+			}
+		}
+	}
+	return algs
+}
+
+// VerifiesAlgorithm reports whether the verifier verifies signatures of the given JSON Web Algorithm
+func VerifiesAlgorithm(verifier Verifier, alg jwa.SignatureAlgorithm) bool {
+	for _, verifierAlg := range VerifierAlgorithms(verifier) {
+		if verifierAlg == alg {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package jwx
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestSignerAndVerifierAlgorithm(t *testing.T) {
+	tests := []struct {
+		kt  crypto.KeyType
+		alg jwa.SignatureAlgorithm
+	}{
+		{kt: crypto.Ed25519, alg: jwa.EdDSA},
+		{kt: crypto.SECP256k1, alg: jwa.ES256K},
+		{kt: crypto.SECP256k1ECDSA, alg: jwa.ES256K},
+		{kt: crypto.P256, alg: jwa.ES256},
+		{kt: crypto.P384, alg: jwa.ES384},
+		{kt: crypto.P521, alg: jwa.ES512},
+		{kt: crypto.RSA, alg: jwa.PS256},
+	}
+	for _, test := range tests {
+		t.Run(test.kt.String(), func(tt *testing.T) {
+			_, privKey, err := crypto.GenerateKeyByKeyType(test.kt)
+			require.NoError(tt, err)
+			signer, err := NewJWXSigner("test-id", nil, privKey)
+			require.NoError(tt, err)
+			assert.Equal(tt, test.alg, SignerAlgorithm(*signer))
+
+			verifier, err := signer.ToVerifier("test-id")
+			require.NoError(tt, err)
+			assert.Equal(tt, test.alg, VerifierAlgorithm(*verifier))
+		})
+	}
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	_, privKey, err := crypto.GenerateKeyByKeyType(crypto.P256)
+	require.NoError(t, err)
+	signer, err := NewJWXSigner("test-id", nil, privKey)
+	require.NoError(t, err)
+	verifier, err := signer.ToVerifier("test-id")
+	require.NoError(t, err)
+
+	original, ok := AlgorithmForKeyType(crypto.P256)
+	require.True(t, ok)
+	t.Cleanup(func() { RegisterAlgorithm(crypto.P256, original) })
+
+	RegisterAlgorithm(crypto.P256, jwa.ES384)
+	assert.Equal(t, jwa.ES384, SignerAlgorithm(*signer))
+	assert.Equal(t, jwa.ES384, VerifierAlgorithm(*verifier))
+
+	// key types without a registration fall back to the signer's algorithm
+	_, ok = AlgorithmForKeyType(crypto.BLS12381G1)
+	assert.False(t, ok)
+	assert.Equal(t, jwa.SignatureAlgorithm("test-alg"), SignerAlgorithm(Signer{PrivateKeyJWK: PrivateKeyJWK{ALG: "test-alg"}}))
+}
+
+func TestRSAAlgorithms(t *testing.T) {
+	_, privKey, err := crypto.GenerateKeyByKeyType(crypto.RSA)
+	require.NoError(t, err)
+	signer, err := NewJWXSigner("test-id", nil, privKey)
+	require.NoError(t, err)
+	verifier, err := signer.ToVerifier("test-id")
+	require.NoError(t, err)
+
+	// RSA keys that declare no algorithm verify both PS256 and RS256, whichever they sign with
+	assert.Equal(t, []jwa.SignatureAlgorithm{jwa.PS256, jwa.RS256}, VerifierAlgorithms(*verifier))
+	assert.True(t, VerifiesAlgorithm(*verifier, jwa.RS256))
+	assert.False(t, VerifiesAlgorithm(*verifier, jwa.RS384))
+
+	rs256Signer := *signer
+	rs256Signer.ALG = jwa.RS256.String()
+	assert.Equal(t, jwa.RS256, SignerAlgorithm(rs256Signer))
+	rs256Verifier, err := rs256Signer.ToVerifier("test-id")
+	require.NoError(t, err)
+	assert.Equal(t, jwa.RS256, VerifierAlgorithm(*rs256Verifier))
+	// a key that declares its algorithm verifies with that one only
+	assert.Equal(t, []jwa.SignatureAlgorithm{jwa.RS256}, VerifierAlgorithms(*rs256Verifier))
+
+	for _, s := range []Signer{*signer, rs256Signer} {
+		token, err := s.SignWithDefaults(map[string]any{"test": "value"})
+		require.NoError(t, err)
+		assert.NoError(t, verifier.Verify(string(token)))
+
+		signed, err := s.SignJWS([]byte("payload"))
+		require.NoError(t, err)
+		assert.NoError(t, verifier.VerifyJWS(string(signed)))
+	}
+
+	ps256JWK := verifier.PublicKeyJWK
+	ps256JWK.ALG = jwa.PS256.String()
+	ps256Verifier, err := NewJWXVerifierFromJWK("test-id", ps256JWK)
+	require.NoError(t, err)
+	assert.Equal(t, []jwa.SignatureAlgorithm{jwa.PS256}, VerifierAlgorithms(*ps256Verifier))
+	rs256Token, err := rs256Signer.SignWithDefaults(map[string]any{"test": "value"})
+	require.NoError(t, err)
+	assert.Error(t, ps256Verifier.Verify(string(rs256Token)))
+	rs256JWS, err := rs256Signer.SignJWS([]byte("payload"))
+	require.NoError(t, err)
+	assert.Error(t, ps256Verifier.VerifyJWS(string(rs256JWS)))
+	ps256Token, err := signer.SignWithDefaults(map[string]any{"test": "value"})
+	require.NoError(t, err)
+	assert.NoError(t, ps256Verifier.Verify(string(ps256Token)))
+
+	// other key types verify only their own algorithm
+	_, privKey, err = crypto.GenerateKeyByKeyType(crypto.P256)
+	require.NoError(t, err)
+	ecSigner, err := NewJWXSigner("test-id", nil, privKey)
+	require.NoError(t, err)
+	ecVerifier, err := ecSigner.ToVerifier("test-id")
+	require.NoError(t, err)
+	assert.Equal(t, []jwa.SignatureAlgorithm{jwa.ES256}, VerifierAlgorithms(*ecVerifier))
+}
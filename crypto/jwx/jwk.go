@@ -368,6 +368,9 @@ func jwkFromRSAPrivateKey(key rsa.PrivateKey) (*PublicKeyJWK, *PrivateKeyJWK, er
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "generating rsa jwk")
 	}
+	if err = jwk.AssignKeyID(rsaJWKGeneric); err != nil {
+		return nil, nil, errors.Wrap(err, "assigning jwk kid")
+	}
 	rsaJWKBytes, err := json.Marshal(rsaJWKGeneric)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "marshalling rsa jwk")
@@ -2,6 +2,7 @@ package jwx
 
 import (
 	"crypto/ecdsa"
+	"crypto/rsa"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
@@ -38,6 +39,12 @@ func TestKeyToJWK(t *testing.T) {
 				pubKey = crypto.SECP256k1ECDSAPubKeyToSECP256k1(pubKey.(ecdsa.PublicKey))
 				privKey = crypto.SECP256k1ECDSASPrivKeyToSECP256k1(privKey.(ecdsa.PrivateKey))
 			}
+			if keyType == crypto.RSA {
+				// the precomputed values of a decoded RSA key differ, so it is compared by its parameters
+				original, decoded := priv.(rsa.PrivateKey), privKey.(rsa.PrivateKey)
+				assert.True(tt, original.Equal(&decoded))
+				privKey = priv
+			}
 
 			assert.Equal(tt, priv, privKey)
 			assert.Equal(tt, pub, pubKey)
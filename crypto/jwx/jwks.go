@@ -0,0 +1,177 @@
+package jwx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+const (
+	// jwksMissRefreshInterval is how long after a fetch a token whose kid is not in the key set is rejected without
+	// fetching again, so that tokens with made-up kids cannot make a verifier flood the JWKS endpoint
+	jwksMissRefreshInterval = 5 * time.Second
+	// jwksFetchTimeout bounds a fetch of the key set, so that an endpoint that hangs does not hold up verifications
+	jwksFetchTimeout = 30 * time.Second
+	// maxJWKSSize is the size in bytes of the largest key set read from an endpoint
+	maxJWKSSize = 1 << 20
+)
+
+// jwksCache holds the keys of a JWKS endpoint, fetching them again once they are older than the refresh interval, or
+// when a token is signed with a key that is not in the set, as after the issuer rotates its keys
+type jwksCache struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	verifiers map[string]*Verifier
+	fetchedAt time.Time
+	// attemptedAt and fetchErr are the time and error of the last fetch, which fails without replacing the keys
+	attemptedAt time.Time
+	fetchErr    error
+	// fetching is closed once the fetch in progress, if any, completes, so that concurrent verifications share it
+	fetching chan struct{}
+	// now is replaceable for testing refreshes
+	now func() time.Time
+}
+
+// NewJWKSVerifier creates a verifier of JWTs signed with the keys published at a JWKS URL, as by OAuth and OpenID
+// Connect providers, selecting the key to verify a token with by the kid in its header. The key set is fetched with
+// the given client on first use and kept for the refresh interval, after which it is fetched again when next used.
+// A token with a kid that is not in the set also fetches it again, so that keys the issuer has rotated in are picked
+// up without waiting for the interval. Should fetching the set fail, the keys fetched before are used until it
+// succeeds. A verification waiting on a fetch gives up when the context of VerifyWithContext, VerifyAndParseWithContext,
+// or VerifyJWSWithContext is done, while the fetch carries on for later verifications. The ID of the verifier is the
+// JWKS URL.
+func NewJWKSVerifier(jwksURL string, httpClient *http.Client, refreshInterval time.Duration) (*Verifier, error) {
+	if jwksURL == "" {
+		return nil, errors.New("jwks url is required")
+	}
+	if httpClient == nil {
+		return nil, errors.New("http client is required")
+	}
+	if refreshInterval <= 0 {
+		return nil, errors.Errorf("jwks refresh interval<%s> must be positive", refreshInterval)
+	}
+	return &Verifier{ID: jwksURL, jwks: &jwksCache{
+		url:             jwksURL,
+		client:          httpClient,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}}, nil
+}
+
+// keyVerifier returns the verifier of the key with the given kid, fetching the key set if it has not been fetched,
+// is older than the refresh interval, or does not have the key. The set is fetched without holding the lock, and
+// once for all the verifications that need it at the same time.
+func (c *jwksCache) keyVerifier(ctx context.Context, verifierID, kid string) (*Verifier, error) {
+	if kid == "" {
+		return nil, errors.New("token has no kid to select a key of the JWKS with")
+	}
+	c.mu.Lock()
+	now := c.now()
+	_, ok := c.verifiers[kid]
+	fresh := c.verifiers != nil && now.Sub(c.fetchedAt) < c.refreshInterval
+	recentlyAttempted := !c.attemptedAt.IsZero() && now.Sub(c.attemptedAt) < jwksMissRefreshInterval
+	if (ok && fresh) || recentlyAttempted {
+		defer c.mu.Unlock()
+		return c.lookup(kid)
+	}
+	fetching := c.fetching
+	if fetching == nil {
+		fetching = make(chan struct{})
+		c.fetching = fetching
+		go c.refresh(verifierID, fetching)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-fetching:
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "fetching jwks<%s>", c.url)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lookup(kid)
+}
+
+// lookup returns the verifier of the cached key with the kid, or the error of the last fetch if it failed. The lock
+// must be held.
+func (c *jwksCache) lookup(kid string) (*Verifier, error) {
+	if verifier, ok := c.verifiers[kid]; ok {
+		return verifier, nil
+	}
+	if c.fetchErr != nil {
+		return nil, c.fetchErr
+	}
+	return nil, errors.Errorf("key<%s> not found in jwks<%s>", kid, c.url)
+}
+
+// refresh fetches the key set, replacing the cached keys with it if the fetch succeeds, and closes done
+func (c *jwksCache) refresh(verifierID string, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	verifiers, err := c.fetch(ctx, verifierID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attemptedAt = c.now()
+	c.fetchErr = err
+	if err == nil {
+		c.verifiers = verifiers
+		c.fetchedAt = c.attemptedAt
+	}
+	c.fetching = nil
+	close(done)
+}
+
+// fetch gets the key set, returning a verifier of each of its keys by kid. Keys which cannot be used to verify, such
+// as those of unsupported types or without a kid, are skipped.
+func (c *jwksCache) fetch(ctx context.Context, verifierID string) (map[string]*Verifier, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting jwks<%s>", c.url)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("getting jwks<%s>, status code: %d", c.url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading jwks<%s>", c.url)
+	}
+	if len(body) > maxJWKSSize {
+		return nil, errors.Errorf("jwks<%s> is larger than %d bytes", c.url, maxJWKSSize)
+	}
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err = json.Unmarshal(body, &set); err != nil {
+		return nil, errors.Wrapf(err, "decoding jwks<%s>", c.url)
+	}
+
+	verifiers := make(map[string]*Verifier, len(set.Keys))
+	for _, rawKey := range set.Keys {
+		var key PublicKeyJWK
+		if err = json.Unmarshal(rawKey, &key); err != nil || key.KID == "" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		verifier, err := NewJWXVerifierFromJWK(verifierID, key)
+		if err != nil {
+			continue
+		}
+		verifiers[key.KID] = verifier
+	}
+	return verifiers, nil
+}
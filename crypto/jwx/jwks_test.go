@@ -0,0 +1,221 @@
+package jwx
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestJWKSVerifier(t *testing.T) {
+	const jwksURL = "https://issuer.example.com/.well-known/jwks.json"
+
+	newSigner := func(tt *testing.T, kid string) *Signer {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		signer, err := NewJWXSigner("https://issuer.example.com", &kid, privKey)
+		require.NoError(tt, err)
+		return signer
+	}
+	jwks := func(tt *testing.T, signers ...*Signer) string {
+		keys := make([]PublicKeyJWK, 0, len(signers))
+		for _, signer := range signers {
+			keys = append(keys, signer.ToPublicKeyJWK())
+		}
+		jwksBytes, err := json.Marshal(map[string]any{"keys": keys})
+		require.NoError(tt, err)
+		return string(jwksBytes)
+	}
+	sign := func(tt *testing.T, signer *Signer) string {
+		token, err := signer.SignWithDefaults(map[string]any{"test": "value"})
+		require.NoError(tt, err)
+		return string(token)
+	}
+
+	t.Run("verifies with the key of the token's kid from one fetch", func(tt *testing.T) {
+		defer gock.Off()
+		first, second := newSigner(tt, "key-1"), newSigner(tt, "key-2")
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(200).BodyString(jwks(tt, first, second))
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		assert.Equal(tt, jwksURL, verifier.ID)
+		assert.NoError(tt, verifier.Verify(sign(tt, first)))
+		_, parsed, err := verifier.VerifyAndParse(sign(tt, second))
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://issuer.example.com", parsed.Issuer())
+
+		jwsToken, err := first.SignJWS([]byte("payload"))
+		require.NoError(tt, err)
+		assert.Error(tt, verifier.VerifyJWS(string(jwsToken)), "a JWS without a kid cannot select a key")
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("rejects tokens signed with another key of the same kid", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Reply(200).BodyString(jwks(tt, newSigner(tt, "key-1")))
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		assert.Error(tt, verifier.Verify(sign(tt, newSigner(tt, "key-1"))))
+	})
+
+	t.Run("refetches on a kid the set does not have", func(tt *testing.T) {
+		defer gock.Off()
+		first, rotated := newSigner(tt, "key-1"), newSigner(tt, "key-2")
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(200).BodyString(jwks(tt, first))
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(200).BodyString(jwks(tt, rotated))
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		now := time.Now()
+		verifier.jwks.now = func() time.Time { return now }
+		assert.NoError(tt, verifier.Verify(sign(tt, first)))
+
+		// a miss right after a fetch does not fetch again
+		err = verifier.Verify(sign(tt, rotated))
+		assert.ErrorContains(tt, err, "key<key-2> not found")
+
+		now = now.Add(jwksMissRefreshInterval)
+		assert.NoError(tt, verifier.Verify(sign(tt, rotated)))
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("refetches after the refresh interval", func(tt *testing.T) {
+		defer gock.Off()
+		first := newSigner(tt, "key-1")
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(200).BodyString(jwks(tt, first))
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(200).BodyString(jwks(tt))
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Minute)
+		require.NoError(tt, err)
+		now := time.Now()
+		verifier.jwks.now = func() time.Time { return now }
+		token := sign(tt, first)
+		assert.NoError(tt, verifier.Verify(token))
+
+		// the key has been removed from the set
+		now = now.Add(time.Minute)
+		assert.ErrorContains(tt, verifier.Verify(token), "key<key-1> not found")
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("skips keys it cannot verify with", func(tt *testing.T) {
+		defer gock.Off()
+		signer := newSigner(tt, "key-1")
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Reply(200).
+			BodyString(`{"keys":[{"kty":"oct","kid":"secret","k":"c2VjcmV0"},{"kty":"OKP","crv":"Ed25519","kid":"enc","use":"enc","x":"` +
+				signer.ToPublicKeyJWK().X + `"},` + jwks(tt, signer)[len(`{"keys":[`):])
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		assert.NoError(tt, verifier.Verify(sign(tt, signer)))
+		assert.ErrorContains(tt, verifier.Verify(sign(tt, newSigner(tt, "enc"))), "key<enc> not found")
+	})
+
+	t.Run("fails when the set cannot be fetched", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Reply(500)
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		assert.ErrorContains(tt, verifier.Verify(sign(tt, newSigner(tt, "key-1"))), "status code: 500")
+	})
+
+	t.Run("keeps the keys it has when a refresh fails", func(tt *testing.T) {
+		defer gock.Off()
+		signer := newSigner(tt, "key-1")
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(200).BodyString(jwks(tt, signer))
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(503)
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Minute)
+		require.NoError(tt, err)
+		now := time.Now()
+		verifier.jwks.now = func() time.Time { return now }
+		assert.NoError(tt, verifier.Verify(sign(tt, signer)))
+
+		now = now.Add(time.Minute)
+		assert.NoError(tt, verifier.Verify(sign(tt, signer)))
+		assert.ErrorContains(tt, verifier.Verify(sign(tt, newSigner(tt, "key-2"))), "status code: 503")
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("fetches once for concurrent verifications, without blocking cached keys", func(tt *testing.T) {
+		defer gock.Off()
+		cached, rotated := newSigner(tt, "key-1"), newSigner(tt, "key-2")
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(200).BodyString(jwks(tt, cached))
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(200).
+			Delay(500 * time.Millisecond).BodyString(jwks(tt, cached, rotated))
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		now := time.Now()
+		verifier.jwks.now = func() time.Time { return now }
+		assert.NoError(tt, verifier.Verify(sign(tt, cached)))
+		now = now.Add(jwksMissRefreshInterval)
+
+		rotatedToken := sign(tt, rotated)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(tt, verifier.Verify(rotatedToken))
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		start := time.Now()
+		assert.NoError(tt, verifier.Verify(sign(tt, cached)))
+		assert.Less(tt, time.Since(start), 250*time.Millisecond)
+		wg.Wait()
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("stops waiting for a fetch when the context is done", func(tt *testing.T) {
+		defer gock.Off()
+		signer := newSigner(tt, "key-1")
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Times(1).Reply(200).
+			Delay(500 * time.Millisecond).BodyString(jwks(tt, signer))
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err = verifier.VerifyWithContext(ctx, sign(tt, signer))
+		assert.ErrorIs(tt, err, context.DeadlineExceeded)
+		assert.Less(tt, time.Since(start), 250*time.Millisecond)
+
+		// the fetch carries on, for the verifications after
+		assert.NoError(tt, verifier.Verify(sign(tt, signer)))
+		assert.True(tt, gock.IsDone())
+	})
+
+	t.Run("rejects a key set that is too large", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://issuer.example.com").Get("/.well-known/jwks.json").Reply(200).
+			BodyString(`{"keys":[],"padding":"` + strings.Repeat("a", maxJWKSSize) + `"}`)
+
+		verifier, err := NewJWKSVerifier(jwksURL, http.DefaultClient, time.Hour)
+		require.NoError(tt, err)
+		assert.ErrorContains(tt, verifier.Verify(sign(tt, newSigner(tt, "key-1"))), "is larger than 1048576 bytes")
+	})
+
+	t.Run("bad arguments", func(tt *testing.T) {
+		_, err := NewJWKSVerifier("", http.DefaultClient, time.Hour)
+		assert.Error(tt, err)
+		_, err = NewJWKSVerifier(jwksURL, nil, time.Hour)
+		assert.Error(tt, err)
+		_, err = NewJWKSVerifier(jwksURL, http.DefaultClient, 0)
+		assert.Error(tt, err)
+	})
+}
@@ -1,8 +1,14 @@
 package jwx
 
 import (
+	"bytes"
+	"context"
+	gocrypto "crypto"
 	"fmt"
+	"reflect"
+	"strings"
 
+	"github.com/goccy/go-json"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
@@ -11,11 +17,12 @@ import (
 
 // SignJWS takes a set of payload and signs it with the key defined in the signer
 func (s *Signer) SignJWS(payload []byte) ([]byte, error) {
+	alg := SignerAlgorithm(*s)
 	headers := jws.NewHeaders()
-	if err := headers.Set(jws.AlgorithmKey, s.ALG); err != nil {
+	if err := headers.Set(jws.AlgorithmKey, alg); err != nil {
 		return nil, errors.Wrap(err, "setting algorithm header")
 	}
-	return jws.Sign(payload, jws.WithKey(jwa.SignatureAlgorithm(s.ALG), s.PrivateKey, jws.WithProtectedHeaders(headers)))
+	return jws.Sign(payload, jws.WithKey(alg, s.PrivateKey, jws.WithProtectedHeaders(headers)))
 }
 
 // Parse attempts to turn a string into a jwt.Token
@@ -33,13 +40,67 @@ func (*Signer) Parse(token string) (jws.Headers, jwt.Token, error) {
 
 // VerifyJWS parses a token given the verifier's known algorithm and key, and returns an error, which is nil upon success.
 func (v *Verifier) VerifyJWS(token string) error {
-	key := jws.WithKey(jwa.SignatureAlgorithm(v.ALG), v.publicKey)
-	if _, err := jws.Verify([]byte(token), key); err != nil {
+	return v.VerifyJWSWithContext(context.Background(), token)
+}
+
+// VerifyJWSWithContext verifies a token as VerifyJWS does, fetching the key set of a verifier of a JWKS with the
+// context
+func (v *Verifier) VerifyJWSWithContext(ctx context.Context, token string) error {
+	keyVerifier, err := v.keyVerifier(ctx, token)
+	if err != nil {
+		return errors.Wrap(err, "verifying JWT")
+	}
+	algs := VerifierAlgorithms(*keyVerifier)
+	keys := make([]jws.VerifyOption, 0, len(algs))
+	for _, alg := range algs {
+		keys = append(keys, jws.WithKey(alg, keyVerifier.publicKey))
+	}
+	if _, err = jws.Verify([]byte(token), keys...); err != nil {
 		return errors.Wrap(err, "verifying JWT")
 	}
 	return nil
 }
 
+// VerifyRaw checks a single signature over the signing input with the public key, without assuming a JWS or JWT
+// envelope, for protocols that carry signatures outside a JWS. The algorithm must be one the key verifies with, as
+// for a Verifier of the key, so an EdDSA signature only verifies with an Ed25519 key and an ES256 signature only with
+// a P-256 key. Signatures of ECDSA algorithms are the concatenated r and s values JWS uses, not ASN.1 encoded ones.
+// An error is returned if the signature does not verify, and nil upon success.
+func VerifyRaw(alg jwa.SignatureAlgorithm, pubKey gocrypto.PublicKey, signingInput, signature []byte) error {
+	if pubKey == nil {
+		return errors.New("key is required")
+	}
+	publicKeyJWK, err := PublicKeyToPublicKeyJWK(nil, pubKey)
+	if err != nil {
+		return errors.Wrap(err, "converting public key to JWK")
+	}
+	if !VerifiesAlgorithm(Verifier{PublicKeyJWK: *publicKeyJWK}, alg) {
+		return fmt.Errorf("algorithm %s cannot be verified with keys of type %s", alg, keyTypeName(*publicKeyJWK))
+	}
+	for reflect.ValueOf(pubKey).Kind() == reflect.Ptr {
+		pubKey = reflect.ValueOf(pubKey).Elem().Interface().(gocrypto.PublicKey)
+	}
+	if convertedPubKey, ok := pubKeyForJWX(pubKey); ok {
+		pubKey = convertedPubKey
+	}
+	verifier, err := jws.NewVerifier(alg)
+	if err != nil {
+		return errors.Wrap(err, "creating verifier")
+	}
+	if err = verifier.Verify(signingInput, signature, pubKey); err != nil {
+		return errors.Wrap(err, "verifying signature")
+	}
+	return nil
+}
+
+// keyTypeName returns the curve of an OKP or EC key, or the key type of other keys, such as RSA
+func keyTypeName(key PublicKeyJWK) string {
+	if key.CRV != "" {
+		return key.CRV
+	}
+	return key.KTY
+}
+
 // ParseJWS attempts to pull of a single signature from a token, containing its headers
 func (*Verifier) ParseJWS(token string) (*jws.Signature, error) {
 	parsed, err := jws.Parse([]byte(token))
@@ -64,3 +125,98 @@ func GetJWSHeaders(token []byte) (jws.Headers, error) {
 	}
 	return msg.Signatures()[0].ProtectedHeaders(), nil
 }
+
+// GetJWSHeadersWithExtensions returns the headers of a JWS signed object as GetJWSHeaders does, along with the header
+// parameters that are not registered by RFC 7515, such as vendor extensions or the b64 parameter of RFC 7797, keyed
+// by their names. None of the parameters is rejected for being unknown, so a verifier can inspect them.
+func GetJWSHeadersWithExtensions(token []byte) (jws.Headers, map[string]any, error) {
+	headers, err := GetJWSHeaders(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	return headers, HeaderExtensions(headers), nil
+}
+
+// HeaderExtensions returns a copy of the header parameters not registered by RFC 7515
+func HeaderExtensions(headers jws.Headers) map[string]any {
+	private := headers.PrivateParams()
+	extensions := make(map[string]any, len(private))
+	for name, value := range private {
+		extensions[name] = value
+	}
+	return extensions
+}
+
+// jsonSerialization is the general JSON serialization of a JWS https://www.rfc-editor.org/rfc/rfc7515#section-7.2.1
+// The flattened serialization is supported by way of the top-level protected, header, and signature values.
+type jsonSerialization struct {
+	Payload    string                   `json:"payload"`
+	Protected  string                   `json:"protected,omitempty"`
+	Header     map[string]any           `json:"header,omitempty"`
+	Signature  string                   `json:"signature,omitempty"`
+	Signatures []jsonSerializationEntry `json:"signatures,omitempty"`
+}
+
+type jsonSerializationEntry struct {
+	Protected string         `json:"protected"`
+	Header    map[string]any `json:"header,omitempty"`
+	Signature string         `json:"signature"`
+}
+
+// IsJSONSerialization reports whether a JWS uses the JSON serialization rather than the compact one
+func IsJSONSerialization(token []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(token), []byte("{"))
+}
+
+// ToJSONSerialization converts a JWS in compact serialization to the general JSON serialization, keeping its
+// protected header, payload, and signature as they are so that the signature remains valid. A JWS already in JSON
+// serialization is returned unchanged.
+func ToJSONSerialization(token []byte) ([]byte, error) {
+	if IsJSONSerialization(token) {
+		return token, nil
+	}
+	parts := strings.Split(string(token), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("compact JWS has %d parts, expected 3", len(parts))
+	}
+	serialized, err := json.Marshal(jsonSerialization{
+		Payload:    parts[1],
+		Signatures: []jsonSerializationEntry{{Protected: parts[0], Signature: parts[2]}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling JWS JSON serialization")
+	}
+	return serialized, nil
+}
+
+// ToCompactSerialization converts a JWS in general or flattened JSON serialization to the compact serialization, so
+// that either can be parsed as a JWT. A JWS already in compact serialization is returned unchanged. Only a JWS with a
+// single signature and no unprotected header can be converted, as the compact serialization carries neither multiple
+// signatures nor unprotected headers.
+func ToCompactSerialization(token []byte) ([]byte, error) {
+	if !IsJSONSerialization(token) {
+		return token, nil
+	}
+	var serialized jsonSerialization
+	if err := json.Unmarshal(token, &serialized); err != nil {
+		return nil, errors.Wrap(err, "parsing JWS JSON serialization")
+	}
+	entry := jsonSerializationEntry{Protected: serialized.Protected, Header: serialized.Header, Signature: serialized.Signature}
+	switch len(serialized.Signatures) {
+	case 0:
+	case 1:
+		if entry.Signature != "" {
+			return nil, errors.New("JWS cannot have both signature and signatures members")
+		}
+		entry = serialized.Signatures[0]
+	default:
+		return nil, fmt.Errorf("JWS has %d signatures; only a JWS with a single signature has a compact serialization", len(serialized.Signatures))
+	}
+	if entry.Signature == "" || entry.Protected == "" {
+		return nil, errors.New("JWS must have a protected header and a signature")
+	}
+	if len(entry.Header) != 0 {
+		return nil, errors.New("JWS with an unprotected header has no compact serialization")
+	}
+	return []byte(strings.Join([]string{entry.Protected, serialized.Payload, entry.Signature}, ".")), nil
+}
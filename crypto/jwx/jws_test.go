@@ -0,0 +1,190 @@
+package jwx
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestGetJWSHeadersWithExtensions(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+
+	t.Run("token with vendor headers", func(tt *testing.T) {
+		headers := jws.NewHeaders()
+		require.NoError(tt, headers.Set(jws.KeyIDKey, signer.KID))
+		require.NoError(tt, headers.Set(jws.TypeKey, "JWT"))
+		require.NoError(tt, headers.Set("ver", "1.0"))
+		require.NoError(tt, headers.Set("b64", true))
+		require.NoError(tt, headers.Set("x-vendor", map[string]any{"region": "eu"}))
+		token, err := jws.Sign([]byte(`{"iss":"did:example:123"}`),
+			jws.WithKey(SignerAlgorithm(signer), signer.PrivateKey, jws.WithProtectedHeaders(headers)))
+		require.NoError(tt, err)
+
+		parsed, extensions, err := GetJWSHeadersWithExtensions(token)
+		assert.NoError(tt, err)
+		assert.Equal(tt, signer.KID, parsed.KeyID())
+		assert.Equal(tt, map[string]any{
+			"ver":      "1.0",
+			"b64":      true,
+			"x-vendor": map[string]any{"region": "eu"},
+		}, extensions)
+
+		// the extensions are a copy, which callers can change without affecting the headers
+		extensions["ver"] = "2.0"
+		ver, _ := parsed.Get("ver")
+		assert.Equal(tt, "1.0", ver)
+
+		verifier, err := signer.ToVerifier(signer.ID)
+		require.NoError(tt, err)
+		assert.NoError(tt, verifier.VerifyJWS(string(token)))
+	})
+
+	t.Run("token without extensions", func(tt *testing.T) {
+		token, err := signer.SignJWS([]byte("payload"))
+		require.NoError(tt, err)
+
+		_, extensions, err := GetJWSHeadersWithExtensions(token)
+		assert.NoError(tt, err)
+		assert.NotNil(tt, extensions)
+		assert.Empty(tt, extensions)
+	})
+
+	t.Run("not a JWS", func(tt *testing.T) {
+		_, _, err := GetJWSHeadersWithExtensions([]byte("not-a-jws"))
+		assert.Error(tt, err)
+	})
+}
+
+func TestJWSSerializations(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	verifier, err := signer.ToVerifier(signer.ID)
+	require.NoError(t, err)
+	compact, err := signer.SignJWS([]byte("payload"))
+	require.NoError(t, err)
+
+	t.Run("round trip", func(tt *testing.T) {
+		serialized, err := ToJSONSerialization(compact)
+		assert.NoError(tt, err)
+		assert.True(tt, IsJSONSerialization(serialized))
+		assert.False(tt, IsJSONSerialization(compact))
+		assert.NoError(tt, verifier.VerifyJWS(string(serialized)))
+
+		converted, err := ToCompactSerialization(serialized)
+		assert.NoError(tt, err)
+		assert.Equal(tt, compact, converted)
+
+		// tokens already in the target serialization are unchanged
+		unchanged, err := ToCompactSerialization(compact)
+		assert.NoError(tt, err)
+		assert.Equal(tt, compact, unchanged)
+		unchanged, err = ToJSONSerialization(serialized)
+		assert.NoError(tt, err)
+		assert.Equal(tt, serialized, unchanged)
+	})
+
+	t.Run("flattened serialization", func(tt *testing.T) {
+		msg, err := jws.Parse(compact)
+		require.NoError(tt, err)
+		flattened, err := msg.MarshalJSON()
+		require.NoError(tt, err)
+
+		converted, err := ToCompactSerialization(flattened)
+		assert.NoError(tt, err)
+		assert.Equal(tt, compact, converted)
+	})
+
+	t.Run("multiple signatures", func(tt *testing.T) {
+		_, otherKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		multi, err := jws.Sign([]byte("payload"), jws.WithJSON(),
+			jws.WithKey(SignerAlgorithm(signer), signer.PrivateKey),
+			jws.WithKey(jwa.EdDSA, otherKey))
+		require.NoError(tt, err)
+
+		_, err = ToCompactSerialization(multi)
+		assert.ErrorContains(tt, err, "JWS has 2 signatures")
+	})
+
+	t.Run("unprotected header", func(tt *testing.T) {
+		withHeader := []byte(`{"payload":"cGF5bG9hZA","protected":"eyJhbGciOiJFZERTQSJ9","header":{"kid":"key-0"},"signature":"c2ln"}`)
+		_, err := ToCompactSerialization(withHeader)
+		assert.ErrorContains(tt, err, "unprotected header")
+	})
+
+	t.Run("malformed tokens", func(tt *testing.T) {
+		_, err := ToJSONSerialization([]byte("not-a-jws"))
+		assert.ErrorContains(tt, err, "compact JWS has 1 parts")
+		_, err = ToCompactSerialization([]byte(`{"payload":"cGF5bG9hZA"}`))
+		assert.ErrorContains(tt, err, "must have a protected header and a signature")
+		_, err = ToCompactSerialization([]byte(`{`))
+		assert.Error(tt, err)
+	})
+}
+
+func TestVerifyRaw(t *testing.T) {
+	// signs the payload as a compact JWS, returning its signing input and decoded signature
+	signRaw := func(tt *testing.T, signer Signer, payload []byte) (signingInput, signature []byte) {
+		compact, err := signer.SignJWS(payload)
+		require.NoError(tt, err)
+		parts := strings.Split(string(compact), ".")
+		require.Len(tt, parts, 3)
+		signature, err = base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(tt, err)
+		return []byte(parts[0] + "." + parts[1]), signature
+	}
+
+	for _, keyType := range []crypto.KeyType{crypto.Ed25519, crypto.SECP256k1, crypto.P256, crypto.P384, crypto.P521, crypto.RSA} {
+		t.Run(keyType.String(), func(tt *testing.T) {
+			pubKey, privKey, err := crypto.GenerateKeyByKeyType(keyType)
+			require.NoError(tt, err)
+			signer, err := NewJWXSigner("did:example:123", nil, privKey)
+			require.NoError(tt, err)
+			signingInput, signature := signRaw(tt, *signer, []byte("payload"))
+			alg := SignerAlgorithm(*signer)
+
+			assert.NoError(tt, VerifyRaw(alg, pubKey, signingInput, signature))
+
+			tampered := append([]byte(nil), signingInput...)
+			tampered[len(tampered)-1] ^= 1
+			assert.ErrorContains(tt, VerifyRaw(alg, pubKey, tampered, signature), "verifying signature")
+
+			otherPubKey, _, err := crypto.GenerateKeyByKeyType(keyType)
+			require.NoError(tt, err)
+			assert.Error(tt, VerifyRaw(alg, otherPubKey, signingInput, signature))
+		})
+	}
+
+	t.Run("keys given by pointer", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		signer, err := NewJWXSigner("did:example:123", nil, privKey)
+		require.NoError(tt, err)
+		signingInput, signature := signRaw(tt, *signer, []byte("payload"))
+		assert.NoError(tt, VerifyRaw(jwa.EdDSA, &pubKey, signingInput, signature))
+	})
+
+	t.Run("algorithms the key does not verify with", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateKeyByKeyType(crypto.P256)
+		require.NoError(tt, err)
+		signer, err := NewJWXSigner("did:example:123", nil, privKey)
+		require.NoError(tt, err)
+		signingInput, signature := signRaw(tt, *signer, []byte("payload"))
+		assert.ErrorContains(tt, VerifyRaw(jwa.ES384, pubKey, signingInput, signature), "cannot be verified with keys of type P-256")
+		assert.ErrorContains(tt, VerifyRaw(jwa.EdDSA, pubKey, signingInput, signature), "cannot be verified with keys of type P-256")
+
+		edPubKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		assert.ErrorContains(tt, VerifyRaw(jwa.ES256, edPubKey, signingInput, signature), "cannot be verified with keys of type Ed25519")
+	})
+
+	t.Run("missing key", func(tt *testing.T) {
+		assert.ErrorContains(tt, VerifyRaw(jwa.EdDSA, nil, []byte("input"), []byte("signature")), "key is required")
+	})
+}
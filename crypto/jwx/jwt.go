@@ -1,6 +1,7 @@
 package jwx
 
 import (
+	"context"
 	gocrypto "crypto"
 	"fmt"
 	"reflect"
@@ -27,18 +28,56 @@ func NewJWXSigner(id string, kid *string, key gocrypto.PrivateKey) (*Signer, err
 	if err != nil {
 		return nil, errors.Wrap(err, "converting private key to JWK")
 	}
+	// a private key declares no algorithm, so the default of its JWK is not kept for RSA keys, see VerifierAlgorithms
+	if privateKeyJWK.KTY == jwa.RSA.String() {
+		privateKeyJWK.ALG = ""
+	}
 	return jwxSigner(id, *privateKeyJWK, key)
 }
 
 // NewJWXSignerFromJWK creates a new signer from a private key to sign and produce JWS values
 func NewJWXSignerFromJWK(id string, key PrivateKeyJWK) (*Signer, error) {
+	// converting the JWK defaults its algorithm, which is kept only if the JWK declares it
+	declaredALG := key.ALG
 	privateKey, err := key.ToPrivateKey()
 	if err != nil {
 		return nil, errors.Wrap(err, "converting JWK to private key")
 	}
+	key.ALG = declaredALG
 	return jwxSigner(id, key, privateKey)
 }
 
+// NewJWXSignerFromCryptoSigner creates a new signer whose signatures are made by a crypto.Signer, such as a key held
+// in a KMS or hardware, so that the private key never has to be in memory. Its JWK holds only the public key.
+func NewJWXSignerFromCryptoSigner(id string, kid *string, signer gocrypto.Signer) (*Signer, error) {
+	if signer == nil {
+		return nil, errors.New("signer is required")
+	}
+	publicKeyJWK, err := PublicKeyToPublicKeyJWK(kid, signer.Public())
+	if err != nil {
+		return nil, errors.Wrap(err, "converting public key to JWK")
+	}
+	jwk := PrivateKeyJWK{
+		KTY:    publicKeyJWK.KTY,
+		CRV:    publicKeyJWK.CRV,
+		X:      publicKeyJWK.X,
+		Y:      publicKeyJWK.Y,
+		N:      publicKeyJWK.N,
+		E:      publicKeyJWK.E,
+		Use:    publicKeyJWK.Use,
+		KeyOps: publicKeyJWK.KeyOps,
+		ALG:    publicKeyJWK.ALG,
+		KID:    publicKeyJWK.KID,
+	}
+	// the algorithm cannot be told from the type of the signer, so it is set from the public key, other than for RSA
+	// keys, which declare none
+	jwk.ALG = ""
+	if jwk.KTY != jwa.RSA.String() {
+		jwk.ALG = VerifierAlgorithm(Verifier{PublicKeyJWK: *publicKeyJWK}).String()
+	}
+	return jwxSigner(id, jwk, signer)
+}
+
 func jwxSigner(id string, jwk PrivateKeyJWK, key gocrypto.PrivateKey) (*Signer, error) {
 	if id == "" {
 		return nil, errors.New("id is required")
@@ -49,15 +88,19 @@ func jwxSigner(id string, jwk PrivateKeyJWK, key gocrypto.PrivateKey) (*Signer,
 	if key == nil {
 		return nil, errors.New("key is required")
 	}
-	if jwk.ALG == "" {
-		alg, err := AlgFromKeyAndCurve(jwk.KTY, jwk.CRV)
-		if err != nil {
+	alg := jwk.ALG
+	if alg == "" {
+		var err error
+		if alg, err = AlgFromKeyAndCurve(jwk.KTY, jwk.CRV); err != nil {
 			return nil, errors.Wrap(err, "getting alg from key and curve")
 		}
-		jwk.ALG = alg
+		// RSA keys that declare no algorithm sign with the registered one, see SignerAlgorithm
+		if jwk.KTY != jwa.RSA.String() {
+			jwk.ALG = alg
+		}
 	}
-	if !IsSupportedJWXSigningVerificationAlgorithm(jwk.ALG) && !IsExperimentalJWXSigningVerificationAlgorithm(jwk.ALG) {
-		return nil, fmt.Errorf("unsupported signing algorithm: %s", jwk.ALG)
+	if !IsSupportedJWXSigningVerificationAlgorithm(alg) && !IsExperimentalJWXSigningVerificationAlgorithm(alg) {
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
 	}
 	if convertedPrivKey, ok := privKeyForJWX(key); ok {
 		key = convertedPrivKey
@@ -91,6 +134,8 @@ type Verifier struct {
 	ID string
 	PublicKeyJWK
 	publicKey gocrypto.PublicKey
+	// jwks is set for verifiers of the keys of a JWKS endpoint, in place of the key, see NewJWKSVerifier
+	jwks *jwksCache
 }
 
 // NewJWXVerifier creates a new verifier from a public key to verify JWTs and JWS signatures
@@ -99,15 +144,22 @@ func NewJWXVerifier(id string, kid *string, key gocrypto.PublicKey) (*Verifier,
 	if err != nil {
 		return nil, errors.Wrap(err, "converting public key to JWK")
 	}
+	// a public key declares no algorithm, so the default of its JWK is not kept for RSA keys, see VerifierAlgorithms
+	if publicKeyJWK.KTY == jwa.RSA.String() {
+		publicKeyJWK.ALG = ""
+	}
 	return jwxVerifier(id, *publicKeyJWK, key)
 }
 
 // NewJWXVerifierFromJWK creates a new verifier from a public key to verify JWTs and JWS signatures
 func NewJWXVerifierFromJWK(id string, key PublicKeyJWK) (*Verifier, error) {
+	// converting the JWK defaults its algorithm, which is kept only if the JWK declares it
+	declaredALG := key.ALG
 	pubKey, err := key.ToPublicKey()
 	if err != nil {
 		return nil, errors.Wrap(err, "converting JWK to public key")
 	}
+	key.ALG = declaredALG
 	return jwxVerifier(id, key, pubKey)
 }
 
@@ -121,15 +173,19 @@ func jwxVerifier(id string, jwk PublicKeyJWK, key gocrypto.PublicKey) (*Verifier
 	if key == nil {
 		return nil, errors.New("key is required")
 	}
-	if jwk.ALG == "" {
-		alg, err := AlgFromKeyAndCurve(jwk.KTY, jwk.CRV)
-		if err != nil {
+	alg := jwk.ALG
+	if alg == "" {
+		var err error
+		if alg, err = AlgFromKeyAndCurve(jwk.KTY, jwk.CRV); err != nil {
 			return nil, errors.Wrap(err, "getting alg from key and curve")
 		}
-		jwk.ALG = alg
+		// RSA keys that declare no algorithm verify with each of theirs
+		if jwk.KTY != jwa.RSA.String() {
+			jwk.ALG = alg
+		}
 	}
-	if !IsSupportedJWXSigningVerificationAlgorithm(jwk.ALG) && !IsSupportedKeyAgreementType(jwk.KTY) {
-		return nil, fmt.Errorf("unsupported signing/verification algorithm: %s", jwk.ALG)
+	if !IsSupportedJWXSigningVerificationAlgorithm(alg) && !IsSupportedKeyAgreementType(jwk.KTY) {
+		return nil, fmt.Errorf("unsupported signing/verification algorithm: %s", alg)
 	}
 	if convertedPubKey, ok := pubKeyForJWX(key); ok {
 		key = convertedPubKey
@@ -180,22 +236,22 @@ func (s *Signer) SignWithDefaults(kvs map[string]any) ([]byte, error) {
 		}
 	}
 
-	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
-	alg := s.ALG
-	if alg == "Ed25519" {
-		alg = jwa.EdDSA.String()
-	}
-	return jwt.Sign(t, jwt.WithKey(jwa.SignatureAlgorithm(alg), s.PrivateKey, jws.WithProtectedHeaders(hdrs)))
+	return jwt.Sign(t, jwt.WithKey(SignerAlgorithm(*s), s.PrivateKey, jws.WithProtectedHeaders(hdrs)))
 }
 
-// Verify parses a token given the verifier's known algorithm and key, and returns an error, which is nil upon success
-func (v *Verifier) Verify(token string) error {
-	alg := jwa.SignatureAlgorithm(v.ALG)
-	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
-	if alg == "Ed25519" {
-		alg = jwa.EdDSA
+// Verify parses a token given the verifier's known algorithm and key, and returns an error, which is nil upon success.
+// Options, such as the clock to validate the token's claims with, are passed on to parsing.
+func (v *Verifier) Verify(token string, opts ...jwt.ParseOption) error {
+	return v.VerifyWithContext(context.Background(), token, opts...)
+}
+
+// VerifyWithContext verifies a token as Verify does, fetching the key set of a verifier of a JWKS with the context
+func (v *Verifier) VerifyWithContext(ctx context.Context, token string, opts ...jwt.ParseOption) error {
+	keyVerifier, err := v.keyVerifier(ctx, token)
+	if err != nil {
+		return errors.Wrap(err, "verifying JWT")
 	}
-	if _, err := jwt.Parse([]byte(token), jwt.WithKey(alg, v.publicKey)); err != nil {
+	if _, err = jwt.Parse([]byte(token), append(keyVerifier.parseKeyOptions(), opts...)...); err != nil {
 		return errors.Wrap(err, "verifying JWT")
 	}
 	return nil
@@ -214,14 +270,20 @@ func (*Verifier) Parse(token string) (jws.Headers, jwt.Token, error) {
 	return headers, parsed, nil
 }
 
-// VerifyAndParse attempts to turn a string into a jwt.Token and verify its signature using the verifier
-func (v *Verifier) VerifyAndParse(token string) (jws.Headers, jwt.Token, error) {
-	alg := jwa.SignatureAlgorithm(v.ALG)
-	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
-	if alg == "Ed25519" {
-		alg = jwa.EdDSA
+// VerifyAndParse attempts to turn a string into a jwt.Token and verify its signature using the verifier. Options are
+// passed on to parsing, as for Verify.
+func (v *Verifier) VerifyAndParse(token string, opts ...jwt.ParseOption) (jws.Headers, jwt.Token, error) {
+	return v.VerifyAndParseWithContext(context.Background(), token, opts...)
+}
+
+// VerifyAndParseWithContext verifies and parses a token as VerifyAndParse does, fetching the key set of a verifier of
+// a JWKS with the context
+func (v *Verifier) VerifyAndParseWithContext(ctx context.Context, token string, opts ...jwt.ParseOption) (jws.Headers, jwt.Token, error) {
+	keyVerifier, err := v.keyVerifier(ctx, token)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing and verifying JWT")
 	}
-	parsed, err := jwt.Parse([]byte(token), jwt.WithKey(alg, v.publicKey))
+	parsed, err := jwt.Parse([]byte(token), append(keyVerifier.parseKeyOptions(), opts...)...)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "parsing and verifying JWT")
 	}
@@ -232,6 +294,29 @@ func (v *Verifier) VerifyAndParse(token string) (jws.Headers, jwt.Token, error)
 	return headers, parsed, nil
 }
 
+// keyVerifier returns the verifier of the key to verify the token with, which for a verifier of a JWKS is the key
+// with the kid of the token's header, fetched with the context
+func (v *Verifier) keyVerifier(ctx context.Context, token string) (*Verifier, error) {
+	if v.jwks == nil {
+		return v, nil
+	}
+	headers, err := GetJWSHeaders([]byte(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting JWT headers")
+	}
+	return v.jwks.keyVerifier(ctx, v.ID, headers.KeyID())
+}
+
+// parseKeyOptions returns an option to verify a JWT with the verifier's key under each of the algorithms it verifies
+func (v *Verifier) parseKeyOptions() []jwt.ParseOption {
+	algs := VerifierAlgorithms(*v)
+	opts := make([]jwt.ParseOption, 0, len(algs))
+	for _, alg := range algs {
+		opts = append(opts, jwt.WithKey(alg, v.publicKey))
+	}
+	return opts
+}
+
 // AlgFromKeyAndCurve returns the supported JSON Web Algorithm for signing for a given key type and curve pair
 // The curve parameter is optional (e.g. "") as in the case of RSA.
 func AlgFromKeyAndCurve(kty, crv string) (string, error) {
@@ -288,6 +373,7 @@ func IsSupportedJWXSigningVerificationAlgorithm(algorithm string) bool {
 func GetSupportedJWXSigningVerificationAlgorithms() []string {
 	return []string{
 		jwa.PS256.String(),
+		jwa.RS256.String(),
 		jwa.ES256.String(),
 		jwa.ES256K.String(),
 		jwa.ES384.String(),
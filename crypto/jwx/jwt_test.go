@@ -1,6 +1,10 @@
 package jwx
 
 import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
@@ -186,3 +190,34 @@ func getTestVectorKey0Signer(t *testing.T) Signer {
 	assert.NoError(t, err)
 	return *signer
 }
+
+// opaqueSigner hides the type of the private key behind a crypto.Signer, as a signer backed by a KMS would
+type opaqueSigner struct {
+	gocrypto.Signer
+}
+
+func TestNewJWXSignerFromCryptoSigner(t *testing.T) {
+	_, edKey, err := crypto.GenerateEd25519Key()
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	for name, key := range map[string]gocrypto.Signer{"Ed25519": edKey, "P-256": ecKey} {
+		t.Run(name, func(tt *testing.T) {
+			kid := "did:example:123#key-1"
+			signer, err := NewJWXSignerFromCryptoSigner("did:example:123", &kid, opaqueSigner{key})
+			assert.NoError(tt, err)
+			assert.Empty(tt, signer.PrivateKeyJWK.D)
+			assert.Equal(tt, kid, signer.KID)
+
+			token, err := signer.SignWithDefaults(map[string]any{"sub": "did:example:456"})
+			assert.NoError(tt, err)
+			verifier, err := signer.ToVerifier(signer.ID)
+			assert.NoError(tt, err)
+			assert.NoError(tt, verifier.Verify(string(token)))
+		})
+	}
+
+	_, err = NewJWXSignerFromCryptoSigner("did:example:123", nil, nil)
+	assert.ErrorContains(t, err, "signer is required")
+}
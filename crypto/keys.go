@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"crypto"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
@@ -9,6 +10,8 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
+	"io"
+	"math/big"
 	"reflect"
 
 	"github.com/btcsuite/btcd/btcec/v2"
@@ -60,6 +63,73 @@ func GenerateKeyByKeyType(kt KeyType) (crypto.PublicKey, crypto.PrivateKey, erro
 	return nil, nil, fmt.Errorf("unsupported key type: %s", kt)
 }
 
+// GenerateKeyByKeyTypeFromReader creates a key from the randomness of the reader, returning the public and private
+// key for the given key type. The same reader output always produces the same key, so that tests can generate stable
+// keys from a seeded source. RSA and P-224 keys, which cannot be generated deterministically, are not supported.
+// Outside of tests, use GenerateKeyByKeyType, which is backed by crypto/rand.
+func GenerateKeyByKeyTypeFromReader(kt KeyType, reader io.Reader) (crypto.PublicKey, crypto.PrivateKey, error) {
+	if reader == nil {
+		return nil, nil, errors.New("reader cannot be empty")
+	}
+	switch kt {
+	case Ed25519:
+		return ed25519.GenerateKey(reader)
+	case X25519:
+		return x25519.GenerateKey(reader)
+	case SECP256k1, SECP256k1ECDSA:
+		privKey, err := secp.GeneratePrivateKeyFromRand(reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		if kt == SECP256k1ECDSA {
+			return *privKey.PubKey().ToECDSA(), *privKey.ToECDSA(), nil
+		}
+		return *privKey.PubKey(), *privKey, nil
+	case P256:
+		return generateECDSAKeyFromReader(elliptic.P256(), ecdh.P256(), reader)
+	case P384:
+		return generateECDSAKeyFromReader(elliptic.P384(), ecdh.P384(), reader)
+	case P521:
+		return generateECDSAKeyFromReader(elliptic.P521(), ecdh.P521(), reader)
+	case Dilithium2:
+		return dilithium.Mode2.GenerateKey(reader)
+	case Dilithium3:
+		return dilithium.Mode3.GenerateKey(reader)
+	case Dilithium5:
+		return dilithium.Mode5.GenerateKey(reader)
+	case P224, RSA:
+		return nil, nil, fmt.Errorf("key type<%s> cannot be generated deterministically from a reader", kt)
+	}
+	return nil, nil, fmt.Errorf("unsupported key type: %s", kt)
+}
+
+// generateECDSAKeyFromReader derives the private scalar from the reader as in FIPS 186-4 B.4.1, since
+// ecdsa.GenerateKey does not produce the same key from the same randomness
+func generateECDSAKeyFromReader(curve elliptic.Curve, ecdhCurve ecdh.Curve, reader io.Reader) (ecdsa.PublicKey, ecdsa.PrivateKey, error) {
+	params := curve.Params()
+	byteLen := (params.N.BitLen() + 7) / 8
+	b := make([]byte, byteLen+8)
+	if _, err := io.ReadFull(reader, b); err != nil {
+		return ecdsa.PublicKey{}, ecdsa.PrivateKey{}, errors.Wrap(err, "reading randomness")
+	}
+	nMinusOne := new(big.Int).Sub(params.N, big.NewInt(1))
+	d := new(big.Int).SetBytes(b)
+	d.Mod(d, nMinusOne).Add(d, big.NewInt(1))
+
+	ecdhKey, err := ecdhCurve.NewPrivateKey(d.FillBytes(make([]byte, byteLen)))
+	if err != nil {
+		return ecdsa.PublicKey{}, ecdsa.PrivateKey{}, errors.Wrap(err, "constructing private key")
+	}
+	// the public key is encoded uncompressed, as 0x04 followed by the coordinates
+	point := ecdhKey.PublicKey().Bytes()[1:]
+	pubKey := ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(point[:len(point)/2]),
+		Y:     new(big.Int).SetBytes(point[len(point)/2:]),
+	}
+	return pubKey, ecdsa.PrivateKey{PublicKey: pubKey, D: d}, nil
+}
+
 type Option int
 
 const (
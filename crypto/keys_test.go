@@ -1,10 +1,17 @@
 package crypto
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
 	"testing"
 
 	"github.com/cloudflare/circl/sign/dilithium"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestKeyToBytes(t *testing.T) {
@@ -232,3 +239,66 @@ func TestSECP256k1Conversions(t *testing.T) {
 	assert.Equal(t, pk, gotPK)
 	assert.Equal(t, sk, gotSK)
 }
+
+func TestGenerateKeyByKeyTypeFromReader(t *testing.T) {
+	for _, keyType := range GetSupportedKeyTypes() {
+		t.Run(string(keyType), func(tt *testing.T) {
+			pub, priv, err := GenerateKeyByKeyTypeFromReader(keyType, rand.New(rand.NewSource(1)))
+			if keyType == P224 || keyType == RSA {
+				assert.ErrorContains(tt, err, "cannot be generated deterministically from a reader")
+				return
+			}
+			require.NoError(tt, err)
+			samePub, samePriv, err := GenerateKeyByKeyTypeFromReader(keyType, rand.New(rand.NewSource(1)))
+			require.NoError(tt, err)
+			otherPub, _, err := GenerateKeyByKeyTypeFromReader(keyType, rand.New(rand.NewSource(2)))
+			require.NoError(tt, err)
+
+			pubKeyBytes, err := PubKeyToBytes(pub)
+			require.NoError(tt, err)
+			samePubKeyBytes, err := PubKeyToBytes(samePub)
+			require.NoError(tt, err)
+			otherPubKeyBytes, err := PubKeyToBytes(otherPub)
+			require.NoError(tt, err)
+			assert.Equal(tt, pubKeyBytes, samePubKeyBytes)
+			assert.NotEqual(tt, pubKeyBytes, otherPubKeyBytes)
+
+			privKeyBytes, err := PrivKeyToBytes(priv)
+			require.NoError(tt, err)
+			samePrivKeyBytes, err := PrivKeyToBytes(samePriv)
+			require.NoError(tt, err)
+			assert.Equal(tt, privKeyBytes, samePrivKeyBytes)
+
+			// the private key can be read back
+			reconstructedPriv, err := BytesToPrivKey(privKeyBytes, keyType)
+			require.NoError(tt, err)
+			assert.NotEmpty(tt, reconstructedPriv)
+		})
+	}
+
+	t.Run("ECDSA keys sign", func(tt *testing.T) {
+		_, priv, err := GenerateKeyByKeyTypeFromReader(P256, rand.New(rand.NewSource(1)))
+		require.NoError(tt, err)
+		privKey := priv.(ecdsa.PrivateKey)
+		digest := sha256.Sum256([]byte("test"))
+		signature, err := ecdsa.SignASN1(rand.New(rand.NewSource(3)), &privKey, digest[:])
+		require.NoError(tt, err)
+		assert.True(tt, ecdsa.VerifyASN1(&privKey.PublicKey, digest[:], signature))
+	})
+
+	t.Run("Ed25519 test vector", func(tt *testing.T) {
+		// test 1 of RFC 8032, section 7.1
+		seed, err := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+		require.NoError(tt, err)
+		pub, _, err := GenerateKeyByKeyTypeFromReader(Ed25519, bytes.NewReader(seed))
+		require.NoError(tt, err)
+		assert.Equal(tt, "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a", hex.EncodeToString(pub.(ed25519.PublicKey)))
+	})
+
+	t.Run("empty reader", func(tt *testing.T) {
+		_, _, err := GenerateKeyByKeyTypeFromReader(Ed25519, nil)
+		assert.ErrorContains(tt, err, "reader cannot be empty")
+		_, _, err = GenerateKeyByKeyTypeFromReader(P256, bytes.NewReader(nil))
+		assert.ErrorContains(tt, err, "reading randomness")
+	})
+}
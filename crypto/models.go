@@ -41,8 +41,12 @@ const (
 	ES256 SignatureAlgorithm = "ES256"
 	// ES384 uses a p-384 curve key
 	ES384 SignatureAlgorithm = "ES384"
+	// ES512 uses a p-521 curve key
+	ES512 SignatureAlgorithm = "ES512"
 	// PS256 uses a 2048-bit RSA key
 	PS256 SignatureAlgorithm = "PS256"
+	// RS256 uses an RSA key with PKCS #1 v1.5 signatures, for interoperability with issuers that do not support PS256
+	RS256 SignatureAlgorithm = "RS256"
 
 	// ECDHESA256KW is a key agreement scheme using X25519 as per https://datatracker.ietf.org/doc/html/rfc7518#section-4.6
 	ECDHESA256KW SignatureAlgorithm = "ECDH-ES+A256KW"
@@ -70,9 +74,9 @@ func IsSupportedKeyType(kt KeyType) bool {
 }
 
 // GetSupportedJWKKeyTypes returns a list of supported JWK key types
-// RSA, secp256k1, and P-224 are not supported by the lib we use for JWK
+// P-224 is not supported by the lib we use for JWK
 func GetSupportedJWKKeyTypes() []KeyType {
-	return []KeyType{Ed25519, X25519, SECP256k1, SECP256k1ECDSA, P256, P384, P521}
+	return []KeyType{Ed25519, X25519, SECP256k1, SECP256k1ECDSA, P256, P384, P521, RSA}
 }
 
 // GetSupportedKeyTypes returns a list of supported key types
@@ -98,7 +102,7 @@ func IsSupportedSignatureAlg(sa SignatureAlgorithm) bool {
 
 // GetSupportedSignatureAlgs returns a list of supported signature algorithms
 func GetSupportedSignatureAlgs() []SignatureAlgorithm {
-	return []SignatureAlgorithm{Ed25519DSA, ES256K, ES256, ES384, PS256}
+	return []SignatureAlgorithm{Ed25519DSA, ES256K, ES256, ES384, ES512, PS256, RS256}
 }
 
 // GetExperimentalSignatureAlgs returns a list of experimental signature algorithms
@@ -68,6 +68,13 @@ type Signer interface {
 
 	SetPayloadFormat(format PayloadFormat)
 	GetPayloadFormat() PayloadFormat
+
+	// SetChallenge and SetDomain bind the proofs the signer creates to a verifier's challenge and domain, as for
+	// presentations https://w3c-ccg.github.io/data-integrity-spec/#proofs
+	SetChallenge(challenge string)
+	GetChallenge() string
+	SetDomain(domain string)
+	GetDomain() string
 }
 
 type Verifier interface {
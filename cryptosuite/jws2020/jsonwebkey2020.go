@@ -8,7 +8,6 @@ import (
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/util"
-	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/pkg/errors"
 )
@@ -168,8 +167,10 @@ func GenerateP384JSONWebKey2020() (*JSONWebKey2020, error) {
 // a message and provide a valid JSON Web Signature (JWS) value as a result.
 type JSONWebKeySigner struct {
 	jwx.Signer
-	purpose cryptosuite.ProofPurpose
-	format  cryptosuite.PayloadFormat
+	purpose   cryptosuite.ProofPurpose
+	format    cryptosuite.PayloadFormat
+	challenge string
+	domain    string
 }
 
 // Sign returns a byte array signature value for a message `tbs`
@@ -182,12 +183,7 @@ func (s *JSONWebKeySigner) Sign(tbs []byte) ([]byte, error) {
 	if err := headers.Set(jws.CriticalKey, []string{b64}); err != nil {
 		return nil, err
 	}
-	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
-	alg := s.ALG
-	if alg == "Ed25519" {
-		alg = jwa.EdDSA.String()
-	}
-	return jws.Sign(nil, jws.WithKey(jwa.SignatureAlgorithm(alg), s.PrivateKey), jws.WithHeaders(headers), jws.WithDetachedPayload(tbs))
+	return jws.Sign(nil, jws.WithKey(jwx.SignerAlgorithm(s.Signer), s.PrivateKey), jws.WithHeaders(headers), jws.WithDetachedPayload(tbs))
 }
 
 func (s *JSONWebKeySigner) GetKeyID() string {
@@ -218,6 +214,22 @@ func (s *JSONWebKeySigner) GetPayloadFormat() cryptosuite.PayloadFormat {
 	return s.format
 }
 
+func (s *JSONWebKeySigner) SetChallenge(challenge string) {
+	s.challenge = challenge
+}
+
+func (s *JSONWebKeySigner) GetChallenge() string {
+	return s.challenge
+}
+
+func (s *JSONWebKeySigner) SetDomain(domain string) {
+	s.domain = domain
+}
+
+func (s *JSONWebKeySigner) GetDomain() string {
+	return s.domain
+}
+
 func NewJSONWebKeySigner(id string, key jwx.PrivateKeyJWK, purpose cryptosuite.ProofPurpose) (*JSONWebKeySigner, error) {
 	signer, err := jwx.NewJWXSignerFromJWK(id, key)
 	if err != nil {
@@ -243,12 +255,7 @@ func (v JSONWebKeyVerifier) Verify(message, signature []byte) error {
 	if err != nil {
 		return errors.Wrap(err, "getting public key")
 	}
-	// Ed25519 is not supported by the jwx library yet https://github.com/TBD54566975/ssi-sdk/issues/520
-	alg := v.ALG
-	if alg == "Ed25519" {
-		alg = jwa.EdDSA.String()
-	}
-	_, err = jws.Verify(signature, jws.WithKey(jwa.SignatureAlgorithm(alg), pubKey), jws.WithDetachedPayload(message))
+	_, err = jws.Verify(signature, jws.WithKey(jwx.VerifierAlgorithm(v.Verifier), pubKey), jws.WithDetachedPayload(message))
 	return err
 }
 
@@ -65,7 +65,7 @@ func (JWSSignatureSuite) RequiredContexts() []string {
 
 func (j JWSSignatureSuite) Sign(s cryptosuite.Signer, p cryptosuite.WithEmbeddedProof) error {
 	// create proof before running the create verify hash algorithm
-	proof := j.createProof(s.GetKeyID(), s.GetProofPurpose())
+	proof := j.createProof(s.GetKeyID(), s.GetProofPurpose(), s.GetChallenge(), s.GetDomain())
 
 	// prepare proof options
 	contexts, err := cryptosuite.GetContextsFromProvable(p)
@@ -274,6 +274,7 @@ type JSONWebSignature2020Proof struct {
 	JWS                string                    `json:"jws,omitempty"`
 	ProofPurpose       cryptosuite.ProofPurpose  `json:"proofPurpose,omitempty"`
 	Challenge          string                    `json:"challenge,omitempty"`
+	Domain             string                    `json:"domain,omitempty"`
 	VerificationMethod string                    `json:"verificationMethod,omitempty"`
 }
 
@@ -317,9 +318,10 @@ func (j *JSONWebSignature2020Proof) DecodeJWS() ([]byte, error) {
 	return base64.RawURLEncoding.DecodeString(jwsParts[2])
 }
 
-func (j JWSSignatureSuite) createProof(verificationMethod string, purpose cryptosuite.ProofPurpose) JSONWebSignature2020Proof {
-	var challenge string
-	if purpose == cryptosuite.Authentication {
+// createProof creates a proof to be signed, where a proof for authentication without a challenge is given a random
+// one
+func (j JWSSignatureSuite) createProof(verificationMethod string, purpose cryptosuite.ProofPurpose, challenge, domain string) JSONWebSignature2020Proof {
+	if challenge == "" && purpose == cryptosuite.Authentication {
 		challenge = uuid.NewString()
 	}
 	return JSONWebSignature2020Proof{
@@ -327,6 +329,7 @@ func (j JWSSignatureSuite) createProof(verificationMethod string, purpose crypto
 		Created:            GetRFC3339Timestamp(),
 		ProofPurpose:       purpose,
 		Challenge:          challenge,
+		Domain:             domain,
 		VerificationMethod: verificationMethod,
 	}
 }
@@ -333,6 +333,42 @@ func TestJSONWebSignature2020TestVectorPresentation0(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestJSONWebSignature2020PresentationChallengeAndDomain(t *testing.T) {
+	signer, jwk := getTestVectorKey0Signer(t, cryptosuite.Authentication)
+	signer.SetChallenge("123")
+	signer.SetDomain("verifier.example.com")
+	pres := TestVerifiablePresentation{
+		Context: []string{"https://www.w3.org/2018/credentials/v1",
+			"https://w3id.org/security/suites/jws-2020/v1"},
+		Type:   []string{"VerifiablePresentation"},
+		Holder: "did:example:123",
+	}
+
+	suite := GetJSONWebSignature2020Suite()
+	assert.NoError(t, suite.Sign(&signer, &pres))
+	proof, err := JSONWebSignatureProofFromGenericProof(*pres.GetProof())
+	assert.NoError(t, err)
+	assert.Equal(t, "123", proof.Challenge)
+	assert.Equal(t, "verifier.example.com", proof.Domain)
+
+	verifier, err := NewJSONWebKeyVerifier("verifier-id", jwk.PublicKeyJWK)
+	assert.NoError(t, err)
+	assert.NoError(t, suite.Verify(verifier, &pres))
+
+	// the challenge and domain are signed
+	proof.Domain = "other.example.com"
+	tampered := crypto.Proof(proof)
+	pres.SetProof(&tampered)
+	assert.Error(t, suite.Verify(verifier, &pres))
+
+	// a random challenge is still created for authentication without one
+	signer.SetChallenge("")
+	assert.NoError(t, suite.Sign(&signer, &pres))
+	proof, err = JSONWebSignatureProofFromGenericProof(*pres.GetProof())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, proof.Challenge)
+}
+
 // https://github.com/decentralized-identity/JWS-Test-Suite/blob/main/data/keys/key-0-ed25519.json
 func TestJSONWebSignature2020TestVectorPresentation1(t *testing.T) {
 	signer, jwk := getTestVectorKey0Signer(t, cryptosuite.Authentication)
@@ -11,6 +11,7 @@ const (
 	WebMethod  Method = "web"
 	IONMethod  Method = "ion"
 	JWKMethod  Method = "jwk"
+	EthrMethod Method = "ethr"
 )
 
 func (m Method) String() string {
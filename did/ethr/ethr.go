@@ -0,0 +1,199 @@
+package ethr
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+)
+
+type DIDEthr string
+
+const (
+	// Prefix did:ethr prefix
+	Prefix = "did:ethr"
+
+	// Mainnet is the network of did:ethr DIDs that do not name one
+	Mainnet = "mainnet"
+
+	// DefaultRegistry is the address of the ERC-1056 registry contract deployed to mainnet and most test networks
+	// https://github.com/uport-project/ethr-did-registry#contract-deployments
+	DefaultRegistry = "0xdca7ef03e98e0dc2b855be647c39abe984fcf21b"
+
+	EcdsaSecp256k1RecoveryMethod2020 cryptosuite.LDKeyType = "EcdsaSecp256k1RecoveryMethod2020"
+	SECP256k1RecoveryContext                               = "https://w3id.org/security/suites/secp256k1recovery-2020/v2"
+
+	controllerFragment    = "#controller"
+	controllerKeyFragment = "#controllerKey"
+)
+
+// Network is an Ethereum network that did:ethr DIDs are anchored to
+type Network struct {
+	// Name identifies the network in DIDs, e.g. mainnet or a hex chain ID such as 0x5
+	Name string
+	// ChainID is the EIP-155 chain ID of the network
+	ChainID uint64
+	// RPCURL is the JSON-RPC endpoint of a node of the network. DIDs on networks without one are resolved offline.
+	RPCURL string
+	// Registry is the address of the network's ERC-1056 registry contract, DefaultRegistry if empty
+	Registry string
+}
+
+func (d DIDEthr) IsValid() bool {
+	_, _, _, err := d.parse()
+	return err == nil
+}
+
+func (d DIDEthr) String() string {
+	return string(d)
+}
+
+// Suffix returns the value without the `did:ethr` prefix
+func (d DIDEthr) Suffix() (string, error) {
+	suffix, ok := strings.CutPrefix(string(d), Prefix+":")
+	if !ok || suffix == "" {
+		return "", fmt.Errorf("not a did:ethr DID: %s", d)
+	}
+	return suffix, nil
+}
+
+func (DIDEthr) Method() did.Method {
+	return did.EthrMethod
+}
+
+// Network returns the name of the network the DID is anchored to
+func (d DIDEthr) Network() (string, error) {
+	network, _, _, err := d.parse()
+	return network, err
+}
+
+// Address returns the Ethereum address identifying the DID, derived from the public key of DIDs identified by one
+func (d DIDEthr) Address() (string, error) {
+	_, address, _, err := d.parse()
+	return address, err
+}
+
+// Expand returns the DID Document of the DID as it is before any change is made to it in the registry, which is
+// derived from the DID alone
+func (d DIDEthr) Expand() (*did.Document, error) {
+	network, address, pubKey, err := d.parse()
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := chainIDForNetwork(Network{Name: network})
+	if err != nil {
+		return nil, err
+	}
+	return expand(d.String(), chainID, address, pubKey)
+}
+
+// parse returns the network, address, and, for DIDs identified by a public key, the compressed public key of the DID
+func (d DIDEthr) parse() (network, address string, pubKey []byte, err error) {
+	suffix, err := d.Suffix()
+	if err != nil {
+		return "", "", nil, err
+	}
+	network = Mainnet
+	identifier := suffix
+	if split := strings.Split(suffix, ":"); len(split) == 2 {
+		network, identifier = split[0], split[1]
+	} else if len(split) > 2 {
+		return "", "", nil, fmt.Errorf("invalid did:ethr DID: %s", d)
+	}
+
+	identifierBytes, err := decodeHex(identifier)
+	if err != nil {
+		return "", "", nil, errors.Wrapf(err, "decoding did:ethr identifier: %s", identifier)
+	}
+	switch len(identifierBytes) {
+	case 20:
+		return network, strings.ToLower(identifier), nil, nil
+	case secp256k1.PubKeyBytesLenCompressed:
+		key, err := secp256k1.ParsePubKey(identifierBytes)
+		if err != nil {
+			return "", "", nil, errors.Wrapf(err, "parsing did:ethr public key: %s", identifier)
+		}
+		return network, publicKeyToAddress(key), identifierBytes, nil
+	}
+	return "", "", nil, fmt.Errorf("did:ethr identifier must be an address or a compressed public key: %s", identifier)
+}
+
+// expand constructs the document of a DID with no changes in the registry, which is controlled by the DID's address
+// https://github.com/decentralized-identity/ethr-did-resolver/blob/master/doc/did-method-spec.md#read-resolve
+func expand(id string, chainID uint64, address string, pubKey []byte) (*did.Document, error) {
+	controllerID := id + controllerFragment
+	doc := did.Document{
+		Context: []string{did.KnownDIDContext, SECP256k1RecoveryContext},
+		ID:      id,
+		VerificationMethod: []did.VerificationMethod{{
+			ID:                  controllerID,
+			Type:                EcdsaSecp256k1RecoveryMethod2020,
+			Controller:          id,
+			BlockchainAccountID: blockchainAccountID(chainID, address),
+		}},
+		Authentication:  []did.VerificationMethodSet{controllerID},
+		AssertionMethod: []did.VerificationMethodSet{controllerID},
+	}
+	if pubKey != nil {
+		controllerKey, err := did.ConstructJWKVerificationMethod(id+controllerKeyFragment, id, pubKey, crypto.SECP256k1)
+		if err != nil {
+			return nil, errors.Wrap(err, "constructing controller key verification method")
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, *controllerKey)
+		doc.Authentication = append(doc.Authentication, controllerKey.ID)
+		doc.AssertionMethod = append(doc.AssertionMethod, controllerKey.ID)
+	}
+	return &doc, nil
+}
+
+// chainIDForNetwork returns the chain ID of the network, which is taken from its name for mainnet and networks named
+// by a hex chain ID when it is not set
+func chainIDForNetwork(network Network) (uint64, error) {
+	if network.ChainID != 0 {
+		return network.ChainID, nil
+	}
+	if network.Name == Mainnet {
+		return 1, nil
+	}
+	if hexID, ok := strings.CutPrefix(network.Name, "0x"); ok {
+		chainID, err := strconv.ParseUint(hexID, 16, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "parsing chain ID of network<%s>", network.Name)
+		}
+		return chainID, nil
+	}
+	return 0, fmt.Errorf("unknown did:ethr network<%s>", network.Name)
+}
+
+func blockchainAccountID(chainID uint64, address string) string {
+	return fmt.Sprintf("eip155:%d:%s", chainID, address)
+}
+
+// publicKeyToAddress returns the Ethereum address of a public key, the last 20 bytes of the Keccak-256 hash of the
+// uncompressed key
+func publicKeyToAddress(key *secp256k1.PublicKey) string {
+	return "0x" + hex.EncodeToString(keccak256(key.SerializeUncompressed()[1:])[12:])
+}
+
+func keccak256(data ...[]byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		hash.Write(d)
+	}
+	return hash.Sum(nil)
+}
+
+func decodeHex(value string) ([]byte, error) {
+	trimmed, ok := strings.CutPrefix(value, "0x")
+	if !ok {
+		return nil, errors.New("hex value must start with 0x")
+	}
+	return hex.DecodeString(trimmed)
+}
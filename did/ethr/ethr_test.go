@@ -0,0 +1,254 @@
+package ethr
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+const (
+	// the example account of the web3.js documentation
+	testAddress   = "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"
+	testPublicKey = "0x024e3b81af9c2234cad09d679ce6035ed1392347ce64ce405f5dcd36228a25de6e"
+	testDelegate  = "0x00000000000000000000000000000000000000de"
+)
+
+func TestDIDEthr(t *testing.T) {
+	t.Run("address on mainnet", func(tt *testing.T) {
+		didEthr := DIDEthr("did:ethr:" + testAddress)
+		assert.True(tt, didEthr.IsValid())
+		assert.Equal(tt, did.EthrMethod, didEthr.Method())
+
+		network, err := didEthr.Network()
+		assert.NoError(tt, err)
+		assert.Equal(tt, Mainnet, network)
+
+		doc, err := didEthr.Expand()
+		assert.NoError(tt, err)
+		assert.NoError(tt, doc.IsValid())
+		assert.Len(tt, doc.VerificationMethod, 1)
+		assert.Equal(tt, didEthr.String()+"#controller", doc.VerificationMethod[0].ID)
+		assert.Equal(tt, "eip155:1:"+testAddress, doc.VerificationMethod[0].BlockchainAccountID)
+		assert.Equal(tt, []did.VerificationMethodSet{didEthr.String() + "#controller"}, doc.Authentication)
+	})
+
+	t.Run("public key on a named chain", func(tt *testing.T) {
+		didEthr := DIDEthr("did:ethr:0x5:" + testPublicKey)
+		address, err := didEthr.Address()
+		assert.NoError(tt, err)
+		assert.Equal(tt, testAddress, address)
+
+		doc, err := didEthr.Expand()
+		assert.NoError(tt, err)
+		assert.Len(tt, doc.VerificationMethod, 2)
+		assert.Equal(tt, "eip155:5:"+testAddress, doc.VerificationMethod[0].BlockchainAccountID)
+		assert.Equal(tt, didEthr.String()+"#controllerKey", doc.VerificationMethod[1].ID)
+		assert.NotNil(tt, doc.VerificationMethod[1].PublicKeyJWK)
+		assert.Len(tt, doc.AssertionMethod, 2)
+	})
+
+	t.Run("invalid DIDs", func(tt *testing.T) {
+		for _, id := range []string{
+			"did:web:example.com",
+			"did:ethr:",
+			"did:ethr:2c7536e3605d9c16a7a3d7b1898e529396a65c23",
+			"did:ethr:0x2c7536e3605d9c16",
+			"did:ethr:a:b:" + testAddress,
+		} {
+			assert.False(tt, DIDEthr(id).IsValid(), id)
+		}
+		_, err := DIDEthr("did:ethr:goerli:" + testAddress).Expand()
+		assert.ErrorContains(tt, err, "unknown did:ethr network<goerli>")
+	})
+}
+
+func TestResolver(t *testing.T) {
+	id := "did:ethr:0x5:" + testAddress
+
+	t.Run("zero value resolves offline", func(tt *testing.T) {
+		result, err := Resolver{}.Resolve(context.Background(), id)
+		assert.NoError(tt, err)
+		assert.Equal(tt, id, result.Document.ID)
+		assert.Len(tt, result.Document.VerificationMethod, 1)
+
+		_, err = Resolver{}.Resolve(context.Background(), "did:key:z6Mk")
+		assert.ErrorContains(tt, err, "not a did:ethr DID")
+	})
+
+	t.Run("invalid networks", func(tt *testing.T) {
+		_, err := NewResolver(nil, Network{Name: "0x5", RPCURL: "https://node.example.com"})
+		assert.ErrorContains(tt, err, "client cannot be nil")
+
+		_, err = NewResolver(http.DefaultClient, Network{Name: "goerli"})
+		assert.ErrorContains(tt, err, "unknown did:ethr network<goerli>")
+
+		_, err = NewResolver(http.DefaultClient, Network{Name: "0x5"}, Network{Name: "0x5"})
+		assert.ErrorContains(tt, err, "duplicate network<0x5>")
+	})
+
+	t.Run("applies registry events", func(tt *testing.T) {
+		edKey := make([]byte, 32)
+		edKey[0] = 1
+		node := testNode{
+			owner:   testAddress,
+			changed: 20,
+			logs: map[uint64][]rpcLog{
+				10: {delegateLog(sigAuthPurpose, testDelegate, 0)},
+				20: {
+					attributeLog("did/pub/Ed25519/veriKey/base64", edKey, 10),
+					attributeLog("did/svc/HubService", []byte("https://hub.example.com"), 20),
+				},
+			},
+		}
+		r := node.resolver(tt)
+
+		result, err := r.Resolve(context.Background(), id)
+		require.NoError(tt, err)
+		doc := result.Document
+		require.Len(tt, doc.VerificationMethod, 3)
+		assert.Equal(tt, id+"#delegate-1", doc.VerificationMethod[1].ID)
+		assert.Equal(tt, "eip155:5:"+testDelegate, doc.VerificationMethod[1].BlockchainAccountID)
+		assert.Equal(tt, id+"#delegate-2", doc.VerificationMethod[2].ID)
+		assert.NotNil(tt, doc.VerificationMethod[2].PublicKeyJWK)
+		assert.Equal(tt, []did.VerificationMethodSet{id + "#controller", id + "#delegate-1"}, doc.Authentication)
+		assert.Equal(tt, []did.VerificationMethodSet{id + "#controller", id + "#delegate-1", id + "#delegate-2"}, doc.AssertionMethod)
+		require.Len(tt, doc.Services, 1)
+		assert.Equal(tt, "HubService", doc.Services[0].Type)
+		assert.Equal(tt, "https://hub.example.com", doc.Services[0].ServiceEndpoint)
+	})
+
+	t.Run("revoked delegates are removed", func(tt *testing.T) {
+		node := testNode{
+			owner:   testAddress,
+			changed: 20,
+			logs: map[uint64][]rpcLog{
+				10: {delegateLog(veriKeyPurpose, testDelegate, 0)},
+				20: {revokedDelegateLog(veriKeyPurpose, testDelegate, 10)},
+			},
+		}
+		result, err := node.resolver(tt).Resolve(context.Background(), id)
+		require.NoError(tt, err)
+		assert.Len(tt, result.Document.VerificationMethod, 1)
+		assert.Len(tt, result.Document.AssertionMethod, 1)
+	})
+
+	t.Run("changed owner controls the DID", func(tt *testing.T) {
+		node := testNode{owner: testDelegate}
+		pubKeyID := "did:ethr:0x5:" + testPublicKey
+		result, err := node.resolver(tt).Resolve(context.Background(), pubKeyID)
+		require.NoError(tt, err)
+		require.Len(tt, result.Document.VerificationMethod, 1)
+		assert.Equal(tt, "eip155:5:"+testDelegate, result.Document.VerificationMethod[0].BlockchainAccountID)
+	})
+
+	t.Run("deactivated DID", func(tt *testing.T) {
+		node := testNode{owner: nullAddress}
+		result, err := node.resolver(tt).Resolve(context.Background(), id)
+		require.NoError(tt, err)
+		assert.True(tt, result.DocumentMetadata.Deactivated)
+		assert.Empty(tt, result.Document.VerificationMethod)
+	})
+
+	t.Run("rpc error", func(tt *testing.T) {
+		node := testNode{fail: true}
+		_, err := node.resolver(tt).Resolve(context.Background(), id)
+		assert.ErrorContains(tt, err, "calling eth_call: execution reverted")
+	})
+}
+
+// testNode serves the registry state of a single identity over JSON-RPC
+type testNode struct {
+	owner   string
+	changed uint64
+	logs    map[uint64][]rpcLog
+	fail    bool
+}
+
+func (n testNode) resolver(t *testing.T) *Resolver {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var request struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+
+		var result any
+		switch request.Method {
+		case "eth_call":
+			if n.fail {
+				_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted"}}`))
+				return
+			}
+			var call map[string]string
+			require.NoError(t, json.Unmarshal(request.Params[0], &call))
+			assert.Equal(t, DefaultRegistry, call["to"])
+			if strings.HasPrefix(call["data"], identityOwnerSelector) {
+				result = "0x" + encodeAddress(n.owner)
+			} else {
+				result = "0x" + encodeUint(n.changed)
+			}
+		case "eth_getLogs":
+			var filter map[string]any
+			require.NoError(t, json.Unmarshal(request.Params[0], &filter))
+			block, err := strconv.ParseUint(strings.TrimPrefix(filter["fromBlock"].(string), "0x"), 16, 64)
+			require.NoError(t, err)
+			result = n.logs[block]
+		}
+		resp, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "result": result})
+		require.NoError(t, err)
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	r, err := NewResolver(server.Client(), Network{Name: "0x5", RPCURL: server.URL})
+	require.NoError(t, err)
+	r.now = func() time.Time { return time.Unix(1000, 0) }
+	return r
+}
+
+func delegateLog(delegateType, delegate string, previousChange uint64) rpcLog {
+	return rpcLog{
+		Topics: []string{delegateChangedTopic, "0x" + encodeAddress(testAddress)},
+		Data:   "0x" + encodeBytes32(delegateType) + encodeAddress(delegate) + encodeUint(2000) + encodeUint(previousChange),
+	}
+}
+
+func revokedDelegateLog(delegateType, delegate string, previousChange uint64) rpcLog {
+	return rpcLog{
+		Topics: []string{delegateChangedTopic, "0x" + encodeAddress(testAddress)},
+		Data:   "0x" + encodeBytes32(delegateType) + encodeAddress(delegate) + encodeUint(0) + encodeUint(previousChange),
+	}
+}
+
+func attributeLog(name string, value []byte, previousChange uint64) rpcLog {
+	padded := make([]byte, (len(value)+wordSize-1)/wordSize*wordSize)
+	copy(padded, value)
+	return rpcLog{
+		Topics: []string{attributeChangedTopic, "0x" + encodeAddress(testAddress)},
+		Data: "0x" + encodeBytes32(name) + encodeUint(4*wordSize) + encodeUint(2000) + encodeUint(previousChange) +
+			encodeUint(uint64(len(value))) + hex.EncodeToString(padded),
+	}
+}
+
+func encodeUint(value uint64) string {
+	return fmt.Sprintf("%064x", new(big.Int).SetUint64(value))
+}
+
+func encodeBytes32(value string) string {
+	word := make([]byte, wordSize)
+	copy(word, value)
+	return hex.EncodeToString(word)
+}
@@ -0,0 +1,289 @@
+package ethr
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/pkg/errors"
+)
+
+// The events and functions of the ERC-1056 registry contract https://eips.ethereum.org/EIPS/eip-1056
+var (
+	ownerChangedTopic     = eventTopic("DIDOwnerChanged(address,address,uint256)")
+	delegateChangedTopic  = eventTopic("DIDDelegateChanged(address,bytes32,address,uint256,uint256)")
+	attributeChangedTopic = eventTopic("DIDAttributeChanged(address,bytes32,bytes,uint256,uint256)")
+
+	changedSelector       = functionSelector("changed(address)")
+	identityOwnerSelector = functionSelector("identityOwner(address)")
+)
+
+const (
+	wordSize = 32
+
+	// nullAddress is the owner of deactivated DIDs
+	nullAddress = "0x0000000000000000000000000000000000000000"
+
+	veriKeyPurpose = "veriKey"
+	sigAuthPurpose = "sigAuth"
+	encPurpose     = "enc"
+)
+
+// registryEvent is a DIDDelegateChanged or DIDAttributeChanged event of the registry, or a DIDOwnerChanged event, of
+// which only the previous change is used since the current owner is read from the registry
+type registryEvent struct {
+	topic          string
+	delegateType   string
+	delegate       string
+	name           string
+	value          []byte
+	validTo        *big.Int
+	previousChange uint64
+}
+
+// decodeRegistryEvent decodes the ABI encoded data of a registry event log
+func decodeRegistryEvent(topic string, data []byte) (*registryEvent, error) {
+	word := func(i int) ([]byte, error) {
+		if len(data) < (i+1)*wordSize {
+			return nil, errors.Errorf("event data too short for word %d", i)
+		}
+		return data[i*wordSize : (i+1)*wordSize], nil
+	}
+	var words [][]byte
+	var n int
+	switch topic {
+	case ownerChangedTopic:
+		n = 2
+	case delegateChangedTopic, attributeChangedTopic:
+		n = 4
+	default:
+		return nil, fmt.Errorf("unknown registry event<%s>", topic)
+	}
+	for i := 0; i < n; i++ {
+		w, err := word(i)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, w)
+	}
+
+	event := registryEvent{topic: topic}
+	var err error
+	switch topic {
+	case ownerChangedTopic:
+		event.previousChange, err = wordToUint64(words[1])
+	case delegateChangedTopic:
+		event.delegateType = bytes32ToString(words[0])
+		event.delegate = wordToAddress(words[1])
+		event.validTo = new(big.Int).SetBytes(words[2])
+		event.previousChange, err = wordToUint64(words[3])
+	case attributeChangedTopic:
+		event.name = bytes32ToString(words[0])
+		event.validTo = new(big.Int).SetBytes(words[2])
+		event.previousChange, err = wordToUint64(words[3])
+		if err == nil {
+			event.value, err = decodeDynamicBytes(data, words[1])
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding registry event")
+	}
+	return &event, nil
+}
+
+// documentEntry is a verification method or service added to a document by a registry event
+type documentEntry struct {
+	method   *did.VerificationMethod
+	purposes []string
+	service  *did.Service
+}
+
+// applyRegistryEvents adds the delegates and attributes of the events, in the order they happened, that are valid
+// at the given time to the document. Events that revoke or expire an earlier delegate or attribute remove it.
+func applyRegistryEvents(doc *did.Document, chainID uint64, events []registryEvent, now time.Time) error {
+	var order []string
+	entries := make(map[string]documentEntry)
+	var delegateCount, serviceCount int
+	for _, event := range events {
+		if event.topic == ownerChangedTopic {
+			continue
+		}
+		var key string
+		if event.topic == delegateChangedTopic {
+			key = "delegate:" + event.delegateType + ":" + event.delegate
+		} else {
+			key = "attribute:" + event.name + ":" + hex.EncodeToString(event.value)
+		}
+
+		if event.validTo.Cmp(big.NewInt(now.Unix())) <= 0 {
+			delete(entries, key)
+			continue
+		}
+		if _, ok := entries[key]; ok {
+			continue
+		}
+
+		var entry *documentEntry
+		var err error
+		switch {
+		case event.topic == delegateChangedTopic:
+			entry = delegateEntry(doc.ID, chainID, event, delegateCount+1)
+			if entry != nil {
+				delegateCount++
+			}
+		case strings.HasPrefix(event.name, "did/pub/"):
+			entry, err = publicKeyEntry(doc.ID, event, delegateCount+1)
+			if err != nil {
+				return err
+			}
+			if entry != nil {
+				delegateCount++
+			}
+		case strings.HasPrefix(event.name, "did/svc/"):
+			serviceCount++
+			entry = &documentEntry{service: &did.Service{
+				ID:              fmt.Sprintf("%s#service-%d", doc.ID, serviceCount),
+				Type:            strings.TrimPrefix(event.name, "did/svc/"),
+				ServiceEndpoint: string(event.value),
+			}}
+		}
+		// unsupported delegate types and attributes are ignored
+		if entry == nil {
+			continue
+		}
+		entries[key] = *entry
+		order = append(order, key)
+	}
+
+	for _, key := range order {
+		entry, ok := entries[key]
+		if !ok {
+			continue
+		}
+		// an entry revoked and added again is listed once, where it was last added
+		delete(entries, key)
+		if entry.service != nil {
+			doc.Services = append(doc.Services, *entry.service)
+			continue
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, *entry.method)
+		for _, purpose := range entry.purposes {
+			switch purpose {
+			case sigAuthPurpose:
+				doc.Authentication = append(doc.Authentication, entry.method.ID)
+			case veriKeyPurpose:
+				doc.AssertionMethod = append(doc.AssertionMethod, entry.method.ID)
+			case encPurpose:
+				doc.KeyAgreement = append(doc.KeyAgreement, entry.method.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// delegateEntry returns the verification method of a delegate, which can sign for the DID, and also authenticate as
+// the DID in the case of a sigAuth delegate
+func delegateEntry(id string, chainID uint64, event registryEvent, n int) *documentEntry {
+	var purposes []string
+	switch event.delegateType {
+	case veriKeyPurpose:
+		purposes = []string{veriKeyPurpose}
+	case sigAuthPurpose:
+		purposes = []string{veriKeyPurpose, sigAuthPurpose}
+	default:
+		return nil
+	}
+	return &documentEntry{
+		method: &did.VerificationMethod{
+			ID:                  fmt.Sprintf("%s#delegate-%d", id, n),
+			Type:                EcdsaSecp256k1RecoveryMethod2020,
+			Controller:          id,
+			BlockchainAccountID: blockchainAccountID(chainID, event.delegate),
+		},
+		purposes: purposes,
+	}
+}
+
+// publicKeyEntry returns the verification method of a public key attribute named did/pub/<key type>/<purpose>/<encoding>,
+// where the attribute's value holds the key's bytes
+func publicKeyEntry(id string, event registryEvent, n int) (*documentEntry, error) {
+	split := strings.Split(event.name, "/")
+	if len(split) < 4 {
+		return nil, nil
+	}
+	var keyType crypto.KeyType
+	switch split[2] {
+	case "Secp256k1":
+		keyType = crypto.SECP256k1
+	case "Ed25519":
+		keyType = crypto.Ed25519
+	case "X25519":
+		keyType = crypto.X25519
+	default:
+		return nil, nil
+	}
+	var purposes []string
+	switch split[3] {
+	case veriKeyPurpose:
+		purposes = []string{veriKeyPurpose}
+	case sigAuthPurpose:
+		purposes = []string{veriKeyPurpose, sigAuthPurpose}
+	case encPurpose:
+		purposes = []string{encPurpose}
+	default:
+		return nil, nil
+	}
+	method, err := did.ConstructJWKVerificationMethod(fmt.Sprintf("%s#delegate-%d", id, n), id, event.value, keyType)
+	if err != nil {
+		return nil, errors.Wrapf(err, "constructing verification method for attribute<%s>", event.name)
+	}
+	return &documentEntry{method: method, purposes: purposes}, nil
+}
+
+func eventTopic(signature string) string {
+	return "0x" + hex.EncodeToString(keccak256([]byte(signature)))
+}
+
+func functionSelector(signature string) string {
+	return "0x" + hex.EncodeToString(keccak256([]byte(signature))[:4])
+}
+
+// encodeAddress returns the ABI encoding of an address, left padded to a word
+func encodeAddress(address string) string {
+	return strings.Repeat("0", 2*wordSize-40) + strings.TrimPrefix(strings.ToLower(address), "0x")
+}
+
+func wordToAddress(word []byte) string {
+	return "0x" + hex.EncodeToString(word[wordSize-20:])
+}
+
+func wordToUint64(word []byte) (uint64, error) {
+	value := new(big.Int).SetBytes(word)
+	if !value.IsUint64() {
+		return 0, errors.Errorf("value<%s> overflows uint64", value)
+	}
+	return value.Uint64(), nil
+}
+
+func bytes32ToString(word []byte) string {
+	return string(bytes.TrimRight(word, "\x00"))
+}
+
+// decodeDynamicBytes decodes the bytes value at the offset held in the given head word
+func decodeDynamicBytes(data, offsetWord []byte) ([]byte, error) {
+	offset, err := wordToUint64(offsetWord)
+	if err != nil || offset+wordSize > uint64(len(data)) {
+		return nil, errors.New("invalid bytes offset")
+	}
+	length, err := wordToUint64(data[offset : offset+wordSize])
+	if err != nil || offset+wordSize+length > uint64(len(data)) {
+		return nil, errors.New("invalid bytes length")
+	}
+	start := offset + wordSize
+	return data[start : start+length], nil
+}
@@ -0,0 +1,265 @@
+package ethr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// Resolver resolves did:ethr DIDs by reading the changes made to them in the ERC-1056 registry of their network. DIDs
+// on networks without a JSON-RPC endpoint, including every network of the zero value, are resolved offline to the
+// document derived from the DID alone.
+type Resolver struct {
+	client   *http.Client
+	networks map[string]Network
+	now      func() time.Time
+}
+
+var _ resolution.Resolver = (*Resolver)(nil)
+
+// NewResolver creates a resolver for DIDs on the given networks, which reads the registry of networks with an RPC URL
+// using the client
+func NewResolver(client *http.Client, networks ...Network) (*Resolver, error) {
+	r := Resolver{client: client, networks: make(map[string]Network, len(networks))}
+	for _, network := range networks {
+		if network.Name == "" {
+			return nil, errors.New("network name cannot be empty")
+		}
+		if _, ok := r.networks[network.Name]; ok {
+			return nil, fmt.Errorf("duplicate network<%s>", network.Name)
+		}
+		if network.RPCURL != "" && client == nil {
+			return nil, errors.New("client cannot be nil")
+		}
+		chainID, err := chainIDForNetwork(network)
+		if err != nil {
+			return nil, err
+		}
+		network.ChainID = chainID
+		if network.Registry == "" {
+			network.Registry = DefaultRegistry
+		}
+		r.networks[network.Name] = network
+	}
+	return &r, nil
+}
+
+func (Resolver) Methods() []did.Method {
+	return []did.Method{did.EthrMethod}
+}
+
+// Resolve resolves a did:ethr DID by applying the delegates and attributes in the registry to the document derived
+// from the DID, which is controlled by the current owner of the DID's address
+// specification: https://github.com/decentralized-identity/ethr-did-resolver/blob/master/doc/did-method-spec.md#read-resolve
+func (r Resolver) Resolve(ctx context.Context, id string, _ ...resolution.Option) (*resolution.Result, error) {
+	if !strings.HasPrefix(id, Prefix) {
+		return nil, fmt.Errorf("not a did:ethr DID: %s", id)
+	}
+	networkName, address, pubKey, err := DIDEthr(id).parse()
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing did:ethr DID: %s", id)
+	}
+	network, ok := r.networks[networkName]
+	if !ok {
+		network = Network{Name: networkName}
+	}
+	chainID, err := chainIDForNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+	if network.RPCURL == "" {
+		doc, err := expand(id, chainID, address, pubKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not expand did:ethr DID: %s", id)
+		}
+		return &resolution.Result{Document: *doc}, nil
+	}
+
+	owner, err := r.identityOwner(ctx, network, address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading owner of did:ethr DID: %s", id)
+	}
+	if owner == nullAddress {
+		return &resolution.Result{
+			Document:         did.Document{Context: []string{did.KnownDIDContext}, ID: id},
+			DocumentMetadata: &resolution.DocumentMetadata{Deactivated: true},
+		}, nil
+	}
+	events, err := r.registryEvents(ctx, network, address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading registry events of did:ethr DID: %s", id)
+	}
+
+	// the key a DID is identified by only controls the DID while its address owns it
+	if owner != address {
+		pubKey = nil
+	}
+	doc, err := expand(id, chainID, owner, pubKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not expand did:ethr DID: %s", id)
+	}
+	now := time.Now
+	if r.now != nil {
+		now = r.now
+	}
+	if err = applyRegistryEvents(doc, chainID, events, now()); err != nil {
+		return nil, errors.Wrapf(err, "applying registry events of did:ethr DID: %s", id)
+	}
+	return &resolution.Result{Document: *doc}, nil
+}
+
+// identityOwner returns the address that owns the identity in the registry
+func (r Resolver) identityOwner(ctx context.Context, network Network, identity string) (string, error) {
+	result, err := r.callRegistry(ctx, network, identityOwnerSelector, identity)
+	if err != nil {
+		return "", err
+	}
+	return wordToAddress(result), nil
+}
+
+// registryEvents returns the registry events of the identity in the order they happened, by walking back through
+// the blocks of its changes from the last one
+func (r Resolver) registryEvents(ctx context.Context, network Network, identity string) ([]registryEvent, error) {
+	result, err := r.callRegistry(ctx, network, changedSelector, identity)
+	if err != nil {
+		return nil, err
+	}
+	block, err := wordToUint64(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks [][]registryEvent
+	for block != 0 {
+		blockHex := "0x" + strconv.FormatUint(block, 16)
+		var logs []rpcLog
+		if err = r.call(ctx, network, "eth_getLogs", []any{map[string]any{
+			"address":   network.Registry,
+			"fromBlock": blockHex,
+			"toBlock":   blockHex,
+			"topics":    []any{nil, "0x" + encodeAddress(identity)},
+		}}, &logs); err != nil {
+			return nil, err
+		}
+
+		var events []registryEvent
+		// events of the same block point back to it, apart from the first of the block
+		previous := uint64(0)
+		for _, log := range logs {
+			if len(log.Topics) == 0 {
+				continue
+			}
+			data, err := decodeHex(log.Data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decoding data of log in block<%d>", block)
+			}
+			event, err := decodeRegistryEvent(log.Topics[0], data)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, *event)
+			if event.previousChange < block && event.previousChange > previous {
+				previous = event.previousChange
+			}
+		}
+		blocks = append(blocks, events)
+		block = previous
+	}
+
+	var events []registryEvent
+	for i := len(blocks) - 1; i >= 0; i-- {
+		events = append(events, blocks[i]...)
+	}
+	return events, nil
+}
+
+// callRegistry calls a function of the registry taking an address, returning the first word of its result
+func (r Resolver) callRegistry(ctx context.Context, network Network, selector, address string) ([]byte, error) {
+	var result string
+	if err := r.call(ctx, network, "eth_call", []any{map[string]string{
+		"to":   network.Registry,
+		"data": selector + encodeAddress(address),
+	}, "latest"}, &result); err != nil {
+		return nil, err
+	}
+	resultBytes, err := decodeHex(result)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding call result")
+	}
+	if len(resultBytes) < wordSize {
+		return nil, fmt.Errorf("call result<%s> is shorter than a word", result)
+	}
+	return resultBytes[:wordSize], nil
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcLog struct {
+	Topics []string `json:"topics"`
+	Data   string   `json:"data"`
+}
+
+// call makes a JSON-RPC call to the node of the network, decoding its result into result
+func (r Resolver) call(ctx context.Context, network Network, method string, params []any, result any) error {
+	reqBytes, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return errors.Wrap(err, "marshalling request")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, network.RPCURL, bytes.NewReader(reqBytes))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "calling %s, with URL: %s", method, network.RPCURL)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "calling %s, with response %+v", method, resp)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("calling %s: %q", method, string(body))
+	}
+	var rpcResp rpcResponse
+	if err = json.Unmarshal(body, &rpcResp); err != nil {
+		return errors.Wrapf(err, "unmarshalling %s response", method)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("calling %s: %s (%d)", method, rpcResp.Error.Message, rpcResp.Error.Code)
+	}
+	if err = json.Unmarshal(rpcResp.Result, result); err != nil {
+		return errors.Wrapf(err, "unmarshalling %s result", method)
+	}
+	return nil
+}
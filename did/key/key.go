@@ -9,6 +9,7 @@ import (
 	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
 	"github.com/TBD54566975/ssi-sdk/did"
 	"github.com/jorrizza/ed2curve25519"
+	"github.com/lestrrat-go/jwx/v2/x25519"
 	"github.com/mr-tron/base58"
 	"github.com/multiformats/go-multibase"
 	"github.com/multiformats/go-multicodec"
@@ -150,38 +151,52 @@ func MultibaseEncodedKey(kt crypto.KeyType, publicKey []byte) (string, error) {
 // Decode takes a did:key and returns the underlying public key value as bytes, the key type, and a possible error
 // https://w3c-ccg.github.io/did-method-key/#document-creation-algorithm
 func (d DIDKey) Decode() ([]byte, crypto.KeyType, error) {
-	parsed, err := d.Suffix()
+	_, pubKeyBytes, cryptoKeyType, err := DecodeDIDKey(d.String())
 	if err != nil {
-		return nil, "", errors.Wrap(err, "parsing did:key")
+		return nil, "", err
+	}
+	return pubKeyBytes, cryptoKeyType, nil
+}
+
+// DecodeDIDKey takes a did:key and returns its multicodec, the underlying public key value as bytes, and the key
+// type, without expanding it into a DID Document
+// https://w3c-ccg.github.io/did-method-key/#document-creation-algorithm
+func DecodeDIDKey(didKey string) (codec uint64, pubKeyBytes []byte, keyType crypto.KeyType, err error) {
+	parsed, err := DIDKey(didKey).Suffix()
+	if err != nil {
+		return 0, nil, "", errors.Wrap(err, "parsing did:key")
 	}
 	if parsed == "" {
-		return nil, "", fmt.Errorf("could not decode did:key value: %s", string(d))
+		return 0, nil, "", fmt.Errorf("could not decode did:key value: %s", didKey)
 	}
 
 	encoding, decoded, err := multibase.Decode(parsed)
 	if err != nil {
-		return nil, "", errors.Wrap(err, "decoding did:key")
+		return 0, nil, "", errors.Wrap(err, "decoding did:key")
 	}
 	if encoding != did.Base58BTCMultiBase {
-		return nil, "", fmt.Errorf("expected %d encoding but found %d", did.Base58BTCMultiBase, encoding)
+		return 0, nil, "", fmt.Errorf("expected %d encoding but found %d", did.Base58BTCMultiBase, encoding)
 	}
 
 	// n = # bytes for the int, which we expect to be two from our multicodec
 	multiCodec, n, err := varint.FromUvarint(decoded)
 	if err != nil {
-		return nil, "", err
+		return 0, nil, "", err
 	}
 	if n != 2 {
-		return nil, "", errors.New("error parsing did:key varint")
+		return 0, nil, "", errors.New("error parsing did:key varint")
 	}
 
-	pubKeyBytes := decoded[n:]
+	pubKeyBytes = decoded[n:]
+	if len(pubKeyBytes) == 0 {
+		return 0, nil, "", fmt.Errorf("did:key<%s> has no public key", didKey)
+	}
 	multiCodecValue := multicodec.Code(multiCodec)
 	cryptoKeyType, err := did.MultiCodecToKeyType(multiCodecValue)
 	if err != nil {
-		return nil, "", errors.Wrap(err, "determining key type")
+		return 0, nil, "", errors.Wrapf(err, "unsupported multicodec<%s>", multiCodecValue)
 	}
-	return pubKeyBytes, cryptoKeyType, nil
+	return multiCodec, pubKeyBytes, cryptoKeyType, nil
 }
 
 // Expand turns the DID key into a compliant DID Document
@@ -347,6 +362,20 @@ func x25519KeyAndID(id string, ed25519PubKey ed25519.PublicKey) ([]byte, string,
 	return x25519Key, id + "#" + suffix, nil
 }
 
+// DeriveKeyAgreementPrivateKey derives the X25519 private key corresponding to the key agreement key that is derived,
+// upon expansion, from the public key of an Ed25519 did:key
+// https://w3c-ccg.github.io/did-method-key/#derive-encryption-key-algorithm
+func DeriveKeyAgreementPrivateKey(privKey ed25519.PrivateKey) (x25519.PrivateKey, error) {
+	if len(privKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("ed25519 private key is not the right size")
+	}
+	x25519Key, err := x25519.NewKeyFromSeed(ed2curve25519.Ed25519PrivateKeyToCurve25519(privKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "constructing x25519 private key")
+	}
+	return x25519Key, nil
+}
+
 func processExpansionOptions(opts ...Option) (cryptosuite.LDKeyType, bool, error) {
 	publicKeyFormat := cryptosuite.JSONWebKey2020Type
 	enableEncryptionKeyDerivation := true
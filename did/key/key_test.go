@@ -12,6 +12,8 @@ import (
 	"testing"
 
 	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
 	"github.com/multiformats/go-multibase"
 	"github.com/multiformats/go-multicodec"
 
@@ -21,8 +23,10 @@ import (
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseDID(t *testing.T) {
@@ -175,6 +179,53 @@ func TestDecodeDIDKey(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "expected 122 encoding but found 98")
 	})
+
+	t.Run("codec and public key of each supported key type", func(t *testing.T) {
+		for _, kt := range GetSupportedDIDKeyTypes() {
+			_, didKey, err := GenerateDIDKey(kt)
+			assert.NoError(t, err)
+			expectedPubKey, expectedKeyType, err := didKey.Decode()
+			assert.NoError(t, err)
+			expectedCodec, err := did.KeyTypeToMultiCodec(kt)
+			assert.NoError(t, err)
+
+			codec, pubKey, keyType, err := DecodeDIDKey(didKey.String())
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(expectedCodec), codec)
+			assert.Equal(t, expectedPubKey, pubKey)
+			assert.Equal(t, expectedKeyType, keyType)
+		}
+	})
+
+	t.Run("unsupported multicodec", func(t *testing.T) {
+		codec := append(varint.ToUvarint(uint64(multicodec.Bls12_381G2Pub)), make([]byte, 96)...)
+		encoded, err := multibase.Encode(did.Base58BTCMultiBase, codec)
+		assert.NoError(t, err)
+
+		_, _, _, err = DecodeDIDKey("did:key:" + encoded)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported multicodec<bls12_381-g2-pub>")
+	})
+
+	t.Run("malformed multibase prefix", func(t *testing.T) {
+		_, _, _, err := DecodeDIDKey("did:key:!z6Mk")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "decoding did:key")
+
+		// not valid base58 after the z prefix
+		_, _, _, err = DecodeDIDKey("did:key:z0OIl")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "decoding did:key")
+	})
+
+	t.Run("no public key", func(t *testing.T) {
+		encoded, err := multibase.Encode(did.Base58BTCMultiBase, varint.ToUvarint(uint64(did.Ed25519MultiCodec)))
+		assert.NoError(t, err)
+
+		_, _, _, err = DecodeDIDKey("did:key:" + encoded)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "has no public key")
+	})
 }
 
 func TestExpandDIDKey(t *testing.T) {
@@ -209,6 +260,65 @@ func TestExpandDIDKey(t *testing.T) {
 	})
 }
 
+func TestExpandX25519DIDKey(t *testing.T) {
+	privKey, didKey, err := GenerateDIDKey(crypto.X25519)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, privKey)
+
+	doc, err := didKey.Expand()
+	assert.NoError(t, err)
+	assert.NoError(t, doc.IsValid())
+	assert.Len(t, doc.VerificationMethod, 1)
+	assert.Equal(t, []did.VerificationMethodSet{doc.VerificationMethod[0].ID}, doc.KeyAgreement)
+	assert.Empty(t, doc.Authentication)
+	assert.Empty(t, doc.AssertionMethod)
+	assert.Equal(t, string(crypto.X25519), doc.VerificationMethod[0].PublicKeyJWK.CRV)
+}
+
+func TestDeriveKeyAgreementPrivateKey(t *testing.T) {
+	t.Run("matches the derived key agreement key", func(tt *testing.T) {
+		privKey, didKey, err := GenerateDIDKey(crypto.Ed25519)
+		assert.NoError(tt, err)
+
+		x25519PrivKey, err := DeriveKeyAgreementPrivateKey(privKey.(ed25519.PrivateKey))
+		assert.NoError(tt, err)
+
+		doc, err := didKey.Expand()
+		assert.NoError(tt, err)
+		assert.Len(tt, doc.KeyAgreement, 1)
+		keyAgreementKey, err := did.GetKeyFromVerificationMethod(*doc, doc.KeyAgreement[0].(string))
+		assert.NoError(tt, err)
+		assert.Equal(tt, keyAgreementKey, x25519PrivKey.Public())
+	})
+
+	t.Run("derived key pair is usable for JWE", func(tt *testing.T) {
+		privKey, didKey, err := GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		x25519PrivKey, err := DeriveKeyAgreementPrivateKey(privKey.(ed25519.PrivateKey))
+		require.NoError(tt, err)
+
+		for _, format := range []cryptosuite.LDKeyType{cryptosuite.JSONWebKey2020Type, cryptosuite.MultikeyType} {
+			doc, err := didKey.Expand(Option{Name: PublicKeyFormatOption, Value: format})
+			require.NoError(tt, err)
+			require.Len(tt, doc.KeyAgreement, 1)
+			keyAgreementKey, err := did.GetKeyFromVerificationMethod(*doc, doc.KeyAgreement[0].(string))
+			require.NoError(tt, err)
+
+			encrypted, err := jwe.Encrypt([]byte("hello"), jwe.WithKey(jwa.ECDH_ES_A256KW, keyAgreementKey))
+			require.NoError(tt, err)
+			decrypted, err := jwe.Decrypt(encrypted, jwe.WithKey(jwa.ECDH_ES_A256KW, x25519PrivKey))
+			assert.NoError(tt, err, format)
+			assert.Equal(tt, []byte("hello"), decrypted)
+		}
+	})
+
+	t.Run("bad key", func(tt *testing.T) {
+		_, err := DeriveKeyAgreementPrivateKey(ed25519.PrivateKey("bad"))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "not the right size")
+	})
+}
+
 func TestGenerateAndDecodeDIDKey(t *testing.T) {
 	for _, kt := range GetSupportedDIDKeyTypes() {
 		privKey, didKey, err := GenerateDIDKey(kt)
@@ -355,4 +465,25 @@ func TestDIDKeySignVerify(t *testing.T) {
 		err = rsa.VerifyPKCS1v15(&rsaPubKey, gocrypto.SHA256, digest[:], signature)
 		assert.NoError(t, err)
 	})
+
+	t.Run("Test RSA 4096 did:key", func(t *testing.T) {
+		rsaPrivKey, err := rsa.GenerateKey(rand.Reader, 4096)
+		require.NoError(t, err)
+		pubKeyBytes, err := crypto.PubKeyToBytes(rsaPrivKey.PublicKey)
+		require.NoError(t, err)
+		didKey, err := CreateDIDKey(crypto.RSA, pubKeyBytes)
+		require.NoError(t, err)
+
+		decoded, keyType, err := didKey.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, crypto.RSA, keyType)
+		assert.Equal(t, pubKeyBytes, decoded)
+
+		doc, err := didKey.Expand()
+		require.NoError(t, err)
+		require.Len(t, doc.VerificationMethod, 1)
+		pubKey, err := doc.VerificationMethod[0].PublicKeyJWK.ToPublicKey()
+		require.NoError(t, err)
+		assert.Equal(t, rsaPrivKey.PublicKey, pubKey)
+	})
 }
@@ -0,0 +1,111 @@
+package resolution
+
+import (
+	"slices"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+// ErrUpdateProofUnsupported is returned when the updates of a DID method cannot be proven with a signature over the
+// new document
+var ErrUpdateProofUnsupported = errors.New("DID method does not support update proofs")
+
+// unprovableUpdateMethods are the DID methods whose updates cannot be proven by VerifyDIDDocumentUpdate: documents of
+// did:key, did:jwk, and did:pkh are derived from the DID and never updated, and updates of did:ion are proven by the
+// update commitments of the method rather than with a key of the document
+var unprovableUpdateMethods = []did.Method{did.KeyMethod, did.JWKMethod, did.PKHMethod, did.IONMethod}
+
+// VerifyDIDDocumentUpdate reports whether an update of a DID document keeps continuity of control with the document
+// it supersedes, as a verifier holding a cached document checks before trusting the keys of a newer one. Both must be
+// documents of the same DID, and proof must be a JWS whose payload is the JSON of the new document, signed with one of
+// the keys the old document authorized to update it: its capabilityInvocation keys, or its authentication keys if it
+// lists none. The kid header, if any, names the verification method of the old document that signed, and all of its
+// update keys are tried otherwise. Continuity holds only if the signature verifies; false is returned when it is not
+// that of an update key. An error wrapping ErrUpdateProofUnsupported is returned for DID methods whose updates cannot
+// be proven this way, and an error is returned when there is no proof or it is not of the new document.
+func VerifyDIDDocumentUpdate(old, updated did.Document, proof []byte) (bool, error) {
+	if old.ID == "" {
+		return false, errors.New("old document must have an id")
+	}
+	if old.ID != updated.ID {
+		return false, errors.Errorf("new document<%s> is not an update of did<%s>", updated.ID, old.ID)
+	}
+	method, err := GetMethodForDID(old.ID)
+	if err != nil {
+		return false, errors.Wrapf(err, "getting method of did<%s>", old.ID)
+	}
+	if slices.Contains(unprovableUpdateMethods, method) {
+		return false, errors.Wrapf(ErrUpdateProofUnsupported, "did:%s", method)
+	}
+	if len(proof) == 0 {
+		return false, errors.Errorf("update of did<%s> has no proof", old.ID)
+	}
+
+	message, err := jws.Parse(proof)
+	if err != nil {
+		return false, errors.Wrap(err, "parsing update proof")
+	}
+	if len(message.Signatures()) != 1 {
+		return false, errors.Errorf("update proof has %d signatures, not one", len(message.Signatures()))
+	}
+	var proven did.Document
+	if err = json.Unmarshal(message.Payload(), &proven); err != nil {
+		return false, errors.Wrap(err, "update proof payload is not a DID document")
+	}
+	if !DocumentsEqual(proven, updated) {
+		return false, errors.Errorf("update proof is not of the new document of did<%s>", old.ID)
+	}
+
+	updateMethods, err := updateVerificationMethods(old)
+	if err != nil {
+		return false, errors.Wrap(err, "getting update keys of old document")
+	}
+	if len(updateMethods) == 0 {
+		return false, errors.Errorf("old document of did<%s> has no keys authorized to update it", old.ID)
+	}
+	kid := message.Signatures()[0].ProtectedHeaders().KeyID()
+	for _, updateMethod := range updateMethods {
+		if kid != "" && absoluteID(old.ID, kid) != absoluteID(old.ID, updateMethod.ID) {
+			continue
+		}
+		pubKey, err := did.GetKeyFromEmbeddedVerificationMethod(updateMethod)
+		if err != nil {
+			return false, errors.Wrapf(err, "getting key of verification method<%s>", updateMethod.ID)
+		}
+		verifier, err := jwx.NewJWXVerifier(old.ID, &updateMethod.ID, pubKey)
+		if err != nil {
+			return false, errors.Wrapf(err, "creating verifier of verification method<%s>", updateMethod.ID)
+		}
+		if verifier.VerifyJWS(string(proof)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// updateVerificationMethods returns the verification methods of the keys a document authorizes to update it
+func updateVerificationMethods(doc did.Document) ([]did.VerificationMethod, error) {
+	purpose := did.CapabilityInvocation
+	if len(doc.CapabilityInvocation) == 0 {
+		purpose = did.Authentication
+	}
+	return did.GetVerificationMethodsForPurpose(doc, purpose)
+}
+
+// verificationMethodThumbprint returns the JWK thumbprint of the key of a verification method, whatever its encoding
+func verificationMethodThumbprint(method did.VerificationMethod) (string, error) {
+	pubKey, err := did.GetKeyFromEmbeddedVerificationMethod(method)
+	if err != nil {
+		return "", err
+	}
+	pubKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(nil, pubKey)
+	if err != nil {
+		return "", errors.Wrap(err, "converting key to JWK")
+	}
+	return pubKeyJWK.Thumbprint()
+}
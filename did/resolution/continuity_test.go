@@ -0,0 +1,134 @@
+package resolution
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+func TestVerifyDIDDocumentUpdate(t *testing.T) {
+	const id = "did:web:issuer.example.com"
+	newKey := func(tt *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		return pubKey, privKey
+	}
+	jwkMethod := func(tt *testing.T, fragment string, pubKey ed25519.PublicKey) did.VerificationMethod {
+		method, err := did.ConstructJWKVerificationMethod(id+fragment, id, pubKey, crypto.Ed25519)
+		require.NoError(tt, err)
+		return *method
+	}
+	document := func(methods []did.VerificationMethod, capabilityInvocation ...did.VerificationMethodSet) did.Document {
+		doc := did.Document{ID: id, VerificationMethod: methods, CapabilityInvocation: capabilityInvocation}
+		for _, method := range methods {
+			doc.Authentication = append(doc.Authentication, method.ID)
+		}
+		return doc
+	}
+	// sign returns a JWS of the document with the key, naming the verification method kid in its header if any
+	sign := func(tt *testing.T, privKey ed25519.PrivateKey, kid string, doc did.Document) []byte {
+		payload, err := json.Marshal(doc)
+		require.NoError(tt, err)
+		headers := jws.NewHeaders()
+		if kid != "" {
+			require.NoError(tt, headers.Set(jws.KeyIDKey, kid))
+		}
+		proof, err := jws.Sign(payload, jws.WithKey(jwa.EdDSA, privKey, jws.WithProtectedHeaders(headers)))
+		require.NoError(tt, err)
+		return proof
+	}
+	updateKey, updatePrivKey := newKey(t)
+	assertionKey, assertionPrivKey := newKey(t)
+	old := document([]did.VerificationMethod{jwkMethod(t, "#update", updateKey), jwkMethod(t, "#assertion", assertionKey)},
+		id+"#update")
+
+	t.Run("signed with an update key", func(tt *testing.T) {
+		newAssertionKey, _ := newKey(tt)
+		rotated := document([]did.VerificationMethod{jwkMethod(tt, "#update", updateKey), jwkMethod(tt, "#assertion-2", newAssertionKey)},
+			id+"#update")
+		for _, kid := range []string{"", "#update", id + "#update"} {
+			continuous, err := VerifyDIDDocumentUpdate(old, rotated, sign(tt, updatePrivKey, kid, rotated))
+			assert.NoError(tt, err, kid)
+			assert.True(tt, continuous, kid)
+		}
+
+		// the update key may itself be rotated away
+		newUpdateKey, _ := newKey(tt)
+		replaced := document([]did.VerificationMethod{jwkMethod(tt, "#update-2", newUpdateKey)}, id+"#update-2")
+		continuous, err := VerifyDIDDocumentUpdate(old, replaced, sign(tt, updatePrivKey, "#update", replaced))
+		assert.NoError(tt, err)
+		assert.True(tt, continuous)
+	})
+
+	t.Run("not signed with an update key", func(tt *testing.T) {
+		// retaining the old update key in the new document proves nothing, as public keys are public
+		attackerKey, attackerPrivKey := newKey(tt)
+		forged := document([]did.VerificationMethod{jwkMethod(tt, "#update", updateKey), jwkMethod(tt, "#attacker", attackerKey)},
+			id+"#update", id+"#attacker")
+		for name, proof := range map[string][]byte{
+			"attacker key":            sign(tt, attackerPrivKey, "", forged),
+			"attacker key as update":  sign(tt, attackerPrivKey, "#update", forged),
+			"assertion key":           sign(tt, assertionPrivKey, "#assertion", forged),
+			"update key of other kid": sign(tt, updatePrivKey, "#assertion", forged),
+		} {
+			continuous, err := VerifyDIDDocumentUpdate(old, forged, proof)
+			assert.NoError(tt, err, name)
+			assert.False(tt, continuous, name)
+		}
+	})
+
+	t.Run("authentication keys without capabilityInvocation", func(tt *testing.T) {
+		authOld := document([]did.VerificationMethod{jwkMethod(tt, "#key-1", assertionKey)})
+		newAuthKey, _ := newKey(tt)
+		updated := document([]did.VerificationMethod{jwkMethod(tt, "#key-2", newAuthKey)})
+		continuous, err := VerifyDIDDocumentUpdate(authOld, updated, sign(tt, assertionPrivKey, "", updated))
+		assert.NoError(tt, err)
+		assert.True(tt, continuous)
+	})
+
+	t.Run("proofs of other documents", func(tt *testing.T) {
+		rotated := document([]did.VerificationMethod{jwkMethod(tt, "#update", updateKey)}, id+"#update")
+		other := document([]did.VerificationMethod{jwkMethod(tt, "#update", updateKey), jwkMethod(tt, "#assertion", assertionKey)})
+		_, err := VerifyDIDDocumentUpdate(old, rotated, sign(tt, updatePrivKey, "", other))
+		assert.ErrorContains(tt, err, "update proof is not of the new document of did<did:web:issuer.example.com>")
+
+		proof, err := jws.Sign([]byte("not a document"), jws.WithKey(jwa.EdDSA, updatePrivKey))
+		require.NoError(tt, err)
+		_, err = VerifyDIDDocumentUpdate(old, rotated, proof)
+		assert.ErrorContains(tt, err, "update proof payload is not a DID document")
+
+		_, err = VerifyDIDDocumentUpdate(old, rotated, nil)
+		assert.ErrorContains(tt, err, "update of did<did:web:issuer.example.com> has no proof")
+		_, err = VerifyDIDDocumentUpdate(old, rotated, []byte("not a proof"))
+		assert.ErrorContains(tt, err, "parsing update proof")
+	})
+
+	t.Run("methods without update proofs", func(tt *testing.T) {
+		for _, method := range []string{"did:key:z6Mk", "did:jwk:eyJ", "did:ion:EiA"} {
+			doc := did.Document{ID: method}
+			_, err := VerifyDIDDocumentUpdate(doc, doc, []byte("proof"))
+			assert.ErrorIs(tt, err, ErrUpdateProofUnsupported, method)
+		}
+	})
+
+	t.Run("invalid documents", func(tt *testing.T) {
+		other := old
+		other.ID = "did:web:other.example.com"
+		_, err := VerifyDIDDocumentUpdate(old, other, sign(tt, updatePrivKey, "", other))
+		assert.ErrorContains(tt, err, "new document<did:web:other.example.com> is not an update of did<did:web:issuer.example.com>")
+
+		_, err = VerifyDIDDocumentUpdate(did.Document{ID: id}, old, sign(tt, updatePrivKey, "", old))
+		assert.ErrorContains(tt, err, "has no keys authorized to update it")
+
+		_, err = VerifyDIDDocumentUpdate(did.Document{}, old, sign(tt, updatePrivKey, "", old))
+		assert.ErrorContains(tt, err, "old document must have an id")
+	})
+}
@@ -0,0 +1,174 @@
+package resolution
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+// DocumentsEqual reports whether two DID documents are semantically equal, as a caching resolver checks to tell
+// whether re-resolving a document changed anything. Unlike comparing their JSON, the order of verification methods,
+// verification relationships, services, controllers, and alsoKnownAs entries is ignored, as are duplicate entries and
+// the difference between a single value and a set of one. Relative ids such as #key-1 are taken relative to the
+// document's id, and verification methods are compared by their id, controller, and public key material, so that a
+// key re-encoded from publicKeyBase58 to publicKeyJwk, for example, is still the same key. The order of @context
+// entries is kept, as it is significant in JSON-LD.
+func DocumentsEqual(a, b did.Document) bool {
+	normalizedA, errA := normalizeDocument(a)
+	normalizedB, errB := normalizeDocument(b)
+	if errA != nil || errB != nil {
+		// documents that cannot be normalized are only equal if they are identical
+		return reflect.DeepEqual(a, b)
+	}
+	return reflect.DeepEqual(normalizedA, normalizedB)
+}
+
+// normalizedDocument is a representation of a DID document where semantically equal documents are deeply equal
+type normalizedDocument struct {
+	id                  string
+	context             any
+	controller          map[string]bool
+	alsoKnownAs         map[string]bool
+	verificationMethods map[string]normalizedMethod
+	// relationships holds the methods listed under each verification relationship by id, with embedded methods
+	// normalized and referenced ones nil
+	relationships map[did.PublicKeyPurpose]map[string]*normalizedMethod
+	services      map[string]any
+}
+
+type normalizedMethod struct {
+	controller string
+	// key is the thumbprint of the method's public key, or its raw key properties if the key cannot be read, as for
+	// blockchain accounts
+	key string
+}
+
+func normalizeDocument(doc did.Document) (*normalizedDocument, error) {
+	context, err := genericJSON(doc.Context)
+	if err != nil {
+		return nil, errors.Wrap(err, "normalizing @context")
+	}
+	if single, ok := context.(string); ok {
+		context = []any{single}
+	}
+	normalized := normalizedDocument{
+		id:                  doc.ID,
+		context:             context,
+		controller:          stringSet(doc.Controller),
+		alsoKnownAs:         stringSet(doc.AlsoKnownAs),
+		verificationMethods: make(map[string]normalizedMethod, len(doc.VerificationMethod)),
+		relationships:       make(map[did.PublicKeyPurpose]map[string]*normalizedMethod),
+		services:            make(map[string]any, len(doc.Services)),
+	}
+	for _, method := range doc.VerificationMethod {
+		normalized.verificationMethods[absoluteID(doc.ID, method.ID)] = normalizeMethod(doc.ID, method)
+	}
+
+	relationships := map[did.PublicKeyPurpose][]did.VerificationMethodSet{
+		did.Authentication:       doc.Authentication,
+		did.AssertionMethod:      doc.AssertionMethod,
+		did.KeyAgreement:         doc.KeyAgreement,
+		did.CapabilityInvocation: doc.CapabilityInvocation,
+		did.CapabilityDelegation: doc.CapabilityDelegation,
+	}
+	for purpose, set := range relationships {
+		if len(set) == 0 {
+			continue
+		}
+		methods := make(map[string]*normalizedMethod, len(set))
+		for _, entry := range set {
+			switch typedEntry := entry.(type) {
+			case string:
+				methods[absoluteID(doc.ID, typedEntry)] = nil
+			case []string:
+				for _, ref := range typedEntry {
+					methods[absoluteID(doc.ID, ref)] = nil
+				}
+			default:
+				var method did.VerificationMethod
+				methodBytes, err := json.Marshal(typedEntry)
+				if err != nil {
+					return nil, errors.Wrapf(err, "marshalling embedded %s verification method", purpose)
+				}
+				if err = json.Unmarshal(methodBytes, &method); err != nil {
+					return nil, errors.Wrapf(err, "reading embedded %s verification method", purpose)
+				}
+				embedded := normalizeMethod(doc.ID, method)
+				methods[absoluteID(doc.ID, method.ID)] = &embedded
+			}
+		}
+		normalized.relationships[purpose] = methods
+	}
+
+	for _, service := range doc.Services {
+		id := absoluteID(doc.ID, service.ID)
+		service.ID = ""
+		genericService, err := genericJSON(service)
+		if err != nil {
+			return nil, errors.Wrapf(err, "normalizing service<%s>", id)
+		}
+		normalized.services[id] = genericService
+	}
+	return &normalized, nil
+}
+
+func normalizeMethod(docID string, method did.VerificationMethod) normalizedMethod {
+	normalized := normalizedMethod{controller: method.Controller}
+	if thumbprint, err := verificationMethodThumbprint(method); err == nil {
+		normalized.key = thumbprint
+		return normalized
+	}
+	method.ID, method.Controller = absoluteID(docID, method.ID), ""
+	if methodBytes, err := json.Marshal(method); err == nil {
+		normalized.key = string(methodBytes)
+	}
+	return normalized
+}
+
+// absoluteID resolves an id relative to the document, such as #key-1, to an absolute one
+func absoluteID(docID, id string) string {
+	if strings.HasPrefix(id, "#") {
+		return docID + id
+	}
+	return id
+}
+
+// stringSet returns the strings of a property that is either a string or a set of strings
+func stringSet(value any) map[string]bool {
+	set := make(map[string]bool)
+	switch typedValue := value.(type) {
+	case string:
+		set[typedValue] = true
+	case []string:
+		for _, v := range typedValue {
+			set[v] = true
+		}
+	case []any:
+		for _, v := range typedValue {
+			if s, ok := v.(string); ok {
+				set[s] = true
+			}
+		}
+	}
+	return set
+}
+
+// genericJSON returns the value as it would be decoded from its JSON representation
+func genericJSON(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err = json.Unmarshal(valueBytes, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
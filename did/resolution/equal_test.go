@@ -0,0 +1,110 @@
+package resolution
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+func TestDocumentsEqual(t *testing.T) {
+	const id = "did:web:issuer.example.com"
+	newKey := func(tt *testing.T) ed25519.PublicKey {
+		pubKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		return pubKey
+	}
+	jwkMethod := func(tt *testing.T, fragment string, pubKey ed25519.PublicKey) did.VerificationMethod {
+		method, err := did.ConstructJWKVerificationMethod(id+fragment, id, pubKey, crypto.Ed25519)
+		require.NoError(tt, err)
+		return *method
+	}
+	firstKey, secondKey := newKey(t), newKey(t)
+	newDocument := func(tt *testing.T) did.Document {
+		return did.Document{
+			Context:            "https://www.w3.org/ns/did/v1",
+			ID:                 id,
+			Controller:         id,
+			VerificationMethod: []did.VerificationMethod{jwkMethod(tt, "#key-1", firstKey), jwkMethod(tt, "#key-2", secondKey)},
+			Authentication:     []did.VerificationMethodSet{id + "#key-1", id + "#key-2"},
+			AssertionMethod:    []did.VerificationMethodSet{id + "#key-1"},
+			Services: []did.Service{
+				{ID: id + "#hub", Type: "LinkedDomains", ServiceEndpoint: "https://issuer.example.com"},
+				{ID: id + "#dwn", Type: "DecentralizedWebNode", ServiceEndpoint: []string{"https://dwn.example.com"}},
+			},
+		}
+	}
+
+	t.Run("identical documents", func(tt *testing.T) {
+		assert.True(tt, DocumentsEqual(newDocument(tt), newDocument(tt)))
+		assert.True(tt, DocumentsEqual(did.Document{}, did.Document{}))
+	})
+
+	t.Run("documents differing in order and representation", func(tt *testing.T) {
+		doc := newDocument(tt)
+		reordered := newDocument(tt)
+		reordered.Context = []string{"https://www.w3.org/ns/did/v1"}
+		reordered.Controller = []string{id, id}
+		reordered.VerificationMethod = []did.VerificationMethod{reordered.VerificationMethod[1], reordered.VerificationMethod[0]}
+		reordered.Authentication = []did.VerificationMethodSet{"#key-2", id + "#key-1"}
+		reordered.Services = []did.Service{reordered.Services[1], reordered.Services[0]}
+		assert.True(tt, DocumentsEqual(doc, reordered))
+
+		// the same key encoded as multibase
+		multibase, err := did.ConstructMultibaseVerificationMethod(id+"#key-2", id, secondKey, cryptosuite.Ed25519VerificationKey2020)
+		require.NoError(tt, err)
+		reordered.VerificationMethod[0] = *multibase
+		assert.True(tt, DocumentsEqual(doc, reordered))
+
+		// and as decoded from JSON
+		docBytes, err := json.Marshal(doc)
+		require.NoError(tt, err)
+		var decoded did.Document
+		require.NoError(tt, json.Unmarshal(docBytes, &decoded))
+		assert.True(tt, DocumentsEqual(decoded, reordered))
+	})
+
+	t.Run("rotated key", func(tt *testing.T) {
+		rotated := newDocument(tt)
+		rotated.VerificationMethod[1] = jwkMethod(tt, "#key-2", newKey(tt))
+		assert.False(tt, DocumentsEqual(newDocument(tt), rotated))
+	})
+
+	t.Run("changed relationships", func(tt *testing.T) {
+		changed := newDocument(tt)
+		changed.AssertionMethod = append(changed.AssertionMethod, id+"#key-2")
+		assert.False(tt, DocumentsEqual(newDocument(tt), changed))
+
+		// an embedded method is not the same as a reference to it
+		changed = newDocument(tt)
+		changed.AssertionMethod = []did.VerificationMethodSet{changed.VerificationMethod[0]}
+		assert.False(tt, DocumentsEqual(newDocument(tt), changed))
+		embedded := newDocument(tt)
+		embedded.AssertionMethod = []did.VerificationMethodSet{embedded.VerificationMethod[0]}
+		assert.True(tt, DocumentsEqual(embedded, changed))
+	})
+
+	t.Run("changed services and properties", func(tt *testing.T) {
+		changed := newDocument(tt)
+		changed.Services[0].ServiceEndpoint = "https://other.example.com"
+		assert.False(tt, DocumentsEqual(newDocument(tt), changed))
+
+		changed = newDocument(tt)
+		changed.Services = changed.Services[:1]
+		assert.False(tt, DocumentsEqual(newDocument(tt), changed))
+
+		changed = newDocument(tt)
+		changed.Controller = "did:example:other"
+		assert.False(tt, DocumentsEqual(newDocument(tt), changed))
+
+		changed = newDocument(tt)
+		changed.Context = []string{"https://www.w3.org/ns/did/v1", "https://w3id.org/security/suites/jws-2020/v1"}
+		assert.False(tt, DocumentsEqual(newDocument(tt), changed))
+	})
+}
@@ -0,0 +1,93 @@
+package resolution
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/metrics"
+)
+
+// FallbackResolver resolves a DID with each of several candidate resolvers in turn until one succeeds, such as a
+// local did:key resolver, then a did:web resolver, then a universal resolver. See MultiResolver.
+type FallbackResolver struct {
+	resolvers []Resolver
+	methods   []did.Method
+}
+
+var _ Resolver = (*FallbackResolver)(nil)
+
+// MultiResolver returns a resolver that routes each DID by its method to the given resolvers that list the method in
+// their Methods, trying them in the order given until one succeeds. Resolvers listing no methods are catch-alls,
+// tried in order only for the methods none of the other resolvers list, so that a DID whose method has a local
+// resolver, such as did:key, is never resolved over the network by a universal resolver. The error returned when no
+// resolver succeeds aggregates the errors of all the resolvers tried.
+func MultiResolver(resolvers ...Resolver) *FallbackResolver {
+	var methods []did.Method
+	for _, resolver := range resolvers {
+		for _, method := range resolver.Methods() {
+			if !slices.Contains(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+	}
+	return &FallbackResolver{resolvers: resolvers, methods: methods}
+}
+
+// Resolve attempts to resolve a DID with each of the candidate resolvers for its method. The time taken by the
+// resolution is reported to the metrics of the context, see metrics.NewContext.
+func (fr FallbackResolver) Resolve(ctx context.Context, id string, opts ...Option) (*Result, error) {
+	method, err := GetMethodForDID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting method for DID before resolving")
+	}
+	candidates := fr.candidates(method)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("unsupported method: %s", method)
+	}
+
+	start := time.Now()
+	defer func() { metrics.FromContext(ctx).ObserveResolution(method, time.Since(start)) }()
+	var errs []error
+	for i, resolver := range candidates {
+		if err = ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		result, err := resolver.Resolve(ctx, id, opts...)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, fmt.Errorf("resolver %d of %d: %w", i+1, len(candidates), err))
+	}
+	return nil, errors.Wrapf(goerrors.Join(errs...), "resolving did<%s>", id)
+}
+
+// candidates returns the resolvers listing the method, or the catch-all resolvers if none does
+func (fr FallbackResolver) candidates(method did.Method) []Resolver {
+	var routed, catchAll []Resolver
+	for _, resolver := range fr.resolvers {
+		methods := resolver.Methods()
+		switch {
+		case len(methods) == 0:
+			catchAll = append(catchAll, resolver)
+		case slices.Contains(methods, method):
+			routed = append(routed, resolver)
+		}
+	}
+	if len(routed) > 0 {
+		return routed
+	}
+	return catchAll
+}
+
+// Methods returns the methods listed by the resolvers. The methods the catch-all resolvers may resolve are unknown,
+// so none is listed for them.
+func (fr FallbackResolver) Methods() []did.Method {
+	return fr.methods
+}
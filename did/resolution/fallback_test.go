@@ -0,0 +1,116 @@
+package resolution
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/metrics"
+)
+
+// recordingResolver resolves any DID, or fails with err, recording the DIDs it is asked to resolve
+type recordingResolver struct {
+	methods  []did.Method
+	err      error
+	resolved *[]string
+}
+
+func (r recordingResolver) Resolve(_ context.Context, id string, _ ...Option) (*Result, error) {
+	*r.resolved = append(*r.resolved, id)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &Result{Document: did.Document{ID: id}}, nil
+}
+
+func (r recordingResolver) Methods() []did.Method {
+	return r.methods
+}
+
+func TestMultiResolver(t *testing.T) {
+	t.Run("routes DIDs by method", func(tt *testing.T) {
+		var universal []string
+		r := MultiResolver(stubResolver{method: did.KeyMethod}, stubResolver{method: did.WebMethod}, recordingResolver{resolved: &universal})
+		assert.Equal(tt, []did.Method{did.KeyMethod, did.WebMethod}, r.Methods())
+
+		resolved, err := r.Resolve(context.Background(), "did:key:z6Mk")
+		require.NoError(tt, err)
+		assert.Equal(tt, "did:key:z6Mk", resolved.Document.ID)
+
+		// a DID that fails to resolve locally is not passed to the catch-all resolver
+		_, err = r.Resolve(context.Background(), "did:key:missing")
+		assert.ErrorContains(tt, err, "not found")
+		assert.Empty(tt, universal)
+
+		// methods without a resolver of their own go to the catch-all resolver
+		resolved, err = r.Resolve(context.Background(), "did:ion:123")
+		require.NoError(tt, err)
+		assert.Equal(tt, "did:ion:123", resolved.Document.ID)
+		assert.Equal(tt, []string{"did:ion:123"}, universal)
+	})
+
+	t.Run("falls back to the next resolver of the method", func(tt *testing.T) {
+		var first, second []string
+		r := MultiResolver(
+			recordingResolver{methods: []did.Method{did.WebMethod}, err: errors.New("connection refused"), resolved: &first},
+			recordingResolver{methods: []did.Method{did.WebMethod, did.IONMethod}, resolved: &second},
+		)
+		assert.Equal(tt, []did.Method{did.WebMethod, did.IONMethod}, r.Methods())
+
+		resolved, err := r.Resolve(context.Background(), "did:web:example.com")
+		require.NoError(tt, err)
+		assert.Equal(tt, "did:web:example.com", resolved.Document.ID)
+		assert.Equal(tt, []string{"did:web:example.com"}, first)
+		assert.Equal(tt, []string{"did:web:example.com"}, second)
+	})
+
+	t.Run("aggregates the errors of the resolvers tried", func(tt *testing.T) {
+		var first, second []string
+		notFound := errors.New("not found")
+		r := MultiResolver(
+			recordingResolver{err: errors.New("connection refused"), resolved: &first},
+			recordingResolver{err: notFound, resolved: &second},
+		)
+		_, err := r.Resolve(context.Background(), "did:web:example.com")
+		assert.ErrorContains(tt, err, "resolver 1 of 2: connection refused")
+		assert.ErrorContains(tt, err, "resolver 2 of 2: not found")
+		assert.ErrorIs(tt, err, notFound)
+	})
+
+	t.Run("stops when the context is done", func(tt *testing.T) {
+		var resolved []string
+		r := MultiResolver(recordingResolver{resolved: &resolved})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := r.Resolve(ctx, "did:web:example.com")
+		assert.ErrorIs(tt, err, context.Canceled)
+		assert.Empty(tt, resolved)
+	})
+
+	t.Run("unsupported methods and invalid DIDs", func(tt *testing.T) {
+		r := MultiResolver(stubResolver{method: did.KeyMethod})
+		_, err := r.Resolve(context.Background(), "did:web:example.com")
+		assert.ErrorContains(tt, err, "unsupported method: web")
+		_, err = r.Resolve(context.Background(), "not a did")
+		assert.ErrorContains(tt, err, "getting method for DID")
+	})
+
+	t.Run("observes resolutions", func(tt *testing.T) {
+		m := &resolutionMetrics{}
+		r := MultiResolver(stubResolver{method: did.KeyMethod})
+		_, err := r.Resolve(metrics.NewContext(context.Background(), m), "did:key:z6Mk")
+		require.NoError(tt, err)
+		assert.Equal(tt, []did.Method{did.KeyMethod}, m.methods)
+	})
+
+	t.Run("can be nested in a multi method resolver", func(tt *testing.T) {
+		r, err := NewResolver(MultiResolver(stubResolver{method: did.KeyMethod}, stubResolver{method: did.WebMethod}))
+		require.NoError(tt, err)
+		_, err = r.Resolve(context.Background(), "did:web:example.com")
+		assert.NoError(tt, err)
+	})
+}
@@ -22,6 +22,11 @@ func (r *Result) IsEmpty() bool {
 	return reflect.DeepEqual(r, Result{})
 }
 
+// IsDeactivated reports whether the document metadata of the result marks the DID as deactivated
+func (r *Result) IsDeactivated() bool {
+	return r != nil && r.DocumentMetadata != nil && r.DocumentMetadata.Deactivated
+}
+
 type Method struct {
 	// The `method` property in https://identity.foundation/sidetree/spec/#did-resolver-output
 	Published bool `json:"published"`
@@ -5,11 +5,13 @@ import (
 	gocrypto "crypto"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
 
 	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/metrics"
 )
 
 // Option https://www.w3.org/TR/did-spec-registries/#did-resolution-options
@@ -49,13 +51,16 @@ func NewResolver(resolvers ...Resolver) (*MultiMethodResolver, error) {
 	return &MultiMethodResolver{resolvers: r, methods: methods}, nil
 }
 
-// Resolve attempts to resolve a DID for a given method
+// Resolve attempts to resolve a DID for a given method. The time taken by the resolution of the method is reported to
+// the metrics of the context, see metrics.NewContext.
 func (dr MultiMethodResolver) Resolve(ctx context.Context, id string, opts ...Option) (*Result, error) {
 	method, err := GetMethodForDID(id)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting method for DID before resolving")
 	}
 	if resolver, ok := dr.resolvers[method]; ok {
+		start := time.Now()
+		defer func() { metrics.FromContext(ctx).ObserveResolution(method, time.Since(start)) }()
 		return resolver.Resolve(ctx, id, opts)
 	}
 	return nil, fmt.Errorf("unsupported method: %s", method)
@@ -1,12 +1,27 @@
 package resolution
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/metrics"
 )
 
+func TestResult_IsDeactivated(t *testing.T) {
+	var nilResult *Result
+	assert.False(t, nilResult.IsDeactivated())
+	assert.False(t, (&Result{}).IsDeactivated())
+	assert.False(t, (&Result{DocumentMetadata: &DocumentMetadata{}}).IsDeactivated())
+	assert.True(t, (&Result{DocumentMetadata: &DocumentMetadata{Deactivated: true}}).IsDeactivated())
+}
+
 func TestDIDDocumentMetadata_IsValid(t *testing.T) {
 	t.Run("returns true with empty", func(t *testing.T) {
 		var metadata DocumentMetadata
@@ -52,3 +67,55 @@ func TestParseDIDResolution(t *testing.T) {
 		assert.Equal(tt, "did:ion:test", resolutionResult.Document.ID)
 	})
 }
+
+type stubResolver struct {
+	method did.Method
+}
+
+func (r stubResolver) Resolve(_ context.Context, id string, _ ...Option) (*Result, error) {
+	if id == "did:"+string(r.method)+":missing" {
+		return nil, errors.New("not found")
+	}
+	return &Result{Document: did.Document{ID: id}}, nil
+}
+
+func (r stubResolver) Methods() []did.Method {
+	return []did.Method{r.method}
+}
+
+type resolutionMetrics struct {
+	metrics.NoOp
+	mu      sync.Mutex
+	methods []did.Method
+}
+
+func (m *resolutionMetrics) ObserveResolution(method did.Method, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.methods = append(m.methods, method)
+}
+
+func TestMultiMethodResolverMetrics(t *testing.T) {
+	r, err := NewResolver(stubResolver{method: did.KeyMethod}, stubResolver{method: did.WebMethod})
+	require.NoError(t, err)
+
+	t.Run("observes resolutions of each method", func(tt *testing.T) {
+		m := &resolutionMetrics{}
+		ctx := metrics.NewContext(context.Background(), m)
+		_, err := r.Resolve(ctx, "did:key:z6Mk")
+		require.NoError(tt, err)
+		_, err = r.Resolve(ctx, "did:web:missing")
+		assert.Error(tt, err)
+		assert.Equal(tt, []did.Method{did.KeyMethod, did.WebMethod}, m.methods)
+
+		// nothing is resolved for unsupported methods
+		_, err = r.Resolve(ctx, "did:example:123")
+		assert.Error(tt, err)
+		assert.Len(tt, m.methods, 2)
+	})
+
+	t.Run("resolves without metrics", func(tt *testing.T) {
+		_, err := r.Resolve(context.Background(), "did:key:z6Mk")
+		assert.NoError(tt, err)
+	})
+}
@@ -0,0 +1,146 @@
+package resolution
+
+import (
+	"context"
+	gocrypto "crypto"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// ErrNotInTrustList is returned when resolving a DID that is not listed in an offline trust list
+var ErrNotInTrustList = errors.New("DID is not in the offline trust list")
+
+// TrustList is a list of trusted issuer DIDs along with their public keys, which is signed by a trust anchor and
+// bundled with a wallet to verify credentials without connectivity
+type TrustList struct {
+	Issuers []TrustedIssuer `json:"issuers" validate:"required,dive"`
+}
+
+// TrustedIssuer is an issuer DID in a TrustList. The kid of each public key is the ID of its verification method,
+// either fully qualified or relative to the DID.
+type TrustedIssuer struct {
+	ID         string             `json:"id" validate:"required"`
+	PublicKeys []jwx.PublicKeyJWK `json:"publicKeys" validate:"required,min=1"`
+}
+
+// SignTrustList signs a trust list as a JWS with the key of the trust anchor, to be loaded with LoadTrustList
+func SignTrustList(signer jwx.Signer, list TrustList) ([]byte, error) {
+	if err := util.IsValidStruct(list); err != nil {
+		return nil, errors.Wrap(err, "invalid trust list")
+	}
+	listBytes, err := json.Marshal(list)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling trust list")
+	}
+	return signer.SignJWS(listBytes)
+}
+
+// TrustListResolver resolves the DIDs of a TrustList to documents holding only the keys listed for them, without
+// any network access. DIDs that are not in the list fail to resolve with ErrNotInTrustList.
+type TrustListResolver struct {
+	documents map[string]did.Document
+	methods   []did.Method
+}
+
+var _ Resolver = (*TrustListResolver)(nil)
+
+// LoadTrustList verifies the signature of a trust list signed by SignTrustList with the key of the trust anchor, and
+// returns a resolver backed entirely by the keys the list embeds. Each listed key is a verification method of its
+// issuer's document, usable both for assertion and authentication.
+func LoadTrustList(signedList []byte, trustAnchorKey gocrypto.PublicKey) (*TrustListResolver, error) {
+	verifier, err := jwx.NewJWXVerifier("trust-anchor", nil, trustAnchorKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating trust anchor verifier")
+	}
+	if err = verifier.VerifyJWS(string(signedList)); err != nil {
+		return nil, errors.Wrap(err, "verifying trust list signature")
+	}
+	msg, err := jws.Parse(signedList)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing trust list")
+	}
+	var list TrustList
+	if err = json.Unmarshal(msg.Payload(), &list); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling trust list")
+	}
+	if err = util.IsValidStruct(list); err != nil {
+		return nil, errors.Wrap(err, "invalid trust list")
+	}
+
+	documents := make(map[string]did.Document, len(list.Issuers))
+	var methods []did.Method
+	for _, issuer := range list.Issuers {
+		if _, ok := documents[issuer.ID]; ok {
+			return nil, errors.Errorf("issuer<%s> is listed more than once", issuer.ID)
+		}
+		method, err := GetMethodForDID(issuer.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting method of issuer<%s>", issuer.ID)
+		}
+		doc, err := trustedIssuerDocument(issuer)
+		if err != nil {
+			return nil, err
+		}
+		documents[issuer.ID] = *doc
+		if !containsMethod(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+	return &TrustListResolver{documents: documents, methods: methods}, nil
+}
+
+// trustedIssuerDocument builds the document of a trusted issuer from its listed keys
+func trustedIssuerDocument(issuer TrustedIssuer) (*did.Document, error) {
+	doc := did.Document{
+		Context: did.KnownDIDContext,
+		ID:      issuer.ID,
+	}
+	for i, key := range issuer.PublicKeys {
+		if key.KID == "" {
+			return nil, errors.Errorf("public key %d of issuer<%s> has no kid", i, issuer.ID)
+		}
+		if _, err := key.ToPublicKey(); err != nil {
+			return nil, errors.Wrapf(err, "converting public key<%s> of issuer<%s>", key.KID, issuer.ID)
+		}
+		id := did.FullyQualifiedVerificationMethodID(issuer.ID, key.KID)
+		doc.VerificationMethod = append(doc.VerificationMethod, did.VerificationMethod{
+			ID:           id,
+			Type:         cryptosuite.JSONWebKey2020Type,
+			Controller:   issuer.ID,
+			PublicKeyJWK: &key,
+		})
+		doc.AssertionMethod = append(doc.AssertionMethod, id)
+		doc.Authentication = append(doc.Authentication, id)
+	}
+	return &doc, nil
+}
+
+func containsMethod(methods []did.Method, method did.Method) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the document of a DID in the trust list, or ErrNotInTrustList if it is not listed
+func (r TrustListResolver) Resolve(_ context.Context, id string, _ ...Option) (*Result, error) {
+	doc, ok := r.documents[id]
+	if !ok {
+		return nil, errors.Wrapf(ErrNotInTrustList, "resolving did<%s>", id)
+	}
+	return &Result{Document: doc}, nil
+}
+
+// Methods returns the methods of the DIDs in the trust list
+func (r TrustListResolver) Methods() []did.Method {
+	return r.methods
+}
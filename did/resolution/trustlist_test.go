@@ -0,0 +1,92 @@
+package resolution
+
+import (
+	"context"
+	gocrypto "crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+func TestLoadTrustList(t *testing.T) {
+	newSigner := func(tt *testing.T, id, kid string) (*jwx.Signer, gocrypto.PublicKey) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		signer, err := jwx.NewJWXSigner(id, &kid, privKey)
+		require.NoError(tt, err)
+		return signer, pubKey
+	}
+	anchor, anchorKey := newSigner(t, "did:web:anchor.example.com", "did:web:anchor.example.com#key-1")
+
+	const issuerID = "did:web:issuer.example.com"
+	issuer, issuerKey := newSigner(t, issuerID, "key-1")
+	other, _ := newSigner(t, "did:example:other", "#key-2")
+	list := TrustList{Issuers: []TrustedIssuer{
+		{ID: issuerID, PublicKeys: []jwx.PublicKeyJWK{issuer.ToPublicKeyJWK()}},
+		{ID: "did:example:other", PublicKeys: []jwx.PublicKeyJWK{other.ToPublicKeyJWK()}},
+	}}
+	signedList, err := SignTrustList(*anchor, list)
+	require.NoError(t, err)
+
+	t.Run("resolves the listed keys", func(tt *testing.T) {
+		r, err := LoadTrustList(signedList, anchorKey)
+		require.NoError(tt, err)
+		assert.ElementsMatch(tt, []did.Method{did.WebMethod, "example"}, r.Methods())
+
+		result, err := r.Resolve(context.Background(), issuerID)
+		require.NoError(tt, err)
+		assert.Equal(tt, issuerID, result.Document.ID)
+		assert.Equal(tt, []did.VerificationMethodSet{issuerID + "#key-1"}, result.Document.AssertionMethod)
+
+		token, err := issuer.SignWithDefaults(map[string]any{"sub": "did:example:holder"})
+		require.NoError(tt, err)
+		verifier, err := did.VerifierFromDIDDocument(result.Document, "key-1")
+		require.NoError(tt, err)
+		assert.NoError(tt, verifier.Verify(string(token)))
+
+		pubKey, err := ResolveKeyForDID(context.Background(), r, "did:example:other", "did:example:other#key-2")
+		require.NoError(tt, err)
+		assert.NotNil(tt, pubKey)
+	})
+
+	t.Run("unlisted DIDs do not resolve", func(tt *testing.T) {
+		r, err := LoadTrustList(signedList, anchorKey)
+		require.NoError(tt, err)
+		_, err = r.Resolve(context.Background(), "did:web:unknown.example.com")
+		assert.ErrorIs(tt, err, ErrNotInTrustList)
+		_, err = ResolveKeyForDID(context.Background(), r, "did:web:unknown.example.com", "key-1")
+		assert.ErrorIs(tt, err, ErrNotInTrustList)
+	})
+
+	t.Run("list not signed by the trust anchor", func(tt *testing.T) {
+		_, err := LoadTrustList(signedList, issuerKey)
+		assert.ErrorContains(tt, err, "verifying trust list signature")
+
+		forged, err := SignTrustList(*issuer, list)
+		require.NoError(tt, err)
+		_, err = LoadTrustList(forged, anchorKey)
+		assert.ErrorContains(tt, err, "verifying trust list signature")
+	})
+
+	t.Run("invalid lists", func(tt *testing.T) {
+		_, err := SignTrustList(*anchor, TrustList{Issuers: []TrustedIssuer{{ID: issuerID}}})
+		assert.ErrorContains(tt, err, "invalid trust list")
+
+		duplicated, err := SignTrustList(*anchor, TrustList{Issuers: []TrustedIssuer{list.Issuers[0], list.Issuers[0]}})
+		require.NoError(tt, err)
+		_, err = LoadTrustList(duplicated, anchorKey)
+		assert.ErrorContains(tt, err, "listed more than once")
+
+		unnamedKey := issuer.ToPublicKeyJWK()
+		unnamedKey.KID = ""
+		unnamed, err := SignTrustList(*anchor, TrustList{Issuers: []TrustedIssuer{{ID: issuerID, PublicKeys: []jwx.PublicKeyJWK{unnamedKey}}}})
+		require.NoError(tt, err)
+		_, err = LoadTrustList(unnamed, anchorKey)
+		assert.ErrorContains(tt, err, "has no kid")
+	})
+}
@@ -38,6 +38,47 @@ const (
 // A KID can be fully qualified (e.g. did:example:123#key-1) or just the fragment (e.g. key-1, #key-1)
 // Some DIDs, like did:key, use the entire DID as the KID, so we need to handle all three cases.
 func GetKeyFromVerificationMethod(did Document, kid string) (gocrypto.PublicKey, error) {
+	method, err := findVerificationMethod(did, kid)
+	if err != nil {
+		return nil, err
+	}
+	return extractKeyFromVerificationMethod(*method)
+}
+
+// VerifierFromDIDDocument returns a verifier for JWTs signed with the key of the verification method with the given
+// kid, matched as GetKeyFromVerificationMethod does. The verifier's ID is the DID, and its KID is the fully qualified
+// ID of the verification method.
+func VerifierFromDIDDocument(did Document, kid string) (*jwx.Verifier, error) {
+	method, err := findVerificationMethod(did, kid)
+	if err != nil {
+		return nil, err
+	}
+	if method.Controller == "" {
+		method.Controller = did.ID
+	}
+	method.ID = FullyQualifiedVerificationMethodID(did.ID, method.ID)
+	return VerifierFromVerificationMethod(*method)
+}
+
+// VerifierFromVerificationMethod returns a verifier for JWTs signed with the key held in a verification method, with
+// the algorithm inferred from the key. The verifier's ID is the method's controller, and its KID is the method's ID.
+func VerifierFromVerificationMethod(method VerificationMethod) (*jwx.Verifier, error) {
+	if method.Controller == "" {
+		return nil, errors.Errorf("verification method<%s> has no controller", method.ID)
+	}
+	pubKey, err := extractKeyFromVerificationMethod(method)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting key from verification method<%s>", method.ID)
+	}
+	verifier, err := jwx.NewJWXVerifier(method.Controller, &method.ID, pubKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "constructing verifier for verification method<%s>", method.ID)
+	}
+	return verifier, nil
+}
+
+// findVerificationMethod returns the verification method of the DID Document matching the kid
+func findVerificationMethod(did Document, kid string) (*VerificationMethod, error) {
 	if did.IsEmpty() {
 		return nil, errors.New("did doc cannot be empty")
 	}
@@ -53,13 +94,96 @@ func GetKeyFromVerificationMethod(did Document, kid string) (gocrypto.PublicKey,
 	for _, method := range verificationMethods {
 		// make sure the kid matches the verification method
 		if matchesKIDConstruction(did.ID, kid, method.ID) {
-			return extractKeyFromVerificationMethod(method)
+			return &method, nil
+		}
+	}
+
+	return nil, errors.Errorf("did<%s> has no verification methods with kid: %s", did.ID, kid)
+}
+
+// GetVerificationMethodsForPurpose returns the verification methods of a DID Document that are listed under a given
+// verification relationship (e.g. keyAgreement). Both referenced and embedded verification methods are supported.
+func GetVerificationMethodsForPurpose(did Document, purpose PublicKeyPurpose) ([]VerificationMethod, error) {
+	var set []VerificationMethodSet
+	switch purpose {
+	case Authentication:
+		set = did.Authentication
+	case AssertionMethod:
+		set = did.AssertionMethod
+	case KeyAgreement:
+		set = did.KeyAgreement
+	case CapabilityInvocation:
+		set = did.CapabilityInvocation
+	case CapabilityDelegation:
+		set = did.CapabilityDelegation
+	default:
+		return nil, errors.Errorf("unsupported verification relationship: %s", purpose)
+	}
+
+	methods := make([]VerificationMethod, 0, len(set))
+	for _, entry := range set {
+		switch typedEntry := entry.(type) {
+		case string:
+			method, err := referencedVerificationMethod(did, typedEntry)
+			if err != nil {
+				return nil, err
+			}
+			methods = append(methods, *method)
+		case []string:
+			// a set of references, as in the documents of did:peer
+			for _, kid := range typedEntry {
+				method, err := referencedVerificationMethod(did, kid)
+				if err != nil {
+					return nil, err
+				}
+				methods = append(methods, *method)
+			}
+		default:
+			// an embedded verification method, either from our object model or a generic JSON representation
+			methodBytes, err := json.Marshal(typedEntry)
+			if err != nil {
+				return nil, errors.Wrapf(err, "marshalling embedded %s verification method", purpose)
+			}
+			var method VerificationMethod
+			if err = json.Unmarshal(methodBytes, &method); err != nil {
+				return nil, errors.Wrapf(err, "unmarshalling embedded %s verification method", purpose)
+			}
+			methods = append(methods, method)
 		}
 	}
+	return methods, nil
+}
 
+// referencedVerificationMethod returns the verification method of the DID Document a relationship references by kid
+func referencedVerificationMethod(did Document, kid string) (*VerificationMethod, error) {
+	for _, method := range did.VerificationMethod {
+		if matchesKIDConstruction(did.ID, kid, method.ID) {
+			return &method, nil
+		}
+	}
 	return nil, errors.Errorf("did<%s> has no verification methods with kid: %s", did.ID, kid)
 }
 
+// HasVerificationMethodForPurpose reports whether the verification method of a DID Document matching the kid is
+// listed under a given verification relationship (e.g. authentication)
+func HasVerificationMethodForPurpose(did Document, kid string, purpose PublicKeyPurpose) (bool, error) {
+	methods, err := GetVerificationMethodsForPurpose(did, purpose)
+	if err != nil {
+		return false, err
+	}
+	for _, method := range methods {
+		if matchesKIDConstruction(did.ID, kid, method.ID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetKeyFromEmbeddedVerificationMethod returns the public key held in a verification method
+func GetKeyFromEmbeddedVerificationMethod(method VerificationMethod) (gocrypto.PublicKey, error) {
+	return extractKeyFromVerificationMethod(method)
+}
+
 // matchesKIDConstruction checks if the targetID matches possible combinations of the did and kid
 func matchesKIDConstruction(did, kid, targetID string) bool {
 	maybeKID1 := kid                                // the kid == the kid
@@ -8,6 +8,7 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 )
 
 func TestGetKeyFromVerificationInformation(t *testing.T) {
@@ -327,3 +328,133 @@ func TestFullyQualifiedVerificationMethodID(t *testing.T) {
 		})
 	}
 }
+
+func TestGetVerificationMethodsForPurpose(t *testing.T) {
+	referenced := VerificationMethod{
+		ID:              "did:example:123#key-1",
+		Type:            cryptosuite.X25519KeyAgreementKey2020,
+		Controller:      "did:example:123",
+		PublicKeyBase58: "JhNWeSVLMYccCk7iopQW4guaSJTojqpMEELgSLhKwRr",
+	}
+	embedded := VerificationMethod{
+		ID:              "did:example:123#key-2",
+		Type:            cryptosuite.X25519KeyAgreementKey2020,
+		Controller:      "did:example:123",
+		PublicKeyBase58: "JhNWeSVLMYccCk7iopQW4guaSJTojqpMEELgSLhKwRr",
+	}
+	doc := Document{
+		ID:                 "did:example:123",
+		VerificationMethod: []VerificationMethod{referenced},
+		KeyAgreement: []VerificationMethodSet{
+			"#key-1",
+			map[string]any{
+				"id":              embedded.ID,
+				"type":            embedded.Type,
+				"controller":      embedded.Controller,
+				"publicKeyBase58": embedded.PublicKeyBase58,
+			},
+		},
+	}
+
+	t.Run("referenced and embedded", func(tt *testing.T) {
+		methods, err := GetVerificationMethodsForPurpose(doc, KeyAgreement)
+		assert.NoError(tt, err)
+		assert.Equal(tt, []VerificationMethod{referenced, embedded}, methods)
+
+		key, err := GetKeyFromEmbeddedVerificationMethod(methods[1])
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, key)
+	})
+
+	t.Run("set of references", func(tt *testing.T) {
+		setDoc := doc
+		setDoc.KeyAgreement = []VerificationMethodSet{[]string{"#key-1"}}
+		methods, err := GetVerificationMethodsForPurpose(setDoc, KeyAgreement)
+		assert.NoError(tt, err)
+		assert.Equal(tt, []VerificationMethod{referenced}, methods)
+	})
+
+	t.Run("empty relationship", func(tt *testing.T) {
+		methods, err := GetVerificationMethodsForPurpose(doc, Authentication)
+		assert.NoError(tt, err)
+		assert.Empty(tt, methods)
+	})
+
+	t.Run("dangling reference", func(tt *testing.T) {
+		badDoc := doc
+		badDoc.KeyAgreement = []VerificationMethodSet{"#key-3"}
+		_, err := GetVerificationMethodsForPurpose(badDoc, KeyAgreement)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has no verification methods with kid: #key-3")
+	})
+
+	t.Run("unknown relationship", func(tt *testing.T) {
+		_, err := GetVerificationMethodsForPurpose(doc, "unknown")
+		assert.Error(tt, err)
+	})
+
+	t.Run("has verification method for purpose", func(tt *testing.T) {
+		ok, err := HasVerificationMethodForPurpose(doc, "did:example:123#key-1", KeyAgreement)
+		assert.NoError(tt, err)
+		assert.True(tt, ok)
+
+		ok, err = HasVerificationMethodForPurpose(doc, "#key-2", KeyAgreement)
+		assert.NoError(tt, err)
+		assert.True(tt, ok)
+
+		ok, err = HasVerificationMethodForPurpose(doc, "did:example:123#key-1", Authentication)
+		assert.NoError(tt, err)
+		assert.False(tt, ok)
+	})
+}
+
+func TestVerifierFromDIDDocument(t *testing.T) {
+	pubKey, privKey, err := crypto.GenerateSECP256k1Key()
+	assert.NoError(t, err)
+	pubKeyBytes, err := crypto.PubKeyToBytes(pubKey)
+	assert.NoError(t, err)
+	jwkMethod, err := ConstructJWKVerificationMethod("did:example:123#key-1", "did:example:123", pubKeyBytes, crypto.SECP256k1)
+	assert.NoError(t, err)
+	multibaseMethod, err := ConstructMultibaseVerificationMethod("#key-2", "", pubKeyBytes, cryptosuite.ECDSASECP256k1VerificationKey2019)
+	assert.NoError(t, err)
+	doc := Document{
+		ID:                 "did:example:123",
+		VerificationMethod: []VerificationMethod{*jwkMethod, *multibaseMethod},
+	}
+
+	kid := "did:example:123#key-1"
+	signer, err := jwx.NewJWXSigner("did:example:123", &kid, privKey)
+	assert.NoError(t, err)
+	token, err := signer.SignWithDefaults(map[string]any{"iss": "did:example:123"})
+	assert.NoError(t, err)
+
+	t.Run("from a verification method", func(tt *testing.T) {
+		verifier, err := VerifierFromVerificationMethod(*jwkMethod)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:123", verifier.ID)
+		assert.Equal(tt, "did:example:123#key-1", verifier.KID)
+		assert.Equal(tt, "ES256K", verifier.ALG)
+		assert.NoError(tt, verifier.Verify(string(token)))
+
+		noController := *jwkMethod
+		noController.Controller = ""
+		_, err = VerifierFromVerificationMethod(noController)
+		assert.ErrorContains(tt, err, "has no controller")
+	})
+
+	t.Run("from a did document", func(tt *testing.T) {
+		verifier, err := VerifierFromDIDDocument(doc, "key-1")
+		assert.NoError(tt, err)
+		assert.NoError(tt, verifier.Verify(string(token)))
+
+		// relative method IDs are fully qualified, and the controller defaults to the DID
+		verifier, err = VerifierFromDIDDocument(doc, "did:example:123#key-2")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:123", verifier.ID)
+		assert.Equal(tt, "did:example:123#key-2", verifier.KID)
+		assert.NoError(tt, verifier.Verify(string(token)))
+
+		_, err = VerifierFromDIDDocument(doc, "key-3")
+		assert.ErrorContains(tt, err, "has no verification methods with kid: key-3")
+	})
+}
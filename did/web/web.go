@@ -184,6 +184,9 @@ func (d DIDWeb) resolveDocBytes(ctx context.Context) ([]byte, http.Header, error
 	defer func() {
 		_ = resp.Body.Close()
 	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("getting doc %s: unexpected status code %d", docURL, resp.StatusCode)
+	}
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, nil, errors.Wrapf(err, "reading response %+v", resp)
@@ -16,6 +16,7 @@ const (
 	didWebBasic            DIDWeb = "did:web:example.com"
 	didWebWithPort         DIDWeb = "did:web:localhost%3A8443"
 	didWebOptionalPath     DIDWeb = "did:web:example.com:user:alice"
+	didWebPortAndPath      DIDWeb = "did:web:example.com%3A3000:user:alice"
 	didWebToBeResolved     DIDWeb = "did:web:demo.ssi-sdk.com"
 	didWebCannotBeResolved DIDWeb = "did:web:doesnotexist.com"
 	didWebNotADomain       DIDWeb = "did:web:"
@@ -39,6 +40,10 @@ func TestDIDWebGetURL(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "https://example.com/user/alice/did.json", docURL)
 
+	docURL, err = didWebPortAndPath.GetDocURL()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com:3000/user/alice/did.json", docURL)
+
 	_, err = didWebNotADomain.GetDocURL()
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "missing the required domain")
@@ -93,6 +98,54 @@ func TestDIDWebResolve(t *testing.T) {
 		assert.Contains(tt, err.Error(), "doc.id<did:web:demo.ssi-sdk.com> does not match did:web value<did:web:doesnotexist.com>")
 	})
 
+	t.Run("Happy Path - Bare Domain", func(tt *testing.T) {
+		gock.New("https://example.com").
+			Get("/.well-known/did.json").
+			Reply(200).
+			BodyString(`{"didDocument": {"id": "did:web:example.com"}}`)
+		defer gock.Off()
+
+		doc, err := didWebBasic.Resolve(context.Background())
+		assert.NoError(tt, err)
+		assert.Equal(tt, string(didWebBasic), doc.ID)
+	})
+
+	t.Run("Happy Path - Domain With Port", func(tt *testing.T) {
+		gock.New("https://localhost:8443").
+			Get("/.well-known/did.json").
+			Reply(200).
+			BodyString(`{"didDocument": {"id": "did:web:localhost%3A8443"}}`)
+		defer gock.Off()
+
+		doc, err := didWebWithPort.Resolve(context.Background())
+		assert.NoError(tt, err)
+		assert.Equal(tt, string(didWebWithPort), doc.ID)
+	})
+
+	t.Run("Happy Path - Domain With Port And Path", func(tt *testing.T) {
+		gock.New("https://example.com:3000").
+			Get("/user/alice/did.json").
+			Reply(200).
+			BodyString(`{"didDocument": {"id": "did:web:example.com%3A3000:user:alice"}}`)
+		defer gock.Off()
+
+		doc, err := didWebPortAndPath.Resolve(context.Background())
+		assert.NoError(tt, err)
+		assert.Equal(tt, string(didWebPortAndPath), doc.ID)
+	})
+
+	t.Run("Unhappy Path - Not Found", func(tt *testing.T) {
+		gock.New("https://example.com").
+			Get("/user/alice/did.json").
+			Reply(404).
+			BodyString("not found")
+		defer gock.Off()
+
+		_, err := didWebOptionalPath.Resolve(context.Background())
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unexpected status code 404")
+	})
+
 	t.Run("Unhappy Path - Unknown DID", func(t *testing.T) {
 		_, err := didWebCannotBeResolved.Resolve(context.Background())
 		assert.Error(t, err)
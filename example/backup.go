@@ -0,0 +1,268 @@
+package example
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+const (
+	// BackupMessageType is the type of the messages produced by CreateBackupMessage
+	BackupMessageType = "ssi-sdk/wallet-backup"
+	// BackupVersion is the version of the wallet format written by CreateBackupMessage. Backups of a later version,
+	// written by a newer wallet, are rejected on restore.
+	BackupVersion = 1
+
+	scryptAlgorithm = "scrypt"
+	scryptN         = 1 << 15
+	scryptR         = 8
+	scryptP         = 1
+	backupKeySize   = 32
+	backupSaltSize  = 16
+
+	// scryptMaxMemory and scryptMaxP bound the cost of the key derivation of a backup being restored, whose parameters
+	// are read from the untrusted message, as scrypt uses 128*N*r bytes of memory and p times the work
+	scryptMaxMemory = 256 << 20
+	scryptMaxP      = 16
+)
+
+// BackupMessage is a versioned, passphrase-encrypted backup of a wallet in the style of a DIDComm message: the
+// envelope carries what is needed to migrate and decrypt the payload, and the payload holds the wallet's DIDs, keys,
+// and credentials, encrypted with AES-256-GCM under a key derived from the passphrase.
+type BackupMessage struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	// CreatedTime is when the backup was made, in seconds since the Unix epoch
+	CreatedTime int64     `json:"created_time"`
+	KDF         BackupKDF `json:"kdf"`
+	// Nonce and Payload are the base64url encoded nonce and ciphertext of the encrypted wallet
+	Nonce   string `json:"nonce"`
+	Payload string `json:"payload"`
+}
+
+// BackupKDF holds the parameters of the key derivation turning a passphrase into the key of a backup
+type BackupKDF struct {
+	Algorithm string `json:"alg"`
+	// Salt is base64url encoded
+	Salt string `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+// backupPayload is the wallet held in a backup, with keys as JWKs so that they are restored with their type
+type backupPayload struct {
+	VCs  map[string]string      `json:"vcs"`
+	DIDs map[string][]backupKey `json:"dids"`
+}
+
+type backupKey struct {
+	ID  string            `json:"id"`
+	Key jwx.PrivateKeyJWK `json:"key"`
+	// KeyRef is set in place of Key for keys held outside the wallet, which are backed up by reference only
+	KeyRef  string `json:"keyRef,omitempty"`
+	Retired bool   `json:"retired,omitempty"`
+}
+
+// CreateBackupMessage encrypts the wallet with the passphrase into a backup message, which RestoreFromBackupMessage
+// restores the wallet from
+func (s *SimpleWallet) CreateBackupMessage(passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	payload, err := s.backupPayload()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling wallet: %w", err)
+	}
+	return sealBackupMessage(plaintext, passphrase)
+}
+
+// sealBackupMessage encrypts the marshalled payload of a wallet with the passphrase into a backup message
+func sealBackupMessage(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	salt := make([]byte, backupSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+	msg := BackupMessage{
+		ID:          uuid.NewString(),
+		Type:        BackupMessageType,
+		Version:     BackupVersion,
+		CreatedTime: time.Now().Unix(),
+		KDF: BackupKDF{
+			Algorithm: scryptAlgorithm,
+			Salt:      base64.RawURLEncoding.EncodeToString(salt),
+			N:         scryptN,
+			R:         scryptR,
+			P:         scryptP,
+		},
+	}
+	aead, err := backupCipher(msg.KDF, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	additionalData, err := msg.additionalData()
+	if err != nil {
+		return nil, err
+	}
+	msg.Nonce = base64.RawURLEncoding.EncodeToString(nonce)
+	msg.Payload = base64.RawURLEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, additionalData))
+	return json.Marshal(msg)
+}
+
+// RestoreFromBackupMessage decrypts a backup message made by CreateBackupMessage with the passphrase, and returns the
+// wallet it holds. Messages of a version later than BackupVersion are rejected.
+func RestoreFromBackupMessage(message []byte, passphrase string) (*SimpleWallet, error) {
+	plaintext, err := openBackupMessage(message, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var payload backupPayload
+	if err = json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, fmt.Errorf("unmarshalling wallet: %w", err)
+	}
+	return restoreBackupPayload(payload)
+}
+
+// openBackupMessage decrypts a backup message with the passphrase, returning the marshalled payload of the wallet
+func openBackupMessage(message []byte, passphrase string) ([]byte, error) {
+	var msg BackupMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, fmt.Errorf("unmarshalling backup message: %w", err)
+	}
+	if msg.Type != BackupMessageType {
+		return nil, fmt.Errorf("unsupported message type<%s>", msg.Type)
+	}
+	if msg.Version < 1 || msg.Version > BackupVersion {
+		return nil, fmt.Errorf("unsupported backup version<%d>, expected at most %d", msg.Version, BackupVersion)
+	}
+
+	aead, err := backupCipher(msg.KDF, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := base64.RawURLEncoding.DecodeString(msg.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid backup nonce")
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(msg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decoding backup payload: %w", err)
+	}
+	additionalData, err := msg.additionalData()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.New("decrypting backup: wrong passphrase or corrupted message")
+	}
+	return plaintext, nil
+}
+
+// additionalData returns the envelope of the message, which is authenticated along with the payload so that none of
+// its metadata can be changed
+func (m BackupMessage) additionalData() ([]byte, error) {
+	envelope := m
+	envelope.Nonce, envelope.Payload = "", ""
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling backup envelope: %w", err)
+	}
+	return data, nil
+}
+
+// backupCipher derives the key of a backup from the passphrase
+func backupCipher(kdf BackupKDF, passphrase string) (cipher.AEAD, error) {
+	if kdf.Algorithm != scryptAlgorithm {
+		return nil, fmt.Errorf("unsupported key derivation algorithm<%s>", kdf.Algorithm)
+	}
+	if kdf.N <= 1 || kdf.R <= 0 || kdf.P <= 0 || kdf.P > scryptMaxP || kdf.N > scryptMaxMemory/128/kdf.R {
+		return nil, fmt.Errorf("unsupported scrypt parameters<n=%d, r=%d, p=%d>", kdf.N, kdf.R, kdf.P)
+	}
+	salt, err := base64.RawURLEncoding.DecodeString(kdf.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("decoding salt: %w", err)
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, kdf.N, kdf.R, kdf.P, backupKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("deriving backup key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("constructing cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *SimpleWallet) backupPayload() (*backupPayload, error) {
+	if s.mux == nil {
+		return nil, errors.New("no mux for wallet")
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	// the credentials are copied, as the payload is marshalled once the lock is released
+	payload := backupPayload{VCs: maps.Clone(s.vcs), DIDs: make(map[string][]backupKey, len(s.dids))}
+	for id, keys := range s.dids {
+		backupKeys := make([]backupKey, 0, len(keys))
+		for _, k := range keys {
+			if k.IsRemote() {
+				backupKeys = append(backupKeys, backupKey{ID: k.ID, KeyRef: k.KeyRef, Retired: k.Retired})
+				continue
+			}
+			kid := k.ID
+			_, privKeyJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, k.Key)
+			if err != nil {
+				return nil, fmt.Errorf("converting key<%s> to JWK: %w", k.ID, err)
+			}
+			backupKeys = append(backupKeys, backupKey{ID: k.ID, Key: *privKeyJWK, Retired: k.Retired})
+		}
+		payload.DIDs[id] = backupKeys
+	}
+	return &payload, nil
+}
+
+func restoreBackupPayload(payload backupPayload) (*SimpleWallet, error) {
+	w := NewSimpleWallet()
+	for credID, cred := range payload.VCs {
+		w.vcs[credID] = cred
+	}
+	for id, keys := range payload.DIDs {
+		walletKeys := make([]WalletKeys, 0, len(keys))
+		for _, k := range keys {
+			if k.KeyRef != "" {
+				walletKeys = append(walletKeys, WalletKeys{ID: k.ID, KeyRef: k.KeyRef, Retired: k.Retired})
+				continue
+			}
+			privKey, err := k.Key.ToPrivateKey()
+			if err != nil {
+				return nil, fmt.Errorf("restoring key<%s>: %w", k.ID, err)
+			}
+			walletKeys = append(walletKeys, WalletKeys{ID: k.ID, Key: privKey, Retired: k.Retired})
+		}
+		w.dids[id] = walletKeys
+	}
+	return w, nil
+}
@@ -0,0 +1,111 @@
+package example
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+func TestSimpleWalletBackupMessage(t *testing.T) {
+	w := NewSimpleWallet()
+	didStr, kid, keyAgreementKID, err := w.InitWithKeyAgreement(did.KeyMethod)
+	require.NoError(t, err)
+	require.NoError(t, w.AddCredentialJWT("cred-1", "header.payload.signature"))
+
+	message, err := w.CreateBackupMessage("correct horse battery staple")
+	require.NoError(t, err)
+
+	t.Run("restores the wallet", func(tt *testing.T) {
+		restored, err := RestoreFromBackupMessage(message, "correct horse battery staple")
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{didStr}, restored.GetDIDs())
+		assert.Equal(tt, 1, restored.Size())
+
+		_, err = restored.NewSigner(kid)
+		assert.NoError(tt, err)
+		_, original, err := w.GetKey(keyAgreementKID)
+		require.NoError(tt, err)
+		_, restoredKey, err := restored.GetKey(keyAgreementKID)
+		require.NoError(tt, err)
+		assert.Equal(tt, original, restoredKey)
+	})
+
+	t.Run("envelope carries migration metadata", func(tt *testing.T) {
+		var msg BackupMessage
+		require.NoError(tt, json.Unmarshal(message, &msg))
+		assert.Equal(tt, BackupMessageType, msg.Type)
+		assert.Equal(tt, BackupVersion, msg.Version)
+		assert.NotZero(tt, msg.CreatedTime)
+		assert.Equal(tt, "scrypt", msg.KDF.Algorithm)
+		assert.NotEmpty(tt, msg.KDF.Salt)
+		assert.NotContains(tt, string(message), didStr)
+	})
+
+	t.Run("wrong passphrase", func(tt *testing.T) {
+		_, err := RestoreFromBackupMessage(message, "wrong")
+		assert.ErrorContains(tt, err, "wrong passphrase or corrupted message")
+	})
+
+	t.Run("newer version", func(tt *testing.T) {
+		var msg BackupMessage
+		require.NoError(tt, json.Unmarshal(message, &msg))
+		msg.Version = BackupVersion + 1
+		newer, err := json.Marshal(msg)
+		require.NoError(tt, err)
+		_, err = RestoreFromBackupMessage(newer, "correct horse battery staple")
+		assert.ErrorContains(tt, err, "unsupported backup version<2>")
+	})
+
+	t.Run("tampered metadata", func(tt *testing.T) {
+		var msg BackupMessage
+		require.NoError(tt, json.Unmarshal(message, &msg))
+		msg.CreatedTime++
+		tampered, err := json.Marshal(msg)
+		require.NoError(tt, err)
+		_, err = RestoreFromBackupMessage(tampered, "correct horse battery staple")
+		assert.ErrorContains(tt, err, "wrong passphrase or corrupted message")
+	})
+
+	t.Run("costly key derivation", func(tt *testing.T) {
+		for _, kdf := range []BackupKDF{
+			{N: 1 << 40, R: 8, P: 1},
+			{N: 1 << 15, R: 1 << 20, P: 1},
+			{N: 1 << 15, R: 8, P: 1 << 20},
+			{N: 0, R: 8, P: 1},
+			{N: 1 << 15, R: -1, P: 1},
+		} {
+			var msg BackupMessage
+			require.NoError(tt, json.Unmarshal(message, &msg))
+			msg.KDF.N, msg.KDF.R, msg.KDF.P = kdf.N, kdf.R, kdf.P
+			costly, err := json.Marshal(msg)
+			require.NoError(tt, err)
+			_, err = RestoreFromBackupMessage(costly, "correct horse battery staple")
+			assert.ErrorContains(tt, err, "unsupported scrypt parameters", "%+v", kdf)
+		}
+	})
+
+	t.Run("concurrently with adding credentials", func(tt *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.NoError(tt, w.AddCredentialJWT(fmt.Sprintf("concurrent-%d", i), "header.payload.signature"))
+			}
+		}()
+		_, err := w.CreateBackupMessage("correct horse battery staple")
+		assert.NoError(tt, err)
+		wg.Wait()
+	})
+
+	t.Run("empty passphrase", func(tt *testing.T) {
+		_, err := w.CreateBackupMessage("")
+		assert.ErrorContains(tt, err, "passphrase cannot be empty")
+	})
+}
@@ -0,0 +1,143 @@
+package example
+
+import (
+	"context"
+	gocrypto "crypto"
+	"errors"
+	"fmt"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/x25519"
+)
+
+// EncryptToDID resolves the recipient DID and encrypts the plaintext as a JWE to each of its X25519 keyAgreement
+// keys using ECDH-ES+A256KW. When the recipient has more than one keyAgreement key the JWE uses the JSON
+// serialization with one recipient per key; otherwise the compact serialization is used.
+func EncryptToDID(ctx context.Context, r resolution.Resolver, recipientDID string, plaintext []byte) ([]byte, error) {
+	recipients, err := resolveKeyAgreementKeys(ctx, r, recipientDID)
+	if err != nil {
+		return nil, err
+	}
+	return encryptToRecipients(recipients, plaintext)
+}
+
+// EncryptToDIDKey resolves the recipient DID and encrypts the plaintext as a JWE to the keyAgreement key with the
+// given kid using ECDH-ES+A256KW.
+func EncryptToDIDKey(ctx context.Context, r resolution.Resolver, recipientDID, kid string, plaintext []byte) ([]byte, error) {
+	recipients, err := resolveKeyAgreementKeys(ctx, r, recipientDID)
+	if err != nil {
+		return nil, err
+	}
+	for _, recipient := range recipients {
+		if recipient.kid == kid || recipient.kid == did.FullyQualifiedVerificationMethodID(recipientDID, kid) {
+			return encryptToRecipients([]keyAgreementRecipient{recipient}, plaintext)
+		}
+	}
+	return nil, fmt.Errorf("did<%s> has no X25519 keyAgreement key<%s>", recipientDID, kid)
+}
+
+// DecryptWithWalletKey decrypts a JWE, in compact or JSON serialization, with the keys in the wallet whose ids match
+// the kid of one of the JWE's recipients, trying each in turn until one decrypts it. It fails with the errors of all
+// of the keys when none does.
+func DecryptWithWalletKey(w *SimpleWallet, message []byte) ([]byte, error) {
+	if w == nil {
+		return nil, errors.New("wallet cannot be empty")
+	}
+	msg, err := jwe.Parse(message)
+	if err != nil {
+		return nil, fmt.Errorf("parsing JWE: %w", err)
+	}
+
+	var kids []string
+	if kid := msg.ProtectedHeaders().KeyID(); kid != "" {
+		kids = append(kids, kid)
+	}
+	for _, recipient := range msg.Recipients() {
+		if kid := recipient.Headers().KeyID(); kid != "" {
+			kids = append(kids, kid)
+		}
+	}
+	if len(kids) == 0 {
+		return nil, errors.New("JWE has no recipient kid")
+	}
+
+	var errs []error
+	for _, kid := range kids {
+		_, privKey, err := w.GetKey(kid)
+		if err != nil {
+			continue
+		}
+		plaintext, err := jwe.Decrypt(message, jwe.WithKey(jwa.ECDH_ES_A256KW, privKey))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("decrypting JWE with key<%s>: %w", kid, err))
+			continue
+		}
+		return plaintext, nil
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return nil, fmt.Errorf("no wallet key found for any JWE recipient: %v", kids)
+}
+
+type keyAgreementRecipient struct {
+	kid string
+	key gocrypto.PublicKey
+}
+
+// resolveKeyAgreementKeys returns the X25519 keyAgreement keys of a DID, identified by their fully qualified ids
+func resolveKeyAgreementKeys(ctx context.Context, r resolution.Resolver, recipientDID string) ([]keyAgreementRecipient, error) {
+	if r == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	resolved, err := r.Resolve(ctx, recipientDID)
+	if err != nil {
+		return nil, fmt.Errorf("resolving recipient did<%s>: %w", recipientDID, err)
+	}
+	methods, err := did.GetVerificationMethodsForPurpose(resolved.Document, did.KeyAgreement)
+	if err != nil {
+		return nil, fmt.Errorf("getting keyAgreement keys for did<%s>: %w", recipientDID, err)
+	}
+
+	var recipients []keyAgreementRecipient
+	for _, method := range methods {
+		pubKey, err := did.GetKeyFromEmbeddedVerificationMethod(method)
+		if err != nil {
+			return nil, fmt.Errorf("getting keyAgreement key<%s>: %w", method.ID, err)
+		}
+		// only X25519 keys are supported for encryption
+		if _, ok := pubKey.(x25519.PublicKey); !ok {
+			continue
+		}
+		recipients = append(recipients, keyAgreementRecipient{
+			kid: did.FullyQualifiedVerificationMethodID(recipientDID, method.ID),
+			key: pubKey,
+		})
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("did<%s> has no X25519 keyAgreement keys", recipientDID)
+	}
+	return recipients, nil
+}
+
+func encryptToRecipients(recipients []keyAgreementRecipient, plaintext []byte) ([]byte, error) {
+	options := []jwe.EncryptOption{jwe.WithContentEncryption(jwa.A256GCM)}
+	if len(recipients) > 1 {
+		options = append(options, jwe.WithJSON())
+	}
+	for _, recipient := range recipients {
+		headers := jwe.NewHeaders()
+		if err := headers.Set(jwe.KeyIDKey, recipient.kid); err != nil {
+			return nil, fmt.Errorf("setting kid header: %w", err)
+		}
+		options = append(options, jwe.WithKey(jwa.ECDH_ES_A256KW, recipient.key, jwe.WithPerRecipientHeaders(headers)))
+	}
+	encrypted, err := jwe.Encrypt(plaintext, options...)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting JWE: %w", err)
+	}
+	return encrypted, nil
+}
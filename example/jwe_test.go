@@ -0,0 +1,102 @@
+package example
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/lestrrat-go/jwx/v2/x25519"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptToDID(t *testing.T) {
+	r, err := resolution.NewResolver(key.Resolver{})
+	require.NoError(t, err)
+
+	recipient := NewSimpleWallet()
+	recipientDID, _, keyAgreementKID, err := recipient.InitWithKeyAgreement(did.KeyMethod)
+	require.NoError(t, err)
+
+	t.Run("round trip", func(tt *testing.T) {
+		plaintext := []byte("hello, did")
+		encrypted, err := EncryptToDID(context.Background(), r, recipientDID, plaintext)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, encrypted)
+
+		decrypted, err := DecryptWithWalletKey(recipient, encrypted)
+		assert.NoError(tt, err)
+		assert.Equal(tt, plaintext, decrypted)
+	})
+
+	t.Run("targeted key", func(tt *testing.T) {
+		plaintext := []byte("hello, key")
+		encrypted, err := EncryptToDIDKey(context.Background(), r, recipientDID, keyAgreementKID, plaintext)
+		assert.NoError(tt, err)
+
+		decrypted, err := DecryptWithWalletKey(recipient, encrypted)
+		assert.NoError(tt, err)
+		assert.Equal(tt, plaintext, decrypted)
+
+		_, err = EncryptToDIDKey(context.Background(), r, recipientDID, "#unknown", plaintext)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has no X25519 keyAgreement key<#unknown>")
+	})
+
+	t.Run("wallet without the recipient key", func(tt *testing.T) {
+		encrypted, err := EncryptToDID(context.Background(), r, recipientDID, []byte("secret"))
+		require.NoError(tt, err)
+
+		other := NewSimpleWallet()
+		_, _, _, err = other.InitWithKeyAgreement(did.KeyMethod)
+		require.NoError(tt, err)
+
+		_, err = DecryptWithWalletKey(other, encrypted)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "no wallet key found")
+	})
+
+	t.Run("wallet with a key that does not decrypt", func(tt *testing.T) {
+		stalePub, _, err := x25519.GenerateKey(rand.Reader)
+		require.NoError(tt, err)
+		_, wrongPriv, err := x25519.GenerateKey(rand.Reader)
+		require.NoError(tt, err)
+		currentPub, currentPriv, err := x25519.GenerateKey(rand.Reader)
+		require.NoError(tt, err)
+
+		w := NewSimpleWallet()
+		walletDID, _, _, err := w.InitWithKeyAgreement(did.KeyMethod)
+		require.NoError(tt, err)
+		require.NoError(tt, w.AddPrivateKey(walletDID, "#stale", wrongPriv))
+		require.NoError(tt, w.AddPrivateKey(walletDID, "#current", currentPriv))
+
+		plaintext := []byte("hello, second key")
+		encrypted, err := encryptToRecipients([]keyAgreementRecipient{
+			{kid: "#stale", key: stalePub},
+			{kid: "#current", key: currentPub},
+		}, plaintext)
+		require.NoError(tt, err)
+		decrypted, err := DecryptWithWalletKey(w, encrypted)
+		assert.NoError(tt, err)
+		assert.Equal(tt, plaintext, decrypted)
+
+		encrypted, err = encryptToRecipients([]keyAgreementRecipient{
+			{kid: "#stale", key: stalePub},
+			{kid: "#current", key: stalePub},
+		}, plaintext)
+		require.NoError(tt, err)
+		_, err = DecryptWithWalletKey(w, encrypted)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "decrypting JWE with key<#stale>")
+		assert.Contains(tt, err.Error(), "decrypting JWE with key<#current>")
+	})
+
+	t.Run("unresolvable did", func(tt *testing.T) {
+		_, err := EncryptToDID(context.Background(), r, "did:example:123", []byte("secret"))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "resolving recipient did<did:example:123>")
+	})
+}
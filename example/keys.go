@@ -0,0 +1,173 @@
+package example
+
+import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+const (
+	privateKeyPEMType    = "PRIVATE KEY"
+	ecPrivateKeyPEMType  = "EC PRIVATE KEY"
+	rsaPrivateKeyPEMType = "RSA PRIVATE KEY"
+	publicKeyPEMType     = "PUBLIC KEY"
+)
+
+// ExportOption changes how a key is exported from a SimpleWallet
+type ExportOption int
+
+const (
+	// AllowPrivateExport must be passed to export a private key, so that secrets are never exported by accident
+	AllowPrivateExport ExportOption = iota
+)
+
+// ImportPrivateKeyPEM adds a PEM encoded private key to the wallet for the given DID, which must already be in the
+// wallet. PKCS #8, SEC 1 EC, and PKCS #1 RSA private keys are supported.
+func (s *SimpleWallet) ImportPrivateKeyPEM(id, kid string, pemBytes []byte) error {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return errors.New("no PEM block found")
+	}
+	var privKey gocrypto.PrivateKey
+	var err error
+	switch block.Type {
+	case privateKeyPEMType:
+		privKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case ecPrivateKeyPEMType:
+		privKey, err = x509.ParseECPrivateKey(block.Bytes)
+	case rsaPrivateKeyPEMType:
+		privKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return fmt.Errorf("unsupported PEM block type<%s>", block.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing PEM private key: %w", err)
+	}
+	// keys are stored by value, as they are generated by the crypto package
+	if reflect.ValueOf(privKey).Kind() == reflect.Ptr {
+		privKey = reflect.ValueOf(privKey).Elem().Interface().(gocrypto.PrivateKey)
+	}
+	return s.AddPrivateKey(id, kid, privKey)
+}
+
+// ImportPrivateKeyJWK adds a JSON encoded private key JWK to the wallet for the given DID, which must already be in
+// the wallet. If kid is empty the JWK's kid is used.
+func (s *SimpleWallet) ImportPrivateKeyJWK(id, kid string, jwkBytes []byte) error {
+	var privKeyJWK jwx.PrivateKeyJWK
+	if err := json.Unmarshal(jwkBytes, &privKeyJWK); err != nil {
+		return fmt.Errorf("unmarshalling JWK: %w", err)
+	}
+	if privKeyJWK.D == "" {
+		return errors.New("JWK is not a private key")
+	}
+	if kid == "" {
+		kid = privKeyJWK.KID
+	}
+	if kid == "" {
+		return errors.New("kid cannot be empty")
+	}
+	privKey, err := privKeyJWK.ToPrivateKey()
+	if err != nil {
+		return fmt.Errorf("converting JWK to private key: %w", err)
+	}
+	return s.AddPrivateKey(id, kid, privKey)
+}
+
+// ExportPrivateKeyPEM returns the key with the given kid as a PKCS #8 PEM encoded private key. It requires the
+// AllowPrivateExport option.
+func (s *SimpleWallet) ExportPrivateKeyPEM(kid string, opts ...ExportOption) ([]byte, error) {
+	privKey, err := s.getKeyForExport(kid, true, opts)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privKey)
+	if err != nil {
+		return nil, fmt.Errorf("encoding private key<%s>: %w", kid, err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMType, Bytes: der}), nil
+}
+
+// ExportPublicKeyPEM returns the public key of the key with the given kid as a PKIX PEM encoded public key
+func (s *SimpleWallet) ExportPublicKeyPEM(kid string) ([]byte, error) {
+	privKey, err := s.getKeyForExport(kid, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := privKey.(gocrypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key<%s> has no public key", kid)
+	}
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("encoding public key<%s>: %w", kid, err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: publicKeyPEMType, Bytes: der}), nil
+}
+
+// ExportPrivateKeyJWK returns the key with the given kid as a JSON encoded private key JWK. It requires the
+// AllowPrivateExport option.
+func (s *SimpleWallet) ExportPrivateKeyJWK(kid string, opts ...ExportOption) ([]byte, error) {
+	privKey, err := s.getKeyForExport(kid, true, opts)
+	if err != nil {
+		return nil, err
+	}
+	_, privKeyJWK, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, privKey)
+	if err != nil {
+		return nil, fmt.Errorf("converting private key<%s> to JWK: %w", kid, err)
+	}
+	return json.Marshal(privKeyJWK)
+}
+
+// ExportPublicKeyJWK returns the public key of the key with the given kid as a JSON encoded public key JWK
+func (s *SimpleWallet) ExportPublicKeyJWK(kid string) ([]byte, error) {
+	privKey, err := s.getKeyForExport(kid, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	pubKeyJWK, _, err := jwx.PrivateKeyToPrivateKeyJWK(&kid, privKey)
+	if err != nil {
+		return nil, fmt.Errorf("converting private key<%s> to JWK: %w", kid, err)
+	}
+	return json.Marshal(pubKeyJWK)
+}
+
+// getKeyForExport returns the stored key with the given kid, checking that private exports are explicitly allowed
+func (s *SimpleWallet) getKeyForExport(kid string, private bool, opts []ExportOption) (gocrypto.PrivateKey, error) {
+	if private && !hasExportOption(opts, AllowPrivateExport) {
+		return nil, fmt.Errorf("exporting private key<%s> requires the AllowPrivateExport option", kid)
+	}
+	_, privKey, err := s.GetKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	return toPEMEncodableKey(privKey), nil
+}
+
+// toPEMEncodableKey returns pointers to ECDSA and RSA keys, which the x509 package requires, as the wallet stores
+// keys by value
+func toPEMEncodableKey(privKey gocrypto.PrivateKey) gocrypto.PrivateKey {
+	switch k := privKey.(type) {
+	case ecdsa.PrivateKey:
+		return &k
+	case rsa.PrivateKey:
+		return &k
+	}
+	return privKey
+}
+
+func hasExportOption(opts []ExportOption, want ExportOption) bool {
+	for _, opt := range opts {
+		if opt == want {
+			return true
+		}
+	}
+	return false
+}
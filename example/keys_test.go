@@ -0,0 +1,89 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimpleWalletKeyImportExport(t *testing.T) {
+	keyTypes := []crypto.KeyType{crypto.Ed25519, crypto.P256, crypto.P384, crypto.RSA}
+
+	for _, kt := range keyTypes {
+		t.Run(kt.String(), func(tt *testing.T) {
+			_, privKey, err := crypto.GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+			source := NewSimpleWallet()
+			require.NoError(tt, source.AddDID("did:example:123"))
+			require.NoError(tt, source.AddPrivateKey("did:example:123", "did:example:123#key-1", privKey))
+
+			// PEM round trip
+			pemBytes, err := source.ExportPrivateKeyPEM("did:example:123#key-1", AllowPrivateExport)
+			require.NoError(tt, err)
+			assert.Contains(tt, string(pemBytes), "BEGIN PRIVATE KEY")
+
+			dest := NewSimpleWallet()
+			require.NoError(tt, dest.AddDID("did:example:123"))
+			require.NoError(tt, dest.ImportPrivateKeyPEM("did:example:123", "did:example:123#pem", pemBytes))
+			importedPEM, err := dest.ExportPrivateKeyPEM("did:example:123#pem", AllowPrivateExport)
+			require.NoError(tt, err)
+			assert.Equal(tt, pemBytes, importedPEM)
+
+			// JWK round trip, taking the kid from the JWK
+			jwkBytes, err := source.ExportPrivateKeyJWK("did:example:123#key-1", AllowPrivateExport)
+			require.NoError(tt, err)
+			require.NoError(tt, dest.ImportPrivateKeyJWK("did:example:123", "", jwkBytes))
+			importedJWK, err := dest.ExportPrivateKeyJWK("did:example:123#key-1", AllowPrivateExport)
+			require.NoError(tt, err)
+			assert.JSONEq(tt, string(jwkBytes), string(importedJWK))
+
+			// the public exports match the imported keys
+			sourcePEM, err := source.ExportPublicKeyPEM("did:example:123#key-1")
+			require.NoError(tt, err)
+			assert.Contains(tt, string(sourcePEM), "BEGIN PUBLIC KEY")
+			destPEM, err := dest.ExportPublicKeyPEM("did:example:123#pem")
+			require.NoError(tt, err)
+			assert.Equal(tt, sourcePEM, destPEM)
+
+			publicJWK, err := source.ExportPublicKeyJWK("did:example:123#key-1")
+			require.NoError(tt, err)
+			assert.NotContains(tt, string(publicJWK), `"d"`)
+		})
+	}
+
+	t.Run("private export requires AllowPrivateExport", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		_, kid, err := w.InitReturning(did.KeyMethod)
+		require.NoError(tt, err)
+
+		_, err = w.ExportPrivateKeyPEM(kid)
+		assert.ErrorContains(tt, err, "requires the AllowPrivateExport option")
+		_, err = w.ExportPrivateKeyJWK(kid)
+		assert.ErrorContains(tt, err, "requires the AllowPrivateExport option")
+	})
+
+	t.Run("invalid imports", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		require.NoError(tt, w.AddDID("did:example:123"))
+
+		assert.ErrorContains(tt, w.ImportPrivateKeyPEM("did:example:123", "key-1", []byte("not a pem")), "no PEM block found")
+		assert.ErrorContains(tt, w.ImportPrivateKeyPEM("did:example:123", "key-1",
+			[]byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n")), "unsupported PEM block type<CERTIFICATE>")
+
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		source := NewSimpleWallet()
+		require.NoError(tt, source.AddDID("did:example:456"))
+		require.NoError(tt, source.AddPrivateKey("did:example:456", "key-1", privKey))
+		publicJWK, err := source.ExportPublicKeyJWK("key-1")
+		require.NoError(tt, err)
+		assert.ErrorContains(tt, w.ImportPrivateKeyJWK("did:example:123", "key-1", publicJWK), "JWK is not a private key")
+
+		privateJWK, err := source.ExportPrivateKeyJWK("key-1", AllowPrivateExport)
+		require.NoError(tt, err)
+		assert.ErrorContains(tt, w.ImportPrivateKeyJWK("did:example:unknown", "key-1", privateJWK), "did<did:example:unknown> not found")
+	})
+}
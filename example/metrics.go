@@ -0,0 +1,119 @@
+package example
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/metrics"
+)
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the histogram buckets of PrometheusMetrics, the same
+// as the Prometheus client's defaults
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+const (
+	resolutionMetric   = "ssi_did_resolution_duration_seconds"
+	verificationMetric = "ssi_verification_duration_seconds"
+)
+
+// PrometheusMetrics is a metrics.Metrics that keeps histograms of resolution and verification durations, and writes
+// them in the Prometheus text exposition format, so that an endpoint serving WriteTo can be scraped without a
+// dependency on the Prometheus client. Resolutions are labelled with the DID method, and verifications with their
+// kind and whether they succeeded. Services already using the Prometheus client would instead observe HistogramVecs
+// with the same labels.
+type PrometheusMetrics struct {
+	mu         sync.Mutex
+	buckets    []float64
+	histograms map[string]*histogram
+}
+
+var _ metrics.Metrics = (*PrometheusMetrics)(nil)
+
+type histogram struct {
+	name   string
+	labels string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheusMetrics returns metrics with histograms of the given buckets, or DefaultDurationBuckets if none
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &PrometheusMetrics{buckets: sorted, histograms: make(map[string]*histogram)}
+}
+
+func (p *PrometheusMetrics) ObserveResolution(method did.Method, duration time.Duration) {
+	p.observe(resolutionMetric, fmt.Sprintf("method=%q", method), duration)
+}
+
+func (p *PrometheusMetrics) ObserveVerification(kind string, duration time.Duration, ok bool) {
+	result := "failed"
+	if ok {
+		result = "ok"
+	}
+	p.observe(verificationMetric, fmt.Sprintf("kind=%q,result=%q", kind, result), duration)
+}
+
+func (p *PrometheusMetrics) observe(name, labels string, duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := name + "{" + labels + "}"
+	h, ok := p.histograms[key]
+	if !ok {
+		h = &histogram{name: name, labels: labels, counts: make([]uint64, len(p.buckets))}
+		p.histograms[key] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// WriteTo writes the histograms in the Prometheus text exposition format
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	keys := make([]string, 0, len(p.histograms))
+	for key := range p.histograms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	help := map[string]string{
+		resolutionMetric:   "Time taken to resolve DIDs, by DID method.",
+		verificationMetric: "Time taken to verify credentials and presentations, by kind and result.",
+	}
+	written := make(map[string]bool)
+	for _, key := range keys {
+		h := p.histograms[key]
+		if !written[h.name] {
+			written[h.name] = true
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, help[h.name], h.name)
+		}
+		for i, bound := range p.buckets {
+			fmt.Fprintf(&b, "%s_bucket{%s,le=%q} %d\n", h.name, h.labels, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", h.name, h.labels, h.count)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", h.name, h.labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", h.name, h.labels, h.count)
+	}
+	p.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
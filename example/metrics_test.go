@@ -0,0 +1,54 @@
+package example
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	t.Run("writes histograms in the text exposition format", func(tt *testing.T) {
+		m := NewPrometheusMetrics(0.1, 1)
+		m.ObserveResolution(did.WebMethod, 50*time.Millisecond)
+		m.ObserveResolution(did.WebMethod, 2*time.Second)
+		m.ObserveVerification("jwt_credential", 500*time.Millisecond, true)
+		m.ObserveVerification("jwt_credential", time.Millisecond, false)
+
+		var b strings.Builder
+		_, err := m.WriteTo(&b)
+		require.NoError(tt, err)
+		out := b.String()
+		assert.Contains(tt, out, "# TYPE ssi_did_resolution_duration_seconds histogram\n")
+		assert.Contains(tt, out, `ssi_did_resolution_duration_seconds_bucket{method="web",le="0.1"} 1`)
+		assert.Contains(tt, out, `ssi_did_resolution_duration_seconds_bucket{method="web",le="1"} 1`)
+		assert.Contains(tt, out, `ssi_did_resolution_duration_seconds_bucket{method="web",le="+Inf"} 2`)
+		assert.Contains(tt, out, `ssi_did_resolution_duration_seconds_sum{method="web"} 2.05`)
+		assert.Contains(tt, out, `ssi_verification_duration_seconds_count{kind="jwt_credential",result="ok"} 1`)
+		assert.Contains(tt, out, `ssi_verification_duration_seconds_bucket{kind="jwt_credential",result="failed",le="0.1"} 1`)
+		assert.Equal(tt, 1, strings.Count(out, "# TYPE ssi_verification_duration_seconds histogram"))
+	})
+
+	t.Run("observes resolutions through the context", func(tt *testing.T) {
+		m := NewPrometheusMetrics()
+		r, err := resolution.NewResolver(key.Resolver{})
+		require.NoError(tt, err)
+		_, didKey, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		_, err = r.Resolve(metrics.NewContext(context.Background(), m), didKey.String())
+		require.NoError(tt, err)
+
+		var b strings.Builder
+		_, err = m.WriteTo(&b)
+		require.NoError(tt, err)
+		assert.Contains(tt, b.String(), `ssi_did_resolution_duration_seconds_count{method="key"} 1`)
+	})
+}
@@ -0,0 +1,66 @@
+package example
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+)
+
+// pairwiseInfo binds pairwise keys to their purpose, so that the same base key used with HKDF elsewhere derives
+// unrelated keys
+const pairwiseInfo = "ssi-sdk pairwise did:key v1|"
+
+// DerivePairwiseDID derives a did:key for presenting to the given verifier from the wallet's key with the given kid,
+// so that each verifier sees a different holder DID that cannot be linked to the others. The Ed25519 key of the DID
+// is derived with HKDF-SHA256 from the base key and the verifier's ID, so deriving again with the same inputs gives
+// the same DID; the wallet only stores the key the first time. Keys held outside the wallet cannot be derived from.
+func (s *SimpleWallet) DerivePairwiseDID(baseKID, verifierID string) (string, error) {
+	if verifierID == "" {
+		return "", errors.New("verifier id cannot be empty")
+	}
+	_, baseKey, err := s.GetKey(baseKID)
+	if err != nil {
+		return "", fmt.Errorf("getting base key: %w", err)
+	}
+	secret, err := crypto.PrivKeyToBytes(baseKey)
+	if err != nil {
+		return "", fmt.Errorf("reading base key<%s>: %w", baseKID, err)
+	}
+
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, secret, []byte(baseKID), []byte(pairwiseInfo+verifierID)), seed); err != nil {
+		return "", fmt.Errorf("deriving pairwise key: %w", err)
+	}
+	privKey := ed25519.NewKeyFromSeed(seed)
+	didKey, err := key.CreateDIDKey(crypto.Ed25519, privKey.Public().(ed25519.PublicKey))
+	if err != nil {
+		return "", err
+	}
+	didStr := didKey.String()
+	expanded, err := didKey.Expand()
+	if err != nil {
+		return "", err
+	}
+
+	created, err := s.EnsureDID(didStr)
+	if err != nil {
+		return "", err
+	}
+	if !created {
+		return didStr, nil
+	}
+	if err = s.AddPrivateKey(didStr, expanded.VerificationMethod[0].ID, privKey); err != nil {
+		return "", err
+	}
+	if err = s.Registry().Register(*expanded); err != nil {
+		return "", err
+	}
+	return didStr, nil
+}
@@ -0,0 +1,71 @@
+package example
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+func TestSimpleWalletDerivePairwiseDID(t *testing.T) {
+	w := NewSimpleWallet()
+	w.SetRegistry(NewRegistry())
+	baseDID, baseKID, err := w.InitReturning(did.KeyMethod)
+	require.NoError(t, err)
+
+	t.Run("derives a DID per verifier", func(tt *testing.T) {
+		first, err := w.DerivePairwiseDID(baseKID, "did:example:verifier-1")
+		require.NoError(tt, err)
+		second, err := w.DerivePairwiseDID(baseKID, "did:example:verifier-2")
+		require.NoError(tt, err)
+		assert.NotEqual(tt, first, second)
+		assert.NotEqual(tt, baseDID, first)
+		assert.Contains(tt, w.GetDIDs(), first)
+
+		keys, err := w.GetKeysForDID(first)
+		require.NoError(tt, err)
+		require.Len(tt, keys, 1)
+		signer, err := w.NewSigner(keys[0].ID)
+		require.NoError(tt, err)
+		assert.Equal(tt, first, signer.ID)
+
+		_, err = w.Registry().Resolve(context.Background(), first)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("derives the same DID again", func(tt *testing.T) {
+		first, err := w.DerivePairwiseDID(baseKID, "did:example:verifier-3")
+		require.NoError(tt, err)
+		stored := w.Stats().KeysStored
+		again, err := w.DerivePairwiseDID(baseKID, "did:example:verifier-3")
+		require.NoError(tt, err)
+		assert.Equal(tt, first, again)
+		assert.Equal(tt, stored, w.Stats().KeysStored)
+
+		// another wallet holding the same base key derives the same DID
+		_, baseKey, err := w.GetKey(baseKID)
+		require.NoError(tt, err)
+		other := NewSimpleWallet()
+		other.SetRegistry(NewRegistry())
+		require.NoError(tt, other.AddDID(baseDID))
+		require.NoError(tt, other.AddPrivateKey(baseDID, baseKID, baseKey))
+		derived, err := other.DerivePairwiseDID(baseKID, "did:example:verifier-3")
+		require.NoError(tt, err)
+		assert.Equal(tt, first, derived)
+	})
+
+	t.Run("bad inputs", func(tt *testing.T) {
+		_, err := w.DerivePairwiseDID(baseKID, "")
+		assert.Error(tt, err)
+		_, err = w.DerivePairwiseDID("did:key:unknown#unknown", "did:example:verifier-1")
+		assert.ErrorContains(tt, err, "not found")
+
+		remote, didStr, kid, _ := newRemoteKeyWallet(tt)
+		_, err = remote.DerivePairwiseDID(kid, didStr)
+		assert.True(tt, errors.Is(err, ErrRemoteKey))
+	})
+}
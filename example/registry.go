@@ -0,0 +1,79 @@
+package example
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// ErrNotRegistered is returned when resolving a DID whose document is not in a Registry
+var ErrNotRegistered = errors.New("DID is not registered")
+
+// Registry is an in-memory store of DID documents that is safe for concurrent use. It is a resolution.Resolver, so
+// a verifier in the same process as a wallet can resolve the DIDs the wallet created without network resolution.
+type Registry struct {
+	mu        sync.RWMutex
+	documents map[string]did.Document
+}
+
+var _ resolution.Resolver = (*Registry)(nil)
+
+// DefaultRegistry is the registry SimpleWallets register the DIDs they create with, unless given another one
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{documents: make(map[string]did.Document)}
+}
+
+// Register stores the document, replacing any already registered for its DID
+func (r *Registry) Register(doc did.Document) error {
+	if doc.ID == "" {
+		return errors.New("document must have an id")
+	}
+	if _, err := resolution.GetMethodForDID(doc.ID); err != nil {
+		return fmt.Errorf("registering did<%s>: %w", doc.ID, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.documents[doc.ID] = doc
+	return nil
+}
+
+// Resolve returns the registered document of the DID, or ErrNotRegistered if there is none
+func (r *Registry) Resolve(_ context.Context, id string, _ ...resolution.Option) (*resolution.Result, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	doc, ok := r.documents[id]
+	if !ok {
+		return nil, fmt.Errorf("resolving did<%s>: %w", id, ErrNotRegistered)
+	}
+	return &resolution.Result{Document: doc}, nil
+}
+
+// Methods returns the methods of the registered DIDs
+func (r *Registry) Methods() []did.Method {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var methods []did.Method
+	seen := make(map[did.Method]bool)
+	for id := range r.documents {
+		method, err := resolution.GetMethodForDID(id)
+		if err != nil || seen[method] {
+			continue
+		}
+		seen[method] = true
+		methods = append(methods, method)
+	}
+	return methods
+}
+
+// Size returns the number of registered documents
+func (r *Registry) Size() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.documents)
+}
@@ -0,0 +1,78 @@
+package example
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("resolves registered documents", func(tt *testing.T) {
+		registry := NewRegistry()
+		require.NoError(tt, registry.Register(did.Document{ID: "did:example:123"}))
+		require.NoError(tt, registry.Register(did.Document{ID: "did:web:example.com"}))
+
+		resolved, err := registry.Resolve(context.Background(), "did:example:123")
+		require.NoError(tt, err)
+		assert.Equal(tt, "did:example:123", resolved.Document.ID)
+		assert.ElementsMatch(tt, []did.Method{"example", did.WebMethod}, registry.Methods())
+		assert.Equal(tt, 2, registry.Size())
+
+		_, err = registry.Resolve(context.Background(), "did:example:456")
+		assert.ErrorIs(tt, err, ErrNotRegistered)
+	})
+
+	t.Run("invalid documents", func(tt *testing.T) {
+		registry := NewRegistry()
+		assert.ErrorContains(tt, registry.Register(did.Document{}), "document must have an id")
+		assert.Error(tt, registry.Register(did.Document{ID: "not-a-did"}))
+		assert.Zero(tt, registry.Size())
+	})
+
+	t.Run("concurrent use", func(tt *testing.T) {
+		registry := NewRegistry()
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				id := fmt.Sprintf("did:example:%d", i)
+				assert.NoError(tt, registry.Register(did.Document{ID: id}))
+				_, err := registry.Resolve(context.Background(), id)
+				assert.NoError(tt, err)
+				registry.Methods()
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(tt, 20, registry.Size())
+	})
+
+	t.Run("verifies credentials issued by wallet DIDs", func(tt *testing.T) {
+		registry := NewRegistry()
+		w := NewSimpleWallet()
+		w.SetRegistry(registry)
+		didStr, kid, err := w.InitReturning(did.PeerMethod)
+		require.NoError(tt, err)
+		signer, err := w.NewSigner(kid)
+		require.NoError(tt, err)
+
+		signed, err := integrity.SignVerifiableCredentialJWT(*signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            didStr,
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		})
+		require.NoError(tt, err)
+		verified, err := integrity.VerifyCredentialSignature(context.Background(), string(signed), registry)
+		require.NoError(tt, err)
+		assert.True(tt, verified)
+	})
+}
@@ -0,0 +1,106 @@
+package example
+
+import (
+	gocrypto "crypto"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+// ErrRemoteKey is returned when the private key of a key held outside the wallet is asked for
+var ErrRemoteKey = errors.New("key is held by a remote signer")
+
+// RemoteSigner signs with keys held outside the wallet, such as in a KMS or in hardware, which are referenced by URIs
+// such as aws-kms://arn:aws:kms:us-east-1:111122223333:key/1234abcd
+type RemoteSigner interface {
+	// Signer returns a signer for the key the URI references, whose Public method returns the key's public key
+	Signer(keyRef string) (gocrypto.Signer, error)
+}
+
+// RegisterRemoteSigner registers the signer of the keys whose KeyRef has the URI scheme, e.g. aws-kms, replacing any
+// signer already registered for it
+func (s *SimpleWallet) RegisterRemoteSigner(scheme string, signer RemoteSigner) error {
+	if scheme == "" {
+		return errors.New("scheme cannot be empty")
+	}
+	if signer == nil {
+		return errors.New("signer cannot be empty")
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.remoteSigners == nil {
+		s.remoteSigners = make(map[string]RemoteSigner)
+	}
+	s.remoteSigners[scheme] = signer
+	return nil
+}
+
+// AddKeyRef adds a key held outside the wallet to the DID, referenced by the URI of the key, whose scheme names the
+// RemoteSigner it signs with. The signer does not have to be registered until the key is used.
+func (s *SimpleWallet) AddKeyRef(id, kid, keyRef string) error {
+	if _, err := keyRefScheme(keyRef); err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	walletKeys, ok := s.dids[id]
+	if !ok {
+		return fmt.Errorf("did<%s> not found", id)
+	}
+	for _, k := range walletKeys {
+		if k.ID == kid {
+			return fmt.Errorf("key<%s> already exists", kid)
+		}
+	}
+	s.dids[id] = append(walletKeys, WalletKeys{ID: kid, KeyRef: keyRef})
+	s.stats.KeysStored++
+	return nil
+}
+
+// IsRemoteKey returns whether the key with the given kid is held outside the wallet
+func (s *SimpleWallet) IsRemoteKey(kid string) (bool, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, d := range s.dids {
+		for _, k := range d {
+			if k.ID == kid {
+				return k.IsRemote(), nil
+			}
+		}
+	}
+	return false, fmt.Errorf("key<%s> not found", kid)
+}
+
+// keySigner constructs a signer for a key of the DID, delegating to the registered RemoteSigner for keys held
+// outside the wallet. The wallet's lock must not be held.
+func (s *SimpleWallet) keySigner(id string, k WalletKeys) (*jwx.Signer, error) {
+	kid := k.ID
+	if !k.IsRemote() {
+		return jwx.NewJWXSigner(id, &kid, k.Key)
+	}
+	scheme, err := keyRefScheme(k.KeyRef)
+	if err != nil {
+		return nil, err
+	}
+	s.mux.Lock()
+	remote, ok := s.remoteSigners[scheme]
+	s.mux.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no remote signer registered for key<%s> at %s", kid, k.KeyRef)
+	}
+	signer, err := remote.Signer(k.KeyRef)
+	if err != nil {
+		return nil, fmt.Errorf("getting remote signer for key<%s>: %w", kid, err)
+	}
+	return jwx.NewJWXSignerFromCryptoSigner(id, &kid, signer)
+}
+
+func keyRefScheme(keyRef string) (string, error) {
+	parsed, err := url.Parse(keyRef)
+	if err != nil || parsed.Scheme == "" {
+		return "", fmt.Errorf("key reference<%s> is not a URI", keyRef)
+	}
+	return parsed.Scheme, nil
+}
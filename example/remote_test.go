@@ -0,0 +1,125 @@
+package example
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// testKMS is a RemoteSigner holding keys in memory by URI, as a KMS would
+type testKMS map[string]ed25519.PrivateKey
+
+func (k testKMS) Signer(keyRef string) (gocrypto.Signer, error) {
+	privKey, ok := k[keyRef]
+	if !ok {
+		return nil, fmt.Errorf("no key at %s", keyRef)
+	}
+	return privKey, nil
+}
+
+func newRemoteKeyWallet(t *testing.T) (w *SimpleWallet, didStr, kid, keyRef string) {
+	pubKey, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	didKey, err := key.CreateDIDKey(crypto.Ed25519, pubKey)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+
+	w = NewSimpleWallet()
+	didStr, kid, keyRef = didKey.String(), expanded.VerificationMethod[0].ID, "test-kms://keys/1"
+	require.NoError(t, w.AddDID(didStr))
+	require.NoError(t, w.AddKeyRef(didStr, kid, keyRef))
+	require.NoError(t, w.RegisterRemoteSigner("test-kms", testKMS{keyRef: privKey}))
+	return w, didStr, kid, keyRef
+}
+
+func TestSimpleWalletRemoteKeys(t *testing.T) {
+	t.Run("signs with the remote signer", func(tt *testing.T) {
+		w, didStr, kid, _ := newRemoteKeyWallet(tt)
+
+		signer, err := w.NewSigner(kid)
+		require.NoError(tt, err)
+		assert.Equal(tt, didStr, signer.ID)
+		assert.Equal(tt, kid, signer.KID)
+
+		token, err := signer.SignWithDefaults(map[string]any{"test": "value"})
+		require.NoError(tt, err)
+		verifier, err := signer.ToVerifier(didStr)
+		require.NoError(tt, err)
+		assert.NoError(tt, verifier.Verify(string(token)))
+		assert.Equal(tt, 1, w.Stats().SignersCreated)
+	})
+
+	t.Run("issues credentials with the remote signer", func(tt *testing.T) {
+		w, didStr, _, _ := newRemoteKeyWallet(tt)
+
+		token, err := w.IssueCredential(didStr, map[string]any{"id": "did:example:456"})
+		require.NoError(tt, err)
+		resolver, err := resolution.NewResolver(key.Resolver{})
+		require.NoError(tt, err)
+		_, err = integrity.VerifyCredentialSignature(context.Background(), string(token), resolver)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("does not return the private key of a remote key", func(tt *testing.T) {
+		w, _, kid, keyRef := newRemoteKeyWallet(tt)
+
+		_, _, err := w.GetKey(kid)
+		assert.True(tt, errors.Is(err, ErrRemoteKey))
+		assert.Contains(tt, err.Error(), keyRef)
+
+		remote, err := w.IsRemoteKey(kid)
+		assert.NoError(tt, err)
+		assert.True(tt, remote)
+
+		_, localKID, err := w.InitReturning(did.KeyMethod)
+		require.NoError(tt, err)
+		remote, err = w.IsRemoteKey(localKID)
+		assert.NoError(tt, err)
+		assert.False(tt, remote)
+	})
+
+	t.Run("no signer registered for the scheme", func(tt *testing.T) {
+		w, didStr, _, _ := newRemoteKeyWallet(tt)
+		require.NoError(tt, w.AddKeyRef(didStr, didStr+"#other", "other-kms://keys/1"))
+
+		_, err := w.NewSigner(didStr + "#other")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "no remote signer registered")
+	})
+
+	t.Run("rejects invalid key references", func(tt *testing.T) {
+		w, didStr, kid, _ := newRemoteKeyWallet(tt)
+
+		assert.ErrorContains(tt, w.AddKeyRef(didStr, didStr+"#other", "not a uri"), "is not a URI")
+		assert.ErrorContains(tt, w.AddKeyRef(didStr, kid, "test-kms://keys/2"), "already exists")
+		assert.ErrorContains(tt, w.AddKeyRef("did:example:unknown", "did:example:unknown#key-1", "test-kms://keys/2"), "not found")
+		assert.Error(tt, w.RegisterRemoteSigner("", testKMS{}))
+		assert.Error(tt, w.RegisterRemoteSigner("test-kms", nil))
+	})
+
+	t.Run("backs up the key reference", func(tt *testing.T) {
+		w, _, kid, keyRef := newRemoteKeyWallet(tt)
+
+		message, err := w.CreateBackupMessage("correct horse battery staple")
+		require.NoError(tt, err)
+		restored, err := RestoreFromBackupMessage(message, "correct horse battery staple")
+		require.NoError(tt, err)
+
+		_, _, err = restored.GetKey(kid)
+		assert.True(tt, errors.Is(err, ErrRemoteKey))
+		assert.Contains(tt, err.Error(), keyRef)
+	})
+}
@@ -0,0 +1,137 @@
+package example
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// ErrImmutableDID is returned when rotating the key of a DID whose document cannot be updated
+var ErrImmutableDID = errors.New("DID method does not support updates")
+
+// immutableMethods are the DID methods whose documents are derived from the DID itself, so no key can be added
+var immutableMethods = map[did.Method]bool{
+	did.KeyMethod:  true,
+	did.PeerMethod: true,
+	did.JWKMethod:  true,
+	did.PKHMethod:  true,
+}
+
+// RotateSigningKey generates a new Ed25519 key for the DID and makes it the DID's assertionMethod key in place of the
+// keys the wallet issued credentials with, returning the id of the new key. The document registered for the DID is
+// updated: the new key is added, and the old keys are removed from its assertionMethod relationship but kept as
+// verification methods, so that credentials already issued with them still verify until they are re-issued. The old
+// keys are marked retired in the wallet, and IssueCredential signs with the new key from then on.
+// CredentialsIssuedWith returns the credentials signed with a retired key. Only DIDs whose method supports updates,
+// such as did:web, can be rotated; the others fail with ErrImmutableDID.
+func (s *SimpleWallet) RotateSigningKey(didStr string) (newKID string, err error) {
+	method, err := resolution.GetMethodForDID(didStr)
+	if err != nil {
+		return "", err
+	}
+	if immutableMethods[method] {
+		return "", fmt.Errorf("rotating key of did<%s>: %w", didStr, ErrImmutableDID)
+	}
+	keys, err := s.GetKeysForDID(didStr)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := s.Registry().Resolve(context.Background(), didStr)
+	if err != nil {
+		return "", err
+	}
+	doc := resolved.Document
+
+	retired := make(map[string]bool)
+	for _, k := range keys {
+		if k.Retired {
+			continue
+		}
+		isAssertionKey, err := did.HasVerificationMethodForPurpose(doc, k.ID, did.AssertionMethod)
+		if err != nil {
+			return "", fmt.Errorf("finding assertion keys of did<%s>: %w", didStr, err)
+		}
+		if isAssertionKey {
+			retired[did.FullyQualifiedVerificationMethodID(didStr, k.ID)] = true
+		}
+	}
+	if len(retired) == 0 {
+		return "", fmt.Errorf("did<%s> has no assertionMethod key in the wallet to rotate", didStr)
+	}
+
+	pubKey, privKey, err := crypto.GenerateEd25519Key()
+	if err != nil {
+		return "", fmt.Errorf("generating key: %w", err)
+	}
+	newKID = nextKeyID(doc)
+	verificationMethod, err := did.ConstructJWKVerificationMethod(newKID, didStr, pubKey, crypto.Ed25519)
+	if err != nil {
+		return "", fmt.Errorf("constructing verification method<%s>: %w", newKID, err)
+	}
+
+	// copy the relationships changed, as the registered document shares them
+	doc.VerificationMethod = append(append([]did.VerificationMethod(nil), doc.VerificationMethod...), *verificationMethod)
+	assertionMethods := make([]did.VerificationMethodSet, 0, len(doc.AssertionMethod))
+	for _, entry := range doc.AssertionMethod {
+		switch typedEntry := entry.(type) {
+		case string:
+			if retired[did.FullyQualifiedVerificationMethodID(didStr, typedEntry)] {
+				continue
+			}
+		case did.VerificationMethod:
+			if retired[did.FullyQualifiedVerificationMethodID(didStr, typedEntry.ID)] {
+				continue
+			}
+		}
+		assertionMethods = append(assertionMethods, entry)
+	}
+	doc.AssertionMethod = append(assertionMethods, newKID)
+
+	if err = s.AddPrivateKey(didStr, newKID, privKey); err != nil {
+		return "", err
+	}
+	if err = s.Registry().Register(doc); err != nil {
+		return "", err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for i, k := range s.dids[didStr] {
+		if retired[did.FullyQualifiedVerificationMethodID(didStr, k.ID)] {
+			s.dids[didStr][i].Retired = true
+		}
+	}
+	return newKID, nil
+}
+
+// nextKeyID returns the id of the form did#key-N of the first N a verification method of the document does not have
+func nextKeyID(doc did.Document) string {
+	ids := make(map[string]bool, len(doc.VerificationMethod))
+	for _, method := range doc.VerificationMethod {
+		ids[did.FullyQualifiedVerificationMethodID(doc.ID, method.ID)] = true
+	}
+	for n := len(doc.VerificationMethod) + 1; ; n++ {
+		if id := fmt.Sprintf("%s#key-%d", doc.ID, n); !ids[id] {
+			return id
+		}
+	}
+}
+
+// CredentialsIssuedWith returns the ids of the credentials IssueCredential signed with the key since the wallet was
+// created or loaded, such as those to re-issue after the key is retired by RotateSigningKey
+func (s *SimpleWallet) CredentialsIssuedWith(kid string) []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	var credIDs []string
+	for credID, signedWith := range s.issued {
+		if signedWith == kid {
+			credIDs = append(credIDs, credID)
+		}
+	}
+	sort.Strings(credIDs)
+	return credIDs
+}
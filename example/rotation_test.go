@@ -0,0 +1,118 @@
+package example
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+// newWebIssuerWallet returns a wallet holding a did:web issuer, whose document is registered in its own registry
+func newWebIssuerWallet(t *testing.T) (w *SimpleWallet, didStr, kid string) {
+	didStr, kid = "did:web:issuer.example.com", "did:web:issuer.example.com#key-1"
+	pubKey, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	method, err := did.ConstructJWKVerificationMethod(kid, didStr, pubKey, crypto.Ed25519)
+	require.NoError(t, err)
+
+	w = NewSimpleWallet()
+	w.SetRegistry(NewRegistry())
+	require.NoError(t, w.AddDID(didStr))
+	require.NoError(t, w.AddPrivateKey(didStr, kid, privKey))
+	require.NoError(t, w.Registry().Register(did.Document{
+		ID:                 didStr,
+		VerificationMethod: []did.VerificationMethod{*method},
+		Authentication:     []did.VerificationMethodSet{kid},
+		AssertionMethod:    []did.VerificationMethodSet{kid},
+	}))
+	return w, didStr, kid
+}
+
+func TestSimpleWalletRotateSigningKey(t *testing.T) {
+	t.Run("rotates the assertion key", func(tt *testing.T) {
+		w, didStr, oldKID := newWebIssuerWallet(tt)
+		before, err := w.IssueCredential(didStr, map[string]any{"id": "did:example:456"})
+		require.NoError(tt, err)
+		beforeCred, err := integrity.ParseVerifiableCredentialFromJWTUnverified(string(before))
+		require.NoError(tt, err)
+
+		newKID, err := w.RotateSigningKey(didStr)
+		require.NoError(tt, err)
+		assert.Equal(tt, didStr+"#key-2", newKID)
+		assert.Equal(tt, []string{beforeCred.Trust().Credential.ID}, w.CredentialsIssuedWith(oldKID))
+
+		resolved, err := w.Registry().Resolve(context.Background(), didStr)
+		require.NoError(tt, err)
+		assert.Len(tt, resolved.Document.VerificationMethod, 2)
+		assert.Equal(tt, []did.VerificationMethodSet{newKID}, resolved.Document.AssertionMethod)
+		assert.Equal(tt, []did.VerificationMethodSet{oldKID}, resolved.Document.Authentication)
+
+		keys, err := w.GetKeysForDID(didStr)
+		require.NoError(tt, err)
+		require.Len(tt, keys, 2)
+		assert.True(tt, keys[0].Retired)
+		assert.False(tt, keys[1].Retired)
+
+		// credentials are issued with the new key, and those issued before still verify
+		after, err := w.IssueCredential(didStr, map[string]any{"id": "did:example:456"})
+		require.NoError(tt, err)
+		afterCred, err := integrity.ParseVerifiableCredentialFromJWTUnverified(string(after))
+		require.NoError(tt, err)
+		assert.Equal(tt, newKID, afterCred.Trust().Headers.KeyID())
+		assert.Equal(tt, []string{afterCred.Trust().Credential.ID}, w.CredentialsIssuedWith(newKID))
+		for _, token := range [][]byte{before, after} {
+			verified, err := integrity.VerifyJWTCredential(context.Background(), string(token), w.Registry())
+			require.NoError(tt, err)
+			assert.True(tt, verified)
+		}
+
+		// rotating again retires the new key
+		newerKID, err := w.RotateSigningKey(didStr)
+		require.NoError(tt, err)
+		assert.Equal(tt, didStr+"#key-3", newerKID)
+		keys, err = w.GetKeysForDID(didStr)
+		require.NoError(tt, err)
+		assert.True(tt, keys[1].Retired)
+	})
+
+	t.Run("retired keys are kept in backups", func(tt *testing.T) {
+		w, didStr, _ := newWebIssuerWallet(tt)
+		_, err := w.RotateSigningKey(didStr)
+		require.NoError(tt, err)
+		backup, err := w.CreateBackupMessage("passphrase")
+		require.NoError(tt, err)
+		restored, err := RestoreFromBackupMessage(backup, "passphrase")
+		require.NoError(tt, err)
+		keys, err := restored.GetKeysForDID(didStr)
+		require.NoError(tt, err)
+		require.Len(tt, keys, 2)
+		assert.True(tt, keys[0].Retired)
+	})
+
+	t.Run("DIDs that cannot be updated", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		w.SetRegistry(NewRegistry())
+		didStr, _, err := w.InitReturning(did.KeyMethod)
+		require.NoError(tt, err)
+		_, err = w.RotateSigningKey(didStr)
+		assert.ErrorIs(tt, err, ErrImmutableDID)
+	})
+
+	t.Run("DIDs the wallet cannot rotate", func(tt *testing.T) {
+		w, _, _ := newWebIssuerWallet(tt)
+		_, err := w.RotateSigningKey("did:web:other.example.com")
+		assert.ErrorContains(tt, err, "not found")
+
+		require.NoError(tt, w.AddDID("did:web:unregistered.example.com"))
+		_, err = w.RotateSigningKey("did:web:unregistered.example.com")
+		assert.ErrorIs(tt, err, ErrNotRegistered)
+
+		_, err = w.RotateSigningKey("not a did")
+		assert.Error(tt, err)
+	})
+}
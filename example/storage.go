@@ -0,0 +1,99 @@
+package example
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// encryptedFileMode is the mode encrypted wallet files are written with, readable only by their owner
+const encryptedFileMode = 0o600
+
+// SaveToEncryptedFile encrypts the wallet with the passphrase, in the format of CreateBackupMessage, and writes it to
+// the file at path, which LoadEncryptedSimpleWallet loads the wallet from. The file is replaced atomically, so that it
+// holds either the previous wallet or the new one if writing is interrupted.
+func (s *SimpleWallet) SaveToEncryptedFile(passphrase []byte, path string) error {
+	message, err := s.CreateBackupMessage(string(passphrase))
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, message)
+}
+
+// LoadEncryptedSimpleWallet decrypts the wallet written to the file at path by SaveToEncryptedFile with the passphrase
+func LoadEncryptedSimpleWallet(passphrase []byte, path string) (*SimpleWallet, error) {
+	message, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading wallet file: %w", err)
+	}
+	return RestoreFromBackupMessage(message, string(passphrase))
+}
+
+// ChangePassphrase re-encrypts the wallet file at path, written by SaveToEncryptedFile, from the old passphrase to the
+// new one. The wallet held by the file is re-encrypted as it is, whatever the contents of s, and the file is replaced
+// atomically so that a crash during rotation leaves it encrypted with either passphrase. If the old passphrase does
+// not decrypt the file, the file is left untouched.
+func (s *SimpleWallet) ChangePassphrase(oldPass, newPass []byte, path string) error {
+	if len(newPass) == 0 {
+		return errors.New("new passphrase cannot be empty")
+	}
+	if s.mux == nil {
+		return errors.New("no mux for wallet")
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	message, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading wallet file: %w", err)
+	}
+	plaintext, err := openBackupMessage(message, string(oldPass))
+	if err != nil {
+		return err
+	}
+	rotated, err := sealBackupMessage(plaintext, string(newPass))
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, rotated)
+}
+
+// writeFileAtomic replaces the file at path with data by writing a temporary file in the same directory, syncing it
+// to disk, and renaming it over the file
+func writeFileAtomic(path string, data []byte) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary wallet file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = tmp.Chmod(encryptedFileMode); err != nil {
+		return fmt.Errorf("setting mode of temporary wallet file: %w", err)
+	}
+	if _, err = tmp.Write(data); err != nil {
+		return fmt.Errorf("writing temporary wallet file: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("syncing temporary wallet file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("closing temporary wallet file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replacing wallet file: %w", err)
+	}
+
+	// syncing the directory persists the rename; not every platform supports it, so failing to is not an error
+	if d, dirErr := os.Open(dir); dirErr == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package example
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+func TestSimpleWalletEncryptedFile(t *testing.T) {
+	w := NewSimpleWallet()
+	didStr, kid, err := w.InitReturning(did.KeyMethod)
+	require.NoError(t, err)
+	require.NoError(t, w.AddCredentialJWT("cred-1", "header.payload.signature"))
+
+	oldPass, newPass := []byte("correct horse battery staple"), []byte("tr0ub4dor&3")
+	save := func(tt *testing.T) string {
+		path := filepath.Join(tt.TempDir(), "wallet.enc")
+		require.NoError(tt, w.SaveToEncryptedFile(oldPass, path))
+		return path
+	}
+
+	t.Run("loads the saved wallet", func(tt *testing.T) {
+		path := save(tt)
+		info, err := os.Stat(path)
+		require.NoError(tt, err)
+		assert.Equal(tt, os.FileMode(encryptedFileMode), info.Mode().Perm())
+
+		loaded, err := LoadEncryptedSimpleWallet(oldPass, path)
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{didStr}, loaded.GetDIDs())
+		assert.Equal(tt, 1, loaded.Size())
+		_, err = loaded.NewSigner(kid)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("changes the passphrase", func(tt *testing.T) {
+		path := save(tt)
+		require.NoError(tt, w.ChangePassphrase(oldPass, newPass, path))
+
+		_, err := LoadEncryptedSimpleWallet(oldPass, path)
+		assert.ErrorContains(tt, err, "wrong passphrase")
+		loaded, err := LoadEncryptedSimpleWallet(newPass, path)
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{didStr}, loaded.GetDIDs())
+		assert.Equal(tt, 1, loaded.Size())
+
+		// no temporary file is left behind
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(tt, err)
+		assert.Len(tt, entries, 1)
+	})
+
+	t.Run("wrong old passphrase leaves the file untouched", func(tt *testing.T) {
+		path := save(tt)
+		before, err := os.ReadFile(path)
+		require.NoError(tt, err)
+
+		err = w.ChangePassphrase([]byte("wrong"), newPass, path)
+		assert.ErrorContains(tt, err, "wrong passphrase")
+		after, err := os.ReadFile(path)
+		require.NoError(tt, err)
+		assert.Equal(tt, before, after)
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(tt, err)
+		assert.Len(tt, entries, 1)
+	})
+
+	t.Run("invalid arguments", func(tt *testing.T) {
+		path := save(tt)
+		assert.ErrorContains(tt, w.ChangePassphrase(oldPass, nil, path), "new passphrase cannot be empty")
+		assert.ErrorContains(tt, w.SaveToEncryptedFile(nil, path), "passphrase cannot be empty")
+		assert.ErrorContains(tt, w.ChangePassphrase(oldPass, newPass, filepath.Join(tt.TempDir(), "missing")),
+			"reading wallet file")
+	})
+}
@@ -67,11 +67,11 @@ func BuildExampleUniversityVC(signer jwx.Signer, universityDID, recipientDID str
 		return "", "", err
 	}
 	cred = string(signedCred)
-	_, credToken, _, err := integrity.ParseVerifiableCredentialFromJWT(string(signedCred))
+	parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(string(signedCred))
 	if err != nil {
 		return "", "", err
 	}
-	credID = credToken.JwtID()
+	credID = parsed.Trust().Token.JwtID()
 
 	example.WriteNote(fmt.Sprintf("VC issued from %s to %s", universityDID, recipientDID))
 
@@ -0,0 +1,93 @@
+package example
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// CredentialStatusChecker checks whether a credential has been revoked according to its credentialStatus, such as a
+// status.StatusChecker does with StatusList2021 entries
+type CredentialStatusChecker interface {
+	IsRevoked(ctx context.Context, cred credential.VerifiableCredential) (bool, error)
+}
+
+var _ CredentialStatusChecker = (*status.StatusChecker)(nil)
+
+// StoredCredentialVerification changes how VerifyStoredCredential verifies a credential
+type StoredCredentialVerification struct {
+	// Status, if set, checks the credentialStatus of credentials that have one
+	Status CredentialStatusChecker
+	// Options are passed to the verification of the credential, such as integrity.WithClock
+	Options []integrity.VerifyOption
+}
+
+// CredentialVerdict is the outcome of verifying a stored credential, with the reason it is not valid, if any
+type CredentialVerdict struct {
+	ID string
+	// Error is why the credential failed verification of its signature, issuer, or time claims, nil if it passed
+	Error error
+	// Expiration is zero for credentials without an exp claim. Expired tells whether it is before the time the
+	// credential was verified at, in which case Error is set as well.
+	Expiration time.Time
+	Expired    bool
+	// StatusChecked tells whether the credentialStatus was checked, and Revoked whether it told the credential has
+	// been revoked. StatusError is why the status could not be checked, if it could not.
+	StatusChecked bool
+	Revoked       bool
+	StatusError   error
+}
+
+// Valid returns whether the credential passed verification and, if its status was checked, has not been revoked
+func (v CredentialVerdict) Valid() bool {
+	return v.Error == nil && !v.Revoked && v.StatusError == nil
+}
+
+// VerifyStoredCredential verifies the credential JWT stored under the ID as a verifier would: its signature with the
+// issuer's key resolved with r, its time claims, and its credentialStatus if the verification has a status checker.
+// The verdict tells whether the credential is valid and why not, so that holders can see which of their credentials
+// would be accepted before presenting them. Invalid credentials are kept in the wallet. An error is only returned if
+// no credential is stored under the ID or the credential cannot be parsed.
+func (s *SimpleWallet) VerifyStoredCredential(ctx context.Context, credID string, r resolution.Resolver, verification StoredCredentialVerification) (*CredentialVerdict, error) {
+	if s.mux == nil {
+		return nil, errors.New("no mux for wallet")
+	}
+	if r == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	s.mux.Lock()
+	token, ok := s.vcs[credID]
+	s.mux.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("credential<%s> not found", credID)
+	}
+	parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(token)
+	if err != nil {
+		return nil, fmt.Errorf("credential<%s> could not be parsed: %w", credID, err)
+	}
+	// the credential is only used to check its status, which is reported alongside the result of its verification
+	cred := parsed.Trust().Credential
+
+	now := time.Now()
+	verdict := CredentialVerdict{ID: credID, Expiration: parsed.Trust().Token.Expiration()}
+	verified, err := integrity.VerifyJWTCredential(ctx, token, r, verification.Options...)
+	if err == nil && !verified {
+		err = integrity.ErrSignatureInvalid
+	}
+	verdict.Error = err
+	verdict.Expired = err != nil && !verdict.Expiration.IsZero() && verdict.Expiration.Before(now)
+
+	if verification.Status != nil && cred.CredentialStatus != nil {
+		verdict.StatusChecked = true
+		if verdict.Revoked, err = verification.Status.IsRevoked(ctx, *cred); err != nil {
+			verdict.StatusError = fmt.Errorf("checking status of credential<%s>: %w", credID, err)
+		}
+	}
+	return &verdict, nil
+}
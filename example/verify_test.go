@@ -0,0 +1,124 @@
+package example
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// revokedCredentials is a CredentialStatusChecker telling the credentials with the given ids are revoked
+type revokedCredentials map[string]bool
+
+func (r revokedCredentials) IsRevoked(_ context.Context, cred credential.VerifiableCredential) (bool, error) {
+	if cred.ID == "unreachable" {
+		return false, errors.New("status list unreachable")
+	}
+	return r[cred.ID], nil
+}
+
+func TestSimpleWalletVerifyStoredCredential(t *testing.T) {
+	w := NewSimpleWallet()
+	didStr, kid, err := w.InitReturning(did.KeyMethod)
+	require.NoError(t, err)
+	signer, err := w.NewSigner(kid)
+	require.NoError(t, err)
+	resolver, err := resolution.NewResolver(key.Resolver{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	signCredential := func(id string, expiration time.Time) string {
+		cred := credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                id,
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            didStr,
+			IssuanceDate:      now.Add(-48 * time.Hour).Format(time.RFC3339),
+			CredentialStatus:  map[string]any{"id": "https://example.com/status/1#" + id, "type": "StatusList2021Entry"},
+			CredentialSubject: map[string]any{"id": didStr},
+		}
+		if !expiration.IsZero() {
+			cred.ExpirationDate = expiration.Format(time.RFC3339)
+		}
+		signed, err := integrity.SignVerifiableCredentialJWT(*signer, cred)
+		require.NoError(t, err)
+		return string(signed)
+	}
+	valid := signCredential("valid", now.Add(time.Hour))
+	require.NoError(t, w.AddCredentialJWT("valid", valid))
+	require.NoError(t, w.AddCredentialJWT("expired", signCredential("expired", now.Add(-time.Hour))))
+	require.NoError(t, w.AddCredentialJWT("revoked", signCredential("revoked", time.Time{})))
+	require.NoError(t, w.AddCredentialJWT("unreachable", signCredential("unreachable", time.Time{})))
+	require.NoError(t, w.AddCredentialJWT("tampered", valid[:len(valid)-4]+"AAAA"))
+	require.NoError(t, w.AddCredentialJWT("malformed", "not-a-jwt"))
+	status := StoredCredentialVerification{Status: revokedCredentials{"revoked": true}}
+
+	t.Run("valid credentials", func(tt *testing.T) {
+		verdict, err := w.VerifyStoredCredential(context.Background(), "valid", resolver, status)
+		require.NoError(tt, err)
+		assert.True(tt, verdict.Valid())
+		assert.Equal(tt, "valid", verdict.ID)
+		assert.True(tt, verdict.StatusChecked)
+		assert.False(tt, verdict.Expired)
+		assert.WithinDuration(tt, now.Add(time.Hour), verdict.Expiration, time.Second)
+	})
+
+	t.Run("invalid credentials are reported and kept", func(tt *testing.T) {
+		verdict, err := w.VerifyStoredCredential(context.Background(), "expired", resolver, status)
+		require.NoError(tt, err)
+		assert.False(tt, verdict.Valid())
+		assert.True(tt, verdict.Expired)
+		assert.ErrorIs(tt, verdict.Error, integrity.ErrClaimsNotSatisfied)
+
+		verdict, err = w.VerifyStoredCredential(context.Background(), "revoked", resolver, status)
+		require.NoError(tt, err)
+		assert.False(tt, verdict.Valid())
+		assert.NoError(tt, verdict.Error)
+		assert.True(tt, verdict.Revoked)
+
+		verdict, err = w.VerifyStoredCredential(context.Background(), "unreachable", resolver, status)
+		require.NoError(tt, err)
+		assert.False(tt, verdict.Valid())
+		assert.ErrorContains(tt, verdict.StatusError, "status list unreachable")
+
+		verdict, err = w.VerifyStoredCredential(context.Background(), "tampered", resolver, status)
+		require.NoError(tt, err)
+		assert.False(tt, verdict.Valid())
+		assert.ErrorIs(tt, verdict.Error, integrity.ErrSignatureInvalid)
+		assert.False(tt, verdict.Expired)
+
+		assert.Equal(tt, 6, w.Size())
+	})
+
+	t.Run("status is only checked when asked", func(tt *testing.T) {
+		verdict, err := w.VerifyStoredCredential(context.Background(), "revoked", resolver, StoredCredentialVerification{})
+		require.NoError(tt, err)
+		assert.True(tt, verdict.Valid())
+		assert.False(tt, verdict.StatusChecked)
+	})
+
+	t.Run("verification options", func(tt *testing.T) {
+		verdict, err := w.VerifyStoredCredential(context.Background(), "expired", resolver,
+			StoredCredentialVerification{Options: []integrity.VerifyOption{integrity.WithClockSkew(2 * time.Hour)}})
+		require.NoError(tt, err)
+		assert.True(tt, verdict.Valid())
+	})
+
+	t.Run("credentials that cannot be verified", func(tt *testing.T) {
+		_, err := w.VerifyStoredCredential(context.Background(), "unknown", resolver, status)
+		assert.ErrorContains(tt, err, "credential<unknown> not found")
+		_, err = w.VerifyStoredCredential(context.Background(), "malformed", resolver, status)
+		assert.ErrorContains(tt, err, "credential<malformed> could not be parsed")
+		_, err = w.VerifyStoredCredential(context.Background(), "valid", nil, status)
+		assert.ErrorContains(tt, err, "resolver cannot be empty")
+	})
+}
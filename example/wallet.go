@@ -3,30 +3,68 @@ package example
 import (
 	"context"
 	gocrypto "crypto"
+	"crypto/ed25519"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"sync"
-	"encoding/json"
 	"os"
+	"sort"
+	"sync"
+	"time"
 
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/did/key"
 	"github.com/TBD54566975/ssi-sdk/did/peer"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/TBD54566975/ssi-sdk/util"
 )
 
 // SimpleWallet is a sample wallet
 // This would NOT be how it would be stored in production, but serves for demonstrative purposes
 // This holds the assigned DIDs, their associated private keys, and VCs
 type SimpleWallet struct {
-	vcs  map[string]string	`json:"vcs"`
-	dids map[string][]WalletKeys	`json:"dids"`
-	mux  *sync.Mutex	`json:"-"`
+	vcs   map[string]string
+	dids  map[string][]WalletKeys
+	stats WalletStats
+	mux   *sync.Mutex
+	// remoteSigners sign with the keys referenced by a KeyRef, by the scheme of its URI
+	remoteSigners map[string]RemoteSigner
+	// registry is where the documents of created DIDs are registered, DefaultRegistry if nil
+	registry *Registry
+	// issued holds the kid of the key each credential IssueCredential issued was signed with, by credential id
+	issued map[string]string
+}
+
+// WalletStats is a snapshot of the operations performed on a SimpleWallet since it was created or loaded
+type WalletStats struct {
+	CredentialsAdded int
+	DIDsCreated      int
+	// DIDsByMethod counts the DIDs created by method e.g. how many did:key vs did:peer identities exist
+	DIDsByMethod map[did.Method]int
+	KeysStored   int
+	// SignersCreated counts the signers constructed with NewSigner, not those PresentCredentials signs with
+	SignersCreated int
+	// SignaturesProduced counts the credentials and presentations signed with IssueCredential and PresentCredentials
+	SignaturesProduced int
 }
 
 type WalletKeys struct {
-	ID  string	`json:"id"`
-	Key gocrypto.PrivateKey	`json:"key"`
+	ID  string              `json:"id"`
+	Key gocrypto.PrivateKey `json:"key"`
+	// KeyRef, set in place of Key, is the URI of a key held outside the wallet, such as in a KMS, which signs with
+	// the RemoteSigner registered for the URI's scheme
+	KeyRef string `json:"keyRef,omitempty"`
+	// Retired keys are no longer used to issue credentials, having been replaced by RotateSigningKey
+	Retired bool `json:"retired,omitempty"`
+}
+
+// IsRemote returns whether the key is held outside the wallet, referenced by its KeyRef
+func (k WalletKeys) IsRemote() bool {
+	return k.KeyRef != ""
 }
 
 func NewSimpleWallet() *SimpleWallet {
@@ -37,6 +75,20 @@ func NewSimpleWallet() *SimpleWallet {
 	}
 }
 
+// SetRegistry sets the registry the documents of DIDs created by Init are registered with, in place of
+// DefaultRegistry
+func (s *SimpleWallet) SetRegistry(registry *Registry) {
+	s.registry = registry
+}
+
+// Registry returns the registry the documents of DIDs created by Init are registered with
+func (s *SimpleWallet) Registry() *Registry {
+	if s.registry == nil {
+		return DefaultRegistry
+	}
+	return s.registry
+}
+
 func LoadSimpleWallet() (*SimpleWallet, error) {
 	file, err := os.Open("wallet.json")
 	if err != nil {
@@ -57,18 +109,43 @@ func LoadSimpleWallet() (*SimpleWallet, error) {
 	return &wallet, nil
 }
 
-
-
 func (s *SimpleWallet) AddDID(id string) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 	if _, ok := s.dids[id]; ok {
 		return errors.New("already an entry")
 	}
-	s.dids[id] = make([]WalletKeys, 0)
+	s.addDID(id)
 	return nil
 }
 
+// EnsureDID adds the DID to the wallet if it is not already present, reporting whether it was created. Unlike
+// AddDID, an existing entry is not an error, so initialization code can safely run more than once.
+func (s *SimpleWallet) EnsureDID(id string) (created bool, err error) {
+	if id == "" {
+		return false, errors.New("did cannot be empty")
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.dids[id]; ok {
+		return false, nil
+	}
+	s.addDID(id)
+	return true, nil
+}
+
+// addDID creates an empty entry for the DID; callers must hold the lock
+func (s *SimpleWallet) addDID(id string) {
+	s.dids[id] = make([]WalletKeys, 0)
+	s.stats.DIDsCreated++
+	if method, err := resolution.GetMethodForDID(id); err == nil {
+		if s.stats.DIDsByMethod == nil {
+			s.stats.DIDsByMethod = make(map[did.Method]int)
+		}
+		s.stats.DIDsByMethod[method]++
+	}
+}
+
 func (s *SimpleWallet) GetDIDs() []string {
 	s.mux.Lock()
 	defer s.mux.Unlock()
@@ -97,15 +174,21 @@ func (s *SimpleWallet) AddPrivateKey(id, kid string, pubKey gocrypto.PrivateKey)
 		Key: pubKey,
 	})
 	s.dids[id] = walletKeys
+	s.stats.KeysStored++
 	return nil
 }
 
+// GetKey returns the private key with the given kid. A key held outside the wallet has no private key to return, so
+// it fails with an error wrapping ErrRemoteKey.
 func (s *SimpleWallet) GetKey(kid string) (string, gocrypto.PrivateKey, error) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 	for _, d := range s.dids {
 		for _, k := range d {
 			if k.ID == kid {
+				if k.IsRemote() {
+					return "", nil, fmt.Errorf("key<%s> is held at %s: %w", kid, k.KeyRef, ErrRemoteKey)
+				}
 				return k.ID, k.Key, nil
 			}
 		}
@@ -113,6 +196,34 @@ func (s *SimpleWallet) GetKey(kid string) (string, gocrypto.PrivateKey, error) {
 	return "", nil, fmt.Errorf("key<%s> not found", kid)
 }
 
+// NewSigner constructs a JWX signer for the key with the given kid, with its algorithm inferred from the key type
+// and its ID set to the DID that owns the key. Keys held outside the wallet sign with their RemoteSigner.
+func (s *SimpleWallet) NewSigner(kid string) (*jwx.Signer, error) {
+	s.mux.Lock()
+	var id string
+	var key *WalletKeys
+	for didStr, d := range s.dids {
+		for _, k := range d {
+			if k.ID == kid {
+				id, key = didStr, &k
+				break
+			}
+		}
+	}
+	s.mux.Unlock()
+	if key == nil {
+		return nil, fmt.Errorf("key<%s> not found", kid)
+	}
+	signer, err := s.keySigner(id, *key)
+	if err != nil {
+		return nil, fmt.Errorf("constructing signer for key<%s>: %w", kid, err)
+	}
+	s.mux.Lock()
+	s.stats.SignersCreated++
+	s.mux.Unlock()
+	return signer, nil
+}
+
 func (s *SimpleWallet) GetKeysForDID(id string) ([]WalletKeys, error) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
@@ -133,60 +244,442 @@ func (s *SimpleWallet) AddCredentialJWT(credID, cred string) error {
 		return fmt.Errorf("duplicate credential<%s>; could not add", credID)
 	}
 	s.vcs[credID] = cred
+	s.stats.CredentialsAdded++
 	return nil
 }
 
+// Stats returns a snapshot of the wallet's operation counters. Unlike Size, which counts the credentials currently
+// stored, the counters only ever increase.
+func (s *SimpleWallet) Stats() WalletStats {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	stats := s.stats
+	stats.DIDsByMethod = make(map[did.Method]int, len(s.stats.DIDsByMethod))
+	for method, count := range s.stats.DIDsByMethod {
+		stats.DIDsByMethod[method] = count
+	}
+	return stats
+}
+
+// PruneExpired removes all stored credentials whose `exp` claim is before the given time, returning the IDs of the
+// removed credentials. Credentials without an `exp` claim are kept. Credentials that cannot be parsed are never
+// removed, to avoid losing data on malformed entries; they are reported in the returned error instead.
+func (s *SimpleWallet) PruneExpired(now time.Time) (removed []string, err error) {
+	if s.mux == nil {
+		return nil, errors.New("no mux for wallet")
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	errs := util.NewAppendError()
+	for credID, cred := range s.vcs {
+		parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(cred)
+		if err != nil {
+			errs.Append(fmt.Errorf("credential<%s> could not be parsed: %w", credID, err))
+			continue
+		}
+		token := parsed.Trust().Token
+		exp := token.Expiration()
+		if !exp.IsZero() && exp.Before(now) {
+			delete(s.vcs, credID)
+			removed = append(removed, credID)
+		}
+	}
+	sort.Strings(removed)
+	return removed, errs.Error()
+}
+
+// CredentialFilter selects the credentials FindCredentials returns. Empty fields match every credential.
+type CredentialFilter struct {
+	// Type is a type the credential must have
+	Type   string
+	Issuer string
+	// Subject is the id of one of the credential's subjects
+	Subject string
+	// ExpiresBefore and ExpiresAfter bound the credential's exp claim. Credentials without one never expire, so
+	// they match ExpiresAfter but not ExpiresBefore.
+	ExpiresBefore time.Time
+	ExpiresAfter  time.Time
+	// Offset is how many matching credentials to skip, and Limit how many to return at most, for paging through
+	// the credentials in the order of their IDs. A Limit of zero returns all matching credentials.
+	Offset int
+	Limit  int
+}
+
+// StoredCredential is a credential stored in the wallet, with a summary of its JWT claims
+type StoredCredential struct {
+	ID      string
+	Summary CredentialSummary
+	Token   string
+}
+
+// CredentialSummary holds the claims of a credential that are commonly filtered or displayed on
+type CredentialSummary struct {
+	Issuer string
+	Types  []string
+	// Subjects are the ids of the credential's subjects that have one
+	Subjects []string
+	// Expiration is zero for credentials without an exp claim
+	Expiration time.Time
+}
+
+// FindCredentials returns the stored credentials matching the filter, in the order of their IDs. Credentials are
+// parsed one at a time until the page is full, and credentials that cannot be parsed are skipped rather than failing
+// the search; they are reported, with their count, in the returned error alongside the credentials found.
+func (s *SimpleWallet) FindCredentials(filter CredentialFilter) ([]StoredCredential, error) {
+	if s.mux == nil {
+		return nil, errors.New("no mux for wallet")
+	}
+	if filter.Offset < 0 || filter.Limit < 0 {
+		return nil, fmt.Errorf("offset<%d> and limit<%d> cannot be negative", filter.Offset, filter.Limit)
+	}
+
+	s.mux.Lock()
+	credIDs := make([]string, 0, len(s.vcs))
+	tokens := make(map[string]string, len(s.vcs))
+	for credID, cred := range s.vcs {
+		credIDs = append(credIDs, credID)
+		tokens[credID] = cred
+	}
+	s.mux.Unlock()
+	sort.Strings(credIDs)
+
+	var found []StoredCredential
+	malformed := util.NewAppendError()
+	skipped := 0
+	for _, credID := range credIDs {
+		if filter.Limit > 0 && len(found) == filter.Limit {
+			break
+		}
+		parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(tokens[credID])
+		if err != nil {
+			malformed.Append(fmt.Errorf("credential<%s> could not be parsed: %w", credID, err))
+			continue
+		}
+		// the summary only describes the credential to the holder, see VerifyStoredCredential to verify it
+		token, cred := parsed.Trust().Token, parsed.Trust().Credential
+		types, _ := util.InterfaceToStrings(cred.Type)
+		summary := CredentialSummary{
+			Issuer:     cred.IssuerID(),
+			Types:      types,
+			Subjects:   subjectIDs(*cred),
+			Expiration: token.Expiration(),
+		}
+		if !filter.matches(summary) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		found = append(found, StoredCredential{ID: credID, Summary: summary, Token: tokens[credID]})
+	}
+	if !malformed.IsEmpty() {
+		return found, fmt.Errorf("skipped %d malformed credential(s): %w", malformed.NumErrors(), malformed.Error())
+	}
+	return found, nil
+}
+
+// CredentialsWhereSubjectIs returns the IDs, in order, of the stored credentials with a subject whose id is the DID,
+// which are the credentials the DID can present about itself. A credential issued to several subjects matches when
+// any of them is the DID. Credentials that cannot be parsed are skipped and reported as by FindCredentials.
+func (s *SimpleWallet) CredentialsWhereSubjectIs(subjectDID string) ([]string, error) {
+	if subjectDID == "" {
+		return nil, errors.New("subject DID cannot be empty")
+	}
+	found, err := s.FindCredentials(CredentialFilter{Subject: subjectDID})
+	credIDs := make([]string, 0, len(found))
+	for _, cred := range found {
+		credIDs = append(credIDs, cred.ID)
+	}
+	return credIDs, err
+}
+
+// subjectIDs returns the ids of the subjects of a credential, whether it has one subject or several
+func subjectIDs(cred credential.VerifiableCredential) []string {
+	subjects := cred.CredentialSubjects
+	if subjects == nil {
+		subjects = []credential.CredentialSubject{cred.CredentialSubject}
+	}
+	var ids []string
+	for _, subject := range subjects {
+		if id := subject.GetID(); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (f CredentialFilter) matches(summary CredentialSummary) bool {
+	if f.Issuer != "" && summary.Issuer != f.Issuer {
+		return false
+	}
+	if f.Subject != "" && !util.Contains(f.Subject, summary.Subjects) {
+		return false
+	}
+	if f.Type != "" {
+		hasType := false
+		for _, t := range summary.Types {
+			if t == f.Type {
+				hasType = true
+				break
+			}
+		}
+		if !hasType {
+			return false
+		}
+	}
+	if !f.ExpiresBefore.IsZero() && (summary.Expiration.IsZero() || !summary.Expiration.Before(f.ExpiresBefore)) {
+		return false
+	}
+	if !f.ExpiresAfter.IsZero() && !summary.Expiration.IsZero() && !summary.Expiration.After(f.ExpiresAfter) {
+		return false
+	}
+	return true
+}
+
+// PresentCredentials signs a presentation, held by the DID, of the credential JWTs stored under the given IDs. Each
+// credential must parse and must not have expired; the returned error names every credential that does not. The
+// presentation is signed with the holder's first key that can sign.
+func (s *SimpleWallet) PresentCredentials(holderDID string, credIDs []string, params *integrity.JWTVVPParameters) ([]byte, error) {
+	if s.mux == nil {
+		return nil, errors.New("no mux for wallet")
+	}
+	if len(credIDs) == 0 {
+		return nil, errors.New("no credentials to present")
+	}
+
+	s.mux.Lock()
+	keys, ok := s.dids[holderDID]
+	if !ok {
+		s.mux.Unlock()
+		return nil, fmt.Errorf("did<%s> not found", holderDID)
+	}
+	now := time.Now()
+	errs := util.NewAppendError()
+	creds := make([]any, 0, len(credIDs))
+	for _, credID := range credIDs {
+		cred, ok := s.vcs[credID]
+		if !ok {
+			errs.Append(fmt.Errorf("credential<%s> not found", credID))
+			continue
+		}
+		parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(cred)
+		if err != nil {
+			errs.Append(fmt.Errorf("credential<%s> could not be parsed: %w", credID, err))
+			continue
+		}
+		token := parsed.Trust().Token
+		if exp := token.Expiration(); !exp.IsZero() && exp.Before(now) {
+			errs.Append(fmt.Errorf("credential<%s> expired at %s", credID, exp.Format(time.RFC3339)))
+			continue
+		}
+		creds = append(creds, cred)
+	}
+	keys = append([]WalletKeys(nil), keys...)
+	s.mux.Unlock()
+	if err := errs.Error(); err != nil {
+		return nil, fmt.Errorf("presenting credentials: %w", err)
+	}
+
+	var signer *jwx.Signer
+	for _, k := range keys {
+		if keySigner, err := s.keySigner(holderDID, k); err == nil {
+			signer = keySigner
+			break
+		}
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("did<%s> has no key that can sign", holderDID)
+	}
+
+	builder := credential.NewVerifiablePresentationBuilder()
+	if err := builder.SetHolder(holderDID); err != nil {
+		return nil, fmt.Errorf("setting holder: %w", err)
+	}
+	if err := builder.AddVerifiableCredentials(creds...); err != nil {
+		return nil, fmt.Errorf("adding credentials: %w", err)
+	}
+	presentation, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("building presentation: %w", err)
+	}
+	signed, err := integrity.SignVerifiablePresentationJWT(*signer, params, *presentation)
+	if err != nil {
+		return nil, err
+	}
+	s.mux.Lock()
+	s.stats.SignaturesProduced++
+	s.mux.Unlock()
+	return signed, nil
+}
+
+// IssueCredential issues a credential about the subject from one of the wallet's DIDs, signed as a JWT with the key
+// of the DID's assertionMethod relationship that is not retired. The credential's id and issuanceDate are generated,
+// and its issuer is the DID. The DID's document is the one registered in the wallet's registry, or else resolved as
+// a did:key or did:peer.
+func (s *SimpleWallet) IssueCredential(issuerDID string, subject map[string]any, opts ...integrity.SignOption) ([]byte, error) {
+	if s.mux == nil {
+		return nil, errors.New("no mux for wallet")
+	}
+	keys, err := s.GetKeysForDID(issuerDID)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := s.Registry().Resolve(context.Background(), issuerDID)
+	if errors.Is(err, ErrNotRegistered) {
+		var resolver *resolution.MultiMethodResolver
+		if resolver, err = resolution.NewResolver(key.Resolver{}, peer.Resolver{}); err != nil {
+			return nil, fmt.Errorf("constructing resolver: %w", err)
+		}
+		resolved, err = resolver.Resolve(context.Background(), issuerDID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolving did<%s>: %w", issuerDID, err)
+	}
+
+	var signer *jwx.Signer
+	var signingKID string
+	for _, k := range keys {
+		if k.Retired {
+			continue
+		}
+		isAssertionKey, err := did.HasVerificationMethodForPurpose(resolved.Document, k.ID, did.AssertionMethod)
+		if err != nil {
+			return nil, fmt.Errorf("finding assertion keys of did<%s>: %w", issuerDID, err)
+		}
+		if !isAssertionKey {
+			continue
+		}
+		if signer, err = s.keySigner(issuerDID, k); err != nil {
+			return nil, fmt.Errorf("constructing signer for key<%s>: %w", k.ID, err)
+		}
+		signingKID = k.ID
+		break
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("did<%s> has no assertionMethod key in the wallet", issuerDID)
+	}
+
+	builder := credential.NewVerifiableCredentialBuilder(credential.GenerateIDValue)
+	if err = builder.SetIssuer(issuerDID); err != nil {
+		return nil, fmt.Errorf("setting issuer: %w", err)
+	}
+	if err = builder.SetCredentialSubject(subject); err != nil {
+		return nil, fmt.Errorf("setting credential subject: %w", err)
+	}
+	cred, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("building credential: %w", err)
+	}
+	signed, err := integrity.SignVerifiableCredentialJWT(*signer, *cred, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.stats.SignaturesProduced++
+	if s.issued == nil {
+		s.issued = make(map[string]string)
+	}
+	s.issued[cred.ID] = signingKID
+	return signed, nil
+}
+
 // Init stores a DID for a particular user and adds it to the registry
 func (s *SimpleWallet) Init(didMethod did.Method) error {
+	_, _, err := s.InitReturning(didMethod)
+	return err
+}
+
+// InitReturning stores a DID for a particular user and adds it to the registry, returning the created DID
+// and the id of its primary key so that they can be used for signing right away
+func (s *SimpleWallet) InitReturning(didMethod did.Method) (didStr, kid string, err error) {
+	didStr, kid, _, err = s.initDID(didMethod, false)
+	return didStr, kid, err
+}
+
+// InitWithKeyAgreement stores a DID for a particular user along with both its Ed25519 authentication key and its
+// X25519 key agreement key, for use with encryption such as JWE or DIDComm. The created DID and the ids of both keys
+// are returned. Only did:key is supported, whose key agreement key is derived from its Ed25519 key.
+func (s *SimpleWallet) InitWithKeyAgreement(didMethod did.Method) (didStr, kid, keyAgreementKID string, err error) {
+	return s.initDID(didMethod, true)
+}
+
+func (s *SimpleWallet) initDID(didMethod did.Method, withKeyAgreement bool) (didStr, kid, keyAgreementKID string, err error) {
 	var privKey gocrypto.PrivateKey
 	var pubKey gocrypto.PublicKey
+	var keyAgreementKey gocrypto.PrivateKey
+	var doc did.Document
 
-	var didStr string
-	var kid string
-	var err error
 	switch didMethod {
 	case did.PeerMethod:
+		if withKeyAgreement {
+			return "", "", "", fmt.Errorf("key agreement keys are not supported for did method<%s>", didMethod)
+		}
 		kt := crypto.Ed25519
 		pubKey, privKey, err = crypto.GenerateKeyByKeyType(kt)
 		if err != nil {
-			return err
+			return "", "", "", err
 		}
 		didPeer, err := peer.Method0{}.Generate(kt, pubKey)
 		if err != nil {
-			return err
+			return "", "", "", err
 		}
 		didStr = didPeer.String()
 		resolvedPeer, err := peer.Resolver{}.Resolve(context.Background(), didPeer.String())
 		if err != nil {
-			return err
+			return "", "", "", err
 		}
 		kid = resolvedPeer.VerificationMethod[0].ID
+		doc = resolvedPeer.Document
 	case did.KeyMethod:
 		var didKey *key.DIDKey
 		privKey, didKey, err = key.GenerateDIDKey(crypto.Ed25519)
 		if err != nil {
-			return err
+			return "", "", "", err
 		}
 		didStr = didKey.String()
 		expanded, err := didKey.Expand()
 		if err != nil {
-			return err
+			return "", "", "", err
 		}
 		kid = expanded.VerificationMethod[0].ID
+		doc = *expanded
+		if withKeyAgreement {
+			if len(expanded.KeyAgreement) != 1 {
+				return "", "", "", fmt.Errorf("expected one key agreement key for did<%s>", didStr)
+			}
+			keyAgreementKID, _ = expanded.KeyAgreement[0].(string)
+			keyAgreementKey, err = key.DeriveKeyAgreementPrivateKey(privKey.(ed25519.PrivateKey))
+			if err != nil {
+				return "", "", "", err
+			}
+		}
 	default:
-		return fmt.Errorf("unsupported did method<%s>", didMethod)
+		return "", "", "", fmt.Errorf("unsupported did method<%s>", didMethod)
 	}
 
 	WriteNote(fmt.Sprintf("DID for holder is: %s", didStr))
 	if err = s.AddDID(didStr); err != nil {
-		return err
+		return "", "", "", err
 	}
 	WriteNote(fmt.Sprintf("DID stored in wallet"))
 	if err = s.AddPrivateKey(didStr, kid, privKey); err != nil {
-		return err
+		return "", "", "", err
 	}
 	WriteNote(fmt.Sprintf("Private Key stored with wallet"))
-	return nil
+	if keyAgreementKey != nil {
+		if err = s.AddPrivateKey(didStr, keyAgreementKID, keyAgreementKey); err != nil {
+			return "", "", "", err
+		}
+		WriteNote(fmt.Sprintf("Key Agreement Private Key stored with wallet"))
+	}
+	if err = s.Registry().Register(doc); err != nil {
+		return "", "", "", err
+	}
+	WriteNote(fmt.Sprintf("DID Document registered"))
+	return didStr, kid, keyAgreementKID, nil
 }
 
 func (s *SimpleWallet) Size() int {
@@ -197,12 +690,12 @@ func (s *SimpleWallet) MarshalJSON() ([]byte, error) {
 	type Alias SimpleWallet
 
 	return json.Marshal(&struct {
-		Vcs map[string]string `json:"vcs"`
+		Vcs  map[string]string       `json:"vcs"`
 		Dids map[string][]WalletKeys `json:"dids"`
 		*Alias
 	}{
-		Vcs: s.vcs,
-		Dids: s.dids,
+		Vcs:   s.vcs,
+		Dids:  s.dids,
 		Alias: (*Alias)(s),
 	})
 }
@@ -212,7 +705,7 @@ func (s *SimpleWallet) UnmarshalJSON(data []byte) error {
 	type Alias SimpleWallet
 
 	temp := &struct {
-		Vcs  map[string]string     `json:"vcs"`
+		Vcs  map[string]string       `json:"vcs"`
 		Dids map[string][]WalletKeys `json:"dids"`
 		*Alias
 	}{
@@ -241,7 +734,7 @@ func (s *SimpleWallet) SaveToFile() error {
 			fmt.Println("Key ID:", key.ID)
 		}
 	}
-	
+
 	// Open a file for writing
 	file, err := os.Create("wallet.json")
 	if err != nil {
@@ -249,7 +742,7 @@ func (s *SimpleWallet) SaveToFile() error {
 		return err
 	}
 	defer file.Close()
-	
+
 	encoder := json.NewEncoder(file)
 	if err := encoder.Encode(s); err != nil {
 		fmt.Println("Error encoding wallet:", err)
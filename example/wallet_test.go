@@ -0,0 +1,482 @@
+package example
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/peer"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimpleWalletInitReturning(t *testing.T) {
+	t.Run("did:key", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		didStr, kid, err := w.InitReturning(did.KeyMethod)
+		assert.NoError(tt, err)
+		assert.Contains(tt, didStr, "did:key:")
+		assert.Contains(tt, kid, didStr)
+
+		assert.Equal(tt, []string{didStr}, w.GetDIDs())
+		gotKID, privKey, err := w.GetKey(kid)
+		assert.NoError(tt, err)
+		assert.Equal(tt, kid, gotKID)
+		assert.NotEmpty(tt, privKey)
+	})
+
+	t.Run("did:peer", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		didStr, kid, err := w.InitReturning(did.PeerMethod)
+		assert.NoError(tt, err)
+		assert.Contains(tt, didStr, "did:peer:")
+		assert.NotEmpty(tt, kid)
+
+		keys, err := w.GetKeysForDID(didStr)
+		assert.NoError(tt, err)
+		assert.Len(tt, keys, 1)
+		assert.Equal(tt, kid, keys[0].ID)
+	})
+
+	t.Run("registers the created DID", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		assert.Equal(tt, DefaultRegistry, w.Registry())
+		registry := NewRegistry()
+		w.SetRegistry(registry)
+		for _, method := range []did.Method{did.KeyMethod, did.PeerMethod} {
+			didStr, kid, err := w.InitReturning(method)
+			require.NoError(tt, err)
+			resolved, err := registry.Resolve(context.Background(), didStr)
+			require.NoError(tt, err)
+			assert.Equal(tt, didStr, resolved.Document.ID)
+			_, err = did.GetKeyFromVerificationMethod(resolved.Document, kid)
+			assert.NoError(tt, err)
+		}
+		assert.Equal(tt, 2, registry.Size())
+	})
+
+	t.Run("unsupported method", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		_, _, err := w.InitReturning(did.WebMethod)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unsupported did method")
+		assert.Empty(tt, w.GetDIDs())
+	})
+}
+
+func TestSimpleWalletPruneExpired(t *testing.T) {
+	w := NewSimpleWallet()
+	didStr, kid, err := w.InitReturning(did.KeyMethod)
+	require.NoError(t, err)
+	_, privKey, err := w.GetKey(kid)
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner(didStr, &kid, privKey)
+	require.NoError(t, err)
+
+	now := time.Now()
+	signCredential := func(id string, expiration time.Time) string {
+		cred := credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                id,
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            didStr,
+			IssuanceDate:      now.Add(-48 * time.Hour).Format(time.RFC3339),
+			CredentialSubject: map[string]any{"id": didStr},
+		}
+		if !expiration.IsZero() {
+			cred.ExpirationDate = expiration.Format(time.RFC3339)
+		}
+		signed, err := integrity.SignVerifiableCredentialJWT(*signer, cred)
+		require.NoError(t, err)
+		return string(signed)
+	}
+
+	require.NoError(t, w.AddCredentialJWT("expired", signCredential("expired", now.Add(-time.Hour))))
+	require.NoError(t, w.AddCredentialJWT("valid", signCredential("valid", now.Add(time.Hour))))
+	require.NoError(t, w.AddCredentialJWT("no-expiry", signCredential("no-expiry", time.Time{})))
+	require.NoError(t, w.AddCredentialJWT("malformed", "not-a-jwt"))
+
+	removed, err := w.PruneExpired(now)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "credential<malformed> could not be parsed")
+	assert.Equal(t, []string{"expired"}, removed)
+	assert.Equal(t, 3, w.Size())
+
+	// nothing else is removed on a second pass
+	removed, err = w.PruneExpired(now)
+	assert.Error(t, err)
+	assert.Empty(t, removed)
+	assert.Equal(t, 3, w.Size())
+}
+
+func TestSimpleWalletFindCredentials(t *testing.T) {
+	w := NewSimpleWallet()
+	didStr, kid, err := w.InitReturning(did.KeyMethod)
+	require.NoError(t, err)
+	_, privKey, err := w.GetKey(kid)
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner(didStr, &kid, privKey)
+	require.NoError(t, err)
+
+	now := time.Now().Truncate(time.Second)
+	signCredential := func(issuer, credType string, expiration time.Time) string {
+		cred := credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential", credType},
+			Issuer:            issuer,
+			IssuanceDate:      now.Add(-48 * time.Hour).Format(time.RFC3339),
+			CredentialSubject: map[string]any{"id": didStr},
+		}
+		if !expiration.IsZero() {
+			cred.ExpirationDate = expiration.Format(time.RFC3339)
+		}
+		signed, err := integrity.SignVerifiableCredentialJWT(*signer, cred)
+		require.NoError(t, err)
+		return string(signed)
+	}
+	require.NoError(t, w.AddCredentialJWT("a-degree", signCredential(didStr, "UniversityDegree", now.Add(time.Hour))))
+	require.NoError(t, w.AddCredentialJWT("b-license", signCredential("did:example:dmv", "DriverLicense", now.Add(48*time.Hour))))
+	require.NoError(t, w.AddCredentialJWT("c-degree", signCredential(didStr, "UniversityDegree", time.Time{})))
+	require.NoError(t, w.AddCredentialJWT("d-malformed", "not-a-jwt"))
+
+	ids := func(found []StoredCredential) []string {
+		var credIDs []string
+		for _, cred := range found {
+			credIDs = append(credIDs, cred.ID)
+		}
+		return credIDs
+	}
+
+	t.Run("filters", func(tt *testing.T) {
+		// the malformed credential is reached by searches that do not fill a page
+		found, err := w.FindCredentials(CredentialFilter{Type: "UniversityDegree"})
+		assert.ErrorContains(tt, err, "credential<d-malformed> could not be parsed")
+		assert.Equal(tt, []string{"a-degree", "c-degree"}, ids(found))
+		assert.Equal(tt, didStr, found[0].Summary.Issuer)
+		assert.Equal(tt, []string{"VerifiableCredential", "UniversityDegree"}, found[0].Summary.Types)
+		assert.Equal(tt, now.Add(time.Hour).Unix(), found[0].Summary.Expiration.Unix())
+		assert.True(tt, found[1].Summary.Expiration.IsZero())
+
+		found, err = w.FindCredentials(CredentialFilter{Issuer: "did:example:dmv"})
+		assert.ErrorContains(tt, err, "credential<d-malformed> could not be parsed")
+		assert.Equal(tt, []string{"b-license"}, ids(found))
+
+		found, err = w.FindCredentials(CredentialFilter{ExpiresBefore: now.Add(24 * time.Hour), Limit: 3})
+		assert.ErrorContains(tt, err, "credential<d-malformed> could not be parsed")
+		assert.Equal(tt, []string{"a-degree"}, ids(found))
+
+		// while it is never parsed once the page is full
+		found, err = w.FindCredentials(CredentialFilter{ExpiresAfter: now.Add(24 * time.Hour), Limit: 2})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{"b-license", "c-degree"}, ids(found))
+	})
+
+	t.Run("pages", func(tt *testing.T) {
+		found, err := w.FindCredentials(CredentialFilter{Limit: 2})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{"a-degree", "b-license"}, ids(found))
+		assert.NotEmpty(tt, found[0].Token)
+
+		found, err = w.FindCredentials(CredentialFilter{Offset: 2, Limit: 2})
+		assert.ErrorContains(tt, err, "skipped 1 malformed credential(s): credential<d-malformed> could not be parsed")
+		assert.Equal(tt, []string{"c-degree"}, ids(found))
+
+		_, err = w.FindCredentials(CredentialFilter{Offset: -1})
+		assert.ErrorContains(tt, err, "offset<-1> and limit<0> cannot be negative")
+	})
+}
+
+func TestSimpleWalletCredentialsWhereSubjectIs(t *testing.T) {
+	w := NewSimpleWallet()
+	didStr, kid, err := w.InitReturning(did.KeyMethod)
+	require.NoError(t, err)
+	_, privKey, err := w.GetKey(kid)
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner(didStr, &kid, privKey)
+	require.NoError(t, err)
+
+	signCredential := func(subjects ...string) string {
+		cred := credential.VerifiableCredential{
+			Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:         []string{"VerifiableCredential"},
+			Issuer:       didStr,
+			IssuanceDate: "2021-01-01T00:00:00Z",
+		}
+		for _, subject := range subjects {
+			cred.CredentialSubjects = append(cred.CredentialSubjects, map[string]any{"id": subject})
+		}
+		if len(subjects) == 1 {
+			cred.CredentialSubject, cred.CredentialSubjects = cred.CredentialSubjects[0], nil
+		}
+		signed, err := integrity.SignVerifiableCredentialJWT(*signer, cred)
+		require.NoError(t, err)
+		return string(signed)
+	}
+	require.NoError(t, w.AddCredentialJWT("a-mine", signCredential(didStr)))
+	require.NoError(t, w.AddCredentialJWT("b-other", signCredential("did:example:other")))
+	require.NoError(t, w.AddCredentialJWT("c-shared", signCredential("did:example:other", didStr)))
+	require.NoError(t, w.AddCredentialJWT("d-malformed", "not-a-jwt"))
+
+	credIDs, err := w.CredentialsWhereSubjectIs(didStr)
+	assert.ErrorContains(t, err, "credential<d-malformed> could not be parsed")
+	assert.Equal(t, []string{"a-mine", "c-shared"}, credIDs)
+
+	credIDs, err = w.CredentialsWhereSubjectIs("did:example:other")
+	assert.ErrorContains(t, err, "credential<d-malformed> could not be parsed")
+	assert.Equal(t, []string{"b-other", "c-shared"}, credIDs)
+
+	credIDs, err = w.CredentialsWhereSubjectIs("did:example:unknown")
+	assert.ErrorContains(t, err, "skipped 1 malformed credential(s)")
+	assert.Empty(t, credIDs)
+
+	_, err = w.CredentialsWhereSubjectIs("")
+	assert.ErrorContains(t, err, "subject DID cannot be empty")
+}
+
+func TestSimpleWalletPresentCredentials(t *testing.T) {
+	w := NewSimpleWallet()
+	didStr, kid, _, err := w.InitWithKeyAgreement(did.KeyMethod)
+	require.NoError(t, err)
+	signer, err := w.NewSigner(kid)
+	require.NoError(t, err)
+
+	now := time.Now()
+	signCredential := func(id string, expiration time.Time) string {
+		signed, err := integrity.SignVerifiableCredentialJWT(*signer, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                id,
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            didStr,
+			IssuanceDate:      now.Add(-48 * time.Hour).Format(time.RFC3339),
+			ExpirationDate:    expiration.Format(time.RFC3339),
+			CredentialSubject: map[string]any{"id": didStr},
+		})
+		require.NoError(t, err)
+		return string(signed)
+	}
+	require.NoError(t, w.AddCredentialJWT("valid-1", signCredential("valid-1", now.Add(time.Hour))))
+	require.NoError(t, w.AddCredentialJWT("valid-2", signCredential("valid-2", now.Add(time.Hour))))
+	require.NoError(t, w.AddCredentialJWT("expired", signCredential("expired", now.Add(-time.Hour))))
+	require.NoError(t, w.AddCredentialJWT("malformed", "not-a-jwt"))
+
+	t.Run("signs a presentation of the credentials", func(tt *testing.T) {
+		presentation, err := w.PresentCredentials(didStr, []string{"valid-1", "valid-2"},
+			&integrity.JWTVVPParameters{Audience: []string{"did:example:verifier"}})
+		require.NoError(tt, err)
+
+		resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+		require.NoError(tt, err)
+		verifier, err := signer.ToVerifier("did:example:verifier")
+		require.NoError(tt, err)
+		_, _, vp, err := integrity.VerifyVerifiablePresentationJWT(context.Background(), *verifier, resolver, string(presentation))
+		assert.NoError(tt, err)
+		assert.Equal(tt, didStr, vp.Holder)
+		assert.Len(tt, vp.VerifiableCredential, 2)
+	})
+
+	t.Run("problematic credentials are named", func(tt *testing.T) {
+		_, err := w.PresentCredentials(didStr, []string{"valid-1", "expired", "malformed", "unknown"}, nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential<expired> expired at")
+		assert.Contains(tt, err.Error(), "credential<malformed> could not be parsed")
+		assert.Contains(tt, err.Error(), "credential<unknown> not found")
+	})
+
+	t.Run("holder without a signing key", func(tt *testing.T) {
+		_, err := w.PresentCredentials("did:example:unknown", []string{"valid-1"}, nil)
+		assert.ErrorContains(tt, err, "did<did:example:unknown> not found")
+
+		require.NoError(tt, w.AddDID("did:example:123"))
+		_, err = w.PresentCredentials("did:example:123", []string{"valid-1"}, nil)
+		assert.ErrorContains(tt, err, "did<did:example:123> has no key that can sign")
+
+		_, err = w.PresentCredentials(didStr, nil, nil)
+		assert.ErrorContains(tt, err, "no credentials to present")
+	})
+}
+
+func TestSimpleWalletIssueCredential(t *testing.T) {
+	resolver, err := resolution.NewResolver(key.Resolver{}, peer.Resolver{})
+	require.NoError(t, err)
+
+	for _, method := range []did.Method{did.KeyMethod, did.PeerMethod} {
+		t.Run(string(method), func(tt *testing.T) {
+			w := NewSimpleWallet()
+			issuerDID, _, err := w.InitReturning(method)
+			require.NoError(tt, err)
+
+			token, err := w.IssueCredential(issuerDID, map[string]any{"id": "did:example:456", "degree": "BSc"})
+			require.NoError(tt, err)
+			verified, err := integrity.VerifyJWTCredential(context.Background(), string(token), resolver)
+			require.NoError(tt, err)
+			assert.True(tt, verified)
+			parsed, err := integrity.ParseVerifiableCredentialFromJWTUnverified(string(token))
+			require.NoError(tt, err)
+			cred := parsed.Trust().Credential
+			assert.Equal(tt, issuerDID, cred.Issuer)
+			assert.NotEmpty(tt, cred.ID)
+			assert.NotEmpty(tt, cred.IssuanceDate)
+			assert.Equal(tt, "BSc", cred.CredentialSubject["degree"])
+			assert.Equal(tt, "did:example:456", cred.CredentialSubject.GetID())
+		})
+	}
+
+	t.Run("issuer without an assertion key", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		_, err := w.IssueCredential("did:example:unknown", map[string]any{"id": "did:example:456"})
+		assert.ErrorContains(tt, err, "id<did:example:unknown> not found")
+
+		issuerDID, _, keyAgreementKID, err := w.InitWithKeyAgreement(did.KeyMethod)
+		require.NoError(tt, err)
+		keys, err := w.GetKeysForDID(issuerDID)
+		require.NoError(tt, err)
+		other := NewSimpleWallet()
+		require.NoError(tt, other.AddDID(issuerDID))
+		require.NoError(tt, other.AddPrivateKey(issuerDID, keyAgreementKID, keys[1].Key))
+		_, err = other.IssueCredential(issuerDID, map[string]any{"id": "did:example:456"})
+		assert.ErrorContains(tt, err, "has no assertionMethod key in the wallet")
+	})
+}
+
+func TestSimpleWalletNewSigner(t *testing.T) {
+	t.Run("signs with the wallet key", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		didStr, kid, err := w.InitReturning(did.KeyMethod)
+		require.NoError(tt, err)
+
+		signer, err := w.NewSigner(kid)
+		assert.NoError(tt, err)
+		assert.Equal(tt, didStr, signer.ID)
+		assert.Equal(tt, kid, signer.KID)
+		assert.Equal(tt, crypto.Ed25519.String(), signer.ALG)
+
+		token, err := signer.SignWithDefaults(map[string]any{"test": "value"})
+		assert.NoError(tt, err)
+		verifier, err := signer.ToVerifier(didStr)
+		assert.NoError(tt, err)
+		assert.NoError(tt, verifier.Verify(string(token)))
+	})
+
+	t.Run("unknown kid", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		_, _, err := w.InitReturning(did.KeyMethod)
+		require.NoError(tt, err)
+
+		_, err = w.NewSigner("did:key:unknown#unknown")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "not found")
+	})
+
+	t.Run("key not usable for signing", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		require.NoError(tt, w.AddDID("did:example:123"))
+		_, privKey, err := crypto.GenerateX25519Key()
+		require.NoError(tt, err)
+		require.NoError(tt, w.AddPrivateKey("did:example:123", "did:example:123#key-1", privKey))
+
+		_, err = w.NewSigner("did:example:123#key-1")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "constructing signer for key<did:example:123#key-1>")
+	})
+}
+
+func TestSimpleWalletInitWithKeyAgreement(t *testing.T) {
+	t.Run("did:key", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		didStr, kid, keyAgreementKID, err := w.InitWithKeyAgreement(did.KeyMethod)
+		assert.NoError(tt, err)
+		assert.NotEqual(tt, kid, keyAgreementKID)
+
+		keys, err := w.GetKeysForDID(didStr)
+		assert.NoError(tt, err)
+		assert.Len(tt, keys, 2)
+
+		_, keyAgreementKey, err := w.GetKey(keyAgreementKID)
+		assert.NoError(tt, err)
+		kt, err := crypto.GetKeyTypeFromPrivateKey(keyAgreementKey)
+		assert.NoError(tt, err)
+		assert.Equal(tt, crypto.X25519, kt)
+
+		// the signing key remains usable for signing
+		_, err = w.NewSigner(kid)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("did:peer is not supported", func(tt *testing.T) {
+		w := NewSimpleWallet()
+		_, _, _, err := w.InitWithKeyAgreement(did.PeerMethod)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "key agreement keys are not supported")
+	})
+}
+
+func TestSimpleWalletStats(t *testing.T) {
+	w := NewSimpleWallet()
+	stats := w.Stats()
+	assert.Zero(t, stats.CredentialsAdded)
+	assert.Zero(t, stats.DIDsCreated)
+	assert.Empty(t, stats.DIDsByMethod)
+
+	didStr, kid, err := w.InitReturning(did.KeyMethod)
+	require.NoError(t, err)
+	_, _, _, err = w.InitWithKeyAgreement(did.KeyMethod)
+	require.NoError(t, err)
+	_, _, err = w.InitReturning(did.PeerMethod)
+	require.NoError(t, err)
+	_, err = w.NewSigner(kid)
+	require.NoError(t, err)
+	require.NoError(t, w.AddCredentialJWT("cred-1", "jwt"))
+	assert.Error(t, w.AddCredentialJWT("cred-1", "jwt"))
+	issued, err := w.IssueCredential(didStr, map[string]any{"id": didStr})
+	require.NoError(t, err)
+	require.NoError(t, w.AddCredentialJWT("cred-2", string(issued)))
+	_, err = w.PresentCredentials(didStr, []string{"cred-2"}, nil)
+	require.NoError(t, err)
+
+	stats = w.Stats()
+	assert.Equal(t, 2, stats.CredentialsAdded)
+	assert.Equal(t, 3, stats.DIDsCreated)
+	assert.Equal(t, map[did.Method]int{did.KeyMethod: 2, did.PeerMethod: 1}, stats.DIDsByMethod)
+	assert.Equal(t, 4, stats.KeysStored)
+	assert.Equal(t, 1, stats.SignersCreated)
+	assert.Equal(t, 2, stats.SignaturesProduced)
+
+	// the snapshot is not affected by later operations
+	_, _, err = w.InitReturning(did.KeyMethod)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.DIDsByMethod[did.KeyMethod])
+	assert.Equal(t, 3, w.Stats().DIDsByMethod[did.KeyMethod])
+}
+
+func TestSimpleWalletEnsureDID(t *testing.T) {
+	w := NewSimpleWallet()
+
+	created, err := w.EnsureDID("did:example:123")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	_, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	require.NoError(t, w.AddPrivateKey("did:example:123", "did:example:123#key-1", privKey))
+
+	// running again keeps the existing entry and its keys
+	created, err = w.EnsureDID("did:example:123")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	keys, err := w.GetKeysForDID("did:example:123")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, 1, w.Stats().DIDsCreated)
+
+	// AddDID remains strict
+	assert.ErrorContains(t, w.AddDID("did:example:123"), "already an entry")
+
+	_, err = w.EnsureDID("")
+	assert.ErrorContains(t, err, "did cannot be empty")
+}
@@ -0,0 +1,47 @@
+// Package metrics provides hooks for timing DID resolution and credential verification, so operators can see
+// which DID methods or verification steps are slow. Metrics are threaded through the context given to resolvers
+// and verification functions with NewContext; without them no timings are reported.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+// Metrics receives the timings of resolutions and verifications. Implementations must be safe for concurrent use,
+// as credentials in a presentation are verified concurrently.
+type Metrics interface {
+	// ObserveResolution is called with the time it took to resolve a DID of the method, whether it resolved or not
+	ObserveResolution(method did.Method, duration time.Duration)
+	// ObserveVerification is called with the time it took to verify a credential or presentation of the kind, and
+	// whether it was verified
+	ObserveVerification(kind string, duration time.Duration, ok bool)
+}
+
+// NoOp is the Metrics used when none are in the context, which discards every observation
+type NoOp struct{}
+
+var _ Metrics = NoOp{}
+
+func (NoOp) ObserveResolution(did.Method, time.Duration) {}
+
+func (NoOp) ObserveVerification(string, time.Duration, bool) {}
+
+type contextKey struct{}
+
+// NewContext returns a copy of the context that carries the metrics
+func NewContext(ctx context.Context, m Metrics) context.Context {
+	return context.WithValue(ctx, contextKey{}, m)
+}
+
+// FromContext returns the metrics carried by the context, or NoOp if it carries none
+func FromContext(ctx context.Context) Metrics {
+	if ctx != nil {
+		if m, ok := ctx.Value(contextKey{}).(Metrics); ok && m != nil {
+			return m
+		}
+	}
+	return NoOp{}
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+)
+
+type recordingMetrics struct {
+	methods []did.Method
+}
+
+func (r *recordingMetrics) ObserveResolution(method did.Method, _ time.Duration) {
+	r.methods = append(r.methods, method)
+}
+
+func (r *recordingMetrics) ObserveVerification(string, time.Duration, bool) {}
+
+func TestFromContext(t *testing.T) {
+	t.Run("no metrics in the context", func(tt *testing.T) {
+		assert.Equal(tt, NoOp{}, FromContext(context.Background()))
+		//nolint:staticcheck // a nil context must not panic
+		assert.Equal(tt, NoOp{}, FromContext(nil))
+		assert.Equal(tt, NoOp{}, FromContext(NewContext(context.Background(), nil)))
+	})
+
+	t.Run("metrics in the context", func(tt *testing.T) {
+		m := &recordingMetrics{}
+		ctx := NewContext(context.Background(), m)
+		FromContext(ctx).ObserveResolution(did.KeyMethod, time.Millisecond)
+		assert.Equal(tt, []did.Method{did.KeyMethod}, m.methods)
+	})
+}
@@ -0,0 +1,222 @@
+package sdjwt
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+// vcClaimName is the claim of a Verifiable Credential JWT holding the credential
+const vcClaimName = "vc"
+
+// KeyBindingOptions are the nonce and audience a verifier requires the key binding JWT of an SD-JWT presentation, the
+// Holder Binding JWT of https://www.ietf.org/archive/id/draft-ietf-oauth-selective-disclosure-jwt-04.html#name-holder-binding-jwt,
+// to contain. They bind the presentation to the current transaction and to the verifier, preventing replay.
+type KeyBindingOptions struct {
+	Nonce    string
+	Audience string
+}
+
+// VerifySDJWTPresentation verifies an SD-JWT presentation of a Verifiable Credential JWT, in the Combined Format for
+// Presentation, and returns the credential with only the disclosed claims filled in. The signature of the SD-JWT is
+// verified with the issuer's verifier, and the digest of each disclosure must be found in an _sd array of the signed
+// payload or of another disclosure; a presentation with a disclosure that matches no digest is rejected.
+//
+// A key binding JWT attached to the presentation is verified with the key of the credential subject's DID, resolved
+// with r using the kid of the key binding JWT. When keyBinding is set, the presentation must carry a key binding JWT
+// containing its nonce and audience; otherwise key binding is optional. A subject identified by a URI other than a
+// DID, such as a urn, cannot be bound to, as it has no keys to resolve.
+func VerifySDJWTPresentation(ctx context.Context, presentation []byte, verifier jwx.Verifier, r resolution.Resolver, keyBinding *KeyBindingOptions) (*credential.VerifiableCredential, error) {
+	sdParts := strings.Split(string(presentation), "~")
+	if len(sdParts) < 2 {
+		return nil, errors.New("presentation must contain an SD-JWT followed by disclosures and a key binding JWT separated by ~")
+	}
+	_, sdToken, err := verifier.VerifyAndParse(sdParts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying SD-JWT")
+	}
+	hashAlg, err := GetHashAlg(sdToken)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(sdParts) - 1
+	disclosuresByDigest, err := parseDisclosures(sdParts[1:n], hashAlg)
+	if err != nil {
+		return nil, err
+	}
+	tokenClaims, err := sdToken.AsMap(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "gathering token map")
+	}
+	digestsFound := make(map[string]struct{}, len(disclosuresByDigest))
+	if err = processPayload(tokenClaims, disclosuresByDigest, digestsFound); err != nil {
+		return nil, err
+	}
+	for digest, disclosure := range disclosuresByDigest {
+		if _, ok := digestsFound[digest]; !ok {
+			return nil, errors.Errorf("disclosure of claim<%s> does not match any digest", disclosure.ClaimName)
+		}
+	}
+
+	cred, err := credentialFromClaims(tokenClaims)
+	if err != nil {
+		return nil, errors.Wrap(err, "reconstructing credential from disclosed claims")
+	}
+
+	keyBindingJWT := sdParts[n]
+	if keyBindingJWT == "" {
+		if keyBinding != nil {
+			return nil, errors.New("key binding required, but key binding JWT not found")
+		}
+		return cred, nil
+	}
+	if err = verifyKeyBinding(ctx, keyBindingJWT, cred, r, keyBinding); err != nil {
+		return nil, errors.Wrap(err, "verifying key binding JWT")
+	}
+	return cred, nil
+}
+
+// credentialFromClaims reconstructs the credential of the vc claim, setting the properties the JWT claims stand in for
+func credentialFromClaims(claims map[string]any) (*credential.VerifiableCredential, error) {
+	vcClaim, ok := claims[vcClaimName]
+	if !ok {
+		return nil, errors.Errorf("did not find %s property in token", vcClaimName)
+	}
+	vcBytes, err := json.Marshal(vcClaim)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential claim")
+	}
+	var cred credential.VerifiableCredential
+	if err = json.Unmarshal(vcBytes, &cred); err != nil {
+		return nil, errors.Wrap(err, "reconstructing Verifiable Credential")
+	}
+
+	if jti, ok := claims[jwt.JwtIDKey].(string); ok && jti != "" {
+		cred.ID = jti
+	}
+	if iat, ok := claims[jwt.IssuedAtKey].(time.Time); ok {
+		cred.IssuanceDate = iat.Format(time.RFC3339)
+	}
+	if exp, ok := claims[jwt.ExpirationKey].(time.Time); ok {
+		cred.ExpirationDate = exp.Format(time.RFC3339)
+	}
+	// an issuer object in the credential is kept, as only string issuers are set from the iss claim
+	if iss, ok := claims[jwt.IssuerKey].(string); ok && iss != "" {
+		if _, isObject := cred.Issuer.(map[string]any); !isObject {
+			cred.Issuer = iss
+		}
+	}
+	if sub, ok := claims[jwt.SubjectKey].(string); ok && sub != "" {
+		if cred.CredentialSubject == nil {
+			cred.CredentialSubject = make(map[string]any)
+		}
+		cred.CredentialSubject[credential.VerifiableCredentialIDProperty] = sub
+	}
+	return &cred, nil
+}
+
+// verifyKeyBinding verifies the key binding JWT with the key of the credential subject's DID, and checks its nonce and
+// audience against keyBinding, if set
+func verifyKeyBinding(ctx context.Context, keyBindingJWT string, cred *credential.VerifiableCredential, r resolution.Resolver, keyBinding *KeyBindingOptions) error {
+	holder := cred.CredentialSubject.GetID()
+	if holder == "" {
+		return errors.New("credential subject has no id to bind the presentation to")
+	}
+	if !strings.HasPrefix(holder, "did:") {
+		return errors.Errorf("credential subject<%s> is not a DID to bind the presentation to", holder)
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(keyBindingJWT))
+	if err != nil {
+		return errors.Wrap(err, "getting key binding JWT headers")
+	}
+	kid := headers.KeyID()
+	if kid == "" {
+		return errors.New("key binding JWT has no kid")
+	}
+	holderKey, err := resolution.ResolveKeyForDID(ctx, r, holder, kid)
+	if err != nil {
+		return errors.Wrapf(err, "resolving key<%s> of holder<%s>", kid, holder)
+	}
+	holderKey, algs, err := keyBindingKey(holderKey)
+	if err != nil {
+		return errors.Wrapf(err, "getting algorithm of key<%s> of holder<%s>", kid, holder)
+	}
+	parseOptions := []jwt.ParseOption{jwt.WithValidate(true)}
+	for _, alg := range algs {
+		parseOptions = append(parseOptions, jwt.WithKey(alg, holderKey))
+	}
+	keyBindingToken, err := jwt.Parse([]byte(keyBindingJWT), parseOptions...)
+	if err != nil {
+		return errors.Wrap(err, "parsing and validating key binding JWT")
+	}
+	if keyBinding == nil {
+		return nil
+	}
+
+	if nonce, ok := keyBindingToken.Get("nonce"); !ok || nonce != keyBinding.Nonce {
+		return errors.Errorf("nonce does not match desired nonce<%s>", keyBinding.Nonce)
+	}
+	for _, audience := range keyBindingToken.Audience() {
+		if audience == keyBinding.Audience {
+			return nil
+		}
+	}
+	return errors.Errorf("desired audience<%s> not found", keyBinding.Audience)
+}
+
+// keyBindingKey converts the holder's key to one the jwx library verifies with, and returns the algorithms of the key,
+// which the key binding JWT is verified with in place of the alg of its header. RSA keys, which declare no algorithm
+// here, verify with both PS256 and RS256.
+func keyBindingKey(key gocrypto.PublicKey) (gocrypto.PublicKey, []jwa.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return k, []jwa.SignatureAlgorithm{jwa.EdDSA}, nil
+	case secp256k1.PublicKey:
+		return k.ToECDSA(), []jwa.SignatureAlgorithm{jwa.ES256K}, nil
+	case *secp256k1.PublicKey:
+		if k != nil {
+			return k.ToECDSA(), []jwa.SignatureAlgorithm{jwa.ES256K}, nil
+		}
+	case ecdsa.PublicKey:
+		return keyBindingKey(&k)
+	case *ecdsa.PublicKey:
+		if k != nil && k.Curve != nil {
+			if alg, ok := ecdsaAlgorithms[k.Curve.Params().Name]; ok {
+				return k, []jwa.SignatureAlgorithm{alg}, nil
+			}
+			return nil, nil, errors.Errorf("unsupported EC curve<%s>", k.Curve.Params().Name)
+		}
+	case rsa.PublicKey:
+		return keyBindingKey(&k)
+	case *rsa.PublicKey:
+		if k != nil {
+			return k, []jwa.SignatureAlgorithm{jwa.PS256, jwa.RS256}, nil
+		}
+	default:
+		return nil, nil, errors.Errorf("unsupported key type<%T>", key)
+	}
+	return nil, nil, errors.New("key cannot be empty")
+}
+
+// ecdsaAlgorithms are the algorithms of ECDSA keys by the name of their curve
+var ecdsaAlgorithms = map[string]jwa.SignatureAlgorithm{
+	"P-256":     jwa.ES256,
+	"P-384":     jwa.ES384,
+	"P-521":     jwa.ES512,
+	"secp256k1": jwa.ES256K,
+}
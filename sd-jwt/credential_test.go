@@ -0,0 +1,177 @@
+package sdjwt
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+)
+
+func TestVerifySDJWTPresentation(t *testing.T) {
+	issuerSigner := createSigner(t)
+	verifier, err := issuerSigner.ToVerifier(issuerSigner.ID)
+	require.NoError(t, err)
+	resolver, err := resolution.NewResolver([]resolution.Resolver{key.Resolver{}}...)
+	require.NoError(t, err)
+
+	holderPrivKey, holderDID, err := key.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expandedHolderDID, err := holderDID.Expand()
+	require.NoError(t, err)
+	holderKID := expandedHolderDID.VerificationMethod[0].ID
+	holderSigner, err := jwx.NewJWXSigner(holderDID.String(), holderKID, holderPrivKey)
+	require.NoError(t, err)
+
+	claimsData := getTestCredentialClaims(t, credential.VerifiableCredential{
+		Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+		ID:           "urn:uuid:sd-jwt",
+		Type:         []string{credential.VerifiableCredentialType},
+		Issuer:       issuerSigner.ID,
+		IssuanceDate: "2021-01-01T00:00:00Z",
+		CredentialSubject: map[string]any{
+			"id":           holderDID.String(),
+			"name":         "John Doe",
+			"emailAddress": "johndoe@example.com",
+		},
+	})
+	sdjwtSigner := NewSDJWTSigner(&lestratSigner{*issuerSigner}, NewSaltGenerator(16))
+	issuance, err := sdjwtSigner.BlindAndSign(claimsData, map[string]BlindOption{
+		"vc": SubClaimBlindOption{claimsToBlind: map[string]BlindOption{
+			"credentialSubject": SubClaimBlindOption{claimsToBlind: map[string]BlindOption{
+				"name":         FlatBlindOption{},
+				"emailAddress": FlatBlindOption{},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+	disclosed, err := SelectDisclosures(issuance, map[string]struct{}{"emailAddress": {}})
+	require.NoError(t, err)
+
+	keyBinding := KeyBindingOptions{Nonce: "my_sample_nonce", Audience: "my_intended_aud"}
+	signKeyBinding := func(tt *testing.T, signer *jwx.Signer, nonce string) []byte {
+		keyBindingJWT, err := signer.SignWithDefaults(map[string]any{"nonce": nonce, "aud": keyBinding.Audience})
+		require.NoError(tt, err)
+		return keyBindingJWT
+	}
+
+	t.Run("only disclosed claims are filled in", func(tt *testing.T) {
+		presentation := CreatePresentation(issuance, disclosed, nil)
+		cred, err := VerifySDJWTPresentation(context.Background(), presentation, *verifier, resolver, nil)
+		require.NoError(tt, err)
+		assert.Equal(tt, "urn:uuid:sd-jwt", cred.ID)
+		assert.Equal(tt, issuerSigner.ID, cred.Issuer)
+		assert.Equal(tt, credential.CredentialSubject{
+			"id":           holderDID.String(),
+			"emailAddress": "johndoe@example.com",
+		}, cred.CredentialSubject)
+	})
+
+	t.Run("key binding", func(tt *testing.T) {
+		presentation := CreatePresentation(issuance, disclosed, signKeyBinding(tt, holderSigner, keyBinding.Nonce))
+		cred, err := VerifySDJWTPresentation(context.Background(), presentation, *verifier, resolver, &keyBinding)
+		require.NoError(tt, err)
+		assert.Equal(tt, "johndoe@example.com", cred.CredentialSubject["emailAddress"])
+
+		// a key binding JWT is verified even if it is not required
+		_, err = VerifySDJWTPresentation(context.Background(), presentation, *verifier, resolver, nil)
+		assert.NoError(tt, err)
+
+		_, err = VerifySDJWTPresentation(context.Background(), CreatePresentation(issuance, disclosed, nil), *verifier, resolver, &keyBinding)
+		assert.ErrorContains(tt, err, "key binding JWT not found")
+
+		presentation = CreatePresentation(issuance, disclosed, signKeyBinding(tt, holderSigner, "another_nonce"))
+		_, err = VerifySDJWTPresentation(context.Background(), presentation, *verifier, resolver, &keyBinding)
+		assert.ErrorContains(tt, err, "nonce does not match")
+
+		otherPrivKey, _, err := key.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		impostor, err := jwx.NewJWXSigner(holderDID.String(), holderKID, otherPrivKey)
+		require.NoError(tt, err)
+		presentation = CreatePresentation(issuance, disclosed, signKeyBinding(tt, impostor, keyBinding.Nonce))
+		_, err = VerifySDJWTPresentation(context.Background(), presentation, *verifier, resolver, nil)
+		assert.ErrorContains(tt, err, "verifying key binding JWT")
+	})
+
+	t.Run("key binding with a P-256 key", func(tt *testing.T) {
+		p256PrivKey, p256DID, err := key.GenerateDIDKey(crypto.P256)
+		require.NoError(tt, err)
+		expandedP256DID, err := p256DID.Expand()
+		require.NoError(tt, err)
+		p256KID := expandedP256DID.VerificationMethod[0].ID
+		p256Signer, err := jwx.NewJWXSigner(p256DID.String(), p256KID, p256PrivKey)
+		require.NoError(tt, err)
+		p256Issuance, err := sdjwtSigner.BlindAndSign(getTestCredentialClaims(tt, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{credential.VerifiableCredentialType},
+			Issuer:            issuerSigner.ID,
+			IssuanceDate:      "2021-01-01T00:00:00Z",
+			CredentialSubject: map[string]any{"id": p256DID.String()},
+		}), nil)
+		require.NoError(tt, err)
+
+		presentation := CreatePresentation(p256Issuance, nil, signKeyBinding(tt, p256Signer, keyBinding.Nonce))
+		cred, err := VerifySDJWTPresentation(context.Background(), presentation, *verifier, resolver, &keyBinding)
+		require.NoError(tt, err)
+		assert.Equal(tt, p256DID.String(), cred.CredentialSubject.GetID())
+	})
+
+	t.Run("key binding to a subject that is not a DID", func(tt *testing.T) {
+		urnIssuance, err := sdjwtSigner.BlindAndSign(getTestCredentialClaims(tt, credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{credential.VerifiableCredentialType},
+			Issuer:            issuerSigner.ID,
+			IssuanceDate:      "2021-01-01T00:00:00Z",
+			CredentialSubject: map[string]any{"id": "urn:uuid:7d3f9c2e-5b1a-4c8e-9f0d-2a6b4e8c1d3f"},
+		}), nil)
+		require.NoError(tt, err)
+
+		cred, err := VerifySDJWTPresentation(context.Background(), CreatePresentation(urnIssuance, nil, nil), *verifier, resolver, nil)
+		require.NoError(tt, err)
+		assert.Equal(tt, "urn:uuid:7d3f9c2e-5b1a-4c8e-9f0d-2a6b4e8c1d3f", cred.CredentialSubject.GetID())
+
+		presentation := CreatePresentation(urnIssuance, nil, signKeyBinding(tt, holderSigner, keyBinding.Nonce))
+		_, err = VerifySDJWTPresentation(context.Background(), presentation, *verifier, resolver, &keyBinding)
+		assert.ErrorContains(tt, err, "is not a DID to bind the presentation to")
+	})
+
+	t.Run("disclosure without a digest is rejected", func(tt *testing.T) {
+		extra, err := Disclosure{Salt: "_26bc4LT-ac6q2KI6cBW5es", ClaimName: "name", ClaimValue: "Jane Doe"}.EncodedDisclosure()
+		require.NoError(tt, err)
+		sdJWT, disclosures, _ := strings.Cut(string(CreatePresentation(issuance, disclosed, nil)), "~")
+		presentation := sdJWT + "~" + extra + "~" + disclosures
+
+		_, err = VerifySDJWTPresentation(context.Background(), []byte(presentation), *verifier, resolver, nil)
+		assert.ErrorContains(tt, err, "disclosure of claim<name> does not match any digest")
+	})
+
+	t.Run("invalid SD-JWT signature", func(tt *testing.T) {
+		otherSigner := createSigner(tt)
+		otherVerifier, err := otherSigner.ToVerifier(otherSigner.ID)
+		require.NoError(tt, err)
+		_, err = VerifySDJWTPresentation(context.Background(), CreatePresentation(issuance, disclosed, nil), *otherVerifier, resolver, nil)
+		assert.ErrorContains(tt, err, "verifying SD-JWT")
+
+		_, err = VerifySDJWTPresentation(context.Background(), []byte("not a presentation"), *verifier, resolver, nil)
+		assert.ErrorContains(tt, err, "separated by ~")
+	})
+}
+
+// getTestCredentialClaims returns the claims of a Verifiable Credential JWT of cred, as JSON
+func getTestCredentialClaims(t *testing.T, cred credential.VerifiableCredential) []byte {
+	claims := map[string]any{"iss": cred.Issuer, "sub": cred.CredentialSubject.GetID(), "vc": cred}
+	if cred.ID != "" {
+		claims["jti"] = cred.ID
+	}
+	claimsData, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return claimsData
+}
@@ -480,7 +480,7 @@ func VerifySDPresentation(presentation []byte, verificationOptions VerificationO
 
 	if verificationOptions.HolderBindingOption == VerifyHolderBinding {
 		// If Holder Binding JWT is not provided, the Verifier MUST reject the Presentation.
-		holderBindingJWT := sdParts[len(presentation)-1]
+		holderBindingJWT := sdParts[len(sdParts)-1]
 		if len(holderBindingJWT) == 0 {
 			return nil, errors.New("holder binding required, but holder binding JWT not found")
 		}
@@ -528,11 +528,8 @@ func processPayload(claims map[string]any, disclosuresByDigest map[string]*Discl
 
 	// Find all _sd keys in the SD-JWT payload. For each such key perform the following steps (*):
 	for _, claimValue := range claims {
-		switch claimMap := claimValue.(type) {
-		case map[string]any:
-			if err := processPayload(claimMap, disclosuresByDigest, digestsFound); err != nil {
-				return err
-			}
+		if err := processValue(claimValue, disclosuresByDigest, digestsFound); err != nil {
+			return err
 		}
 	}
 	sdClaimValue, ok := claims[sdClaimName]
@@ -574,10 +571,8 @@ func processPayload(claims map[string]any, disclosuresByDigest map[string]*Discl
 		newClaims[disclosure.ClaimName] = disclosure.ClaimValue
 
 		//  If the decoded value contains an _sd key in an object, recursively process the key using the steps described in (*).
-		if decodedClaim, ok := disclosure.ClaimValue.(map[string]any); ok {
-			if err := processPayload(decodedClaim, disclosuresByDigest, digestsFound); err != nil {
-				return err
-			}
+		if err := processValue(disclosure.ClaimValue, disclosuresByDigest, digestsFound); err != nil {
+			return err
 		}
 	}
 
@@ -590,6 +585,21 @@ func processPayload(claims map[string]any, disclosuresByDigest map[string]*Discl
 	return nil
 }
 
+// processValue processes the _sd keys of the objects within value, which may be an object or an array of values.
+func processValue(value any, disclosuresByDigest map[string]*Disclosure, digestsFound map[string]struct{}) error {
+	switch v := value.(type) {
+	case map[string]any:
+		return processPayload(v, disclosuresByDigest, digestsFound)
+	case []any:
+		for _, elem := range v {
+			if err := processValue(elem, disclosuresByDigest, digestsFound); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 type IssuanceVerificationOptions struct {
 	alg       string
 	issuerKey gocrypto.PublicKey
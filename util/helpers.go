@@ -80,39 +80,63 @@ func NewLDProcessor() (*LDProcessor, error) {
 	}, nil
 }
 
+// knownContexts are the contexts bundled with this module, by URL
+var knownContexts = []struct {
+	url      string
+	contents string
+}{
+	{url: "https://www.w3.org/2018/credentials/v1", contents: w3c2018CredentialsV1},
+	{url: "https://www.w3.org/2018/credentials/examples/v1", contents: w3c2018CredentialsExamplesV1},
+	{url: "https://www.w3.org/ns/did/v1", contents: w3cNamespaceDIDV1},
+	{url: "https://w3c.github.io/vc-di-bbs/contexts/v1", contents: w3cVCDIBBSV1},
+	{url: "https://w3id.org/security/suites/jws-2020/v1", contents: w3cJWS2020V1},
+	{url: "https://w3id.org/security/v1", contents: w3idSecurityV1},
+	{url: "https://w3id.org/security/v2", contents: w3idSecurityV2},
+	{url: "https://w3id.org/citizenship/v1", contents: w3idCitizenshipV1},
+	{url: "https://www.w3.org/ns/odrl.jsonld", contents: w3NamespaceODRL},
+}
+
 func NewLDDocumentLoader() (*ld.CachingDocumentLoader, error) {
 	rfcDocLoader := ld.NewRFC7324CachingDocumentLoader(nil)
 	docLoader := ld.NewCachingDocumentLoader(rfcDocLoader)
 
 	// We cache the contexts we know we'll use over and over.
-	if err := preloadContext(docLoader, w3c2018CredentialsV1, "https://www.w3.org/2018/credentials/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3c2018CredentialsExamplesV1, "https://www.w3.org/2018/credentials/examples/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3cNamespaceDIDV1, "https://www.w3.org/ns/did/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3cVCDIBBSV1, "https://w3c.github.io/vc-di-bbs/contexts/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3cJWS2020V1, "https://w3id.org/security/suites/jws-2020/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3idSecurityV1, "https://w3id.org/security/v1"); err != nil {
-		return nil, err
-	}
-	if err := preloadContext(docLoader, w3idSecurityV2, "https://w3id.org/security/v2"); err != nil {
-		return nil, err
+	for _, known := range knownContexts {
+		if err := preloadContext(docLoader, known.contents, known.url); err != nil {
+			return nil, err
+		}
 	}
-	if err := preloadContext(docLoader, w3idCitizenshipV1, "https://w3id.org/citizenship/v1"); err != nil {
-		return nil, err
+	return docLoader, nil
+}
+
+// OfflineLDDocumentLoader is a JSON-LD document loader serving only the contexts bundled with this module. It never
+// fetches remote documents, which makes it suitable for processing documents from untrusted sources.
+type OfflineLDDocumentLoader struct {
+	documents map[string]*ld.RemoteDocument
+}
+
+var _ ld.DocumentLoader = (*OfflineLDDocumentLoader)(nil)
+
+// NewOfflineLDDocumentLoader creates a document loader for the contexts bundled with this module
+func NewOfflineLDDocumentLoader() (*OfflineLDDocumentLoader, error) {
+	documents := make(map[string]*ld.RemoteDocument, len(knownContexts))
+	for _, known := range knownContexts {
+		document, err := ld.DocumentFromReader(strings.NewReader(known.contents))
+		if err != nil {
+			return nil, fmt.Errorf("parsing context<%s>: %w", known.url, err)
+		}
+		documents[known.url] = &ld.RemoteDocument{DocumentURL: known.url, Document: document}
 	}
-	if err := preloadContext(docLoader, w3NamespaceODRL, "https://www.w3.org/ns/odrl.jsonld"); err != nil {
-		return nil, err
+	return &OfflineLDDocumentLoader{documents: documents}, nil
+}
+
+// LoadDocument returns the bundled context with the given URL, or an error if the context is not bundled
+func (l OfflineLDDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	document, ok := l.documents[u]
+	if !ok {
+		return nil, ld.NewJsonLdError(ld.LoadingDocumentFailed, fmt.Sprintf("context<%s> is not known offline", u))
 	}
-	return docLoader, nil
+	return document, nil
 }
 
 func preloadContext(docLoader *ld.CachingDocumentLoader, contents string, url string) error {